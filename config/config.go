@@ -119,20 +119,40 @@ func ConnectDB() {
 	END$$;
 	`)
 
+	// NOTE - เพิ่มค่าใหม่ของ enum ให้ database ที่สร้าง enum ไปก่อนหน้านี้แล้ว
+	DB.Exec(`ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'refunded'`)
+	DB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'complete'`)
+	DB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'refunded'`)
+	DB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'partially_refunded'`)
+
+
+	if err := prepareVariantSKUIndex(DB); err != nil {
+		log.Fatal("Failed to prepare variant SKU index:", err)
+	}
 
 	// NOTE - AutoMigrate จะตรวจสอบและอัปเดตฐานข้อมูล
 	err = DB.AutoMigrate(
 		&models.CartItem{},   // NOTE - ให้ตรวจสอบตาราง CartItem
 		&models.Category{},   // NOTE - ให้ตรวจสอบตาราง Category
 		&models.Coupon{},   // NOTE - ให้ตรวจสอบตาราง Coupon
+		&models.CouponRedemption{},   // NOTE - ให้ตรวจสอบตาราง CouponRedemption
 		&models.Order{},   // NOTE - ให้ตรวจสอบตาราง Order
 		&models.OrderItem{},   // NOTE - ให้ตรวจสอบตาราง OrderItem
+		&models.OrderStatusEvent{},   // NOTE - ให้ตรวจสอบตาราง OrderStatusEvent
+		&models.StockReservation{},   // NOTE - ให้ตรวจสอบตาราง StockReservation
 		&models.Payment{},   // NOTE - ให้ตรวจสอบตาราง Payment
+		&models.StripeEvent{},   // NOTE - ให้ตรวจสอบตาราง StripeEvent
+		&models.ReturnRequest{},   // NOTE - ให้ตรวจสอบตาราง ReturnRequest
 		&models.Product{},   // NOTE - ให้ตรวจสอบตาราง Product
 		&models.ProductVariant{}, // NOTE - ให้ตรวจสอบตาราง ProductVariant
+		&models.ProductSlugRedirect{}, // NOTE - ให้ตรวจสอบตาราง ProductSlugRedirect
+		&models.InventoryMovement{}, // NOTE - ให้ตรวจสอบตาราง InventoryMovement
+		&models.Shipment{},   // NOTE - ให้ตรวจสอบตาราง Shipment
+		&models.ShipmentItem{},   // NOTE - ให้ตรวจสอบตาราง ShipmentItem
 		&models.Review{},   // NOTE - ให้ตรวจสอบตาราง Review
 		&models.User{},   // NOTE - ให้ตรวจสอบตาราง User
 		&models.ProductImage{}, // NOTE - ให้ตรวจสอบตาราง ProductImage
+		&models.ProductImageThumbnail{}, // NOTE - ให้ตรวจสอบตาราง ProductImageThumbnail
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
@@ -199,20 +219,40 @@ func ConnectTestDB() {
 	END$$;
 	`)
 
+	// NOTE - เพิ่มค่าใหม่ของ enum ให้ database ที่สร้าง enum ไปก่อนหน้านี้แล้ว
+	TestDB.Exec(`ALTER TYPE payment_status ADD VALUE IF NOT EXISTS 'refunded'`)
+	TestDB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'complete'`)
+	TestDB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'refunded'`)
+	TestDB.Exec(`ALTER TYPE status ADD VALUE IF NOT EXISTS 'partially_refunded'`)
+
+
+	if err := prepareVariantSKUIndex(TestDB); err != nil {
+		log.Fatal("Failed to prepare variant SKU index:", err)
+	}
 
 	// NOTE - AutoMigrate จะตรวจสอบและอัปเดตฐานข้อมูล
 	err = TestDB.AutoMigrate(
 		&models.CartItem{},   // NOTE - ให้ตรวจสอบตาราง CartItem
 		&models.Category{},   // NOTE - ให้ตรวจสอบตาราง Category
 		&models.Coupon{},   // NOTE - ให้ตรวจสอบตาราง Coupon
+		&models.CouponRedemption{},   // NOTE - ให้ตรวจสอบตาราง CouponRedemption
 		&models.Order{},   // NOTE - ให้ตรวจสอบตาราง Order
 		&models.OrderItem{},   // NOTE - ให้ตรวจสอบตาราง OrderItem
+		&models.OrderStatusEvent{},   // NOTE - ให้ตรวจสอบตาราง OrderStatusEvent
+		&models.StockReservation{},   // NOTE - ให้ตรวจสอบตาราง StockReservation
 		&models.Payment{},   // NOTE - ให้ตรวจสอบตาราง Payment
+		&models.StripeEvent{},   // NOTE - ให้ตรวจสอบตาราง StripeEvent
+		&models.ReturnRequest{},   // NOTE - ให้ตรวจสอบตาราง ReturnRequest
 		&models.Product{},   // NOTE - ให้ตรวจสอบตาราง Product
 		&models.ProductVariant{}, // NOTE - ให้ตรวจสอบตาราง ProductVariant
+		&models.ProductSlugRedirect{}, // NOTE - ให้ตรวจสอบตาราง ProductSlugRedirect
+		&models.InventoryMovement{}, // NOTE - ให้ตรวจสอบตาราง InventoryMovement
+		&models.Shipment{},   // NOTE - ให้ตรวจสอบตาราง Shipment
+		&models.ShipmentItem{},   // NOTE - ให้ตรวจสอบตาราง ShipmentItem
 		&models.Review{},   // NOTE - ให้ตรวจสอบตาราง Review
 		&models.User{},   // NOTE - ให้ตรวจสอบตาราง User
 		&models.ProductImage{}, // NOTE - ให้ตรวจสอบตาราง ProductImage
+		&models.ProductImageThumbnail{}, // NOTE - ให้ตรวจสอบตาราง ProductImageThumbnail
 	)
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"gorm.io/gorm"
+)
+
+const variantSKUIndex = "idx_product_variants_active_sku"
+
+// NOTE - เตรียมข้อมูลก่อน AutoMigrate สร้าง unique index ของ SKU ไม่อย่างนั้นแถวที่ซ้ำกันอยู่แล้วทำให้ migrate พังและ server เปิดไม่ขึ้น
+// SKU ที่ซ้ำกันใน variant ที่ยังขายอยู่ ตัวที่ id น้อยสุดได้ SKU เดิม ตัวอื่นต่อท้ายด้วย -dup-<id> และ log ไว้ให้ admin แก้
+func prepareVariantSKUIndex(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.ProductVariant{}) || !db.Migrator().HasColumn(&models.ProductVariant{}, "SKU") {
+		return nil
+	}
+
+	// NOTE - index รุ่นแรกไม่ได้ยกเว้น SKU ว่าง ลบทิ้งให้ AutoMigrate สร้างใหม่ตามเงื่อนไขปัจจุบัน
+	var indexDefs []string
+	if err := db.Raw("SELECT indexdef FROM pg_indexes WHERE indexname = ?", variantSKUIndex).Scan(&indexDefs).Error; err != nil {
+		return err
+	}
+
+	if len(indexDefs) > 0 && !strings.Contains(indexDefs[0], "<> ''") {
+		if err := db.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", variantSKUIndex)).Error; err != nil {
+			return err
+		}
+	}
+
+	var duplicates []models.ProductVariant
+	err := db.Raw(`
+	SELECT v.id, v.sku FROM product_variants v
+	WHERE v.deleted_at IS NULL AND v.sku <> '' AND EXISTS (
+		SELECT 1 FROM product_variants o
+		WHERE o.deleted_at IS NULL AND o.sku = v.sku AND o.id < v.id
+	)
+	ORDER BY v.id`).Scan(&duplicates).Error
+	if err != nil {
+		return err
+	}
+
+	for _, variant := range duplicates {
+		newSKU := fmt.Sprintf("%s-dup-%d", variant.SKU, variant.ID)
+		if err := db.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).Update("sku", newSKU).Error; err != nil {
+			return err
+		}
+		log.Printf("Variant %d had duplicate SKU '%s', renamed to '%s'", variant.ID, variant.SKU, newSKU)
+	}
+
+	return nil
+}
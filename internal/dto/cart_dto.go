@@ -0,0 +1,41 @@
+package dto
+
+type AddCartItemDTO struct {
+	VariantID uint `json:"variantID" validate:"required"`
+	Quantity  uint `json:"quantity" validate:"required,min=1"`
+}
+
+type UpdateCartItemDTO struct {
+	Quantity uint `json:"quantity" validate:"required,min=1"`
+}
+
+type CartItemResponseDTO struct {
+	ID          uint    `json:"id"`
+	VariantID   uint    `json:"variantID"`
+	ProductID   uint    `json:"productId"`
+	ProductName string  `json:"productName"`
+	Size        string  `json:"size"`
+	SKU         string  `json:"sku"`
+	Stock       int     `json:"stock"`
+	Quantity    uint    `json:"quantity"`
+	Price       float64 `json:"price"`
+	FinalPrice  float64 `json:"finalPrice"`
+	LineTotal   float64 `json:"lineTotal"`
+}
+
+type CartResponseDTO struct {
+	Items      []CartItemResponseDTO `json:"items"`
+	TotalItems uint                  `json:"totalItems"`
+	TotalPrice float64               `json:"totalPrice"`
+}
+
+type CartCheckoutDTO struct {
+	CouponCode  string `json:"couponCode"`
+	FullName    string `json:"fullName" validate:"required"`
+	Phone       string `json:"phone" validate:"required"`
+	Address     string `json:"address" validate:"required"`
+	Province    string `json:"province" validate:"required"`
+	District    string `json:"district" validate:"required"`
+	Subdistrict string `json:"subdistrict" validate:"required"`
+	Zipcode     string `json:"zipcode" validate:"required"`
+}
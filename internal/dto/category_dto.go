@@ -3,20 +3,45 @@ package dto
 // NOTE - Category DTOs
 
 type CategoryCreateDTO struct {
-	Name string `json:"name" validate:"required,min=2,max=100"`
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	ParentID *uint  `json:"parentId"`
 }
 
 type CategoryCreateResponseDTO struct {
-	ID   uint   `json:"id" validate:"required"`
-	Name string `json:"name" validate:"required,min=2,max=100"`
-	Slug string `json:"slug"`
+	ID       uint   `json:"id" validate:"required"`
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parentId"`
 }
 
+// NOTE - ParentID เป็น nil คือย้ายไปเป็น category ระดับบนสุด
 type UpdateCategoryDTO struct {
-	Name string `json:"name" validate:"required,min=2,max=100"`
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	ParentID *uint  `json:"parentId"`
 }
 
 type UpdateCategoryResponseDTO struct {
-	Name string `json:"name" validate:"required,min=2,max=100"`
+	Name     string `json:"name" validate:"required,min=2,max=100"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parentId"`
+}
+
+type CategoryTreeDTO struct {
+	ID       uint              `json:"id"`
+	Name     string            `json:"name"`
+	Slug     string            `json:"slug"`
+	Children []CategoryTreeDTO `json:"children"`
+}
+
+// NOTE - เรียงจาก category บนสุดลงมาถึง category ของสินค้า
+type CategoryBreadcrumbDTO struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
 	Slug string `json:"slug"`
+}
+
+// NOTE - source จะถูกลบหลังย้ายทุกอย่างไปที่ target
+type MergeCategoryDTO struct {
+	SourceID uint `json:"sourceId" validate:"required"`
+	TargetID uint `json:"targetId" validate:"required"`
 }
\ No newline at end of file
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+)
+
+type CouponRequestDTO struct {
+	Code                  string              `json:"code" validate:"required,min=3,max=50"`
+	DiscountType          models.DiscountType `json:"discountType" validate:"required,oneof=percentage fixed"`
+	DiscountAmount        float64             `json:"discountAmount" validate:"required,gt=0"`
+	MaxDiscount           float64             `json:"maxDiscount" validate:"min=0"`
+	MinOrderAmount        float64             `json:"minOrderAmount" validate:"min=0"`
+	MaxRedemptions        int                 `json:"maxRedemptions" validate:"min=0"`
+	MaxRedemptionsPerUser int                 `json:"maxRedemptionsPerUser" validate:"min=0"`
+	StartAt               *time.Time          `json:"startAt"`
+	ExpiredAt             time.Time           `json:"expiredAt" validate:"required"`
+	CategoryIDs           []uint              `json:"categoryIds"`
+	ProductIDs            []uint              `json:"productIds"`
+}
+
+type CouponResponseDTO struct {
+	ID                    uint                `json:"id"`
+	Code                  string              `json:"code"`
+	DiscountType          models.DiscountType `json:"discountType"`
+	DiscountAmount        float64             `json:"discountAmount"`
+	MaxDiscount           float64             `json:"maxDiscount"`
+	MinOrderAmount        float64             `json:"minOrderAmount"`
+	MaxRedemptions        int                 `json:"maxRedemptions"`
+	MaxRedemptionsPerUser int                 `json:"maxRedemptionsPerUser"`
+	StartAt               *time.Time          `json:"startAt"`
+	ExpiredAt             time.Time           `json:"expiredAt"`
+	CategoryIDs           []uint              `json:"categoryIds"`
+	ProductIDs            []uint              `json:"productIds"`
+	RedemptionCount       int64               `json:"redemptionCount"`
+}
+
+type CouponRedemptionResponseDTO struct {
+	OrderID        uint          `json:"orderId"`
+	UserID         uint          `json:"userId"`
+	DiscountAmount float64       `json:"discountAmount"`
+	OrderStatus    models.Status `json:"orderStatus"`
+	CreatedAt      string        `json:"createdAt"`
+}
@@ -17,6 +17,7 @@ type DashboardSummaryDTO struct {
 	StatusPaid            int     `json:"statusPaid"`
 	StatusShipped         int     `json:"statusShipped"`
 	StatusCancel          int     `json:"statusCancel"`
+	AvgHoursToShip        float64 `json:"avgHoursToShip"`
 }
 
 type TopProductDTO struct {
@@ -0,0 +1,41 @@
+package dto
+
+type AdjustStockDTO struct {
+	Delta int    `json:"delta" validate:"required"` //NOTE - บวกคือเพิ่ม stock ลบคือลด stock
+	Note  string `json:"note" validate:"required"`
+}
+
+type InventoryMovementDTO struct {
+	ID               uint   `json:"id"`
+	ProductVariantID uint   `json:"productVariantId"`
+	Delta            int    `json:"delta"`
+	StockAfter       int    `json:"stockAfter"`
+	Reason           string `json:"reason"`
+	OrderID          *uint  `json:"orderId"`
+	ActorUserID      *uint  `json:"actorUserId"`
+	Note             string `json:"note"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+type UpdateReorderThresholdDTO struct {
+	ReorderThreshold *int `json:"reorderThreshold" validate:"required,gte=0"`
+}
+
+type LowStockVariantDTO struct {
+	VariantID        uint    `json:"variantId"`
+	ProductID        uint    `json:"productId"`
+	ProductName      string  `json:"productName"`
+	Size             string  `json:"size"`
+	SKU              string  `json:"sku"`
+	Stock            int     `json:"stock"`
+	ReorderThreshold int     `json:"reorderThreshold"`
+	OutOfStock       bool    `json:"outOfStock"`
+	UnitsSold        int64   `json:"unitsSold"`     //NOTE - จำนวนที่ขายได้ในช่วงที่ใช้คำนวณ velocity
+	DailyVelocity    float64 `json:"dailyVelocity"` //NOTE - ขายได้เฉลี่ยต่อวัน
+}
+
+type UpdateVariantBySKUDTO struct {
+	Stock *int     `json:"stock" validate:"omitempty,min=0"`
+	Price *float64 `json:"price" validate:"omitempty,gt=0"`
+	Note  string   `json:"note"`
+}
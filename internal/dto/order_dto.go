@@ -8,7 +8,7 @@ type CreateOrderItemDTO struct{
 }
 
 type CreateOrderRequestDTO struct {
-	Counpon *uint `json:"counponID"`
+	CouponCode string `json:"couponCode"`
 	FullName string `json:"fullName"`
 	Phone string `json:phone`
 	Address string `json:address`
@@ -31,6 +31,7 @@ type OrderResponseDTO struct {
 	Zipcode    string 				   `json:zipcode`
 	Coupon     uint		   			   `json:"coupon"`
 	Status     models.Status           `json:"status"`
+	Discount   float64                 `json:"discount"`
 	TotalPrice float64                 `json:"totalPrice"`
 	Items      []OrderItemResponseDTO  `json:"items"`
 }
@@ -63,8 +64,10 @@ type OrderByIDResponseDTO struct {
 	Zipcode    string 				   `json:"zipcode"`
 	Coupon     models.Coupon           `json:"coupon"`
 	OrderItem  []OrderItemResponseDTO  `json:"orderItem"`
+	Discount    float64 `json:"discount"`
 	TotalPrice  float64 `json:"totalPrice"`
 	PaymentExpireAt string             `json:"paymentExpireAt"`
+	Shipments   []ShipmentResponseDTO  `json:"shipments"`
 	CreatedAt string `json:"createdAt"`
 }
 
@@ -78,6 +81,7 @@ type OrderListResponseDTO struct {
 
 type UpdateStatusByUserOrderDTO struct {
 	Status  models.Status  `json:"status" validate:"required"`
+	Note    string         `json:"note"` //NOTE - เหตุผลการเปลี่ยนสถานะ บันทึกลง timeline (ใช้ฝั่ง admin)
 }
 
 type OrderListDataTableDTOResponse struct {
@@ -87,4 +91,13 @@ type OrderListDataTableDTOResponse struct {
 	Status     models.Status `json:"status"`
 	TotalPrice  float64 `json:"totalPrice"`
 	OrderItem  []OrderItemResponseDTO  `json:"orderItem"`
-}
\ No newline at end of file
+}
+type OrderStatusEventDTO struct {
+	FromStatus  models.Status `json:"fromStatus"`
+	ToStatus    models.Status `json:"toStatus"`
+	ActorUserID *uint         `json:"actorUserId"`
+	ActorRole   string        `json:"actorRole"`
+	Source      string        `json:"source"`
+	Note        string        `json:"note"`
+	CreatedAt   string        `json:"createdAt"`
+}
@@ -1,7 +1,19 @@
 package dto
 
+import "github.com/Beluga-Whale/ecommerce-api/internal/models"
+
 type CreatePaymentIntentRequestDTO struct {
-	Amount  int64 `json:"amount"`
-	OrderID uint  `json:"orderId"`
-	UserID  uint  `json:"userId"`
-}
\ No newline at end of file
+	OrderID uint `json:"orderId" validate:"required"`
+}
+
+type PaymentResponseDTO struct {
+	ID              uint                 `json:"id"`
+	OrderID         uint                 `json:"orderId"`
+	PaymentIntentID string               `json:"paymentIntentId"`
+	Amount          float64              `json:"amount"`
+	Currency        string               `json:"currency"`
+	Status          models.StatusPayment `json:"status"`
+	FailureMessage  string               `json:"failureMessage"`
+	PaidAt          string               `json:"paidAt"`
+	CreatedAt       string               `json:"createdAt"`
+}
@@ -1,54 +1,65 @@
 package dto
 
 type ProductCreateDTO struct {
-	Name        string              `json:"name" validate:"required,min=2,max=100"`
-	Title       string              `json:"title" validate:"required,min=2,max=100"`
-	Description string              `json:"description" validate:"required,min=10`
-	Images      []ProductImageDTO   `json:"images" validate:"required,dive"`
-	Variants    []ProductVariantDTO `json:"variants" validate:"required,dive"`
-	IsFeatured  bool                `json:"isFeatured" validate:"omitempty"`
-	IsOnSale    bool                `json:"isOnSale" validate:"omitempty"`
-	SalePrice   *float64            `json:"salePrice"`
-	CategoryID  uint                `json:"categoryID"`
+	Name            string              `json:"name" validate:"required,min=2,max=100"`
+	Title           string              `json:"title" validate:"required,min=2,max=100"`
+	Description     string              `json:"description" validate:"required,min=10`
+	Images          []ProductImageDTO   `json:"images" validate:"required,dive"`
+	Variants        []ProductVariantDTO `json:"variants" validate:"required,dive"`
+	IsFeatured      bool                `json:"isFeatured" validate:"omitempty"`
+	IsOnSale        bool                `json:"isOnSale" validate:"omitempty"`
+	SalePrice       *float64            `json:"salePrice"`
+	CategoryID      uint                `json:"categoryID"`
+	MetaTitle       string              `json:"metaTitle" validate:"omitempty,max=70"`
+	MetaDescription string              `json:"metaDescription" validate:"omitempty,max=160"`
 }
 
 type ProductCreateResponseDTO struct {
-	ID           uint                `json:"id" validate:"required"`
-	Name         string              `json:"name" validate:"required,min=2,max=100"`
-	Title        string              `json:"title" validate:"required,min=2,max=100"`
-	Description  string              `json:"description" validate:"required,min=10`
-	Images       []ProductImageDTO   `json:"images" validate:"required,dive"`
-	Variants     []ProductVariantDTO `json:"variants" validate:"required,dive"`
-	IsFeatured   bool                `json:"isFeatured" validate:"omitempty"`
-	IsOnSale     bool                `json:"isOnSale" validate:"omitempty"`
-	SalePrice    *float64            `json:"salePrice"`
-	CategoryID   uint                `json:"categoryID"`
-	CategoryName string              `json:"categoryName"`
+	ID              uint                `json:"id" validate:"required"`
+	Name            string              `json:"name" validate:"required,min=2,max=100"`
+	Title           string              `json:"title" validate:"required,min=2,max=100"`
+	Description     string              `json:"description" validate:"required,min=10`
+	Images          []ProductImageDTO   `json:"images" validate:"required,dive"`
+	Variants        []ProductVariantDTO `json:"variants" validate:"required,dive"`
+	IsFeatured      bool                `json:"isFeatured" validate:"omitempty"`
+	IsOnSale        bool                `json:"isOnSale" validate:"omitempty"`
+	SalePrice       *float64            `json:"salePrice"`
+	CategoryID      uint                `json:"categoryID"`
+	CategoryName    string              `json:"categoryName"`
+	Slug            string              `json:"slug"`
+	MetaTitle       string              `json:"metaTitle"`
+	MetaDescription string              `json:"metaDescription"`
 }
 
 type ProductUpdateDTO struct {
-	Name        string              `json:"name" validate:"required,min=2,max=100"`
-	Title       string              `json:"title" validate:"required,min=2,max=100"`
-	Description string              `json:"description" validate:"required,min=10`
-	Images      []ProductImageDTO   `json:"images" validate:"required,dive"`
-	Variants    []ProductVariantDTO `json:"variants" validate:"required,dive"`
-	IsFeatured  bool                `json:"isFeatured" validate:"omitempty"`
-	IsOnSale    bool                `json:"isOnSale" validate:"omitempty"`
-	SalePrice   *float64            `json:"salePrice"`
-	CategoryID  uint                `json:"categoryID"`
+	Name            string              `json:"name" validate:"required,min=2,max=100"`
+	Title           string              `json:"title" validate:"required,min=2,max=100"`
+	Description     string              `json:"description" validate:"required,min=10`
+	Images          []ProductImageDTO   `json:"images" validate:"required,dive"`
+	Variants        []ProductVariantDTO `json:"variants" validate:"required,dive"`
+	IsFeatured      bool                `json:"isFeatured" validate:"omitempty"`
+	IsOnSale        bool                `json:"isOnSale" validate:"omitempty"`
+	SalePrice       *float64            `json:"salePrice"`
+	CategoryID      uint                `json:"categoryID"`
+	MetaTitle       string              `json:"metaTitle" validate:"omitempty,max=70"`
+	MetaDescription string              `json:"metaDescription" validate:"omitempty,max=160"`
 }
 
 type ProductUpdateResponseDTO struct {
-	ID          uint                `json:"id" validate:"required"`
-	Name        string              `json:"name" validate:"required,min=2,max=100"`
-	Title       string              `json:"title" validate:"required,min=2,max=100"`
-	Description string              `json:"description" validate:"required,min=10`
-	Images      []ProductImageDTO   `json:"images" validate:"required,dive"`
-	Variants    []ProductVariantDTO `json:"variants" validate:"required,dive"`
-	IsFeatured  bool                `json:"isFeatured" validate:"omitempty"`
-	IsOnSale    bool                `json:"isOnSale" validate:"omitempty"`
-	SalePrice   *float64            `json:"salePrice"`
-	CategoryID  uint                `json:"categoryID"`
+	ID              uint                    `json:"id" validate:"required"`
+	Name            string                  `json:"name" validate:"required,min=2,max=100"`
+	Title           string                  `json:"title" validate:"required,min=2,max=100"`
+	Description     string                  `json:"description" validate:"required,min=10`
+	Images          []ProductImageDTO       `json:"images" validate:"required,dive"`
+	Variants        []ProductVariantDTO     `json:"variants" validate:"required,dive"`
+	IsFeatured      bool                    `json:"isFeatured" validate:"omitempty"`
+	IsOnSale        bool                    `json:"isOnSale" validate:"omitempty"`
+	SalePrice       *float64                `json:"salePrice"`
+	CategoryID      uint                    `json:"categoryID"`
+	Slug            string                  `json:"slug"`
+	MetaTitle       string                  `json:"metaTitle"`
+	MetaDescription string                  `json:"metaDescription"`
+	Breadcrumbs     []CategoryBreadcrumbDTO `json:"breadcrumbs,omitempty"`
 }
 
 type ProductVariantDTO struct {
@@ -60,6 +71,87 @@ type ProductVariantDTO struct {
 	FinalPrice float64 `json:"finalPrice"`
 }
 
+// NOTE - ตอนสร้างหรือแก้สินค้าใช้ลำดับใน list เป็นลำดับรูป ถ้าไม่มีรูปไหนเป็น isPrimary รูปแรกจะเป็นรูปหลัก
 type ProductImageDTO struct {
-	URL string `json:"url" validate:"required"`
-}
\ No newline at end of file
+	ID         uint                       `json:"id,omitempty"`
+	URL        string                     `json:"url" validate:"required"`
+	IsPrimary  bool                       `json:"isPrimary"`
+	Position   int                        `json:"position"`
+	Thumbnails []ProductImageThumbnailDTO `json:"thumbnails,omitempty"`
+}
+
+type ProductImageThumbnailDTO struct {
+	Size   string `json:"size"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	URL    string `json:"url"`
+}
+
+// NOTE - imageIds ต้องมีครบทุกรูปของสินค้า primaryImageId เป็น 0 คือให้รูปแรกเป็นรูปหลัก
+type ReorderProductImagesDTO struct {
+	ImageIDs       []uint `json:"imageIds" validate:"required,min=1"`
+	PrimaryImageID uint   `json:"primaryImageId"`
+}
+type ProductVariantBySKUResponseDTO struct {
+	ProductID   uint              `json:"productId"`
+	ProductName string            `json:"productName"`
+	Title       string            `json:"title"`
+	IsOnSale    bool              `json:"isOnSale"`
+	SalePrice   *float64          `json:"salePrice"`
+	CategoryID  uint              `json:"categoryID"`
+	Images      []ProductImageDTO `json:"images"`
+	Variant     ProductVariantDTO `json:"variant"`
+}
+
+// NOTE - ตัวเลือกการเรียงสินค้าในหน้ารายการ
+const (
+	ProductSortNewest      = "newest"
+	ProductSortPriceAsc    = "price_asc"
+	ProductSortPriceDesc   = "price_desc"
+	ProductSortBestSelling = "best_selling"
+	ProductSortTopRated    = "top_rated"
+)
+
+// NOTE - filter ของหน้ารายการสินค้า ค่าที่เป็น nil หรือว่างคือไม่กรอง
+type ProductListQueryDTO struct {
+	Page        uint
+	Limit       uint
+	MinPrice    *float64
+	MaxPrice    *float64
+	SearchName  string
+	CategoryIDs []int
+	Sizes       []string
+	OnSale      *bool
+	Featured    *bool
+	MinRating   *float64
+	InStock     bool
+	Sort        string
+}
+
+type ProductSearchResultDTO struct {
+	Product    ProductCreateResponseDTO `json:"product"`
+	Score      float64                  `json:"score"`
+	Highlights map[string]string        `json:"highlights"`
+}
+
+type FacetCountDTO struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+	Count int64  `json:"count"`
+}
+
+// NOTE - Max เป็น nil คือช่วงสุดท้ายที่ไม่มีเพดานราคา
+type PriceBucketFacetDTO struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int64    `json:"count"`
+}
+
+// NOTE - แต่ละ facet นับโดยใช้ filter อื่นทั้งหมดยกเว้น filter ของตัวเอง ตัวเลขคือจำนวนสินค้าถ้าเลือกค่านั้น
+type ProductFacetsDTO struct {
+	Categories   []FacetCountDTO       `json:"categories"`
+	Sizes        []FacetCountDTO       `json:"sizes"`
+	PriceBuckets []PriceBucketFacetDTO `json:"priceBuckets"`
+	OnSale       []FacetCountDTO       `json:"onSale"`
+	Ratings      []FacetCountDTO       `json:"ratings"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+import "github.com/Beluga-Whale/ecommerce-api/internal/models"
+
+type CreateReturnRequestDTO struct {
+	OrderItemID uint   `json:"orderItemId" validate:"required"`
+	Quantity    uint   `json:"quantity" validate:"required,min=1"`
+	Reason      string `json:"reason" validate:"required,max=500"`
+}
+
+type ApproveReturnDTO struct {
+	RefundAmount *float64 `json:"refundAmount" validate:"omitempty,gt=0"` //NOTE - ไม่ส่งมาจะคืนตามราคาที่ซื้อ x จำนวน
+	AdminNote    string   `json:"adminNote"`
+}
+
+type RejectReturnDTO struct {
+	AdminNote string `json:"adminNote" validate:"required"`
+}
+
+type ReturnResponseDTO struct {
+	ID           uint                `json:"id"`
+	OrderID      uint                `json:"orderId"`
+	OrderItemID  uint                `json:"orderItemId"`
+	UserID       uint                `json:"userId"`
+	ProductName  string              `json:"productName"`
+	Size         string              `json:"size"`
+	Quantity     uint                `json:"quantity"`
+	Reason       string              `json:"reason"`
+	Status       models.ReturnStatus `json:"status"`
+	AdminNote    string              `json:"adminNote"`
+	RefundAmount float64             `json:"refundAmount"`
+	CreatedAt    string              `json:"createdAt"`
+	ResolvedAt   string              `json:"resolvedAt"`
+}
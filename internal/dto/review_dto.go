@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+)
 
 type CreateReviewDTO struct {
 	ProductID uint   `json:"productId",required`
@@ -15,6 +19,7 @@ type ReviewResponse struct {
 }
 
 type ReviewAllProduct struct {
+	ID        uint      `json:"id"`
 	FirstName string    `json:"firstName"`
 	LastName  string    `json:"lastName"`
 	ProductID uint      `json:"productId"`
@@ -25,8 +30,8 @@ type ReviewAllProduct struct {
 }
 
 type ReviewAllProductSummaryResponse struct {
-	Average      float64            `json:"average"`
-	Total        int                `json:"total"`
-	CountPerStar map[int]int        `json:"countPerStar"` // 1–5 stars
-	ReviewList   []ReviewAllProduct `json:"reviewList"`
+	Average      float64                          `json:"average"`
+	Total        int                              `json:"total"`
+	CountPerStar map[int]int                      `json:"countPerStar"` // 1–5 stars
+	ReviewList   pagination.Page[ReviewAllProduct] `json:"reviewList"`
 }
\ No newline at end of file
@@ -0,0 +1,33 @@
+package dto
+
+type ShipmentItemDTO struct {
+	OrderItemID uint `json:"orderItemId" validate:"required"`
+	Quantity    uint `json:"quantity" validate:"required,gt=0"`
+}
+
+type CreateShipmentDTO struct {
+	Carrier        string            `json:"carrier" validate:"required"`
+	TrackingNumber string            `json:"trackingNumber" validate:"required"`
+	Items          []ShipmentItemDTO `json:"items" validate:"dive"` //NOTE - ไม่ส่งมาคือส่งสินค้าที่เหลือทั้งหมดในพัสดุเดียว
+}
+
+type UpdateShipmentDTO struct {
+	Carrier        *string `json:"carrier"`
+	TrackingNumber *string `json:"trackingNumber"`
+	Delivered      bool    `json:"delivered"` //NOTE - true คือพัสดุถึงมือลูกค้าแล้ว
+}
+
+type ShipmentItemResponseDTO struct {
+	OrderItemID uint `json:"orderItemId"`
+	Quantity    uint `json:"quantity"`
+}
+
+type ShipmentResponseDTO struct {
+	ID             uint                      `json:"id"`
+	OrderID        uint                      `json:"orderId"`
+	Carrier        string                    `json:"carrier"`
+	TrackingNumber string                    `json:"trackingNumber"`
+	ShippedAt      string                    `json:"shippedAt"`
+	DeliveredAt    string                    `json:"deliveredAt"`
+	Items          []ShipmentItemResponseDTO `json:"items"`
+}
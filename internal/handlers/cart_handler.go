@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type CartHandlerInterface interface {
+	GetCart(c *fiber.Ctx) error
+	AddItem(c *fiber.Ctx) error
+	UpdateItemQuantity(c *fiber.Ctx) error
+	RemoveItem(c *fiber.Ctx) error
+	ClearCart(c *fiber.Ctx) error
+	Checkout(c *fiber.Ctx) error
+}
+
+type CartHandler struct {
+	cartService services.CartServiceInterface
+}
+
+func NewCartHandler(cartService services.CartServiceInterface) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+func (h *CartHandler) GetCart(c *fiber.Ctx) error {
+	// NOTE - ดึง userID จาก Locals แล้วแปลง string -> uint
+	userIDStr, ok := c.Locals("userID").(string)
+
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	cart, err := h.cartService.GetCart(uint(userIDUint))
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get cart success", cart)
+}
+
+func (h *CartHandler) AddItem(c *fiber.Ctx) error {
+	var req dto.AddCartItemDTO
+
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// NOTE - Validate request body
+	if err := Validate.Struct(req); err != nil {
+		// NOTE - บอกว่า field ไหนผิด
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	// NOTE - ดึง userID จาก Locals แล้วแปลง string -> uint
+	userIDStr, ok := c.Locals("userID").(string)
+
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	if err := h.cartService.AddItem(uint(userIDUint), req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Add item to cart success", nil)
+}
+
+func (h *CartHandler) UpdateItemQuantity(c *fiber.Ctx) error {
+	itemID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cart item ID")
+	}
+
+	var req dto.UpdateCartItemDTO
+
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	if err := h.cartService.UpdateQuantity(uint(userIDUint), uint(itemID), req.Quantity); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Update cart item success", nil)
+}
+
+func (h *CartHandler) RemoveItem(c *fiber.Ctx) error {
+	itemID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cart item ID")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	if err := h.cartService.RemoveItem(uint(userIDUint), uint(itemID)); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Remove cart item success", nil)
+}
+
+func (h *CartHandler) ClearCart(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	if err := h.cartService.ClearCart(uint(userIDUint)); err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Clear cart success", nil)
+}
+
+func (h *CartHandler) Checkout(c *fiber.Ctx) error {
+	var req dto.CartCheckoutDTO
+
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	order, err := h.cartService.Checkout(uint(userIDUint), req)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Order created successfully", toOrderResponseDTO(order))
+}
@@ -0,0 +1,252 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetCart(t *testing.T) {
+	t.Run("GetCart Success", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartService.On("GetCart", uint(1)).Return(&dto.CartResponseDTO{TotalPrice: 100}, nil)
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Get("/user/cart", testMiddleware, cartHandler.GetCart)
+
+		req := httptest.NewRequest("GET", "/user/cart", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Get cart success")
+		cartService.AssertExpectations(t)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", nil)
+			return c.Next()
+		}
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Get("/user/cart", testMiddleware, cartHandler.GetCart)
+
+		req := httptest.NewRequest("GET", "/user/cart", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestAddCartItem(t *testing.T) {
+	t.Run("AddItem Success", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartService.On("AddItem", uint(1), dto.AddCartItemDTO{VariantID: 2, Quantity: 3}).Return(nil)
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Post("/user/cart", testMiddleware, cartHandler.AddItem)
+
+		reqBody := []byte(`{"variantID": 2, "quantity": 3}`)
+		req := httptest.NewRequest("POST", "/user/cart", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+		cartService.AssertExpectations(t)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Post("/user/cart", testMiddleware, cartHandler.AddItem)
+
+		reqBody := []byte(`{"variantID": 2, "quantity": 0}`)
+		req := httptest.NewRequest("POST", "/user/cart", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Validation error")
+	})
+
+	t.Run("Stock not enough", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartService.On("AddItem", uint(1), mock.Anything).Return(errors.New("stock not enough"))
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Post("/user/cart", testMiddleware, cartHandler.AddItem)
+
+		reqBody := []byte(`{"variantID": 2, "quantity": 30}`)
+		req := httptest.NewRequest("POST", "/user/cart", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "stock not enough")
+	})
+}
+
+func TestRemoveCartItem(t *testing.T) {
+	t.Run("RemoveItem Success", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartService.On("RemoveItem", uint(1), uint(5)).Return(nil)
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Delete("/user/cart/:id", testMiddleware, cartHandler.RemoveItem)
+
+		req := httptest.NewRequest("DELETE", "/user/cart/5", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		cartService.AssertExpectations(t)
+	})
+}
+
+func TestCheckoutCart(t *testing.T) {
+	t.Run("Checkout Success", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderMock := &models.Order{Model: gorm.Model{ID: 1}, UserID: 1, Status: models.Pending}
+		cartService.On("Checkout", uint(1), mock.Anything).Return(orderMock, nil)
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Post("/user/cart/checkout", testMiddleware, cartHandler.Checkout)
+
+		reqBody := []byte(`{
+			"fullName": "John Doe",
+			"phone": "0987678976",
+			"address": "1",
+			"province": "1",
+			"district": "1",
+			"subdistrict": "1",
+			"zipcode": "1"
+		}`)
+		req := httptest.NewRequest("POST", "/user/cart/checkout", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Order created successfully")
+		cartService.AssertExpectations(t)
+	})
+
+	t.Run("Cart is empty", func(t *testing.T) {
+		cartService := servicesMock.NewCartServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		cartService.On("Checkout", uint(1), mock.Anything).Return(nil, errors.New("cart is empty"))
+
+		cartHandler := handlers.NewCartHandler(cartService)
+
+		app := fiber.New()
+		app.Post("/user/cart/checkout", testMiddleware, cartHandler.Checkout)
+
+		reqBody := []byte(`{
+			"fullName": "John Doe",
+			"phone": "0987678976",
+			"address": "1",
+			"province": "1",
+			"district": "1",
+			"subdistrict": "1",
+			"zipcode": "1"
+		}`)
+		req := httptest.NewRequest("POST", "/user/cart/checkout", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "cart is empty")
+	})
+}
@@ -30,6 +30,16 @@ func (h *CategoryHandler) GetAll(c *fiber.Ctx) error {
 	return JSONSuccess(c, fiber.StatusOK,"Get all categories successfully", categories)
 }
 
+func (h *CategoryHandler) GetTree(c *fiber.Ctx) error {
+	tree, err := h.categoryService.GetCategoryTree()
+
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Failed to fetch category tree")
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get category tree successfully", tree)
+}
+
 func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 	// NOTE - Parse request body use DTO
 	var req dto.CategoryCreateDTO
@@ -50,6 +60,7 @@ func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 
 	category := &models.Category{
 		Name: 	  req.Name,
+		ParentID: req.ParentID,
 	}
 
 	err := h.categoryService.CreateCategory(category)
@@ -61,6 +72,7 @@ func (h *CategoryHandler) Create(c *fiber.Ctx) error {
 		ID: category.ID,
 		Name: category.Name,
 		Slug: category.Slug,
+		ParentID: category.ParentID,
 	})
 
 }
@@ -88,6 +100,7 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 
 	category := &models.Category{
 		Name: 	  req.Name,
+		ParentID: req.ParentID,
 	}
 
 	err := h.categoryService.UpdateCategory(uint(categoryID),category)
@@ -97,6 +110,7 @@ func (h *CategoryHandler) Update(c *fiber.Ctx) error {
 	return JSONSuccess(c, fiber.StatusOK, "Category update successfully", dto.UpdateCategoryResponseDTO{
 		Name: category.Name,
 		Slug: slug.Make(category.Name) ,
+		ParentID: category.ParentID,
 	})
 }
 
@@ -104,11 +118,47 @@ func (h *CategoryHandler) Delete(c *fiber.Ctx) error {
 	// NOTE - Get category ID from URL
 	categoryID, _ := strconv.Atoi(c.Params("id")) 
 
+	// NOTE - ?targetCategoryId= ใช้ย้ายสินค้าที่ยังอยู่ใน category ไป category อื่นก่อนลบ
+	var targetID *uint
+	if value := c.Query("targetCategoryId"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || parsed == 0 {
+			return JSONError(c, fiber.StatusBadRequest, "Invalid target category ID")
+		}
+		target := uint(parsed)
+		targetID = &target
+	}
+
 	// NOTE - Delete category
-	err := h.categoryService.DeleteCategory(uint(categoryID))
+	err := h.categoryService.DeleteCategory(uint(categoryID), targetID)
 	if err != nil {
 		return JSONError(c, fiber.StatusBadRequest, err.Error())
 	}
 
 	return JSONSuccess(c, fiber.StatusOK, "Category deleted successfully", nil)
+}
+
+func (h *CategoryHandler) Merge(c *fiber.Ctx) error {
+	// NOTE - Parse request body use DTO
+	var req dto.MergeCategoryDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// NOTE - Validate request body
+	if err := Validate.Struct(req); err != nil {
+		// NOTE - บอกว่า field ไหนผิด
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	err := h.categoryService.MergeCategories(req.SourceID, req.TargetID)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Categories merged successfully", nil)
 }
\ No newline at end of file
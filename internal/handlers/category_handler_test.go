@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	services "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
@@ -71,6 +72,57 @@ func TestGetAll(t *testing.T) {
 	})
 }
 
+func TestGetTree(t *testing.T) {
+	t.Run("GetTree Success",func(t *testing.T) {
+		treeMock := []dto.CategoryTreeDTO{
+			{
+				ID: 1,
+				Name: "Men",
+				Slug: "men",
+				Children: []dto.CategoryTreeDTO{
+					{ID: 2, Name: "Shirts", Slug: "shirts", Children: []dto.CategoryTreeDTO{}},
+				},
+			},
+		}
+
+		categoryService := services.NewCategoryServiceMock()
+		categoryService.On("GetCategoryTree").Return(treeMock,nil)
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Get("/category/tree",categoryHandler.GetTree)
+
+		req := httptest.NewRequest("GET", "/category/tree",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"children":[{"id":2,"name":"Shirts","slug":"shirts","children":[]}]`)
+	})
+
+	t.Run("GetTree Error",func(t *testing.T) {
+		categoryService := services.NewCategoryServiceMock()
+		categoryService.On("GetCategoryTree").Return(nil,errors.New("Error retrieving categories"))
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Get("/category/tree",categoryHandler.GetTree)
+
+		req := httptest.NewRequest("GET", "/category/tree",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Failed to fetch category tree")
+	})
+}
+
 func TestCreate(t *testing.T) {
 	t.Run("Create category success",func(t *testing.T) {
 		categoryMock := &models.Category{
@@ -311,7 +363,7 @@ func TestDelete(t *testing.T) {
 	t.Run("Delete Success",func(t *testing.T) {
 
 		categoryService := services.NewCategoryServiceMock()
-		categoryService.On("DeleteCategory",uint(1)).Return(nil)
+		categoryService.On("DeleteCategory",uint(1),(*uint)(nil)).Return(nil)
 
 		categoryHandler := handlers.NewCategoryHandler(categoryService)
 
@@ -331,7 +383,7 @@ func TestDelete(t *testing.T) {
 	t.Run("Error to delete",func(t *testing.T) {
 
 		categoryService := services.NewCategoryServiceMock()
-		categoryService.On("DeleteCategory",uint(1)).Return(errors.New("Error to delete category"))
+		categoryService.On("DeleteCategory",uint(1),(*uint)(nil)).Return(errors.New("Error to delete category"))
 
 		categoryHandler := handlers.NewCategoryHandler(categoryService)
 
@@ -348,4 +400,99 @@ func TestDelete(t *testing.T) {
 		body, _ := io.ReadAll(res.Body)
 		assert.Contains(t, string(body), "Error to delete category")
 	})
-}
\ No newline at end of file
+	t.Run("Delete With Target Category",func(t *testing.T) {
+		targetID := uint(2)
+
+		categoryService := services.NewCategoryServiceMock()
+		categoryService.On("DeleteCategory",uint(1),&targetID).Return(nil)
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Delete("/category/:id",categoryHandler.Delete)
+
+		req := httptest.NewRequest("DELETE", "/category/1?targetCategoryId=2",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		categoryService.AssertExpectations(t)
+	})
+	t.Run("Invalid Target Category",func(t *testing.T) {
+		categoryService := services.NewCategoryServiceMock()
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Delete("/category/:id",categoryHandler.Delete)
+
+		req := httptest.NewRequest("DELETE", "/category/1?targetCategoryId=abc",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Invalid target category ID")
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("Merge Success",func(t *testing.T) {
+		categoryService := services.NewCategoryServiceMock()
+		categoryService.On("MergeCategories",uint(1),uint(2)).Return(nil)
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Post("/category/merge",categoryHandler.Merge)
+
+		req := httptest.NewRequest("POST", "/category/merge",bytes.NewReader([]byte(`{"sourceId":1,"targetId":2}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Categories merged successfully")
+	})
+	t.Run("Missing Target",func(t *testing.T) {
+		categoryService := services.NewCategoryServiceMock()
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Post("/category/merge",categoryHandler.Merge)
+
+		req := httptest.NewRequest("POST", "/category/merge",bytes.NewReader([]byte(`{"sourceId":1}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "TargetID is required")
+	})
+	t.Run("Error To Merge",func(t *testing.T) {
+		categoryService := services.NewCategoryServiceMock()
+		categoryService.On("MergeCategories",uint(1),uint(2)).Return(errors.New("Cannot merge a category into its own subcategory"))
+
+		categoryHandler := handlers.NewCategoryHandler(categoryService)
+
+		app := fiber.New()
+		app.Post("/category/merge",categoryHandler.Merge)
+
+		req := httptest.NewRequest("POST", "/category/merge",bytes.NewReader([]byte(`{"sourceId":1,"targetId":2}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Cannot merge a category into its own subcategory")
+	})
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CouponHandlerInterface interface {
+	GetAll(c *fiber.Ctx) error
+	GetByID(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	GetRedemptions(c *fiber.Ctx) error
+}
+
+type CouponHandler struct {
+	couponService services.CouponServiceInterface
+}
+
+func NewCouponHandler(couponService services.CouponServiceInterface) *CouponHandler {
+	return &CouponHandler{couponService: couponService}
+}
+
+func (h *CouponHandler) GetAll(c *fiber.Ctx) error {
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	coupons, err := h.couponService.GetAllCoupons(params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get all coupons successfully", coupons)
+}
+
+func (h *CouponHandler) GetByID(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid coupon ID")
+	}
+
+	coupon, err := h.couponService.GetCouponByID(uint(id))
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get coupon successfully", coupon)
+}
+
+func (h *CouponHandler) Create(c *fiber.Ctx) error {
+	// NOTE - Parse request body use DTO
+	var req dto.CouponRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// NOTE - Validate request body
+	if err := Validate.Struct(req); err != nil {
+		// NOTE - บอกว่า field ไหนผิด
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	coupon := toCouponModel(req)
+
+	if err := h.couponService.CreateCoupon(coupon); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Coupon created successfully", fiber.Map{
+		"id":   coupon.ID,
+		"code": coupon.Code,
+	})
+}
+
+func (h *CouponHandler) Update(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid coupon ID")
+	}
+
+	var req dto.CouponRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	if err := h.couponService.UpdateCoupon(uint(id), toCouponModel(req)); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Coupon updated successfully", nil)
+}
+
+func (h *CouponHandler) Delete(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid coupon ID")
+	}
+
+	if err := h.couponService.DeleteCoupon(uint(id)); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Coupon deleted successfully", nil)
+}
+
+func (h *CouponHandler) GetRedemptions(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid coupon ID")
+	}
+
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	redemptions, err := h.couponService.GetRedemptions(uint(id), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get coupon redemptions successfully", redemptions)
+}
+
+func toCouponModel(req dto.CouponRequestDTO) *models.Coupon {
+	coupon := &models.Coupon{
+		Code:                  req.Code,
+		DiscountType:          req.DiscountType,
+		DiscountAmount:        req.DiscountAmount,
+		MaxDiscount:           req.MaxDiscount,
+		MinOrderAmount:        req.MinOrderAmount,
+		MaxRedemptions:        req.MaxRedemptions,
+		MaxRedemptionsPerUser: req.MaxRedemptionsPerUser,
+		StartAt:               req.StartAt,
+		ExpiredAt:             req.ExpiredAt,
+		Categories:            []models.Category{},
+		Products:              []models.Product{},
+	}
+
+	for _, id := range req.CategoryIDs {
+		coupon.Categories = append(coupon.Categories, models.Category{Model: gorm.Model{ID: id}})
+	}
+
+	for _, id := range req.ProductIDs {
+		coupon.Products = append(coupon.Products, models.Product{Model: gorm.Model{ID: id}})
+	}
+
+	return coupon
+}
@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCoupon(t *testing.T) {
+	t.Run("CreateCoupon Success", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponService.On("CreateCoupon", mock.MatchedBy(func(c *models.Coupon) bool {
+			return c.Code == "SALE10" && len(c.Categories) == 2 && c.Categories[1].ID == 4
+		})).Return(nil)
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Post("/admin/coupon", couponHandler.Create)
+
+		reqBody := []byte(`{
+			"code": "SALE10",
+			"discountType": "percentage",
+			"discountAmount": 10,
+			"expiredAt": "2030-01-01T00:00:00Z",
+			"categoryIds": [3, 4]
+		}`)
+		req := httptest.NewRequest("POST", "/admin/coupon", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Coupon created successfully")
+		couponService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid discount type", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Post("/admin/coupon", couponHandler.Create)
+
+		reqBody := []byte(`{
+			"code": "SALE10",
+			"discountType": "bogo",
+			"discountAmount": 10,
+			"expiredAt": "2030-01-01T00:00:00Z"
+		}`)
+		req := httptest.NewRequest("POST", "/admin/coupon", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "DiscountType is oneof")
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponService.On("CreateCoupon", mock.Anything).Return(errors.New("Coupon code already exists"))
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Post("/admin/coupon", couponHandler.Create)
+
+		reqBody := []byte(`{
+			"code": "SALE10",
+			"discountType": "fixed",
+			"discountAmount": 10,
+			"expiredAt": "2030-01-01T00:00:00Z"
+		}`)
+		req := httptest.NewRequest("POST", "/admin/coupon", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Coupon code already exists")
+	})
+}
+
+func TestGetAllCoupons(t *testing.T) {
+	t.Run("GetAll Success", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponService.On("GetAllCoupons", pagination.Params{Limit: pagination.DefaultLimit}).Return(pagination.Page[dto.CouponResponseDTO]{
+			Items: []dto.CouponResponseDTO{{ID: 1, Code: "SALE10", RedemptionCount: 3}},
+		}, nil)
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Get("/admin/coupon", couponHandler.GetAll)
+
+		req := httptest.NewRequest("GET", "/admin/coupon", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"redemptionCount":3`)
+	})
+}
+
+func TestGetCouponRedemptions(t *testing.T) {
+	t.Run("GetRedemptions Success", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponService.On("GetRedemptions", uint(2), pagination.Params{Limit: 5}).Return(pagination.Page[dto.CouponRedemptionResponseDTO]{
+			Items: []dto.CouponRedemptionResponseDTO{{OrderID: 10}},
+		}, nil)
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Get("/admin/coupon/:id/redemptions", couponHandler.GetRedemptions)
+
+		req := httptest.NewRequest("GET", "/admin/coupon/2/redemptions?limit=5", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		couponService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid coupon ID", func(t *testing.T) {
+		couponService := servicesMock.NewCouponServiceMock()
+
+		couponHandler := handlers.NewCouponHandler(couponService)
+
+		app := fiber.New()
+		app.Get("/admin/coupon/:id/redemptions", couponHandler.GetRedemptions)
+
+		req := httptest.NewRequest("GET", "/admin/coupon/abc/redemptions", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
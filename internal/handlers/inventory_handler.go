@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type InventoryHandlerInterface interface {
+	GetMovements(c *fiber.Ctx) error
+	AdjustStock(c *fiber.Ctx) error
+	UpdateReorderThreshold(c *fiber.Ctx) error
+	GetLowStockReport(c *fiber.Ctx) error
+	UpdateVariantBySKU(c *fiber.Ctx) error
+}
+
+type InventoryHandler struct {
+	inventoryService services.InventoryServiceInterface
+}
+
+func NewInventoryHandler(inventoryService services.InventoryServiceInterface) *InventoryHandler {
+	return &InventoryHandler{inventoryService: inventoryService}
+}
+
+func (h *InventoryHandler) GetMovements(c *fiber.Ctx) error {
+	variantID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid variant ID")
+	}
+
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	movements, err := h.inventoryService.GetMovements(uint(variantID), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get inventory movements success", pagination.Map(movements, toInventoryMovementDTO))
+}
+
+func (h *InventoryHandler) AdjustStock(c *fiber.Ctx) error {
+	variantID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid variant ID")
+	}
+
+	var req dto.AdjustStockDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	movement, err := h.inventoryService.AdjustStock(uint(adminIDUint), uint(variantID), req)
+	if err != nil {
+		// NOTE - ลด stock จนติดลบไม่ได้ ตอบ 409
+		if errors.Is(err, services.ErrOutOfStock) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Stock adjusted successfully", toInventoryMovementDTO(*movement))
+}
+
+func (h *InventoryHandler) UpdateReorderThreshold(c *fiber.Ctx) error {
+	variantID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid variant ID")
+	}
+
+	var req dto.UpdateReorderThresholdDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	if err := h.inventoryService.UpdateReorderThreshold(uint(variantID), *req.ReorderThreshold); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Reorder threshold updated successfully", nil)
+}
+
+func (h *InventoryHandler) GetLowStockReport(c *fiber.Ctx) error {
+	variants, err := h.inventoryService.GetLowStockReport()
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get low stock report success", variants)
+}
+
+func (h *InventoryHandler) UpdateVariantBySKU(c *fiber.Ctx) error {
+	sku := c.Params("sku")
+	if sku == "" {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid SKU")
+	}
+
+	var req dto.UpdateVariantBySKUDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	variant, err := h.inventoryService.UpdateVariantBySKU(uint(adminIDUint), sku, req)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Product variant updated successfully", dto.ProductVariantDTO{
+		VariantId: variant.ID,
+		Size:      variant.Size,
+		Stock:     variant.Stock,
+		SKU:       variant.SKU,
+		Price:     variant.Price,
+	})
+}
+
+func toInventoryMovementDTO(movement models.InventoryMovement) dto.InventoryMovementDTO {
+	return dto.InventoryMovementDTO{
+		ID:               movement.ID,
+		ProductVariantID: movement.ProductVariantID,
+		Delta:            movement.Delta,
+		StockAfter:       movement.StockAfter,
+		Reason:           string(movement.Reason),
+		OrderID:          movement.OrderID,
+		ActorUserID:      movement.ActorUserID,
+		Note:             movement.Note,
+		CreatedAt:        movement.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
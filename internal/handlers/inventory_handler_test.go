@@ -0,0 +1,275 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestAdjustStock(t *testing.T) {
+	testMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userID", "9")
+		return c.Next()
+	}
+
+	t.Run("AdjustStock Success", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		req := dto.AdjustStockDTO{Delta: -3, Note: "damaged in warehouse"}
+		inventoryService.On("AdjustStock", uint(9), uint(5), req).Return(&models.InventoryMovement{
+			Model:            gorm.Model{ID: 1},
+			ProductVariantID: 5,
+			Delta:            -3,
+			StockAfter:       37,
+			Reason:           models.InventoryAdjustment,
+		}, nil)
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Post("/admin/inventory/variants/:id/adjustments", testMiddleware, inventoryHandler.AdjustStock)
+
+		httpReq := httptest.NewRequest("POST", "/admin/inventory/variants/5/adjustments", bytes.NewReader([]byte(`{"delta":-3,"note":"damaged in warehouse"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"stockAfter":37`)
+		inventoryService.AssertExpectations(t)
+	})
+
+	t.Run("Missing note", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Post("/admin/inventory/variants/:id/adjustments", testMiddleware, inventoryHandler.AdjustStock)
+
+		httpReq := httptest.NewRequest("POST", "/admin/inventory/variants/5/adjustments", bytes.NewReader([]byte(`{"delta":5}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Note is required")
+	})
+
+	t.Run("Stock cannot go negative", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		req := dto.AdjustStockDTO{Delta: -50, Note: "count"}
+		inventoryService.On("AdjustStock", uint(9), uint(5), req).Return(nil, fmt.Errorf("%w: stock cannot be negative", servicesPkg.ErrOutOfStock))
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Post("/admin/inventory/variants/:id/adjustments", testMiddleware, inventoryHandler.AdjustStock)
+
+		httpReq := httptest.NewRequest("POST", "/admin/inventory/variants/5/adjustments", bytes.NewReader([]byte(`{"delta":-50,"note":"count"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+	})
+}
+
+func TestGetInventoryMovements(t *testing.T) {
+	t.Run("GetMovements Success", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		orderID := uint(1)
+		cursor := pagination.Cursor{AfterID: 30}
+		inventoryService.On("GetMovements", uint(5), pagination.Params{Limit: 1, Cursor: &cursor}).Return(pagination.Page[models.InventoryMovement]{
+			Items: []models.InventoryMovement{
+				{Model: gorm.Model{ID: 29}, ProductVariantID: 5, Delta: -2, StockAfter: 38, Reason: models.InventorySale, OrderID: &orderID},
+			},
+			NextCursor: pagination.Encode(pagination.Cursor{AfterID: 29}),
+		}, nil)
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Get("/admin/inventory/variants/:id/movements", inventoryHandler.GetMovements)
+
+		httpReq := httptest.NewRequest("GET", "/admin/inventory/variants/5/movements?limit=1&cursor="+pagination.Encode(cursor), nil)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"reason":"sale"`)
+		assert.Contains(t, string(body), `"nextCursor":"`+pagination.Encode(pagination.Cursor{AfterID: 29})+`"`)
+		inventoryService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Get("/admin/inventory/variants/:id/movements", inventoryHandler.GetMovements)
+
+		httpReq := httptest.NewRequest("GET", "/admin/inventory/variants/5/movements?cursor=bad", nil)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		inventoryService.AssertNotCalled(t, "GetMovements", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid variant ID", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Get("/admin/inventory/variants/:id/movements", inventoryHandler.GetMovements)
+
+		httpReq := httptest.NewRequest("GET", "/admin/inventory/variants/abc/movements", nil)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestGetLowStockReport(t *testing.T) {
+	t.Run("GetLowStockReport Success", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		inventoryService.On("GetLowStockReport").Return([]dto.LowStockVariantDTO{
+			{VariantID: 2, ProductName: "T-Shirt", Size: "L", Stock: 0, ReorderThreshold: 5, OutOfStock: true},
+		}, nil)
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Get("/admin/inventory/low-stock", inventoryHandler.GetLowStockReport)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/admin/inventory/low-stock", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"outOfStock":true`)
+		inventoryService.AssertExpectations(t)
+	})
+}
+
+func TestUpdateReorderThreshold(t *testing.T) {
+	t.Run("UpdateReorderThreshold Success", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		inventoryService.On("UpdateReorderThreshold", uint(5), 0).Return(nil)
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Patch("/admin/inventory/variants/:id/threshold", inventoryHandler.UpdateReorderThreshold)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/inventory/variants/5/threshold", bytes.NewReader([]byte(`{"reorderThreshold":0}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		inventoryService.AssertExpectations(t)
+	})
+
+	t.Run("Missing threshold", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Patch("/admin/inventory/variants/:id/threshold", inventoryHandler.UpdateReorderThreshold)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/inventory/variants/5/threshold", bytes.NewReader([]byte(`{}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestUpdateVariantBySKU(t *testing.T) {
+	testMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userID", "9")
+		return c.Next()
+	}
+
+	t.Run("UpdateVariantBySKU Success", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+
+		stock := 25
+		req := dto.UpdateVariantBySKUDTO{Stock: &stock, Note: "cycle count"}
+		inventoryService.On("UpdateVariantBySKU", uint(9), "TS-M", req).Return(&models.ProductVariant{
+			Model: gorm.Model{ID: 5},
+			Size:  "M",
+			SKU:   "TS-M",
+			Stock: 25,
+			Price: 100,
+		}, nil)
+
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Patch("/admin/inventory/sku/:sku", testMiddleware, inventoryHandler.UpdateVariantBySKU)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/inventory/sku/TS-M", bytes.NewReader([]byte(`{"stock":25,"note":"cycle count"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"stock":25`)
+		inventoryService.AssertExpectations(t)
+	})
+
+	t.Run("Negative stock", func(t *testing.T) {
+		inventoryService := servicesMock.NewInventoryServiceMock()
+		inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+
+		app := fiber.New()
+		app.Patch("/admin/inventory/sku/:sku", testMiddleware, inventoryHandler.UpdateVariantBySKU)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/inventory/sku/TS-M", bytes.NewReader([]byte(`{"stock":-1}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
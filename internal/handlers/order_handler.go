@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,6 +25,8 @@ type OrderHandlerInterface interface{
 	GetSalesChart(c *fiber.Ctx) error
 	DeleteOrder(c *fiber.Ctx) error
 	GetCustomer(c *fiber.Ctx) error
+	GetOrderTimeline(c *fiber.Ctx) error
+	GetOrderTimelineAdmin(c *fiber.Ctx) error
 }
 
 type OrderHandler struct {
@@ -56,35 +61,18 @@ func (h *OrderHandler) CreateOrder(c *fiber.Ctx) error {
 	order,err := h.OrderService.CreateOrder(uint(userIDUint), req)
 
 	if err != nil{
+		// NOTE - stock ไม่พอหรือถูก order อื่นตัดไปก่อน ตอบ 409
+		if errors.Is(err, services.ErrOutOfStock) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
+		// NOTE - coupon หาไม่เจอหรือใช้กับ order นี้ไม่ได้ ตอบ 422 พร้อมเหตุผล
+		if isCouponError(err) {
+			return JSONError(c, fiber.StatusUnprocessableEntity, err.Error())
+		}
 		return JSONError(c, fiber.StatusInternalServerError, err.Error())
 	}
 
-	response := dto.OrderResponseDTO{
-		OrderID: order.ID,
-		Status:  order.Status,
-		TotalPrice: order.TotalPrice,
-		User : order.User.ID,
-		FullName: order.FullName,
-		Phone:    order.Phone,
-		Address:  order.Address,
-		Province: order.Province,
-		District: order.District,
-		Subdistrict: order.Subdistrict,
-		Zipcode: order.Zipcode,
-		Coupon: order.Coupon.ID,
-		Items: []dto.OrderItemResponseDTO{},
-	}
-
-	for _, item := range order.OrderItem {
-		response.Items = append(response.Items, dto.OrderItemResponseDTO{
-			VariantID:       item.ProductVariantID,
-			ProductName:     item.ProductVariant.Product.Name, 
-			Quantity:        item.Quantity,
-			Size: 			 item.ProductVariant.Size,	
-			PriceAtPurchase: item.PriceAtPurchase,
-		})
-	}
-
+	response := toOrderResponseDTO(order)
 
 	return JSONSuccess(c, fiber.StatusCreated, "Order created successfully", response)
 	
@@ -103,6 +91,9 @@ func (h *OrderHandler) UpdateStatusOrder(c *fiber.Ctx) error {
 	}
 
 	if err := h.OrderService.UpdateStatusOrder(&req.OrderId,req.Status,req.UserId); err !=nil{
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
 		return JSONError(c, fiber.StatusInternalServerError, err.Error())
 	}
 
@@ -151,9 +142,11 @@ func (h *OrderHandler) GetOrderByID(c *fiber.Ctx) error {
 		Zipcode:    order.Zipcode,
 		Coupon:     order.Coupon,
 		OrderItem:  orderItems,
+		Discount:   order.Discount,
 		TotalPrice: order.TotalPrice,
 		CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05"),
 		PaymentExpireAt: order.PaymentExpireAt.Format("2006-01-02 15:04:05"),
+		Shipments:  toShipmentResponseDTOs(order.Shipments),
 	})
 }
 
@@ -172,19 +165,25 @@ func (h *OrderHandler) GetAllOrderByUserId(c *fiber.Ctx) error {
 		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
 	}
 
-	orderAll, err :=h.OrderService.GetAllOrderByUserId(uint(userIDUint))
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
 
-	var orderList []dto.OrderListResponseDTO
+	orderAll, err :=h.OrderService.GetAllOrderByUserId(uint(userIDUint), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Get Order Error")
+	}
 
-	for _, order := range orderAll {
-	orderList = append(orderList, dto.OrderListResponseDTO{
-		OrderID:    order.ID,
-		TotalPrice: order.TotalPrice,
-		Status:     string(order.Status),
-		ItemCount:  len(order.OrderItem),
-		CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05"),
+	orderList := pagination.Map(orderAll, func(order models.Order) dto.OrderListResponseDTO {
+		return dto.OrderListResponseDTO{
+			OrderID:    order.ID,
+			TotalPrice: order.TotalPrice,
+			Status:     string(order.Status),
+			ItemCount:  len(order.OrderItem),
+			CreatedAt:  order.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
 	})
-	}
 
 	return JSONSuccess(c, fiber.StatusOK, "Get All Orders Success", orderList)
 }
@@ -215,6 +214,10 @@ func (h *OrderHandler) UpdateOrderStatusByUser(c *fiber.Ctx) error {
 	err = h.OrderService.UpdateStatusByUser(uint(userIDUint), &orderIDUint, req.Status)
 	
 	if err != nil {
+		// NOTE - เปลี่ยนสถานะผิดลำดับ ตอบ 409
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, fmt.Sprintf("Update status failed: %v", err))
+		}
     	return JSONError(c, fiber.StatusInternalServerError, fmt.Sprintf("Update status failed: %v", err))
 	}
 
@@ -222,15 +225,18 @@ func (h *OrderHandler) UpdateOrderStatusByUser(c *fiber.Ctx) error {
 }
 
 func (h *OrderHandler)	GetAllOrders(c *fiber.Ctx) error {
-	orders, err := h.OrderService.GetAllOrdersAdmin()
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	orders, err := h.OrderService.GetAllOrdersAdmin(params)
 
 	if err != nil {
 		return JSONError(c, fiber.StatusInternalServerError,"Get Order Error")	
 	}
 
-	var orderResponse []dto.OrderListDataTableDTOResponse
-
-	for _, item := range orders {
+	orderResponse := pagination.Map(orders, func(item models.Order) dto.OrderListDataTableDTOResponse {
 		var orderItemResponse []dto.OrderItemResponseDTO
 
 		for _, v := range item.OrderItem {
@@ -243,15 +249,15 @@ func (h *OrderHandler)	GetAllOrders(c *fiber.Ctx) error {
 			})
 		}
 
-		orderResponse = append(orderResponse, dto.OrderListDataTableDTOResponse{
+		return dto.OrderListDataTableDTOResponse{
 			OrderID:    item.ID,
 			CreatedAt:  item.CreatedAt.Format("2006-01-02 15:04:05"),
 			UserName:   item.FullName,
 			Status:     item.Status,
 			TotalPrice: item.TotalPrice,
 			OrderItem:  orderItemResponse,
-		})
-	}
+		}
+	})
 
 	return JSONSuccess(c,fiber.StatusOK,"Get All Order Success",orderResponse)
 }
@@ -267,10 +273,24 @@ func (h *OrderHandler) UpdateOrderStatusByAdmin(c *fiber.Ctx) error{
 	if err := c.BodyParser(&req); err != nil {
 		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
 	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
 	orderIDUint := uint(orderID)
-	err = h.OrderService.UpdateStatusByAdmin( &orderIDUint, req.Status)
+	err = h.OrderService.UpdateStatusByAdmin(uint(adminIDUint), &orderIDUint, req.Status, req.Note)
 	
 	if err != nil {
+		// NOTE - เปลี่ยนสถานะผิดลำดับ ตอบ 409
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, fmt.Sprintf("Update status failed: %v", err))
+		}
     	return JSONError(c, fiber.StatusInternalServerError, fmt.Sprintf("Update status failed: %v", err))
 	}
 
@@ -357,11 +377,103 @@ func (h *OrderHandler) DeleteOrder(c *fiber.Ctx) error{
 }
 
 func (h *OrderHandler) GetCustomer(c *fiber.Ctx) error {
-	customers,err := h.OrderService.GetCustomerDetail()
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	customers,err := h.OrderService.GetCustomerDetail(params)
 
 	if err != nil {
 		return JSONError(c, fiber.StatusInternalServerError, "Error to get customer")
 	}
 
 	return JSONSuccess(c,fiber.StatusCreated, "Product created successfully",customers)
-}
\ No newline at end of file
+}
+
+// NOTE - แปลง order เป็น response ใช้ร่วมกันระหว่าง create order และ checkout cart
+func toOrderResponseDTO(order *models.Order) dto.OrderResponseDTO {
+	response := dto.OrderResponseDTO{
+		OrderID: order.ID,
+		Status:  order.Status,
+		TotalPrice: order.TotalPrice,
+		User : order.User.ID,
+		FullName: order.FullName,
+		Phone:    order.Phone,
+		Address:  order.Address,
+		Province: order.Province,
+		District: order.District,
+		Subdistrict: order.Subdistrict,
+		Zipcode: order.Zipcode,
+		Coupon: order.Coupon.ID,
+		Discount: order.Discount,
+		Items: []dto.OrderItemResponseDTO{},
+	}
+
+	for _, item := range order.OrderItem {
+		response.Items = append(response.Items, dto.OrderItemResponseDTO{
+			VariantID:       item.ProductVariantID,
+			ProductName:     item.ProductVariant.Product.Name, 
+			Quantity:        item.Quantity,
+			Size: 			 item.ProductVariant.Size,	
+			PriceAtPurchase: item.PriceAtPurchase,
+		})
+	}
+
+	return response
+}
+
+func (h *OrderHandler) GetOrderTimeline(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
+
+	timeline, err := h.OrderService.GetOrderTimelineByUser(uint(userIDUint), uint(orderID))
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get order timeline success", timeline)
+}
+
+func (h *OrderHandler) GetOrderTimelineAdmin(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	timeline, err := h.OrderService.GetOrderTimeline(uint(orderID))
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get order timeline success", timeline)
+}
+
+func isCouponError(err error) bool {
+	for _, target := range []error{
+		services.ErrCouponNotFound,
+		services.ErrCouponNotActive,
+		services.ErrCouponExpired,
+		services.ErrCouponMinSubtotal,
+		services.ErrCouponNotApplicable,
+		services.ErrCouponUsageLimit,
+		services.ErrCouponUserLimit,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,13 +3,17 @@ package handlers_test
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
 	services "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -70,6 +74,94 @@ func TestCreateOrder(t *testing.T) {
 		assert.Contains(t, string(body), "Order created successfully")
 	})
 
+	t.Run("Out of stock",func(t *testing.T) {
+		orderService := services.NewOrderServiceMock()
+
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("CreateOrder",mock.Anything,mock.Anything).Return(nil,fmt.Errorf("%w: T-Shirt size S", servicesPkg.ErrOutOfStock))
+
+		app := fiber.New()
+		app.Post("/user/order",testMiddleware,orderHandler.CreateOrder)
+
+		reqBody:= []byte(`{
+			"fullName": "T-Shirt Update",
+			"items": [
+				{
+					"variantId": 92,
+					"quantity": 1
+				}
+			]
+		}`)
+
+		req :=httptest.NewRequest("POST","/user/order",bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type","application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "stock not enough: T-Shirt size S")
+	})
+
+	t.Run("Coupon rejected",func(t *testing.T) {
+		orderService := services.NewOrderServiceMock()
+
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("CreateOrder",mock.Anything,mock.Anything).Return(nil,servicesPkg.ErrCouponUsageLimit)
+
+		app := fiber.New()
+		app.Post("/user/order",testMiddleware,orderHandler.CreateOrder)
+
+		req :=httptest.NewRequest("POST","/user/order",bytes.NewReader([]byte(`{"couponCode": "ONCE", "items": [{"variantId": 92, "quantity": 1}]}`)))
+		req.Header.Set("Content-Type","application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnprocessableEntity, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "coupon usage limit reached")
+	})
+
+	t.Run("Database failure stays 500",func(t *testing.T) {
+		orderService := services.NewOrderServiceMock()
+
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("CreateOrder",mock.Anything,mock.Anything).Return(nil,errors.New("failed to reserve stock"))
+
+		app := fiber.New()
+		app.Post("/user/order",testMiddleware,orderHandler.CreateOrder)
+
+		req :=httptest.NewRequest("POST","/user/order",bytes.NewReader([]byte(`{"items": [{"variantId": 92, "quantity": 1}]}`)))
+		req.Header.Set("Content-Type","application/json")
+
+		res,err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+	})
+
 	t.Run("Invalid request body",func(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 
@@ -614,6 +706,30 @@ func TestUpdateOrderStatusByUser(t *testing.T) {
 		assert.Contains(t, string(body), "Update status failed")
 	})
 
+	t.Run("Illegal status transition",func(t *testing.T) {
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService := services.NewOrderServiceMock()
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		orderService.On("UpdateStatusByUser",uint(1),mock.Anything,models.Paid).Return(fmt.Errorf("%w: user cannot change order from pending to paid", servicesPkg.ErrInvalidStatusTransition))
+
+		app := fiber.New()
+		app.Patch("/user/order/:id/status", testMiddleware,orderHandler.UpdateOrderStatusByUser)
+
+		req := httptest.NewRequest("PATCH", "/user/order/1/status",bytes.NewReader([]byte(`{"status":"paid"}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "cannot change order from pending to paid")
+	})
 }
 
 func TestGetAllOrderByUserId(t *testing.T) {
@@ -632,7 +748,7 @@ func TestGetAllOrderByUserId(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 		orderHandler := handlers.NewOrderHandler(orderService)
 		
-		orderService.On("GetAllOrderByUserId",mock.Anything).Return(&orderMock,nil)
+		orderService.On("GetAllOrderByUserId",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(pagination.Page[models.Order]{Items: []models.Order{orderMock}},nil)
 
 		app := fiber.New()
 		app.Get("/user/order", testMiddleware,orderHandler.GetAllOrderByUserId)
@@ -707,12 +823,18 @@ func TestGetAllOrders(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 		orderHandler := handlers.NewOrderHandler(orderService)
 		
-		orderService.On("GetAllOrdersAdmin").Return(&orderMock,nil)
+		// NOTE - cursor จาก query ต้องถูกส่งต่อไปถึง service
+		cursor := pagination.Cursor{AfterID: 10}
+		params := pagination.Params{Limit: 1, Cursor: &cursor}
+		orderService.On("GetAllOrdersAdmin",params).Return(pagination.Page[models.Order]{
+			Items:      []models.Order{orderMock},
+			NextCursor: pagination.Encode(pagination.Cursor{AfterID: 1}),
+		},nil)
 
 		app := fiber.New()
 		app.Get("/admin/order",orderHandler.GetAllOrders)
 
-		req := httptest.NewRequest("GET", "/admin/order",nil)
+		req := httptest.NewRequest("GET", "/admin/order?limit=1&cursor="+pagination.Encode(cursor),nil)
 		req.Header.Set("Content-Type", "application/json")
 
 		res, err := app.Test(req)
@@ -721,12 +843,32 @@ func TestGetAllOrders(t *testing.T) {
 
 		body, _ := io.ReadAll(res.Body)
 		assert.Contains(t, string(body), "Get All Order Success")
+		assert.Contains(t, string(body), `"nextCursor":"`+pagination.Encode(pagination.Cursor{AfterID: 1})+`"`)
+		orderService.AssertExpectations(t)
+	})
+	t.Run("Invalid cursor",func(t *testing.T) {
+		orderService := services.NewOrderServiceMock()
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		app := fiber.New()
+		app.Get("/admin/order",orderHandler.GetAllOrders)
+
+		req := httptest.NewRequest("GET", "/admin/order?cursor=bad",nil)
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Invalid cursor")
+		orderService.AssertNotCalled(t, "GetAllOrdersAdmin", mock.Anything)
 	})
 	t.Run("Error GetAllOrders",func(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 		orderHandler := handlers.NewOrderHandler(orderService)
 		
-		orderService.On("GetAllOrdersAdmin").Return(nil,errors.New("Get Order Error"))
+		orderService.On("GetAllOrdersAdmin",mock.Anything).Return(nil,errors.New("Get Order Error"))
 
 		app := fiber.New()
 		app.Get("/admin/order",orderHandler.GetAllOrders)
@@ -753,7 +895,7 @@ func TestUpdateOrderStatusByAdmin(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 		orderHandler := handlers.NewOrderHandler(orderService)
 		
-		orderService.On("UpdateStatusByAdmin",mock.Anything,mock.Anything).Return(nil)
+		orderService.On("UpdateStatusByAdmin",mock.Anything,mock.Anything,mock.Anything,mock.Anything).Return(nil)
 
 		app := fiber.New()
 		app.Patch("/admin/order/:id/status", testMiddleware,orderHandler.UpdateOrderStatusByAdmin)
@@ -833,7 +975,7 @@ func TestUpdateOrderStatusByAdmin(t *testing.T) {
 		orderService := services.NewOrderServiceMock()
 		orderHandler := handlers.NewOrderHandler(orderService)
 		
-		orderService.On("UpdateStatusByAdmin",mock.Anything,mock.Anything).Return(errors.New("Update status failed"))
+		orderService.On("UpdateStatusByAdmin",mock.Anything,mock.Anything,mock.Anything,mock.Anything).Return(errors.New("Update status failed"))
 
 		app := fiber.New()
 		app.Patch("/admin/order/:id/status", testMiddleware,orderHandler.UpdateOrderStatusByAdmin)
@@ -930,4 +1072,71 @@ func TestDeleteOrder(t *testing.T) {
 		assert.Contains(t, string(body), "Error to delete")
 	})
 
-}
\ No newline at end of file
+}
+func TestGetOrderTimeline(t *testing.T) {
+	t.Run("Get Order Timeline Success",func(t *testing.T) {
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "2")
+			return c.Next()
+		}
+
+		orderService := services.NewOrderServiceMock()
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		orderService.On("GetOrderTimelineByUser",uint(2),uint(1)).Return([]dto.OrderStatusEventDTO{
+			{ToStatus: models.Pending, Source: "user"},
+			{FromStatus: models.Pending, ToStatus: models.Paid, Source: "webhook"},
+		},nil)
+
+		app := fiber.New()
+		app.Get("/user/order/:id/timeline", testMiddleware,orderHandler.GetOrderTimeline)
+
+		req := httptest.NewRequest("GET", "/user/order/1/timeline",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "webhook")
+		orderService.AssertExpectations(t)
+	})
+
+	t.Run("Get Order Timeline of another user",func(t *testing.T) {
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "3")
+			return c.Next()
+		}
+
+		orderService := services.NewOrderServiceMock()
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		orderService.On("GetOrderTimelineByUser",uint(3),uint(1)).Return(nil,errors.New("unauthorized to update this order"))
+
+		app := fiber.New()
+		app.Get("/user/order/:id/timeline", testMiddleware,orderHandler.GetOrderTimeline)
+
+		req := httptest.NewRequest("GET", "/user/order/1/timeline",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Get Order Timeline Admin",func(t *testing.T) {
+		orderService := services.NewOrderServiceMock()
+		orderHandler := handlers.NewOrderHandler(orderService)
+
+		orderService.On("GetOrderTimeline",uint(1)).Return([]dto.OrderStatusEventDTO{{ToStatus: models.Pending}},nil)
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/timeline", orderHandler.GetOrderTimelineAdmin)
+
+		req := httptest.NewRequest("GET", "/admin/order/1/timeline",nil)
+
+		res, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		orderService.AssertExpectations(t)
+	})
+}
@@ -2,51 +2,108 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stripe/stripe-go/v82"
 	"github.com/stripe/stripe-go/v82/paymentintent"
+	"gorm.io/gorm"
 )
 
 type PaymentHandlerInterface interface {
 	CreatePaymentIntent(c *fiber.Ctx) error
+	Webhook(c *fiber.Ctx) error
+	GetPaymentsByOrderID(c *fiber.Ctx) error
 }
 
 type StripeHandler struct{
 	orderService services.OrderServiceInterface
+	paymentService services.PaymentServiceInterface
 }
 
-func NewStripeHandler(orderService services.OrderServiceInterface) *StripeHandler {
-	return &StripeHandler{orderService:orderService}
+func NewStripeHandler(orderService services.OrderServiceInterface, paymentService services.PaymentServiceInterface) *StripeHandler {
+	return &StripeHandler{orderService:orderService, paymentService:paymentService}
 }
 
 func (h *StripeHandler) CreatePaymentIntent(c *fiber.Ctx) error {
-	var req dto.CreatePaymentIntentRequestDTO
+	// NOTE - Get userID from locals
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	// NOTE - แปลง userID จาก string เป็น uint
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid user ID")
+	}
 
-	
+	var req dto.CreatePaymentIntentRequestDTO
 	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request",
-		})
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	// NOTE - ราคาเอามาจาก order ใน DB เท่านั้น ไม่เชื่อค่าที่ client ส่งมา
+	order, err := h.orderService.GetPayableOrder(req.OrderID, uint(userIDUint))
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
 	}
 
+	// NOTE - ลูกค้าเริ่มจ่ายเงินแล้ว ต่อเวลากัน stock ไม่ให้หมดเวลาระหว่างกรอกบัตร
+	// ถ้าต่อไม่ได้ห้ามสร้าง intent ลูกค้าจะจ่ายเงินให้ stock ที่อาจถูกคืนไปแล้ว
+	if err := h.orderService.ExtendReservation(order.ID); err != nil {
+		if errors.Is(err, services.ErrOrderNotPending) {
+			return JSONError(c, fiber.StatusConflict, "Order is no longer awaiting payment")
+		}
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	// NOTE - Stripe ใช้หน่วยเล็กสุดของสกุลเงิน (cent)
+	amount := int64(math.Round(order.TotalPrice * 100))
+
 	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
 
+	// NOTE - ถ้า order นี้มี intent ที่ยังจ่ายไม่เสร็จและยอดตรงกันอยู่แล้ว ให้ใช้ตัวเดิม
+	if order.PaymentIntentID != "" {
+		existing, err := paymentintent.Get(order.PaymentIntentID, nil)
+		if err == nil && existing.Amount == amount &&
+			existing.Status != stripe.PaymentIntentStatusCanceled &&
+			existing.Status != stripe.PaymentIntentStatusSucceeded {
+			return c.JSON(fiber.Map{
+				"clientSecret": existing.ClientSecret,
+				"amount":       amount,
+			})
+		}
+	}
+
 	params := &stripe.PaymentIntentParams{
-		Amount:   stripe.Int64(req.Amount),
+		Amount:   stripe.Int64(amount),
 		Currency: stripe.String(string("usd")),
 		AutomaticPaymentMethods: &stripe.PaymentIntentAutomaticPaymentMethodsParams{
 			Enabled: stripe.Bool(true),
 		},
 	}
-	params.AddMetadata("orderId", strconv.Itoa(int(req.OrderID)))
-	params.AddMetadata("userId", strconv.Itoa(int(req.UserID)))
+	params.AddMetadata("orderId", strconv.Itoa(int(order.ID)))
+	params.AddMetadata("userId", strconv.Itoa(int(order.UserID)))
 
 	intent, err := paymentintent.New(params)
 	if err != nil {
@@ -54,8 +111,14 @@ func (h *StripeHandler) CreatePaymentIntent(c *fiber.Ctx) error {
 			"error": err.Error(),
 		})
 	}
+
+	if err := h.orderService.SetPaymentIntentID(order.ID, intent.ID); err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
 	return c.JSON(fiber.Map{
 		"clientSecret": intent.ClientSecret,
+		"amount":       amount,
 	})
 }
 
@@ -74,34 +137,236 @@ func (h *StripeHandler) Webhook(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).SendString("Webhook Error")
 	}
 
+	// NOTE - Stripe อาจส่ง event เดิมซ้ำ ถ้าเคยประมวลผลแล้วให้ตอบ 200 เฉยๆ
+	isNew, err := h.paymentService.BeginEvent(event.ID, string(event.Type))
+	if err != nil {
+		fmt.Println(" Failed to record event:", err)
+		return c.Status(fiber.StatusInternalServerError).SendString("Record event failed")
+	}
+
+	if !isNew {
+		fmt.Println("Duplicate event skipped:", event.ID)
+		return c.SendStatus(fiber.StatusOK)
+	}
+
+	if err := h.handleEvent(event); err != nil {
+		// NOTE - เปลี่ยนสถานะไม่ได้ตาม state machine ให้ตอบ 200 เพราะ retry ไปก็ไม่สำเร็จ
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			fmt.Println(" Order status not changed:", err)
+			return c.SendStatus(fiber.StatusOK)
+		}
+
+		// NOTE - ประมวลผลไม่สำเร็จ ลบ event ออกเพื่อให้ Stripe retry มาใหม่ได้
+		if releaseErr := h.paymentService.ReleaseEvent(event.ID); releaseErr != nil {
+			fmt.Println(" Failed to release event:", releaseErr)
+		}
+
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			return c.Status(fiberErr.Code).SendString(fiberErr.Message)
+		}
+		return c.Status(fiber.StatusInternalServerError).SendString(err.Error())
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+func (h *StripeHandler) handleEvent(event stripe.Event) error {
 	switch event.Type {
-case "payment_intent.succeeded":
-    var paymentIntent stripe.PaymentIntent
-    err := json.Unmarshal(event.Data.Raw, &paymentIntent)
-    if err != nil {
-        fmt.Println(" Failed to parse payment intent:", err)
-        return c.Status(fiber.StatusBadRequest).SendString("Invalid payment data")
-    }
-
-    fmt.Println(" PaymentIntent was successful")
-
-    orderID, _ := strconv.Atoi(paymentIntent.Metadata["orderId"])
-    userID, _ := strconv.Atoi(paymentIntent.Metadata["userId"])
-    status := models.Status("paid")
-    orderIDUint := uint(orderID)
-
-    err = h.orderService.UpdateStatusOrder(&orderIDUint, status, uint(userID))
-    if err != nil {
-        fmt.Println(" Failed to update order:", err)
-        return c.Status(fiber.StatusInternalServerError).SendString("Update failed")
-    }
-
-    fmt.Println("✅ Order updated")
+	case "payment_intent.succeeded":
+		var paymentIntent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
+			fmt.Println(" Failed to parse payment intent:", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid payment data")
+		}
+
+		fmt.Println(" PaymentIntent was successful")
+
+		orderID, _ := strconv.Atoi(paymentIntent.Metadata["orderId"])
+		userID, _ := strconv.Atoi(paymentIntent.Metadata["userId"])
+		orderIDUint := uint(orderID)
+
+		if err := h.orderService.UpdateStatusOrder(&orderIDUint, models.Paid, uint(userID)); err != nil {
+			if !errors.Is(err, services.ErrInvalidStatusTransition) {
+				fmt.Println(" Failed to update order:", err)
+				return fiber.NewError(fiber.StatusInternalServerError, "Update failed")
+			}
+
+			// NOTE - order ไม่ได้ pending แล้ว (เช่นหมดเวลาหรือถูกยกเลิกไปก่อน) stock ถูกคืนไปแล้ว ต้องคืนเงินลูกค้า
+			fmt.Println(" Order not marked as paid, refunding:", err)
+			if err := h.paymentService.RefundUnpayablePayment(paymentFromIntent(&paymentIntent, models.Payed)); err != nil {
+				fmt.Println(" Failed to record payment:", err)
+				return fiber.NewError(fiber.StatusInternalServerError, "Record payment failed")
+			}
+			return nil
+		}
+
+		// NOTE - เก็บประวัติการจ่ายเงินไว้ให้ฝ่ายบัญชีตรวจสอบ
+		if err := h.paymentService.RecordPayment(paymentFromIntent(&paymentIntent, models.Payed)); err != nil {
+			fmt.Println(" Failed to record payment:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Record payment failed")
+		}
+
+		fmt.Println("✅ Order updated")
 	case "payment_intent.payment_failed":
+		var paymentIntent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
+			fmt.Println(" Failed to parse payment intent:", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid payment data")
+		}
+
 		fmt.Println(" Payment failed:", event.ID)
+
+		// NOTE - order ยังคง pending ไว้ ลูกค้าลองจ่ายใหม่ได้จนกว่าจะหมดเวลา
+		if err := h.paymentService.RecordPayment(paymentFromIntent(&paymentIntent, models.Failed)); err != nil {
+			fmt.Println(" Failed to record payment:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Record payment failed")
+		}
+	case "payment_intent.canceled":
+		var paymentIntent stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &paymentIntent); err != nil {
+			fmt.Println(" Failed to parse payment intent:", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid payment data")
+		}
+
+		orderID, _ := strconv.Atoi(paymentIntent.Metadata["orderId"])
+
+		// NOTE - intent ถูกยกเลิก ให้ยกเลิก order และคืน stock (ทำเฉพาะ order ที่ยัง pending)
+		if err := h.orderService.CancelOrderAndRestoreStock(uint(orderID), services.SourceWebhook); err != nil {
+			if !errors.Is(err, services.ErrInvalidStatusTransition) {
+				fmt.Println(" Failed to cancel order:", err)
+				return fiber.NewError(fiber.StatusInternalServerError, "Cancel order failed")
+			}
+			fmt.Println(" Order not cancelled:", err)
+		}
+
+		payment := paymentFromIntent(&paymentIntent, models.Failed)
+		if payment.FailureMessage == "" {
+			payment.FailureMessage = "payment intent canceled: " + string(paymentIntent.CancellationReason)
+		}
+
+		if err := h.paymentService.RecordPayment(payment); err != nil {
+			fmt.Println(" Failed to record payment:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Record payment failed")
+		}
+	case "charge.refunded":
+		var charge stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &charge); err != nil {
+			fmt.Println(" Failed to parse charge:", err)
+			return fiber.NewError(fiber.StatusBadRequest, "Invalid charge data")
+		}
+
+		if charge.PaymentIntent == nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Charge has no payment intent")
+		}
+
+		order, err := h.orderService.GetOrderByPaymentIntentID(charge.PaymentIntent.ID)
+		if err != nil {
+			// NOTE - refund ของ charge ที่ไม่ได้สร้างจากระบบนี้ ถือว่าจัดการแล้ว ไม่อย่างนั้น Stripe จะส่งซ้ำไปอีกหลายวัน
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Println(" Refund for unknown payment intent:", charge.PaymentIntent.ID)
+				return nil
+			}
+			fmt.Println(" Failed to find order:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Find order failed")
+		}
+
+		// NOTE - refund ที่ทำผ่านระบบ return ถูกบันทึกไว้แล้ว บันทึกเพิ่มเฉพาะส่วนที่ทำจาก Stripe dashboard
+		recorded, err := h.paymentService.GetRefundedAmount(order.ID)
+		if err != nil {
+			fmt.Println(" Failed to get refunded amount:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Get refunded amount failed")
+		}
+
+		refunded := float64(charge.AmountRefunded) / 100
+		if refunded-recorded > 0.005 {
+			payment := &models.Payment{
+				OrderID:               order.ID,
+				StripePaymentIntentID: charge.PaymentIntent.ID,
+				Amount:                refunded - recorded,
+				Currency:              string(charge.Currency),
+				Status:                models.RefundedPayment,
+			}
+
+			if err := h.paymentService.RecordPayment(payment); err != nil {
+				fmt.Println(" Failed to record payment:", err)
+				return fiber.NewError(fiber.StatusInternalServerError, "Record payment failed")
+			}
+		}
+
+		status := models.PartiallyRefunded
+		if charge.Refunded {
+			status = models.Refunded
+		}
+
+		if err := h.orderService.UpdateStatusBySystem(order.ID, status, services.SourceWebhook, "charge.refunded "+charge.ID); err != nil {
+			fmt.Println(" Failed to update order:", err)
+			return fiber.NewError(fiber.StatusInternalServerError, "Update failed")
+		}
 	default:
 		fmt.Println("Unhandled event type:", event.Type)
 	}
 
-	return c.SendStatus(fiber.StatusOK)
-}
\ No newline at end of file
+	return nil
+}
+
+func (h *StripeHandler) GetPaymentsByOrderID(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	payments, err := h.paymentService.GetPaymentsByOrderID(uint(orderID), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get payments success", pagination.Map(payments, toPaymentResponseDTO))
+}
+
+func toPaymentResponseDTO(p models.Payment) dto.PaymentResponseDTO {
+	paidAt := ""
+	if p.PaidAt != nil {
+		paidAt = p.PaidAt.Format("2006-01-02 15:04:05")
+	}
+
+	return dto.PaymentResponseDTO{
+		ID:              p.ID,
+		OrderID:         p.OrderID,
+		PaymentIntentID: p.StripePaymentIntentID,
+		Amount:          p.Amount,
+		Currency:        p.Currency,
+		Status:          p.Status,
+		FailureMessage:  p.FailureMessage,
+		PaidAt:          paidAt,
+		CreatedAt:       p.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// NOTE - แปลง PaymentIntent ของ Stripe เป็น models.Payment (amount ของ Stripe เป็นหน่วยสตางค์/เซนต์)
+func paymentFromIntent(pi *stripe.PaymentIntent, status models.StatusPayment) *models.Payment {
+	orderID, _ := strconv.Atoi(pi.Metadata["orderId"])
+
+	payment := &models.Payment{
+		OrderID:               uint(orderID),
+		StripePaymentIntentID: pi.ID,
+		Amount:                float64(pi.Amount) / 100,
+		Currency:              string(pi.Currency),
+		Status:                status,
+	}
+
+	if status == models.Payed {
+		paidAt := time.Now()
+		payment.PaidAt = &paidAt
+	}
+
+	if pi.LastPaymentError != nil {
+		payment.FailureMessage = pi.LastPaymentError.Msg
+	}
+
+	return payment
+}
@@ -0,0 +1,558 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/webhook"
+	"gorm.io/gorm"
+)
+
+func TestGetPaymentsByOrderID(t *testing.T) {
+	t.Run("GetPaymentsByOrderID Success", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paidAt := time.Now()
+		payments := []models.Payment{
+			{Model: gorm.Model{ID: 1}, OrderID: 1, StripePaymentIntentID: "pi_123", Amount: 100, Currency: "usd", Status: models.Payed, PaidAt: &paidAt},
+		}
+		paymentService.On("GetPaymentsByOrderID", uint(1), pagination.Params{Limit: 1}).Return(pagination.Page[models.Payment]{
+			Items:      payments,
+			NextCursor: pagination.Encode(pagination.Cursor{AfterID: 1}),
+		}, nil)
+
+		stripeHandler := handlers.NewStripeHandler(orderService, paymentService)
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/payments", stripeHandler.GetPaymentsByOrderID)
+
+		req := httptest.NewRequest("GET", "/admin/order/1/payments?limit=1", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "pi_123")
+		assert.Contains(t, string(body), `"nextCursor":"`+pagination.Encode(pagination.Cursor{AfterID: 1})+`"`)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		paymentService := servicesMock.NewPaymentServiceMock()
+		stripeHandler := handlers.NewStripeHandler(servicesMock.NewOrderServiceMock(), paymentService)
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/payments", stripeHandler.GetPaymentsByOrderID)
+
+		req := httptest.NewRequest("GET", "/admin/order/1/payments?cursor=bad", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		paymentService.AssertNotCalled(t, "GetPaymentsByOrderID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("GetPaymentsByOrderID", uint(9), pagination.Params{Limit: pagination.DefaultLimit}).Return(nil, errors.New("order not found"))
+
+		stripeHandler := handlers.NewStripeHandler(orderService, paymentService)
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/payments", stripeHandler.GetPaymentsByOrderID)
+
+		req := httptest.NewRequest("GET", "/admin/order/9/payments", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Invalid order ID", func(t *testing.T) {
+		stripeHandler := handlers.NewStripeHandler(servicesMock.NewOrderServiceMock(), servicesMock.NewPaymentServiceMock())
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/payments", stripeHandler.GetPaymentsByOrderID)
+
+		req := httptest.NewRequest("GET", "/admin/order/abc/payments", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
+
+func TestCreatePaymentIntent(t *testing.T) {
+	t.Run("Unauthorized", func(t *testing.T) {
+		stripeHandler := handlers.NewStripeHandler(servicesMock.NewOrderServiceMock(), servicesMock.NewPaymentServiceMock())
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", nil)
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/stripe/payment-intent", testMiddleware, stripeHandler.CreatePaymentIntent)
+
+		req := httptest.NewRequest("POST", "/stripe/payment-intent", bytes.NewReader([]byte(`{"orderId": 1}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		stripeHandler := handlers.NewStripeHandler(servicesMock.NewOrderServiceMock(), servicesMock.NewPaymentServiceMock())
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		app := fiber.New()
+		app.Post("/stripe/payment-intent", testMiddleware, stripeHandler.CreatePaymentIntent)
+
+		req := httptest.NewRequest("POST", "/stripe/payment-intent", bytes.NewReader([]byte(`{}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Validation error")
+	})
+
+	t.Run("Order is not pending", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("GetPayableOrder", uint(5), uint(1)).Return(nil, errors.New("order is not pending"))
+
+		stripeHandler := handlers.NewStripeHandler(orderService, servicesMock.NewPaymentServiceMock())
+
+		app := fiber.New()
+		app.Post("/stripe/payment-intent", testMiddleware, stripeHandler.CreatePaymentIntent)
+
+		req := httptest.NewRequest("POST", "/stripe/payment-intent", bytes.NewReader([]byte(`{"orderId": 5, "amount": 1}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "order is not pending")
+		orderService.AssertExpectations(t)
+	})
+
+	t.Run("Reservation expired before extending", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("GetPayableOrder", uint(5), uint(1)).Return(&models.Order{
+			Model:      gorm.Model{ID: 5},
+			Status:     models.Pending,
+			TotalPrice: 100,
+		}, nil)
+		orderService.On("ExtendReservation", uint(5)).Return(servicesPkg.ErrOrderNotPending)
+
+		stripeHandler := handlers.NewStripeHandler(orderService, servicesMock.NewPaymentServiceMock())
+
+		app := fiber.New()
+		app.Post("/stripe/payment-intent", testMiddleware, stripeHandler.CreatePaymentIntent)
+
+		req := httptest.NewRequest("POST", "/stripe/payment-intent", bytes.NewReader([]byte(`{"orderId": 5, "amount": 1}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Order is no longer awaiting payment")
+		orderService.AssertExpectations(t)
+	})
+
+	t.Run("Extending reservation fails", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		orderService.On("GetPayableOrder", uint(5), uint(1)).Return(&models.Order{
+			Model:      gorm.Model{ID: 5},
+			Status:     models.Pending,
+			TotalPrice: 100,
+		}, nil)
+		orderService.On("ExtendReservation", uint(5)).Return(errors.New("Error extending reservation"))
+
+		stripeHandler := handlers.NewStripeHandler(orderService, servicesMock.NewPaymentServiceMock())
+
+		app := fiber.New()
+		app.Post("/stripe/payment-intent", testMiddleware, stripeHandler.CreatePaymentIntent)
+
+		req := httptest.NewRequest("POST", "/stripe/payment-intent", bytes.NewReader([]byte(`{"orderId": 5, "amount": 1}`)))
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Error extending reservation")
+		orderService.AssertExpectations(t)
+	})
+}
+
+const testWebhookSecret = "whsec_test_secret"
+
+// NOTE - สร้าง payload ของ event แบบเดียวกับที่ Stripe ส่งมา เพื่อเทส webhook แบบ offline
+func newStripeEventPayload(t *testing.T, eventID string, eventType string, object map[string]interface{}) []byte {
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":          eventID,
+		"object":      "event",
+		"api_version": stripe.APIVersion,
+		"type":        eventType,
+		"data": map[string]interface{}{
+			"object": object,
+		},
+	})
+	assert.NoError(t, err)
+	return payload
+}
+
+// NOTE - sign payload ด้วย secret ของเทส แล้วยิงเข้า webhook
+func sendSignedWebhook(t *testing.T, app *fiber.App, payload []byte) *http.Response {
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{
+		Payload: payload,
+		Secret:  testWebhookSecret,
+	})
+
+	req := httptest.NewRequest("POST", "/stripe/webhook", bytes.NewReader(signed.Payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Stripe-Signature", signed.Header)
+
+	res, err := app.Test(req)
+	assert.NoError(t, err)
+	return res
+}
+
+func newWebhookApp(orderService *servicesMock.OrderServiceMock, paymentService *servicesMock.PaymentServiceMock) *fiber.App {
+	stripeHandler := handlers.NewStripeHandler(orderService, paymentService)
+
+	app := fiber.New()
+	app.Post("/stripe/webhook", stripeHandler.Webhook)
+	return app
+}
+
+func TestWebhook(t *testing.T) {
+	t.Setenv("STRIPE_WEBHOOK_SECRET", testWebhookSecret)
+
+	succeededIntent := map[string]interface{}{
+		"id":       "pi_123",
+		"object":   "payment_intent",
+		"amount":   25000,
+		"currency": "usd",
+		"metadata": map[string]string{"orderId": "1", "userId": "2"},
+	}
+
+	t.Run("Payment succeeded", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("BeginEvent", "evt_1", "payment_intent.succeeded").Return(true, nil)
+		orderService.On("UpdateStatusOrder", mock.Anything, models.Paid, uint(2)).Return(nil)
+		paymentService.On("RecordPayment", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.OrderID == 1 && p.StripePaymentIntentID == "pi_123" && p.Amount == 250 && p.Status == models.Payed && p.PaidAt != nil
+		})).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_1", "payment_intent.succeeded", succeededIntent))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		orderService.AssertExpectations(t)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Duplicate event is a no-op", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("BeginEvent", "evt_1", "payment_intent.succeeded").Return(false, nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_1", "payment_intent.succeeded", succeededIntent))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		orderService.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything, mock.Anything)
+		paymentService.AssertNotCalled(t, "RecordPayment", mock.Anything)
+	})
+
+	t.Run("Failed processing releases event", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("BeginEvent", "evt_2", "payment_intent.succeeded").Return(true, nil)
+		orderService.On("UpdateStatusOrder", mock.Anything, models.Paid, uint(2)).Return(errors.New("db error"))
+		paymentService.On("ReleaseEvent", "evt_2").Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_2", "payment_intent.succeeded", succeededIntent))
+
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Payment failed keeps order pending", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		failedIntent := map[string]interface{}{
+			"id":                 "pi_123",
+			"object":             "payment_intent",
+			"amount":             25000,
+			"currency":           "usd",
+			"metadata":           map[string]string{"orderId": "1", "userId": "2"},
+			"last_payment_error": map[string]interface{}{"message": "Your card was declined."},
+		}
+
+		paymentService.On("BeginEvent", "evt_3", "payment_intent.payment_failed").Return(true, nil)
+		paymentService.On("RecordPayment", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.Failed && p.FailureMessage == "Your card was declined." && p.PaidAt == nil
+		})).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_3", "payment_intent.payment_failed", failedIntent))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		paymentService.AssertExpectations(t)
+		orderService.AssertNotCalled(t, "UpdateStatusOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Payment intent canceled cancels order", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		canceledIntent := map[string]interface{}{
+			"id":                  "pi_123",
+			"object":              "payment_intent",
+			"amount":              25000,
+			"currency":            "usd",
+			"cancellation_reason": "abandoned",
+			"metadata":            map[string]string{"orderId": "1", "userId": "2"},
+		}
+
+		paymentService.On("BeginEvent", "evt_4", "payment_intent.canceled").Return(true, nil)
+		orderService.On("CancelOrderAndRestoreStock", uint(1), servicesPkg.SourceWebhook).Return(nil)
+		paymentService.On("RecordPayment", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.Failed && p.FailureMessage == "payment intent canceled: abandoned"
+		})).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_4", "payment_intent.canceled", canceledIntent))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		orderService.AssertExpectations(t)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Charge fully refunded from dashboard", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		charge := map[string]interface{}{
+			"id":              "ch_123",
+			"object":          "charge",
+			"amount":          25000,
+			"amount_refunded": 25000,
+			"currency":        "usd",
+			"refunded":        true,
+			"payment_intent":  "pi_123",
+		}
+
+		paymentService.On("BeginEvent", "evt_5", "charge.refunded").Return(true, nil)
+		orderService.On("GetOrderByPaymentIntentID", "pi_123").Return(&models.Order{Model: gorm.Model{ID: 1}}, nil)
+		paymentService.On("GetRefundedAmount", uint(1)).Return(0.0, nil)
+		paymentService.On("RecordPayment", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.OrderID == 1 && p.Status == models.RefundedPayment && p.Amount == 250
+		})).Return(nil)
+		orderService.On("UpdateStatusBySystem", uint(1), models.Refunded, servicesPkg.SourceWebhook, mock.Anything).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_5", "charge.refunded", charge))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		orderService.AssertExpectations(t)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		app := newWebhookApp(orderService, paymentService)
+
+		payload := newStripeEventPayload(t, "evt_6", "payment_intent.succeeded", succeededIntent)
+		req := httptest.NewRequest("POST", "/stripe/webhook", bytes.NewReader(payload))
+		req.Header.Set("Stripe-Signature", "t=1,v1=invalid")
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		paymentService.AssertNotCalled(t, "BeginEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Charge refunded through return is not recorded twice", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		charge := map[string]interface{}{
+			"id":              "ch_123",
+			"object":          "charge",
+			"amount":          25000,
+			"amount_refunded": 10000,
+			"currency":        "usd",
+			"refunded":        false,
+			"payment_intent":  "pi_123",
+		}
+
+		paymentService.On("BeginEvent", "evt_7", "charge.refunded").Return(true, nil)
+		orderService.On("GetOrderByPaymentIntentID", "pi_123").Return(&models.Order{Model: gorm.Model{ID: 1}}, nil)
+		paymentService.On("GetRefundedAmount", uint(1)).Return(100.0, nil)
+		orderService.On("UpdateStatusBySystem", uint(1), models.PartiallyRefunded, servicesPkg.SourceWebhook, mock.Anything).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_7", "charge.refunded", charge))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		paymentService.AssertNotCalled(t, "RecordPayment", mock.Anything)
+		orderService.AssertExpectations(t)
+	})
+
+	t.Run("Refund of charge from another system", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		charge := map[string]interface{}{
+			"id":              "ch_999",
+			"object":          "charge",
+			"amount":          25000,
+			"amount_refunded": 25000,
+			"currency":        "usd",
+			"refunded":        true,
+			"payment_intent":  "pi_999",
+		}
+
+		paymentService.On("BeginEvent", "evt_8", "charge.refunded").Return(true, nil)
+		orderService.On("GetOrderByPaymentIntentID", "pi_999").Return(nil, fmt.Errorf("order not found: %w", gorm.ErrRecordNotFound))
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_8", "charge.refunded", charge))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		paymentService.AssertNotCalled(t, "ReleaseEvent", mock.Anything)
+		paymentService.AssertNotCalled(t, "RecordPayment", mock.Anything)
+	})
+
+	t.Run("Database error finding refunded order", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		charge := map[string]interface{}{
+			"id":              "ch_123",
+			"object":          "charge",
+			"amount_refunded": 25000,
+			"refunded":        true,
+			"payment_intent":  "pi_123",
+		}
+
+		paymentService.On("BeginEvent", "evt_9", "charge.refunded").Return(true, nil)
+		paymentService.On("ReleaseEvent", "evt_9").Return(nil)
+		orderService.On("GetOrderByPaymentIntentID", "pi_123").Return(nil, errors.New("Error finding order: connection refused"))
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_9", "charge.refunded", charge))
+
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+		paymentService.AssertExpectations(t)
+	})
+
+	t.Run("Payment succeeded for expired order is refunded", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("BeginEvent", "evt_10", "payment_intent.succeeded").Return(true, nil)
+		orderService.On("UpdateStatusOrder", mock.Anything, models.Paid, uint(2)).
+			Return(fmt.Errorf("%w: expired -> paid", servicesPkg.ErrInvalidStatusTransition))
+		paymentService.On("RefundUnpayablePayment", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.OrderID == 1 && p.StripePaymentIntentID == "pi_123" && p.Amount == 250 && p.Status == models.Payed
+		})).Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_10", "payment_intent.succeeded", succeededIntent))
+
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		paymentService.AssertExpectations(t)
+		paymentService.AssertNotCalled(t, "RecordPayment", mock.Anything)
+		paymentService.AssertNotCalled(t, "ReleaseEvent", mock.Anything)
+	})
+
+	t.Run("Refund for expired order not recorded releases event", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		paymentService := servicesMock.NewPaymentServiceMock()
+
+		paymentService.On("BeginEvent", "evt_11", "payment_intent.succeeded").Return(true, nil)
+		orderService.On("UpdateStatusOrder", mock.Anything, models.Paid, uint(2)).
+			Return(fmt.Errorf("%w: cancelled -> paid", servicesPkg.ErrInvalidStatusTransition))
+		paymentService.On("RefundUnpayablePayment", mock.Anything).Return(errors.New("Error recording payment"))
+		paymentService.On("ReleaseEvent", "evt_11").Return(nil)
+
+		app := newWebhookApp(orderService, paymentService)
+		res := sendSignedWebhook(t, app, newStripeEventPayload(t, "evt_11", "payment_intent.succeeded", succeededIntent))
+
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+		paymentService.AssertExpectations(t)
+	})
+}
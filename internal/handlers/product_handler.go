@@ -9,6 +9,7 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type ProductHandlerInterface interface{
@@ -16,7 +17,10 @@ type ProductHandlerInterface interface{
 	UpdateProduct(c *fiber.Ctx) error
 	DeleteProduct(c *fiber.Ctx) error
 	GetProductByID(c *fiber.Ctx) error
+	GetProductBySlug(c *fiber.Ctx) error
+	GetVariantBySKU(c *fiber.Ctx) error
 	GetAllProducts(c *fiber.Ctx) error
+	SearchProducts(c *fiber.Ctx) error
 }
 
 type ProductHandler struct {
@@ -51,6 +55,8 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		IsOnSale:    req.IsOnSale,
 		SalePrice:   req.SalePrice,
 		CategoryID:  req.CategoryID,
+		MetaTitle:   req.MetaTitle,
+		MetaDescription: req.MetaDescription,
 	}
 
 	for _, v := range req.Variants{
@@ -62,11 +68,7 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	for _, i := range req.Images {
-		product.Images = append(product.Images, models.ProductImage{
-			URL: i.URL,
-		})
-	}
+	product.Images = toProductImageModels(req.Images)
 
 	err := h.productService.CreateProduct(product)
 
@@ -85,12 +87,7 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	var imageURLs []dto.ProductImageDTO
-	for _, img := range product.Images {
-		imageURLs = append(imageURLs, dto.ProductImageDTO{
-			URL: img.URL,
-		})
-	}
+	imageURLs := toProductImageDTOs(product.Images)
 
 	return JSONSuccess(c,fiber.StatusCreated, "Product created successfully", dto.ProductCreateResponseDTO{
 		ID: 		 product.ID,
@@ -103,6 +100,9 @@ func (h *ProductHandler) CreateProduct(c *fiber.Ctx) error {
 		SalePrice:   product.SalePrice,
 		CategoryID:  product.CategoryID,
 		Variants:    variantsDTOs,
+		Slug:        product.Slug,
+		MetaTitle:   product.MetaTitle,
+		MetaDescription: product.MetaDescription,
 	})
 }
 
@@ -133,10 +133,13 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 		IsOnSale:    req.IsOnSale,
 		SalePrice:   req.SalePrice,
 		CategoryID:  req.CategoryID,
+		MetaTitle:   req.MetaTitle,
+		MetaDescription: req.MetaDescription,
 	}
 
 	for _, v := range req.Variants{
 		product.Variants = append(product.Variants, models.ProductVariant{
+			Model: gorm.Model{ID: v.VariantId},
 			Size: v.Size,
 			Stock: v.Stock,
 			SKU: v.SKU,
@@ -145,11 +148,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 	}
 
 
-	for _, i := range req.Images {
-		product.Images = append(product.Images, models.ProductImage{
-			URL: i.URL,
-		})
-	}
+	product.Images = toProductImageModels(req.Images)
 
 
 	err = h.productService.UpdateProduct(uint(id),product)
@@ -161,6 +160,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 
 	for _, v:= range product.Variants {
 		variantsDTOs = append(variantsDTOs, dto.ProductVariantDTO{
+			VariantId: v.ID,
 			Size: v.Size,
 			Stock: v.Stock,
 			SKU: v.SKU,
@@ -168,12 +168,7 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	var imageURLs []dto.ProductImageDTO
-	for _, img := range product.Images {
-		imageURLs = append(imageURLs, dto.ProductImageDTO{
-			URL: img.URL,
-		})
-	}
+	imageURLs := toProductImageDTOs(product.Images)
 
 	return JSONSuccess(c, fiber.StatusOK, "Product updated successfully", dto.ProductUpdateResponseDTO{
 		Name:        product.Name,
@@ -185,6 +180,9 @@ func (h *ProductHandler) UpdateProduct(c *fiber.Ctx) error {
 		SalePrice:   product.SalePrice,
 		CategoryID:  product.CategoryID,
 		Variants:    variantsDTOs,
+		Slug:        product.Slug,
+		MetaTitle:   product.MetaTitle,
+		MetaDescription: product.MetaDescription,
 	})
 }
 
@@ -216,58 +214,91 @@ func (h *ProductHandler) GetProductByID(c *fiber.Ctx) error {
 		return JSONError(c, fiber.StatusNotFound, "Product not found")
 	}
 
-	var variantsDTOs []dto.ProductVariantDTO
-	for _, v:= range product.Variants {
-		finalPrice := v.Price
+	return h.productDetailResponse(c, product)
+}
 
-		if product.IsOnSale && product.SalePrice != nil {
-			finalPrice = v.Price - *product.SalePrice
-			if finalPrice < 0 {
-				finalPrice = 0 //NOTE - กันราคาติดลบ
-			}
-		}
+// NOTE - slug เก่าของสินค้าที่เปลี่ยนชื่อไปแล้วจะ redirect ไป URL ของ slug ปัจจุบัน
+func (h *ProductHandler) GetProductBySlug(c *fiber.Ctx) error {
+	productSlug := c.Params("slug")
+	if productSlug == "" {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid product slug")
+	}
 
-		variantsDTOs = append(variantsDTOs, dto.ProductVariantDTO{
-			VariantId: v.ID,
-			Size: v.Size,
-			Stock: v.Stock,
-			SKU: v.SKU,
-			Price: v.Price,
-			FinalPrice: finalPrice,
-		})
+	product, err := h.productService.GetProductBySlug(productSlug)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if product == nil {
+		return JSONError(c, fiber.StatusNotFound, "Product not found")
 	}
 
+	if product.Slug != productSlug {
+		return c.Redirect(strings.TrimSuffix(c.Path(), productSlug)+product.Slug, fiber.StatusMovedPermanently)
+	}
 
-	var imageURLs []dto.ProductImageDTO
-	for _, img := range product.Images {
-		imageURLs = append(imageURLs, dto.ProductImageDTO{
-			URL: img.URL,
-		})
+	return h.productDetailResponse(c, product)
+}
+
+// NOTE - หน้ารายละเอียดสินค้าแนบ breadcrumbs ของ category ไปด้วย
+func (h *ProductHandler) productDetailResponse(c *fiber.Ctx, product *models.Product) error {
+	breadcrumbs, err := h.productService.GetCategoryBreadcrumbs(product.CategoryID)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
 	}
 
+	detail := toProductDetailDTO(product)
+	detail.Breadcrumbs = breadcrumbs
 
-	return JSONSuccess(c, fiber.StatusOK, "Product retrieved successfully", dto.ProductUpdateResponseDTO{
-		ID:          product.ID,
-		Name:        product.Name,
+	return JSONSuccess(c, fiber.StatusOK, "Product retrieved successfully", detail)
+}
+
+func (h *ProductHandler) GetVariantBySKU(c *fiber.Ctx) error {
+	sku := c.Params("sku")
+	if sku == "" {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid SKU")
+	}
+
+	variant, err := h.productService.GetVariantBySKU(sku)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+	if variant == nil {
+		return JSONError(c, fiber.StatusNotFound, "Product variant not found")
+	}
+
+	product := variant.Product
+	finalPrice := services.FinalUnitPrice(variant.Price, product)
+
+	imageURLs := toProductImageDTOs(product.Images)
+
+	return JSONSuccess(c, fiber.StatusOK, "Product variant retrieved successfully", dto.ProductVariantBySKUResponseDTO{
+		ProductID:   product.ID,
+		ProductName: product.Name,
 		Title:       product.Title,
-		Description: product.Description,
-		Images:      imageURLs,
-		IsFeatured:  product.IsFeatured,
 		IsOnSale:    product.IsOnSale,
 		SalePrice:   product.SalePrice,
 		CategoryID:  product.CategoryID,
-		Variants: 	 variantsDTOs,
+		Images:      imageURLs,
+		Variant: dto.ProductVariantDTO{
+			VariantId:  variant.ID,
+			Size:       variant.Size,
+			Stock:      variant.Stock,
+			SKU:        variant.SKU,
+			Price:      variant.Price,
+			FinalPrice: finalPrice,
+		},
 	})
 }
 
+// NOTE - รายการสินค้าและ SearchProducts ยังใช้ page/limit ไม่ใช้ cursor ของ pagination เพราะเรียงตามราคา ชื่อ หรือ relevance ได้ (cursor ใช้ได้แค่เรียงตาม id)
+// และหน้าร้านต้องรู้จำนวนหน้าทั้งหมดกับ facets ของ filter ชุดเดียวกัน
 func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 	page := c.QueryInt("page",1)
 	limit := c.QueryInt("limit",12)
-	maxPrice := c.QueryInt("maxPrice",999999)
-	minPrice := c.QueryInt("minPrice",0)
 	searchName := c.Query("searchName","")
 	category := c.Query("category","")
 	size := c.Query("size","")
+	sort := c.Query("sort", dto.ProductSortNewest)
 
 	categoryArr := strings.Split(category,",")
 	sizeArr := strings.Split(size,",")
@@ -296,65 +327,80 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 		sizeIDs = append(sizeIDs, i)
 	}
 
+	if page <= 0 {
+		page = 1
+	}
+
 	if limit <= 0 {
 		limit = 1000000
 	}
 
-	if minPrice > maxPrice {
-		return JSONError(c, fiber.StatusInternalServerError, "minPrice must be less than maxPrice")
+	minPrice, err := optionalQueryFloat(c, "minPrice")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid minPrice")
+	}
+
+	maxPrice, err := optionalQueryFloat(c, "maxPrice")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid maxPrice")
+	}
+
+	if minPrice != nil && maxPrice != nil && *minPrice > *maxPrice {
+		return JSONError(c, fiber.StatusBadRequest, "minPrice must be less than maxPrice")
+	}
+
+	minRating, err := optionalQueryFloat(c, "minRating")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid minRating")
+	}
+
+	onSale, err := optionalQueryBool(c, "onSale")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid onSale")
 	}
 
-	products, pageTotal ,err := h.productService.GetAllProducts(uint(page),uint(limit),int64(minPrice),int64(maxPrice),searchName,categoryIDs,sizeIDs)
+	featured, err := optionalQueryBool(c, "featured")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid featured")
+	}
+
+	switch sort {
+	case dto.ProductSortNewest, dto.ProductSortPriceAsc, dto.ProductSortPriceDesc, dto.ProductSortBestSelling, dto.ProductSortTopRated:
+	default:
+		return JSONError(c, fiber.StatusBadRequest, "Invalid sort option")
+	}
+
+	query := dto.ProductListQueryDTO{
+		Page:        uint(page),
+		Limit:       uint(limit),
+		MinPrice:    minPrice,
+		MaxPrice:    maxPrice,
+		SearchName:  searchName,
+		CategoryIDs: categoryIDs,
+		Sizes:       sizeIDs,
+		OnSale:      onSale,
+		Featured:    featured,
+		MinRating:   minRating,
+		InStock:     c.QueryBool("inStock", false),
+		Sort:        sort,
+	}
+
+	products, pageTotal ,err := h.productService.GetAllProducts(query)
 
 	if err != nil {
 		return JSONError(c, fiber.StatusInternalServerError, err.Error())
 	}
 
+	// NOTE - นับจำนวนสินค้าของแต่ละตัวเลือกใน filter sidebar จาก filter ชุดเดียวกับรายการ
+	facets, err := h.productService.GetProductFacets(query)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
 	var productsDTO []dto.ProductCreateResponseDTO
 
 	for _,product := range products {
-		var variantsDTOs []dto.ProductVariantDTO
-
-		for _, v := range product.Variants {
-			finalPrice := v.Price
-
-			if product.IsOnSale && product.SalePrice != nil {
-				finalPrice = v.Price - *product.SalePrice
-				if finalPrice < 0 {
-					finalPrice = 0
-				}
-			}
-
-			variantsDTOs = append(variantsDTOs, dto.ProductVariantDTO{
-				VariantId:  v.ID,
-				Size:       v.Size,
-				Stock:      v.Stock,
-				SKU:        v.SKU,
-				Price:      v.Price,
-				FinalPrice: finalPrice,
-			})
-		}
-
-		var imageURLs []dto.ProductImageDTO
-		for _, img := range product.Images {
-			imageURLs = append(imageURLs, dto.ProductImageDTO{
-				URL: img.URL,
-			})
-		}
-
-		productsDTO = append(productsDTO, dto.ProductCreateResponseDTO{
-			ID: 		 product.ID,	
-			Name:        product.Name,
-			Title:       product.Title,
-			Description: product.Description,
-			Images:      imageURLs,
-			IsFeatured:  product.IsFeatured,
-			IsOnSale:    product.IsOnSale,
-			SalePrice:   product.SalePrice,
-			CategoryID:  product.CategoryID,
-			CategoryName: product.Category.Name,
-			Variants:    variantsDTOs,
-		})
+		productsDTO = append(productsDTO, toProductListDTO(product))
 	}
 
 	return JSONSuccess(c, fiber.StatusOK, "Products retrieved successfully", fiber.Map{
@@ -362,6 +408,186 @@ func (h *ProductHandler) GetAllProducts(c *fiber.Ctx) error {
 		"page": page,
 		"limit" : limit,
 		"pageTotal": pageTotal,
+		"facets": facets,
 	})
 }
 
+// NOTE - ใช้ page/limit เหมือน GetAllProducts เพราะผลค้นหาเรียงตาม relevance
+func (h *ProductHandler) SearchProducts(c *fiber.Ctx) error {
+	text := strings.TrimSpace(c.Query("q"))
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 12)
+
+	if text == "" {
+		return JSONError(c, fiber.StatusBadRequest, "Please provide search query")
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+
+	results, pageTotal, err := h.productService.SearchProducts(text, uint(page), uint(limit))
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	resultsDTO := []dto.ProductSearchResultDTO{}
+	for _, result := range results {
+		resultsDTO = append(resultsDTO, dto.ProductSearchResultDTO{
+			Product:    toProductListDTO(result.Product),
+			Score:      result.Score,
+			Highlights: result.Highlights,
+		})
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Products retrieved successfully", fiber.Map{
+		"results":   resultsDTO,
+		"page":      page,
+		"limit":     limit,
+		"pageTotal": pageTotal,
+	})
+}
+
+func toProductListDTO(product models.Product) dto.ProductCreateResponseDTO {
+	var variantsDTOs []dto.ProductVariantDTO
+
+	for _, v := range product.Variants {
+		finalPrice := services.FinalUnitPrice(v.Price, product)
+
+		variantsDTOs = append(variantsDTOs, dto.ProductVariantDTO{
+			VariantId:  v.ID,
+			Size:       v.Size,
+			Stock:      v.Stock,
+			SKU:        v.SKU,
+			Price:      v.Price,
+			FinalPrice: finalPrice,
+		})
+	}
+
+	imageURLs := toProductImageDTOs(product.Images)
+
+	return dto.ProductCreateResponseDTO{
+		ID:           product.ID,
+		Name:         product.Name,
+		Title:        product.Title,
+		Description:  product.Description,
+		Images:       imageURLs,
+		IsFeatured:   product.IsFeatured,
+		IsOnSale:     product.IsOnSale,
+		SalePrice:    product.SalePrice,
+		CategoryID:   product.CategoryID,
+		CategoryName: product.Category.Name,
+		Variants:     variantsDTOs,
+		Slug:         product.Slug,
+		MetaTitle:    product.MetaTitle,
+		MetaDescription: product.MetaDescription,
+	}
+}
+
+// NOTE - query ที่ไม่ได้ส่งมาคืน nil เพื่อแยกกับค่าที่ส่งมาเป็น 0 หรือ false
+func optionalQueryFloat(c *fiber.Ctx, key string) (*float64, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func optionalQueryBool(c *fiber.Ctx, key string) (*bool, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+func toProductDetailDTO(product *models.Product) dto.ProductUpdateResponseDTO {
+	var variantsDTOs []dto.ProductVariantDTO
+	for _, v:= range product.Variants {
+		finalPrice := services.FinalUnitPrice(v.Price, *product)
+
+		variantsDTOs = append(variantsDTOs, dto.ProductVariantDTO{
+			VariantId: v.ID,
+			Size: v.Size,
+			Stock: v.Stock,
+			SKU: v.SKU,
+			Price: v.Price,
+			FinalPrice: finalPrice,
+		})
+	}
+
+
+	imageURLs := toProductImageDTOs(product.Images)
+
+
+	return dto.ProductUpdateResponseDTO{
+		ID:          product.ID,
+		Name:        product.Name,
+		Title:       product.Title,
+		Description: product.Description,
+		Images:      imageURLs,
+		IsFeatured:  product.IsFeatured,
+		IsOnSale:    product.IsOnSale,
+		SalePrice:   product.SalePrice,
+		CategoryID:  product.CategoryID,
+		Variants: 	 variantsDTOs,
+		Slug:        product.Slug,
+		MetaTitle:   product.MetaTitle,
+		MetaDescription: product.MetaDescription,
+	}
+}
+
+func toProductImageModels(images []dto.ProductImageDTO) []models.ProductImage {
+	var productImages []models.ProductImage
+	for _, img := range images {
+		productImages = append(productImages, models.ProductImage{
+			URL:       img.URL,
+			IsPrimary: img.IsPrimary,
+		})
+	}
+
+	return productImages
+}
+
+func toProductImageDTOs(images []models.ProductImage) []dto.ProductImageDTO {
+	var imageDTOs []dto.ProductImageDTO
+	for _, img := range images {
+		imageDTOs = append(imageDTOs, toProductImageDTO(img))
+	}
+
+	return imageDTOs
+}
+
+func toProductImageDTO(img models.ProductImage) dto.ProductImageDTO {
+	var thumbnails []dto.ProductImageThumbnailDTO
+	for _, thumbnail := range img.Thumbnails {
+		thumbnails = append(thumbnails, dto.ProductImageThumbnailDTO{
+			Size:   thumbnail.Size,
+			Width:  thumbnail.Width,
+			Height: thumbnail.Height,
+			URL:    thumbnail.URL,
+		})
+	}
+
+	return dto.ProductImageDTO{
+		ID:         img.ID,
+		URL:        img.URL,
+		IsPrimary:  img.IsPrimary,
+		Position:   img.Position,
+		Thumbnails: thumbnails,
+	}
+}
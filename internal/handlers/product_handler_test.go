@@ -7,11 +7,15 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
 	services "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func TestCreateProduct(t *testing.T) {
@@ -518,4 +522,309 @@ func TestDeleteProduct( t *testing.T) {
 		body, _ := io.ReadAll(res.Body)
 		assert.Contains(t, string(body), "Error to delete product")	
 	})
-}
\ No newline at end of file
+}
+
+func TestGetProductBySlug(t *testing.T) {
+	t.Run("GetProductBySlug Success", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetProductBySlug", "classic-tee").Return(&models.Product{
+			Model:      gorm.Model{ID: 1},
+			Name:       "Classic Tee",
+			Slug:       "classic-tee",
+			MetaTitle:  "Classic Tee | Shop",
+			CategoryID: 3,
+		}, nil)
+		productService.On("GetCategoryBreadcrumbs", uint(3)).Return([]dto.CategoryBreadcrumbDTO{
+			{ID: 1, Name: "Men", Slug: "men"},
+			{ID: 3, Name: "Shirts", Slug: "shirts"},
+		}, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/slug/:slug", productHandler.GetProductBySlug)
+
+		req := httptest.NewRequest("GET", "/product/slug/classic-tee", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"slug":"classic-tee"`)
+		assert.Contains(t, string(body), `"metaTitle":"Classic Tee | Shop"`)
+		assert.Contains(t, string(body), `"breadcrumbs":[{"id":1,"name":"Men","slug":"men"},{"id":3,"name":"Shirts","slug":"shirts"}]`)
+	})
+
+	t.Run("Error to get breadcrumbs", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetProductBySlug", "classic-tee").Return(&models.Product{
+			Model:      gorm.Model{ID: 1},
+			Slug:       "classic-tee",
+			CategoryID: 3,
+		}, nil)
+		productService.On("GetCategoryBreadcrumbs", uint(3)).Return(nil, errors.New("Error retrieving category breadcrumbs"))
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/slug/:slug", productHandler.GetProductBySlug)
+
+		req := httptest.NewRequest("GET", "/product/slug/classic-tee", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Error retrieving category breadcrumbs")
+	})
+
+	t.Run("Old slug redirects to current slug", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetProductBySlug", "classic-tee").Return(&models.Product{
+			Model: gorm.Model{ID: 1},
+			Slug:  "oversized-tee",
+		}, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/api/product/slug/:slug", productHandler.GetProductBySlug)
+
+		req := httptest.NewRequest("GET", "/api/product/slug/classic-tee", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusMovedPermanently, res.StatusCode)
+		assert.Equal(t, "/api/product/slug/oversized-tee", res.Header.Get("Location"))
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetProductBySlug", "missing").Return(nil, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/slug/:slug", productHandler.GetProductBySlug)
+
+		req := httptest.NewRequest("GET", "/product/slug/missing", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("Error finding product", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetProductBySlug", "classic-tee").Return(nil, errors.New("Error finding product"))
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/slug/:slug", productHandler.GetProductBySlug)
+
+		req := httptest.NewRequest("GET", "/product/slug/classic-tee", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Error finding product")
+	})
+}
+
+func TestGetVariantBySKU(t *testing.T) {
+	t.Run("GetVariantBySKU Success", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		salePrice := 20.0
+		productService.On("GetVariantBySKU", "TS-M").Return(&models.ProductVariant{
+			Model:     gorm.Model{ID: 5},
+			ProductID: 1,
+			Size:      "M",
+			SKU:       "TS-M",
+			Stock:     10,
+			Price:     100,
+			Product: models.Product{
+				Model:     gorm.Model{ID: 1},
+				Name:      "T-shirt",
+				IsOnSale:  true,
+				SalePrice: &salePrice,
+			},
+		}, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/sku/:sku", productHandler.GetVariantBySKU)
+
+		req := httptest.NewRequest("GET", "/product/sku/TS-M", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"productName":"T-shirt"`)
+		assert.Contains(t, string(body), `"finalPrice":80`)
+		productService.AssertExpectations(t)
+	})
+
+	t.Run("Variant not found", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("GetVariantBySKU", "NOPE").Return(nil, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/sku/:sku", productHandler.GetVariantBySKU)
+
+		req := httptest.NewRequest("GET", "/product/sku/NOPE", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, res.StatusCode)
+	})
+}
+
+func TestGetAllProducts(t *testing.T) {
+	t.Run("Parse filters and sort", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		minPrice := 100.0
+		maxPrice := 500.5
+		onSale := true
+		minRating := 4.0
+		query := dto.ProductListQueryDTO{
+			Page:        2,
+			Limit:       12,
+			MinPrice:    &minPrice,
+			MaxPrice:    &maxPrice,
+			CategoryIDs: []int{1, 3},
+			Sizes:       []string{"M"},
+			OnSale:      &onSale,
+			MinRating:   &minRating,
+			InStock:     true,
+			Sort:        dto.ProductSortBestSelling,
+		}
+		productService.On("GetAllProducts", query).Return([]models.Product{{Model: gorm.Model{ID: 1}, Name: "T-shirt"}}, int64(2), nil)
+		productService.On("GetProductFacets", query).Return(&dto.ProductFacetsDTO{
+			Sizes: []dto.FacetCountDTO{{Value: "M", Label: "M", Count: 12}},
+		}, nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product", productHandler.GetAllProducts)
+
+		req := httptest.NewRequest("GET", "/product?page=2&minPrice=100&maxPrice=500.5&category=1,3&size=M&onSale=true&minRating=4&inStock=true&sort=best_selling", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"pageTotal":2`)
+		assert.Contains(t, string(body), `"sizes":[{"value":"M","label":"M","count":12}]`)
+		productService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid sort option", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product", productHandler.GetAllProducts)
+
+		req := httptest.NewRequest("GET", "/product?sort=random", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		productService.AssertNotCalled(t, "GetAllProducts", mock.Anything)
+	})
+
+	t.Run("Min price greater than max price", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product", productHandler.GetAllProducts)
+
+		req := httptest.NewRequest("GET", "/product?minPrice=500&maxPrice=100", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "minPrice must be less than maxPrice")
+	})
+}
+
+func TestSearchProducts(t *testing.T) {
+	t.Run("SearchProducts Success", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+
+		productService.On("SearchProducts", "linen shirt", uint(1), uint(12)).Return([]servicesPkg.ProductSearchResult{
+			{
+				Product:    models.Product{Model: gorm.Model{ID: 2}, Name: "Linen Shirt"},
+				Score:      1.8,
+				Highlights: map[string]string{"name": "<mark>Linen</mark> <mark>Shirt</mark>"},
+			},
+		}, int64(1), nil)
+
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/search", productHandler.SearchProducts)
+
+		req := httptest.NewRequest("GET", "/product/search?q=linen%20shirt", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), `"score":1.8`)
+		assert.Contains(t, string(body), `"name":"Linen Shirt"`)
+		assert.Contains(t, string(body), `\u003cmark\u003eLinen\u003c/mark\u003e`)
+		productService.AssertExpectations(t)
+	})
+
+	t.Run("Missing query", func(t *testing.T) {
+		productService := services.NewProductServiceMock()
+		productHandler := handlers.NewProductHandler(productService)
+
+		app := fiber.New()
+		app.Get("/product/search", productHandler.SearchProducts)
+
+		req := httptest.NewRequest("GET", "/product/search", nil)
+
+		res, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+		productService.AssertNotCalled(t, "SearchProducts", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ProductImageHandler struct {
+	productImageService services.ProductImageServiceInterface
+}
+
+func NewProductImageHandler(productImageService services.ProductImageServiceInterface) *ProductImageHandler {
+	return &ProductImageHandler{productImageService: productImageService}
+}
+
+// NOTE - รับไฟล์จาก multipart field "image" ทีละรูป
+func (h *ProductImageHandler) Upload(c *fiber.Ctx) error {
+	productID, err := c.ParamsInt("id")
+	if err != nil || productID <= 0 {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid product ID")
+	}
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Image file is required")
+	}
+
+	if fileHeader.Size > int64(services.ProductImageMaxBytes()) {
+		return JSONError(c, fiber.StatusRequestEntityTooLarge, "Image file is too large")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid image file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid image file")
+	}
+
+	image, err := h.productImageService.UploadProductImage(uint(productID), data)
+	if err != nil {
+		if errors.Is(err, services.ErrImageStorage) {
+			return JSONError(c, fiber.StatusInternalServerError, "Error saving product image")
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Product image uploaded successfully", toProductImageDTO(*image))
+}
+
+func (h *ProductImageHandler) Reorder(c *fiber.Ctx) error {
+	productID, err := c.ParamsInt("id")
+	if err != nil || productID <= 0 {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid product ID")
+	}
+
+	var req dto.ReorderProductImagesDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		// NOTE - บอกว่า field ไหนผิด
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	images, err := h.productImageService.ReorderProductImages(uint(productID), req.ImageIDs, req.PrimaryImageID)
+	if err != nil {
+		if errors.Is(err, services.ErrImageStorage) {
+			return JSONError(c, fiber.StatusInternalServerError, "Error saving product image")
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Product images reordered successfully", toProductImageDTOs(images))
+}
+
+func (h *ProductImageHandler) Delete(c *fiber.Ctx) error {
+	productID, err := c.ParamsInt("id")
+	if err != nil || productID <= 0 {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid product ID")
+	}
+
+	imageID, err := c.ParamsInt("imageId")
+	if err != nil || imageID <= 0 {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid image ID")
+	}
+
+	err = h.productImageService.DeleteProductImage(uint(productID), uint(imageID))
+	if err != nil {
+		if errors.Is(err, services.ErrImageStorage) {
+			return JSONError(c, fiber.StatusInternalServerError, "Error deleting product image")
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Product image deleted successfully", nil)
+}
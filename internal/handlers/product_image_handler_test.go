@@ -0,0 +1,236 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func multipartImageBody(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile(field, "photo.png")
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	return body, writer.FormDataContentType()
+}
+
+func TestUploadProductImage(t *testing.T) {
+	t.Run("Upload Success", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("UploadProductImage", uint(7), []byte("image-bytes")).Return(&models.ProductImage{
+			Model:     gorm.Model{ID: 3},
+			URL:       "/uploads/products/7/abc.png",
+			IsPrimary: true,
+			Thumbnails: []models.ProductImageThumbnail{
+				{Size: "small", Width: 150, Height: 75, URL: "/uploads/products/7/abc-small.png"},
+			},
+		}, nil)
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Post("/product/:id/images", productImageHandler.Upload)
+
+		body, contentType := multipartImageBody(t, "image", []byte("image-bytes"))
+		httpReq := httptest.NewRequest("POST", "/product/7/images", body)
+		httpReq.Header.Set("Content-Type", contentType)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), `"isPrimary":true`)
+		assert.Contains(t, string(resBody), `"size":"small"`)
+		productImageService.AssertExpectations(t)
+	})
+
+	t.Run("Missing image field", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Post("/product/:id/images", productImageHandler.Upload)
+
+		body, contentType := multipartImageBody(t, "file", []byte("image-bytes"))
+		httpReq := httptest.NewRequest("POST", "/product/7/images", body)
+		httpReq.Header.Set("Content-Type", contentType)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "Image file is required")
+	})
+
+	t.Run("Image too large", func(t *testing.T) {
+		t.Setenv("PRODUCT_IMAGE_MAX_BYTES", "4")
+		productImageService := servicesMock.NewProductImageServiceMock()
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Post("/product/:id/images", productImageHandler.Upload)
+
+		body, contentType := multipartImageBody(t, "image", []byte("image-bytes"))
+		httpReq := httptest.NewRequest("POST", "/product/7/images", body)
+		httpReq.Header.Set("Content-Type", contentType)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, res.StatusCode)
+		productImageService.AssertNotCalled(t, "UploadProductImage")
+	})
+
+	t.Run("Invalid image type", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("UploadProductImage", uint(7), []byte("image-bytes")).Return(nil, fmt.Errorf("Only JPEG and PNG images are allowed"))
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Post("/product/:id/images", productImageHandler.Upload)
+
+		body, contentType := multipartImageBody(t, "image", []byte("image-bytes"))
+		httpReq := httptest.NewRequest("POST", "/product/7/images", body)
+		httpReq.Header.Set("Content-Type", contentType)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "Only JPEG and PNG images are allowed")
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("UploadProductImage", uint(7), []byte("image-bytes")).Return(nil, fmt.Errorf("%w: bucket unavailable", servicesPkg.ErrImageStorage))
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Post("/product/:id/images", productImageHandler.Upload)
+
+		body, contentType := multipartImageBody(t, "image", []byte("image-bytes"))
+		httpReq := httptest.NewRequest("POST", "/product/7/images", body)
+		httpReq.Header.Set("Content-Type", contentType)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "Error saving product image")
+	})
+}
+
+func TestReorderProductImages(t *testing.T) {
+	t.Run("Reorder Success", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("ReorderProductImages", uint(7), []uint{3, 1}, uint(3)).Return([]models.ProductImage{
+			{Model: gorm.Model{ID: 3}, URL: "c", Position: 0, IsPrimary: true},
+			{Model: gorm.Model{ID: 1}, URL: "a", Position: 1},
+		}, nil)
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Put("/product/:id/images/order", productImageHandler.Reorder)
+
+		httpReq := httptest.NewRequest("PUT", "/product/7/images/order", bytes.NewReader([]byte(`{"imageIds":[3,1],"primaryImageId":3}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "Product images reordered successfully")
+		productImageService.AssertExpectations(t)
+	})
+
+	t.Run("Empty image list", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Put("/product/:id/images/order", productImageHandler.Reorder)
+
+		httpReq := httptest.NewRequest("PUT", "/product/7/images/order", bytes.NewReader([]byte(`{"imageIds":[]}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "Validation error")
+	})
+}
+
+func TestDeleteProductImage(t *testing.T) {
+	t.Run("Delete Success", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("DeleteProductImage", uint(7), uint(3)).Return(nil)
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Delete("/product/:id/images/:imageId", productImageHandler.Delete)
+
+		res, err := app.Test(httptest.NewRequest("DELETE", "/product/7/images/3", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		productImageService.AssertExpectations(t)
+	})
+
+	t.Run("Must keep minimum images", func(t *testing.T) {
+		productImageService := servicesMock.NewProductImageServiceMock()
+
+		productImageService.On("DeleteProductImage", uint(7), uint(3)).Return(fmt.Errorf("A product must keep at least 1 images"))
+
+		productImageHandler := handlers.NewProductImageHandler(productImageService)
+
+		app := fiber.New()
+		app.Delete("/product/:id/images/:imageId", productImageHandler.Delete)
+
+		res, err := app.Test(httptest.NewRequest("DELETE", "/product/7/images/3", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		resBody, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(resBody), "A product must keep at least 1 images")
+	})
+}
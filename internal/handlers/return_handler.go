@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReturnHandlerInterface interface {
+	CreateReturn(c *fiber.Ctx) error
+	GetMyReturns(c *fiber.Ctx) error
+	GetAllReturns(c *fiber.Ctx) error
+	ApproveReturn(c *fiber.Ctx) error
+	RejectReturn(c *fiber.Ctx) error
+}
+
+type ReturnHandler struct {
+	returnService services.ReturnServiceInterface
+}
+
+func NewReturnHandler(returnService services.ReturnServiceInterface) *ReturnHandler {
+	return &ReturnHandler{returnService: returnService}
+}
+
+func (h *ReturnHandler) CreateReturn(c *fiber.Ctx) error {
+	// NOTE - ดึง userID จาก Locals แล้วแปลง string -> uint
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req dto.CreateReturnRequestDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	// NOTE - Validate request body
+	if err := Validate.Struct(req); err != nil {
+		// NOTE - บอกว่า field ไหนผิด
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	returnRequest, err := h.returnService.CreateReturn(uint(userIDUint), uint(orderID), req)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Return request created successfully", fiber.Map{
+		"id":     returnRequest.ID,
+		"status": returnRequest.Status,
+	})
+}
+
+func (h *ReturnHandler) GetMyReturns(c *fiber.Ctx) error {
+	userIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	returnRequests, err := h.returnService.GetReturnsByUser(uint(userIDUint), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get return requests success", returnRequests)
+}
+
+func (h *ReturnHandler) GetAllReturns(c *fiber.Ctx) error {
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	returnRequests, err := h.returnService.GetAllReturns(params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get return requests success", returnRequests)
+}
+
+func (h *ReturnHandler) ApproveReturn(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid return request ID")
+	}
+
+	var req dto.ApproveReturnDTO
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+		}
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	if err := h.returnService.ApproveReturn(uint(adminIDUint), uint(id), req); err != nil {
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Return request approved successfully", nil)
+}
+
+func (h *ReturnHandler) RejectReturn(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid return request ID")
+	}
+
+	var req dto.RejectReturnDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	if err := h.returnService.RejectReturn(uint(id), req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Return request rejected successfully", nil)
+}
@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateReturn(t *testing.T) {
+	t.Run("CreateReturn Success", func(t *testing.T) {
+		returnService := servicesMock.NewReturnServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		req := dto.CreateReturnRequestDTO{OrderItemID: 10, Quantity: 1, Reason: "Broken"}
+		returnService.On("CreateReturn", uint(1), uint(3), req).Return(&models.ReturnRequest{Model: gorm.Model{ID: 7}, Status: models.ReturnRequested}, nil)
+
+		returnHandler := handlers.NewReturnHandler(returnService)
+
+		app := fiber.New()
+		app.Post("/user/order/:id/returns", testMiddleware, returnHandler.CreateReturn)
+
+		reqBody := []byte(`{"orderItemId": 10, "quantity": 1, "reason": "Broken"}`)
+		httpReq := httptest.NewRequest("POST", "/user/order/3/returns", bytes.NewReader(reqBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+		returnService.AssertExpectations(t)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		returnService := servicesMock.NewReturnServiceMock()
+
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		returnHandler := handlers.NewReturnHandler(returnService)
+
+		app := fiber.New()
+		app.Post("/user/order/:id/returns", testMiddleware, returnHandler.CreateReturn)
+
+		reqBody := []byte(`{"orderItemId": 10, "quantity": 1}`)
+		httpReq := httptest.NewRequest("POST", "/user/order/3/returns", bytes.NewReader(reqBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Validation error")
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		returnHandler := handlers.NewReturnHandler(servicesMock.NewReturnServiceMock())
+
+		app := fiber.New()
+		app.Post("/user/order/:id/returns", returnHandler.CreateReturn)
+
+		httpReq := httptest.NewRequest("POST", "/user/order/3/returns", nil)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func TestApproveReturn(t *testing.T) {
+	t.Run("ApproveReturn Success", func(t *testing.T) {
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		returnService := servicesMock.NewReturnServiceMock()
+
+		returnService.On("ApproveReturn", uint(1), uint(7), dto.ApproveReturnDTO{}).Return(nil)
+
+		returnHandler := handlers.NewReturnHandler(returnService)
+
+		app := fiber.New()
+		app.Patch("/admin/returns/:id/approve", testMiddleware, returnHandler.ApproveReturn)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/returns/7/approve", nil)
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		returnService.AssertExpectations(t)
+	})
+
+	t.Run("Refund failed", func(t *testing.T) {
+		testMiddleware := func(c *fiber.Ctx) error {
+			c.Locals("userID", "1")
+			return c.Next()
+		}
+
+		returnService := servicesMock.NewReturnServiceMock()
+
+		amount := 50.0
+		returnService.On("ApproveReturn", uint(1), uint(7), dto.ApproveReturnDTO{RefundAmount: &amount}).Return(errors.New("refund failed: card_declined"))
+
+		returnHandler := handlers.NewReturnHandler(returnService)
+
+		app := fiber.New()
+		app.Patch("/admin/returns/:id/approve", testMiddleware, returnHandler.ApproveReturn)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/returns/7/approve", bytes.NewReader([]byte(`{"refundAmount": 50}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "refund failed")
+	})
+}
+
+func TestRejectReturn(t *testing.T) {
+	t.Run("RejectReturn Success", func(t *testing.T) {
+		returnService := servicesMock.NewReturnServiceMock()
+
+		returnService.On("RejectReturn", uint(7), dto.RejectReturnDTO{AdminNote: "Used item"}).Return(nil)
+
+		returnHandler := handlers.NewReturnHandler(returnService)
+
+		app := fiber.New()
+		app.Patch("/admin/returns/:id/reject", returnHandler.RejectReturn)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/returns/7/reject", bytes.NewReader([]byte(`{"adminNote": "Used item"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+		returnService.AssertExpectations(t)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"strconv"
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/gofiber/fiber/v2"
 )
@@ -35,20 +37,23 @@ func (h *ReviewHandler) GetUserReviews(c *fiber.Ctx) error {
 	if err != nil {
 		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
 	}
-	reviews, err := h.ReviewService.GetReviewsByUserID(uint(userIDUint))
+	params, err := pagination.ParseParams(c)
 	if err != nil {
-		return JSONError(c, fiber.StatusInternalServerError, "Failed to get reviews")
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
 	}
 
-	var reviewProduct []dto.ReviewResponse
+	reviews, err := h.ReviewService.GetReviewsByUserID(uint(userIDUint), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Failed to get reviews")
+	}
 
-	for _,item := range reviews {
-		reviewProduct = append(reviewProduct,dto.ReviewResponse{
+	reviewProduct := pagination.Map(reviews, func(item models.Review) dto.ReviewResponse {
+		return dto.ReviewResponse{
 			ProductID: item.ProductID,
 			Rating: item.Rating,
 			Comment: item.Comment,
-		} )
-	}
+		}
+	})
 
 	return JSONSuccess(c, fiber.StatusOK, "User reviews", reviewProduct)
 }
@@ -85,7 +90,12 @@ func (h *ReviewHandler) GetReviewProductAllByProductId(c *fiber.Ctx) error {
 		return JSONError(c, fiber.StatusBadRequest, "Invalid product ID")
 	}
 
-	response, err := h.ReviewService.GetReviewAll(uint(productId))
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	response, err := h.ReviewService.GetReviewAll(uint(productId), params)
 	if err != nil {
 		return JSONError(c, fiber.StatusInternalServerError, err.Error())
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -36,7 +37,7 @@ func TestGetUserReviews(t *testing.T) {
 			return c.Next()
 		}
 
-		reviewService.On("GetReviewsByUserID",uint(1)).Return(reviews,nil)
+		reviewService.On("GetReviewsByUserID",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(pagination.Page[models.Review]{Items: reviews},nil)
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
@@ -75,7 +76,7 @@ func TestGetUserReviews(t *testing.T) {
 			return c.Next()
 		}
 
-		reviewService.On("GetReviewsByUserID",uint(1)).Return(reviews,nil)
+		reviewService.On("GetReviewsByUserID",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(pagination.Page[models.Review]{Items: reviews},nil)
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
@@ -114,7 +115,7 @@ func TestGetUserReviews(t *testing.T) {
 			return c.Next()
 		}
 
-		reviewService.On("GetReviewsByUserID",uint(1)).Return(reviews,nil)
+		reviewService.On("GetReviewsByUserID",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(pagination.Page[models.Review]{Items: reviews},nil)
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
@@ -142,7 +143,7 @@ func TestGetUserReviews(t *testing.T) {
 			return c.Next()
 		}
 
-		reviewService.On("GetReviewsByUserID",uint(1)).Return(nil,errors.New("Failed to get reviews"))
+		reviewService.On("GetReviewsByUserID",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(nil,errors.New("Failed to get reviews"))
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
@@ -343,23 +344,25 @@ func TestGetReviewProductAllByProductId(t *testing.T) {
 		reviewMock := dto.ReviewAllProductSummaryResponse{
 			Average: 4,
 			Total: 4,
-			ReviewList: []dto.ReviewAllProduct{
-				{
-					FirstName: "A",
-					Rating: 4,
+			ReviewList: pagination.Page[dto.ReviewAllProduct]{
+				Items: []dto.ReviewAllProduct{
+					{
+						FirstName: "A",
+						Rating: 4,
+					},
 				},
 			},
 		}
 		reviewService := servicesMock.NewReviewServiceMock()
 
-		reviewService.On("GetReviewAll",uint(1)).Return(reviewMock,nil)
+		reviewService.On("GetReviewAll",uint(1),pagination.Params{Limit: 5}).Return(reviewMock,nil)
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
 		app := fiber.New()
 		app.Get("/product/review-all/:id",reviewHandler.GetReviewProductAllByProductId)
 
-		req :=httptest.NewRequest("GET","/product/review-all/1",nil)
+		req :=httptest.NewRequest("GET","/product/review-all/1?limit=5",nil)
 		req.Header.Set("Content-Type","application/json")
 
 		res,err := app.Test(req)
@@ -369,6 +372,8 @@ func TestGetReviewProductAllByProductId(t *testing.T) {
 
 		body, _ := io.ReadAll(res.Body)
 		assert.Contains(t, string(body), "Get Review Product successfully")
+		assert.Contains(t, string(body), `"items":[`)
+		reviewService.AssertExpectations(t)
 	})
 
 	t.Run("Invalid Param Url",func(t *testing.T) {
@@ -395,7 +400,7 @@ func TestGetReviewProductAllByProductId(t *testing.T) {
 	t.Run("Error to get ReviewByProductID",func(t *testing.T) {
 		reviewService := servicesMock.NewReviewServiceMock()
 
-		reviewService.On("GetReviewAll",uint(1)).Return(nil,errors.New("Error to get reviewByProductID"))
+		reviewService.On("GetReviewAll",uint(1),pagination.Params{Limit: pagination.DefaultLimit}).Return(nil,errors.New("Error to get reviewByProductID"))
 
 		reviewHandler :=  handlers.NewReviewHandler(reviewService)
 
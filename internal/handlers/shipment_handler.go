@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ShipmentHandlerInterface interface {
+	CreateShipment(c *fiber.Ctx) error
+	UpdateShipment(c *fiber.Ctx) error
+	GetShipmentsByOrderID(c *fiber.Ctx) error
+}
+
+type ShipmentHandler struct {
+	shipmentService services.ShipmentServiceInterface
+}
+
+func NewShipmentHandler(shipmentService services.ShipmentServiceInterface) *ShipmentHandler {
+	return &ShipmentHandler{shipmentService: shipmentService}
+}
+
+func (h *ShipmentHandler) CreateShipment(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	var req dto.CreateShipmentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	if err := Validate.Struct(req); err != nil {
+		var messages []string
+		for _, err := range err.(validator.ValidationErrors) {
+			messages = append(messages, err.Field()+" is "+err.Tag())
+		}
+		return JSONError(c, fiber.StatusBadRequest, "Validation error: "+strings.Join(messages, ", "))
+	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	shipment, err := h.shipmentService.CreateShipment(uint(adminIDUint), uint(orderID), req)
+	if err != nil {
+		// NOTE - order ยังไม่จ่ายเงินหรือถูกยกเลิกไปแล้ว ตอบ 409
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusCreated, "Shipment created successfully", toShipmentResponseDTO(*shipment))
+}
+
+func (h *ShipmentHandler) UpdateShipment(c *fiber.Ctx) error {
+	shipmentID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid shipment ID")
+	}
+
+	var req dto.UpdateShipmentDTO
+	if err := c.BodyParser(&req); err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid request body")
+	}
+
+	adminIDStr, ok := c.Locals("userID").(string)
+	if !ok {
+		return JSONError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	adminIDUint, err := strconv.ParseUint(adminIDStr, 10, 64)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, "Invalid user ID format")
+	}
+
+	shipment, err := h.shipmentService.UpdateShipment(uint(adminIDUint), uint(shipmentID), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatusTransition) {
+			return JSONError(c, fiber.StatusConflict, err.Error())
+		}
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Shipment updated successfully", toShipmentResponseDTO(*shipment))
+}
+
+func (h *ShipmentHandler) GetShipmentsByOrderID(c *fiber.Ctx) error {
+	orderID, err := c.ParamsInt("id")
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid order ID")
+	}
+
+	params, err := pagination.ParseParams(c)
+	if err != nil {
+		return JSONError(c, fiber.StatusBadRequest, "Invalid cursor")
+	}
+
+	shipments, err := h.shipmentService.GetShipmentsByOrderID(uint(orderID), params)
+	if err != nil {
+		return JSONError(c, fiber.StatusInternalServerError, err.Error())
+	}
+
+	return JSONSuccess(c, fiber.StatusOK, "Get shipments success", pagination.Map(shipments, toShipmentResponseDTO))
+}
+
+func toShipmentResponseDTO(shipment models.Shipment) dto.ShipmentResponseDTO {
+	shippedAt := ""
+	if shipment.ShippedAt != nil {
+		shippedAt = shipment.ShippedAt.Format("2006-01-02 15:04:05")
+	}
+
+	deliveredAt := ""
+	if shipment.DeliveredAt != nil {
+		deliveredAt = shipment.DeliveredAt.Format("2006-01-02 15:04:05")
+	}
+
+	items := []dto.ShipmentItemResponseDTO{}
+	for _, item := range shipment.Items {
+		items = append(items, dto.ShipmentItemResponseDTO{
+			OrderItemID: item.OrderItemID,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	return dto.ShipmentResponseDTO{
+		ID:             shipment.ID,
+		OrderID:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		ShippedAt:      shippedAt,
+		DeliveredAt:    deliveredAt,
+		Items:          items,
+	}
+}
+
+func toShipmentResponseDTOs(shipments []models.Shipment) []dto.ShipmentResponseDTO {
+	response := []dto.ShipmentResponseDTO{}
+	for _, shipment := range shipments {
+		response = append(response, toShipmentResponseDTO(shipment))
+	}
+
+	return response
+}
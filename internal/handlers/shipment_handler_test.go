@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateShipment(t *testing.T) {
+	testMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userID", "9")
+		return c.Next()
+	}
+
+	t.Run("CreateShipment Success", func(t *testing.T) {
+		shipmentService := servicesMock.NewShipmentServiceMock()
+
+		now := time.Now()
+		req := dto.CreateShipmentDTO{Carrier: "Kerry", TrackingNumber: "KER123"}
+		shipmentService.On("CreateShipment", uint(9), uint(1), req).Return(&models.Shipment{
+			Model:          gorm.Model{ID: 3},
+			OrderID:        1,
+			Carrier:        "Kerry",
+			TrackingNumber: "KER123",
+			ShippedAt:      &now,
+			Items:          []models.ShipmentItem{{OrderItemID: 10, Quantity: 2}},
+		}, nil)
+
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+
+		app := fiber.New()
+		app.Post("/admin/order/:id/shipments", testMiddleware, shipmentHandler.CreateShipment)
+
+		httpReq := httptest.NewRequest("POST", "/admin/order/1/shipments", bytes.NewReader([]byte(`{"carrier":"Kerry","trackingNumber":"KER123"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "KER123")
+		shipmentService.AssertExpectations(t)
+	})
+
+	t.Run("Missing tracking number", func(t *testing.T) {
+		shipmentService := servicesMock.NewShipmentServiceMock()
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+
+		app := fiber.New()
+		app.Post("/admin/order/:id/shipments", testMiddleware, shipmentHandler.CreateShipment)
+
+		httpReq := httptest.NewRequest("POST", "/admin/order/1/shipments", bytes.NewReader([]byte(`{"carrier":"Kerry"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "TrackingNumber is required")
+	})
+
+	t.Run("Order not ready to ship", func(t *testing.T) {
+		shipmentService := servicesMock.NewShipmentServiceMock()
+
+		req := dto.CreateShipmentDTO{Carrier: "Kerry", TrackingNumber: "KER123"}
+		shipmentService.On("CreateShipment", uint(9), uint(1), req).Return(nil, fmt.Errorf("%w: cannot ship order in status pending", servicesPkg.ErrInvalidStatusTransition))
+
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+
+		app := fiber.New()
+		app.Post("/admin/order/:id/shipments", testMiddleware, shipmentHandler.CreateShipment)
+
+		httpReq := httptest.NewRequest("POST", "/admin/order/1/shipments", bytes.NewReader([]byte(`{"carrier":"Kerry","trackingNumber":"KER123"}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusConflict, res.StatusCode)
+	})
+}
+
+func TestUpdateShipment(t *testing.T) {
+	testMiddleware := func(c *fiber.Ctx) error {
+		c.Locals("userID", "9")
+		return c.Next()
+	}
+
+	t.Run("Mark shipment delivered", func(t *testing.T) {
+		shipmentService := servicesMock.NewShipmentServiceMock()
+
+		now := time.Now()
+		shipmentService.On("UpdateShipment", uint(9), uint(3), dto.UpdateShipmentDTO{Delivered: true}).Return(&models.Shipment{
+			Model:       gorm.Model{ID: 3},
+			OrderID:     1,
+			DeliveredAt: &now,
+		}, nil)
+
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+
+		app := fiber.New()
+		app.Patch("/admin/shipments/:id", testMiddleware, shipmentHandler.UpdateShipment)
+
+		httpReq := httptest.NewRequest("PATCH", "/admin/shipments/3", bytes.NewReader([]byte(`{"delivered":true}`)))
+		httpReq.Header.Set("Content-Type", "application/json")
+
+		res, err := app.Test(httpReq)
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), now.Format("2006-01-02 15:04:05"))
+		shipmentService.AssertExpectations(t)
+	})
+}
+
+func TestGetShipmentsByOrderID(t *testing.T) {
+	t.Run("GetShipmentsByOrderID Success", func(t *testing.T) {
+		shipmentService := servicesMock.NewShipmentServiceMock()
+
+		cursor := pagination.Cursor{AfterID: 2}
+		shipmentService.On("GetShipmentsByOrderID", uint(1), pagination.Params{Limit: 1, Cursor: &cursor}).Return(pagination.Page[models.Shipment]{
+			Items: []models.Shipment{
+				{Model: gorm.Model{ID: 3}, OrderID: 1, Carrier: "Kerry", TrackingNumber: "KE123"},
+			},
+			NextCursor: pagination.Encode(pagination.Cursor{AfterID: 3}),
+		}, nil)
+
+		shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/shipments", shipmentHandler.GetShipmentsByOrderID)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/admin/order/1/shipments?limit=1&cursor="+pagination.Encode(cursor), nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "KE123")
+		assert.Contains(t, string(body), `"nextCursor":"`+pagination.Encode(pagination.Cursor{AfterID: 3})+`"`)
+		shipmentService.AssertExpectations(t)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		shipmentHandler := handlers.NewShipmentHandler(servicesMock.NewShipmentServiceMock())
+
+		app := fiber.New()
+		app.Get("/admin/order/:id/shipments", shipmentHandler.GetShipmentsByOrderID)
+
+		res, err := app.Test(httptest.NewRequest("GET", "/admin/order/1/shipments?cursor=bad", nil))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+	})
+}
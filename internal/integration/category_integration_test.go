@@ -13,6 +13,7 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/middleware"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
@@ -30,7 +31,11 @@ func setUpAppCategory() *fiber.App {
 	jwtUtil := utils.NewJwt()
 
 	categoryRepo := repositories.NewCategoryRepository(config.TestDB)
-	categoryService := services.NewCategoryService(categoryRepo)
+	searchIndex := search.NewPostgresSearchIndex(config.TestDB)
+	if err := searchIndex.Migrate(); err != nil {
+		log.Fatalf("Failed to prepare search index: %v", err)
+	}
+	categoryService := services.NewCategoryService(categoryRepo, searchIndex)
 
 	userRepo := repositories.NewUserRepository(config.TestDB)
 	userService := services.NewUserService(userRepo,hashPassword,jwtUtil)
@@ -52,11 +57,23 @@ func setUpAppCategory() *fiber.App {
 
 func clearDataBaseUserCategory(){
 	tables := []string{
+		"coupon_redemptions",
+		"coupon_categories",
+		"coupon_products",
+		"return_requests",
+		"shipment_items",
+		"shipments",
+		"order_status_events",
+		"stock_reservations",
+		"inventory_movements",
 		"order_items",
 		"orders",
 		"payments",
+		"stripe_events",
 		"reviews",
 		"cart_items",
+		"product_slug_redirects",
+		"product_image_thumbnails",
 		"product_images",
 		"product_variants",
 		"products",
@@ -8,6 +8,7 @@ import (
 	"log"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/Beluga-Whale/ecommerce-api/config"
@@ -15,7 +16,9 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
 	"github.com/Beluga-Whale/ecommerce-api/internal/middleware"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -38,8 +41,12 @@ func setUpAppOrder() *fiber.App {
 	categoryRepo := repositories.NewCategoryRepository(config.TestDB)
 	
 	userService := services.NewUserService(userRepo, hashPassword, jwtUtil)
-	categoryService := services.NewCategoryService(categoryRepo)
-	productService:= services.NewProductService(productRepo,categoryRepo)
+	searchIndex := search.NewPostgresSearchIndex(config.TestDB)
+	categoryService := services.NewCategoryService(categoryRepo, searchIndex)
+	if err := searchIndex.Migrate(); err != nil {
+		log.Fatalf("Failed to prepare search index: %v", err)
+	}
+	productService:= services.NewProductService(productRepo, categoryRepo, searchIndex, storage.NewMemoryStorage())
 	orderService := services.NewOrderService(config.TestDB,orderRepo,productUtil)
 	
 	userHandler := handlers.NewUserHandler(userService)
@@ -77,11 +84,23 @@ func setUpAppOrder() *fiber.App {
 
 func clearDataBaseOrder(){
 	tables := []string{
+		"coupon_redemptions",
+		"coupon_categories",
+		"coupon_products",
+		"return_requests",
+		"shipment_items",
+		"shipments",
+		"order_status_events",
+		"stock_reservations",
+		"inventory_movements",
 		"order_items",
 		"orders",
 		"payments",
+		"stripe_events",
 		"reviews",
 		"cart_items",
+		"product_slug_redirects",
+		"product_image_thumbnails",
 		"product_images",
 		"product_variants",
 		"products",
@@ -992,4 +1011,95 @@ func TestGetOrderByIDIntegration(t *testing.T) {
 		assert.Contains(t, string(getOrderIdResBody), "Error go get order by ID")
 
 	})
-}
\ No newline at end of file
+}
+func TestCreateOrderConcurrencyIntegration(t *testing.T){
+	t.Run("Integration CreateOrder last unit sold only once",func(t *testing.T) {
+		clearDataBaseOrder()
+		app := setUpAppOrder()
+
+		token := RegisterAndLoginOrder(t, app, "orderuser@gmail.com", "password")
+
+		categoryID := CreateCategoryOrder(t, app, token, "Clothing")
+
+		err := CreateProductOrder(t, app, token, categoryID)
+		require.NoError(t, err)
+
+		// NOTE - size M มี stock เหลือ 1 ชิ้น
+		var variantID uint
+		err = config.TestDB.
+			Table("product_variants").
+			Select("id").
+			Where("sku = ?", "T-SHIRT-M").
+			Scan(&variantID).Error
+		require.NoError(t, err)
+
+		orderPayload := dto.CreateOrderRequestDTO{
+			FullName:    "Thanathat Jivapaiboonsak",
+			Phone:       "0899999999",
+			Address:     "123 Main St",
+			Province:    "Bangkok",
+			District:    "Chatuchak",
+			Subdistrict: "Lat Yao",
+			Zipcode:     "10900",
+			Items: []dto.CreateOrderItemDTO{
+				{VariantID: variantID, Quantity: 1},
+			},
+		}
+
+		body, err := json.Marshal(orderPayload)
+		require.NoError(t, err)
+
+		// NOTE - ยิง order พร้อมกันหลาย request ไปที่ variant เดียวกัน
+		const parallelOrders = 20
+		statusCodes := make(chan int, parallelOrders)
+		var wg sync.WaitGroup
+
+		for i := 0; i < parallelOrders; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				req := httptest.NewRequest("POST", "/user/order", bytes.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Cookie", "jwt="+token)
+
+				res, err := app.Test(req, -1)
+				if err != nil {
+					statusCodes <- 0
+					return
+				}
+				statusCodes <- res.StatusCode
+			}()
+		}
+
+		wg.Wait()
+		close(statusCodes)
+
+		created := 0
+		conflicts := 0
+		for code := range statusCodes {
+			switch code {
+			case fiber.StatusCreated:
+				created++
+			case fiber.StatusConflict:
+				conflicts++
+			}
+		}
+
+		assert.Equal(t, 1, created)
+		assert.Equal(t, parallelOrders-1, conflicts)
+
+		var stock int
+		err = config.TestDB.
+			Table("product_variants").
+			Select("stock").
+			Where("id = ?", variantID).
+			Scan(&stock).Error
+		require.NoError(t, err)
+		assert.Equal(t, 0, stock)
+
+		var orderCount int64
+		config.TestDB.Table("orders").Where("deleted_at IS NULL").Count(&orderCount)
+		assert.Equal(t, int64(1), orderCount)
+	})
+}
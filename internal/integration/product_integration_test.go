@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"log"
+	"mime/multipart"
 	"net/http/httptest"
 	"testing"
 
@@ -14,7 +17,9 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/middleware"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -31,7 +36,8 @@ func setUpAppProduct() *fiber.App {
 	hashPassword := utils.NewPasswordUtil()
 
 	categoryRepo := repositories.NewCategoryRepository(config.TestDB)
-	categoryService := services.NewCategoryService(categoryRepo)
+	searchIndex := search.NewPostgresSearchIndex(config.TestDB)
+	categoryService := services.NewCategoryService(categoryRepo, searchIndex)
 
 	userRepo := repositories.NewUserRepository(config.TestDB)
 	userService := services.NewUserService(userRepo, hashPassword, jwtUtil)
@@ -40,8 +46,14 @@ func setUpAppProduct() *fiber.App {
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 
 	productRepo := repositories.NewProductRepository(config.TestDB)
-	productService:= services.NewProductService(productRepo,categoryRepo)
+	if err := searchIndex.Migrate(); err != nil {
+		log.Fatalf("Failed to prepare search index: %v", err)
+	}
+	imageStorage := storage.NewMemoryStorage()
+	productService:= services.NewProductService(productRepo, categoryRepo, searchIndex, imageStorage)
 	productHandler:= handlers.NewProductHandler(productService) 
+	productImageService := services.NewProductImageService(productRepo, imageStorage)
+	productImageHandler := handlers.NewProductImageHandler(productImageService)
 	// NOTE - Fiber
 	app := fiber.New()
 
@@ -51,24 +63,43 @@ func setUpAppProduct() *fiber.App {
 
 	// NOTE - Category
 	app.Post("/category",middleware.AuthMiddleware(jwtUtil), categoryHandler.Create)
+	app.Post("/category/merge",middleware.AuthMiddleware(jwtUtil), categoryHandler.Merge)
+	app.Delete("/category/:id",middleware.AuthMiddleware(jwtUtil), categoryHandler.Delete)
 
 	// NOTE - Product
 	app.Get("/product",productHandler.GetAllProducts)
+	app.Get("/product/search",productHandler.SearchProducts)
+	app.Get("/product/slug/:slug",productHandler.GetProductBySlug)
 	app.Get("/product/:id",productHandler.GetProductByID)
 	app.Post("/product",middleware.AuthMiddleware(jwtUtil),productHandler.CreateProduct)
 	app.Put("/product/:id",middleware.AuthMiddleware(jwtUtil),productHandler.UpdateProduct)
 	app.Delete("/product/:id",middleware.AuthMiddleware(jwtUtil),productHandler.DeleteProduct)
+	app.Post("/product/:id/images",middleware.AuthMiddleware(jwtUtil),productImageHandler.Upload)
+	app.Put("/product/:id/images/order",middleware.AuthMiddleware(jwtUtil),productImageHandler.Reorder)
+	app.Delete("/product/:id/images/:imageId",middleware.AuthMiddleware(jwtUtil),productImageHandler.Delete)
 
 	return app
 }
 
 func clearDataBaseProduct(){
 	tables := []string{
+		"coupon_redemptions",
+		"coupon_categories",
+		"coupon_products",
+		"return_requests",
+		"shipment_items",
+		"shipments",
+		"order_status_events",
+		"stock_reservations",
+		"inventory_movements",
 		"order_items",
 		"orders",
 		"payments",
+		"stripe_events",
 		"reviews",
 		"cart_items",
+		"product_slug_redirects",
+		"product_image_thumbnails",
 		"product_images",
 		"product_variants",
 		"products",
@@ -383,7 +414,7 @@ func TestCreateProductIntegration(t *testing.T) {
 		})
 	})
 
-	t.Run("Integration CreateProduct Image Must 3",func(t *testing.T) {
+	t.Run("Integration CreateProduct Without Images",func(t *testing.T) {
 		clearDataBaseProduct()
 		app := setUpAppProduct()
 
@@ -396,11 +427,7 @@ func TestCreateProductIntegration(t *testing.T) {
 			"name": "T-Shirt",
 			"title": "test title",
 			"description": "<p>TEST</p>",
-			"images": [
-				{
-					"url": "https://res.cloudinary.com/dnue94koc/image/upload/v1750060281/Beluga_Whale_gtyr3j.webp"
-				}
-			],
+			"images": [],
 			"isFeatured": false,
 			"isOnSale": true,
 			"salePrice": 20,
@@ -433,7 +460,7 @@ func TestCreateProductIntegration(t *testing.T) {
 
 		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
 
-		assert.Contains(t, string(body), "You must upload exactly 3 product images")
+		assert.Contains(t, string(body), "You must upload between 1 and 10 product images")
 		t.Cleanup(func() {
 			clearDataBaseProduct()
 		})
@@ -634,6 +661,12 @@ func TestUpdateProduct(t *testing.T) {
 			t.Fatalf("Failed to fetch category: %v", err)
 		}
 
+		var variantBefore models.ProductVariant
+		if err := config.TestDB.Where("product_id = ?", productId.ID).Order("id ASC").First(&variantBefore).Error; err != nil {
+			t.Fatalf("Failed to fetch variant: %v", err)
+		}
+		firstVariantID := variantBefore.ID
+
 		reqUpdateBody := []byte(fmt.Sprintf(`{
 			"name": "T-Shirt Update",
 			"title": "test title",
@@ -678,6 +711,13 @@ func TestUpdateProduct(t *testing.T) {
 
 		assert.Equal(t, fiber.StatusOK, res.StatusCode, "Product updated successfully")
 
+		//NOTE - variant ที่ SKU เดิมต้องถูกแก้ในแถวเดิม id ไม่เปลี่ยน
+		var variantsAfter []models.ProductVariant
+		if err := config.TestDB.Where("product_id = ?", productId.ID).Order("id ASC").Find(&variantsAfter).Error; err != nil {
+			t.Fatalf("Failed to fetch variants: %v", err)
+		}
+		assert.Equal(t, firstVariantID, variantsAfter[0].ID)
+
 		t.Cleanup(func() {
 			clearDataBaseProduct()
 		})
@@ -1023,7 +1063,7 @@ func TestUpdateProduct(t *testing.T) {
 		})
 	})
 
-	t.Run("Integration Update Product Image Is Equal 3",func(t *testing.T) {
+	t.Run("Integration Update Product Without Images",func(t *testing.T) {
 		clearDataBaseProduct()
 
 		app := setUpAppProduct()
@@ -1092,11 +1132,7 @@ func TestUpdateProduct(t *testing.T) {
 			"name": "T-Shirt Update",
 			"title": "test title",
 			"description": "<p>TEST</p>",
-			"images": [
-				{
-					"url": "https://res.cloudinary.com/dnue94koc/image/upload/v1750060281/Beluga_Whale_gtyr3j.webp"
-				}
-			],
+			"images": [],
 			"isFeatured": false,
 			"isOnSale": true,
 			"salePrice": 20,
@@ -1124,7 +1160,7 @@ func TestUpdateProduct(t *testing.T) {
 
 		body, _= io.ReadAll(res.Body)
 
-		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode, "You must upload exactly 3 product images")
+		assert.Equal(t, fiber.StatusInternalServerError, res.StatusCode, "You must upload between 1 and 10 product images")
 
 		t.Cleanup(func() {
 			clearDataBaseProduct()
@@ -1762,3 +1798,570 @@ func TestGetProductByID(t *testing.T) {
 		})
 	})	
 }
+
+func TestSearchProductIntegration(t *testing.T) {
+	t.Run("Integration SearchProducts ranked with highlight and typo", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		categoryID := CreateCategoryProduct(t, app, token, "Shirts")
+
+		createProduct := func(name string, title string, sku string) {
+			reqBody := []byte(fmt.Sprintf(`{
+				"name": "%s",
+				"title": "%s",
+				"description": "Soft fabric for every day",
+				"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+				"isFeatured": false,
+				"isOnSale": false,
+				"salePrice": 0,
+				"categoryId": %d,
+				"variants": [{"size": "M", "stock": 3, "sku": "%s", "price": 100}]
+			}`, name, title, categoryID, sku))
+
+			req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Cookie", "jwt="+token)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		}
+
+		createProduct("Linen Shirt", "Summer collection", "LINEN-M")
+		createProduct("Denim Jacket", "Linen lined jacket", "DENIM-M")
+
+		req := httptest.NewRequest("GET", "/product/search?q=linen", nil)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var response struct {
+			Data struct {
+				Results []struct {
+					Product struct {
+						Name string `json:"name"`
+					} `json:"product"`
+					Highlights map[string]string `json:"highlights"`
+				} `json:"results"`
+			} `json:"data"`
+		}
+		body, _ := io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		require.Len(t, response.Data.Results, 2)
+		assert.Equal(t, "Linen Shirt", response.Data.Results[0].Product.Name)
+		assert.Contains(t, response.Data.Results[0].Highlights["name"], "<mark>Linen</mark>")
+
+		// NOTE - พิมพ์ผิดยังเจอจาก trigram
+		req = httptest.NewRequest("GET", "/product/search?q=jackt", nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+
+		body, _ = io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Denim Jacket")
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestProductFacetsIntegration(t *testing.T) {
+	t.Run("Integration GetAllProducts returns facet counts", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		shirtsID := CreateCategoryProduct(t, app, token, "Shirts")
+		shoesID := CreateCategoryProduct(t, app, token, "Shoes")
+
+		createProduct := func(name string, categoryID uint, size string, sku string, price int) {
+			reqBody := []byte(fmt.Sprintf(`{
+				"name": "%s",
+				"title": "test title",
+				"description": "Soft fabric for every day",
+				"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+				"isFeatured": false,
+				"isOnSale": false,
+				"salePrice": 0,
+				"categoryId": %d,
+				"variants": [{"size": "%s", "stock": 3, "sku": "%s", "price": %d}]
+			}`, name, categoryID, size, sku, price))
+
+			req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Cookie", "jwt="+token)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusCreated, res.StatusCode)
+		}
+
+		createProduct("Linen Shirt", shirtsID, "M", "SHIRT-M", 300)
+		createProduct("Denim Shirt", shirtsID, "L", "SHIRT-L", 800)
+		createProduct("Runner", shoesID, "42", "SHOE-42", 2500)
+
+		req := httptest.NewRequest("GET", fmt.Sprintf("/product?category=%d", shirtsID), nil)
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var response struct {
+			Data struct {
+				Products []struct{} `json:"products"`
+				Facets   struct {
+					Categories []struct {
+						Label string `json:"label"`
+						Count int64  `json:"count"`
+					} `json:"categories"`
+					Sizes []struct {
+						Value string `json:"value"`
+						Count int64  `json:"count"`
+					} `json:"sizes"`
+					PriceBuckets []struct {
+						Min   float64 `json:"min"`
+						Count int64   `json:"count"`
+					} `json:"priceBuckets"`
+				} `json:"facets"`
+			} `json:"data"`
+		}
+		body, _ := io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		assert.Len(t, response.Data.Products, 2)
+
+		// NOTE - facet category ไม่กรองด้วย category ที่เลือก ยังเห็นจำนวนของหมวดอื่น
+		require.Len(t, response.Data.Facets.Categories, 2)
+		assert.Equal(t, "Shirts", response.Data.Facets.Categories[0].Label)
+		assert.Equal(t, int64(2), response.Data.Facets.Categories[0].Count)
+		assert.Equal(t, int64(1), response.Data.Facets.Categories[1].Count)
+
+		// NOTE - size นับเฉพาะสินค้าใน category ที่เลือก
+		require.Len(t, response.Data.Facets.Sizes, 2)
+		assert.Equal(t, "L", response.Data.Facets.Sizes[0].Value)
+		assert.Equal(t, "M", response.Data.Facets.Sizes[1].Value)
+
+		assert.Equal(t, int64(1), response.Data.Facets.PriceBuckets[0].Count)
+		assert.Equal(t, int64(1), response.Data.Facets.PriceBuckets[1].Count)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestProductFinalPriceIntegration(t *testing.T) {
+	t.Run("Integration GetAllProducts filters sale price above variant price as 0", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		categoryID := CreateCategoryProduct(t, app, token, "Shirts")
+
+		reqBody := []byte(fmt.Sprintf(`{
+			"name": "Clearance Shirt",
+			"title": "test title",
+			"description": "Soft fabric for every day",
+			"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+			"isFeatured": false,
+			"isOnSale": true,
+			"salePrice": 50,
+			"categoryId": %d,
+			"variants": [{"size": "M", "stock": 3, "sku": "CLEAR-M", "price": 100}]
+		}`, categoryID))
+
+		req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		// NOTE - ข้อมูลเก่าที่ส่วนลดมากกว่าราคา variant API แสดงราคา 0 การกรองก็ต้องใช้ 0 ด้วย
+		require.NoError(t, config.TestDB.Model(&models.Product{}).Where("name = ?", "Clearance Shirt").Update("sale_price", 150).Error)
+
+		req = httptest.NewRequest("GET", "/product?minPrice=0&maxPrice=10", nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var response struct {
+			Data struct {
+				Products []struct {
+					Name string `json:"name"`
+				} `json:"products"`
+			} `json:"data"`
+		}
+		body, _ := io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &response))
+
+		require.Len(t, response.Data.Products, 1)
+		assert.Equal(t, "Clearance Shirt", response.Data.Products[0].Name)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestProductSlugIntegration(t *testing.T) {
+	t.Run("Integration product slug with collision suffix and redirect after rename", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		categoryID := CreateCategoryProduct(t, app, token, "Shirts")
+
+		productBody := func(name string, sku string) []byte {
+			return []byte(fmt.Sprintf(`{
+				"name": "%s",
+				"title": "test title",
+				"description": "Soft fabric for every day",
+				"metaTitle": "%s | Shop",
+				"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+				"isFeatured": false,
+				"isOnSale": false,
+				"salePrice": 0,
+				"categoryId": %d,
+				"variants": [{"size": "M", "stock": 3, "sku": "%s", "price": 100}]
+			}`, name, name, categoryID, sku))
+		}
+
+		type productResponse struct {
+			Data struct {
+				ID        uint   `json:"id"`
+				Slug      string `json:"slug"`
+				MetaTitle string `json:"metaTitle"`
+			} `json:"data"`
+		}
+
+		createProduct := func(name string, sku string) productResponse {
+			req := httptest.NewRequest("POST", "/product", bytes.NewReader(productBody(name, sku)))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Cookie", "jwt="+token)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+			var response productResponse
+			body, _ := io.ReadAll(res.Body)
+			require.NoError(t, json.Unmarshal(body, &response))
+			return response
+		}
+
+		first := createProduct("Classic Tee", "TEE-1")
+		second := createProduct("Classic Tee", "TEE-2")
+
+		assert.Equal(t, "classic-tee", first.Data.Slug)
+		assert.Equal(t, "classic-tee-2", second.Data.Slug)
+		assert.Equal(t, "Classic Tee | Shop", first.Data.MetaTitle)
+
+		// NOTE - เปลี่ยนชื่อแล้ว slug เก่าต้อง redirect ไป slug ใหม่
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/product/%d", first.Data.ID), bytes.NewReader(productBody("Oversized Tee", "TEE-1")))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		req = httptest.NewRequest("GET", "/product/slug/oversized-tee", nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		req = httptest.NewRequest("GET", "/product/slug/classic-tee", nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusMovedPermanently, res.StatusCode)
+		assert.Equal(t, "/product/slug/oversized-tee", res.Header.Get("Location"))
+
+		// NOTE - slug เก่าที่ยัง redirect อยู่ สินค้าใหม่จะไม่ได้ไปใช้
+		third := createProduct("Classic Tee", "TEE-3")
+		assert.Equal(t, "classic-tee-3", third.Data.Slug)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestCategoryHierarchyIntegration(t *testing.T) {
+	t.Run("Integration parent category lists products of subcategories with breadcrumbs", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		menID := CreateCategoryProduct(t, app, token, "Men")
+		shoesID := CreateCategoryProduct(t, app, token, "Shoes")
+
+		req := httptest.NewRequest("POST", "/category", bytes.NewReader([]byte(fmt.Sprintf(`{"name": "Shirts", "parentId": %d}`, menID))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		var categoryResponse struct {
+			Data struct {
+				ID uint `json:"id"`
+			} `json:"data"`
+		}
+		body, _ := io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &categoryResponse))
+		shirtsID := categoryResponse.Data.ID
+
+		createProduct := func(name string, categoryID uint, sku string) uint {
+			reqBody := []byte(fmt.Sprintf(`{
+				"name": "%s",
+				"title": "test title",
+				"description": "Soft fabric for every day",
+				"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+				"isFeatured": false,
+				"isOnSale": false,
+				"salePrice": 0,
+				"categoryId": %d,
+				"variants": [{"size": "M", "stock": 3, "sku": "%s", "price": 100}]
+			}`, name, categoryID, sku))
+
+			req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Cookie", "jwt="+token)
+
+			res, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+			var response struct {
+				Data struct {
+					ID uint `json:"id"`
+				} `json:"data"`
+			}
+			body, _ := io.ReadAll(res.Body)
+			require.NoError(t, json.Unmarshal(body, &response))
+			return response.Data.ID
+		}
+
+		shirtID := createProduct("Linen Shirt", shirtsID, "SHIRT-M")
+		createProduct("Runner", shoesID, "SHOE-42")
+
+		// NOTE - เลือก Men ต้องได้สินค้าใน Shirts ที่เป็น category ลูก
+		req = httptest.NewRequest("GET", fmt.Sprintf("/product?category=%d", menID), nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var listResponse struct {
+			Data struct {
+				Products []struct {
+					Name string `json:"name"`
+				} `json:"products"`
+			} `json:"data"`
+		}
+		body, _ = io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &listResponse))
+		require.Len(t, listResponse.Data.Products, 1)
+		assert.Equal(t, "Linen Shirt", listResponse.Data.Products[0].Name)
+
+		req = httptest.NewRequest("GET", fmt.Sprintf("/product/%d", shirtID), nil)
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var detailResponse struct {
+			Data struct {
+				Breadcrumbs []struct {
+					Name string `json:"name"`
+				} `json:"breadcrumbs"`
+			} `json:"data"`
+		}
+		body, _ = io.ReadAll(res.Body)
+		require.NoError(t, json.Unmarshal(body, &detailResponse))
+		require.Len(t, detailResponse.Data.Breadcrumbs, 2)
+		assert.Equal(t, "Men", detailResponse.Data.Breadcrumbs[0].Name)
+		assert.Equal(t, "Shirts", detailResponse.Data.Breadcrumbs[1].Name)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestCategoryDeleteWithProductsIntegration(t *testing.T) {
+	t.Run("Integration delete refuses category with products until a target is given", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		shirtsID := CreateCategoryProduct(t, app, token, "Shirts")
+		topsID := CreateCategoryProduct(t, app, token, "Tops")
+		teesID := CreateCategoryProduct(t, app, token, "Tees")
+
+		reqBody := []byte(fmt.Sprintf(`{
+			"name": "Linen Shirt",
+			"title": "test title",
+			"description": "Soft fabric for every day",
+			"images": [{"url": "https://example.com/1.jpg"}, {"url": "https://example.com/2.jpg"}, {"url": "https://example.com/3.jpg"}],
+			"isFeatured": false,
+			"isOnSale": false,
+			"salePrice": 0,
+			"categoryId": %d,
+			"variants": [{"size": "M", "stock": 3, "sku": "SHIRT-M", "price": 100}]
+		}`, shirtsID))
+
+		req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		req = httptest.NewRequest("DELETE", fmt.Sprintf("/category/%d", shirtsID), nil)
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		body, _ := io.ReadAll(res.Body)
+		assert.Contains(t, string(body), "Category still has products")
+
+		req = httptest.NewRequest("DELETE", fmt.Sprintf("/category/%d?targetCategoryId=%d", shirtsID, topsID), nil)
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var product models.Product
+		require.NoError(t, config.TestDB.Where("name = ?", "Linen Shirt").First(&product).Error)
+		assert.Equal(t, topsID, product.CategoryID)
+
+		// NOTE - merge ย้ายสินค้าจาก Tops ไป Tees แล้วลบ Tops
+		req = httptest.NewRequest("POST", "/category/merge", bytes.NewReader([]byte(fmt.Sprintf(`{"sourceId": %d, "targetId": %d}`, topsID, teesID))))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		require.NoError(t, config.TestDB.Where("name = ?", "Linen Shirt").First(&product).Error)
+		assert.Equal(t, teesID, product.CategoryID)
+
+		var remaining int64
+		config.TestDB.Model(&models.Category{}).Where("id IN ?", []uint{shirtsID, topsID}).Count(&remaining)
+		assert.Equal(t, int64(0), remaining)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
+
+func TestProductImageUploadIntegration(t *testing.T) {
+	t.Run("Integration upload, reorder and delete product images", func(t *testing.T) {
+		clearDataBaseProduct()
+
+		app := setUpAppProduct()
+
+		token := RegisterAndLoginProduct(t, app, "halay@gmail.com", "password")
+		categoryID := CreateCategoryProduct(t, app, token, "Clothing")
+
+		reqBody := []byte(fmt.Sprintf(`{
+			"name": "Linen Shirt",
+			"title": "test title",
+			"description": "Soft fabric for every day",
+			"images": [{"url": "https://example.com/1.jpg"}],
+			"isFeatured": false,
+			"isOnSale": false,
+			"salePrice": 0,
+			"categoryId": %d,
+			"variants": [{"size": "M", "stock": 3, "sku": "SHIRT-M", "price": 100}]
+		}`, categoryID))
+
+		req := httptest.NewRequest("POST", "/product", bytes.NewReader(reqBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		var product models.Product
+		require.NoError(t, config.TestDB.Where("name = ?", "Linen Shirt").First(&product).Error)
+
+		var pngData bytes.Buffer
+		require.NoError(t, png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 600, 300))))
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("image", "photo.png")
+		require.NoError(t, err)
+		part.Write(pngData.Bytes())
+		writer.Close()
+
+		req = httptest.NewRequest("POST", fmt.Sprintf("/product/%d/images", product.ID), body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusCreated, res.StatusCode)
+
+		var images []models.ProductImage
+		require.NoError(t, config.TestDB.Preload("Thumbnails").Where("product_id = ?", product.ID).Order("position").Find(&images).Error)
+		require.Len(t, images, 2)
+		assert.True(t, images[0].IsPrimary)
+		assert.False(t, images[1].IsPrimary)
+		assert.Equal(t, 1, images[1].Position)
+		assert.Len(t, images[1].Thumbnails, 3)
+
+		// NOTE - ย้ายรูปที่อัปโหลดขึ้นไปเป็นรูปแรกและเป็นรูปหลัก
+		reorderBody := []byte(fmt.Sprintf(`{"imageIds": [%d, %d]}`, images[1].ID, images[0].ID))
+		req = httptest.NewRequest("PUT", fmt.Sprintf("/product/%d/images/order", product.ID), bytes.NewReader(reorderBody))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		req = httptest.NewRequest("DELETE", fmt.Sprintf("/product/%d/images/%d", product.ID, images[1].ID), nil)
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, res.StatusCode)
+
+		var remaining []models.ProductImage
+		require.NoError(t, config.TestDB.Where("product_id = ?", product.ID).Find(&remaining).Error)
+		require.Len(t, remaining, 1)
+		assert.Equal(t, images[0].ID, remaining[0].ID)
+		assert.True(t, remaining[0].IsPrimary)
+
+		// NOTE - รูปสุดท้ายลบไม่ได้
+		req = httptest.NewRequest("DELETE", fmt.Sprintf("/product/%d/images/%d", product.ID, images[0].ID), nil)
+		req.Header.Set("Cookie", "jwt="+token)
+
+		res, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusBadRequest, res.StatusCode)
+
+		t.Cleanup(func() {
+			clearDataBaseProduct()
+		})
+	})
+}
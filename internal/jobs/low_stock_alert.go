@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"expvar"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+)
+
+const defaultLowStockAlertInterval = 15 * time.Minute
+
+var lowStockAlertsSent = expvar.NewInt("low_stock_alerts_sent")
+
+type LowStockAlertJob struct {
+	inventoryService services.InventoryServiceInterface
+	notifier         utils.StockAlertNotifierInterface
+	interval         time.Duration
+	stop             chan struct{}
+	done             chan struct{}
+	stopOnce         sync.Once
+}
+
+// NOTE - เช็ค stock ใกล้หมดตามรอบที่ตั้งด้วย LOW_STOCK_ALERT_INTERVAL แล้วแจ้งผ่าน notifier
+func StartLowStockAlertJob(inventoryService services.InventoryServiceInterface, notifier utils.StockAlertNotifierInterface) *LowStockAlertJob {
+	job := &LowStockAlertJob{
+		inventoryService: inventoryService,
+		notifier:         notifier,
+		interval:         utils.DurationFromEnv("LOW_STOCK_ALERT_INTERVAL", defaultLowStockAlertInterval),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+
+	go job.run()
+	log.Printf("Low stock alert scheduler started")
+
+	return job
+}
+
+func (j *LowStockAlertJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+	log.Printf("Low stock alert scheduler stopped")
+}
+
+func (j *LowStockAlertJob) run() {
+	defer close(j.done)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.sendAlerts()
+
+		select {
+		case <-j.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *LowStockAlertJob) sendAlerts() {
+	sent, err := j.inventoryService.SendLowStockAlerts(j.notifier)
+	if err != nil {
+		log.Printf("Error sending low stock alerts: %v", err)
+		return
+	}
+
+	if sent > 0 {
+		lowStockAlertsSent.Add(int64(sent))
+		log.Printf("Sent low stock alerts for %d variants", sent)
+	}
+}
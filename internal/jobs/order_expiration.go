@@ -1,43 +1,141 @@
 package jobs
 
 import (
+	"expvar"
 	"log"
+	"sync"
 	"time"
 
-	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
-	"github.com/robfig/cron"
-	"gorm.io/gorm"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 )
 
-func StartOrderExpirationJob(db *gorm.DB, orderService *services.OrderService) {
-	c := cron.New()
+// NOTE - ถ้าไม่มี order รอจ่ายเงินเลย ให้กลับมาเช็คทุกช่วงนี้ เผื่อมี order ใหม่เข้ามา
+const maxReservationRecheck = 30 * time.Second
 
-	c.AddFunc("@every 15m", func() {
-		// NOTE -เวลาปัจจุบัน
-		now := time.Now()
+const defaultExpirationBatchSize = 100
 
-		var orders []models.Order
-		// NOTE - หา order จาก สถานะเป้น peding และ payment_expire_at
-		if err := db.Model(&models.Order{}).
-			Where("status = ? AND payment_expire_at <= ?", models.Pending, now).
-			Find(&orders).Error; err != nil {
-			log.Printf("Error finding expired orders: %v", err)
+// NOTE - ตัวนับสำหรับ monitoring ดูได้ที่ /debug/vars (admin เท่านั้น)
+var (
+	expiredOrdersCancelled = expvar.NewInt("order_expiration_cancelled")
+	expiredOrdersSkipped   = expvar.NewInt("order_expiration_skipped")
+	expiredOrdersFailed    = expvar.NewInt("order_expiration_failed")
+)
+
+type OrderExpirationJob struct {
+	orderService services.OrderServiceInterface
+	batchSize    int
+	stop         chan struct{}
+	done         chan struct{}
+	stopOnce     sync.Once
+}
+
+func StartOrderExpirationJob(orderService services.OrderServiceInterface) *OrderExpirationJob {
+	job := &OrderExpirationJob{
+		orderService: orderService,
+		batchSize:    utils.IntFromEnv("ORDER_EXPIRATION_BATCH_SIZE", defaultExpirationBatchSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go job.run()
+	log.Printf("Order expiration scheduler started")
+
+	return job
+}
+
+// NOTE - หยุด job และรอให้รอบที่กำลังทำอยู่เสร็จก่อน ใช้ตอนปิด server
+func (j *OrderExpirationJob) Stop() {
+	j.stopOnce.Do(func() {
+		close(j.stop)
+	})
+	<-j.done
+	log.Printf("Order expiration scheduler stopped")
+}
+
+// NOTE - หลับจนถึงเวลาหมดอายุของ order ถัดไป แล้วยกเลิก order ที่หมดเวลาทันที แทนการกวาดทุก 15 นาที
+func (j *OrderExpirationJob) run() {
+	defer close(j.done)
+
+	for {
+		stalled := j.releaseExpiredOrders(time.Now())
+
+		timer := time.NewTimer(j.nextExpirationWait(time.Now(), stalled))
+		select {
+		case <-j.stop:
+			timer.Stop()
 			return
+		case <-timer.C:
 		}
+	}
+}
+
+// NOTE - order ใหม่จะหมดเวลาหลังจากสร้างอย่างน้อยเท่ากับเวลากัน stock จึงเช็คซ้ำถี่กว่านั้นก็ไม่พลาด order ไหน
+func (j *OrderExpirationJob) nextExpirationWait(now time.Time, stalled bool) time.Duration {
+	wait := maxReservationRecheck
+	if hold := services.ReservationHoldDuration(); hold < wait {
+		wait = hold
+	}
 
-		for _, order := range orders{
-			err := orderService.CancelOrderAndRestoreStock(order.ID)
-			if err != nil{
-				log.Printf("Failed to cancel order %d: %v\n",order.ID, err)
-			}else {
-				// NOTE - cancel สำเสร็จและทำการคืน stock เรียบบร้อย
-				log.Printf("Cancelled order %d due to timeout\n", order.ID)
-			}
+	// NOTE - รอบที่แล้วมี order ที่ยกเลิกไม่ผ่าน order นั้นยังหมดเวลาและจะเป็นตัวถัดไปเสมอ
+	// ถ้าตื่นตามเวลาของมันจะวนทุก 100ms จึงรอรอบปกติแทน
+	if stalled {
+		return wait
+	}
+
+	next, err := j.orderService.NextPaymentExpiry()
+	if err != nil {
+		log.Printf("Error finding next order expiration: %v", err)
+		return wait
+	}
+
+	if next != nil {
+		if until := next.Sub(now); until < wait {
+			wait = until
 		}
-	})
+	}
+
+	if wait < 0 {
+		wait = 0
+	}
 
-	c.Start()
-	log.Printf("Order expiration cron job started")
+	// NOTE - เผื่อเวลานิดหน่อยให้ order หมดเวลาแน่นอนตอนตื่นขึ้นมา
+	return wait + 100*time.Millisecond
 }
 
+// NOTE - ทำทีละ batch จนกว่า order ที่หมดเวลาจะหมด หรือได้รับสัญญาณให้หยุด
+// แต่ละ batch เริ่มต่อจาก order ตัวสุดท้ายของ batch ก่อน order ที่ยกเลิกไม่ผ่านจึงไม่บัง order ข้างหลัง
+// คืน true ถ้า error หรือมี order หมดเวลาที่ยังยกเลิกไม่ได้ค้างอยู่
+func (j *OrderExpirationJob) releaseExpiredOrders(now time.Time) bool {
+	var cursor services.ExpirationCursor
+	unresolved := 0
+
+	for {
+		result, err := j.orderService.ExpireOrders(now, cursor, j.batchSize)
+		if err != nil {
+			log.Printf("Error expiring orders: %v", err)
+			return true
+		}
+		cursor = result.Next
+		unresolved += result.Skipped + result.Failed
+
+		expiredOrdersCancelled.Add(int64(result.Cancelled))
+		expiredOrdersSkipped.Add(int64(result.Skipped))
+		expiredOrdersFailed.Add(int64(result.Failed))
+
+		if result.Found > 0 {
+			log.Printf("Order expiration: cancelled %d, skipped %d, failed %d", result.Cancelled, result.Skipped, result.Failed)
+		}
+
+		// NOTE - batch ไม่เต็มแปลว่าหมดแล้ว
+		if result.Found < j.batchSize {
+			return unresolved > 0
+		}
+
+		select {
+		case <-j.stop:
+			return false
+		default:
+		}
+	}
+}
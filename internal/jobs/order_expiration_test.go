@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseExpiredOrders(t *testing.T) {
+	now := time.Now()
+	start := servicesPkg.ExpirationCursor{}
+
+	t.Run("Cancelled every expired order", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		orderService.On("ExpireOrders", now, start, 100).Return(servicesPkg.ExpirationResult{Found: 2, Cancelled: 2}, nil)
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 100, stop: make(chan struct{})}
+
+		assert.False(t, job.releaseExpiredOrders(now))
+		orderService.AssertNumberOfCalls(t, "ExpireOrders", 1)
+	})
+
+	t.Run("Full batch runs again", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		second := servicesPkg.ExpirationCursor{PaymentExpireAt: now.Add(-time.Minute), ID: 8}
+		orderService.On("ExpireOrders", now, start, 2).Return(servicesPkg.ExpirationResult{Found: 2, Cancelled: 2, Next: second}, nil).Once()
+		orderService.On("ExpireOrders", now, second, 2).Return(servicesPkg.ExpirationResult{Found: 1, Cancelled: 1}, nil).Once()
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 2, stop: make(chan struct{})}
+
+		assert.False(t, job.releaseExpiredOrders(now))
+		orderService.AssertNumberOfCalls(t, "ExpireOrders", 2)
+	})
+
+	t.Run("Failed batch does not block orders behind it", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		// NOTE - batch แรกเป็น order เก่าสุดที่ยกเลิกไม่ผ่านทั้งหมด batch ถัดไปต้องเริ่มหลังจากนั้น
+		second := servicesPkg.ExpirationCursor{PaymentExpireAt: now.Add(-time.Hour), ID: 2}
+		third := servicesPkg.ExpirationCursor{PaymentExpireAt: now.Add(-time.Minute), ID: 9}
+		orderService.On("ExpireOrders", now, start, 2).Return(servicesPkg.ExpirationResult{Found: 2, Failed: 2, Next: second}, nil).Once()
+		orderService.On("ExpireOrders", now, second, 2).Return(servicesPkg.ExpirationResult{Found: 2, Cancelled: 2, Next: third}, nil).Once()
+		orderService.On("ExpireOrders", now, third, 2).Return(servicesPkg.ExpirationResult{Found: 1, Cancelled: 1}, nil).Once()
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 2, stop: make(chan struct{})}
+
+		assert.True(t, job.releaseExpiredOrders(now))
+		orderService.AssertExpectations(t)
+		orderService.AssertNumberOfCalls(t, "ExpireOrders", 3)
+	})
+
+	t.Run("Nothing expired", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		orderService.On("ExpireOrders", now, start, 100).Return(servicesPkg.ExpirationResult{}, nil)
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 100, stop: make(chan struct{})}
+
+		assert.False(t, job.releaseExpiredOrders(now))
+	})
+
+	t.Run("Expired order keeps failing", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		orderService.On("ExpireOrders", now, start, 100).Return(servicesPkg.ExpirationResult{Found: 1, Failed: 1}, nil)
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 100, stop: make(chan struct{})}
+
+		assert.True(t, job.releaseExpiredOrders(now))
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		orderService := servicesMock.NewOrderServiceMock()
+		orderService.On("ExpireOrders", now, start, 100).Return(servicesPkg.ExpirationResult{}, errors.New("db down"))
+
+		job := &OrderExpirationJob{orderService: orderService, batchSize: 100, stop: make(chan struct{})}
+
+		assert.True(t, job.releaseExpiredOrders(now))
+	})
+}
+
+func TestNextExpirationWait(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Wake up when next order expires", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		orderService := servicesMock.NewOrderServiceMock()
+		next := now.Add(5 * time.Second)
+		orderService.On("NextPaymentExpiry").Return(&next, nil)
+
+		job := &OrderExpirationJob{orderService: orderService}
+
+		assert.Equal(t, 5*time.Second+100*time.Millisecond, job.nextExpirationWait(now, false))
+	})
+
+	t.Run("Overdue order wakes up right away", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		orderService := servicesMock.NewOrderServiceMock()
+		next := now.Add(-time.Minute)
+		orderService.On("NextPaymentExpiry").Return(&next, nil)
+
+		job := &OrderExpirationJob{orderService: orderService}
+
+		assert.Equal(t, 100*time.Millisecond, job.nextExpirationWait(now, false))
+	})
+
+	t.Run("No pending orders", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		orderService := servicesMock.NewOrderServiceMock()
+		orderService.On("NextPaymentExpiry").Return(nil, nil)
+
+		job := &OrderExpirationJob{orderService: orderService}
+
+		assert.Equal(t, maxReservationRecheck+100*time.Millisecond, job.nextExpirationWait(now, false))
+	})
+
+	t.Run("Stalled batch backs off to recheck interval", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		orderService := servicesMock.NewOrderServiceMock()
+
+		job := &OrderExpirationJob{orderService: orderService}
+
+		// NOTE - order ที่ยกเลิกไม่ผ่านยังหมดเวลาอยู่ ถ้าไม่ backoff จะได้ 100ms
+		assert.Equal(t, maxReservationRecheck, job.nextExpirationWait(now, true))
+		orderService.AssertNotCalled(t, "NextPaymentExpiry")
+	})
+
+	t.Run("Short reservation hold caps the wait", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "10s")
+		orderService := servicesMock.NewOrderServiceMock()
+
+		job := &OrderExpirationJob{orderService: orderService}
+
+		assert.Equal(t, 10*time.Second, job.nextExpirationWait(now, true))
+	})
+}
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type ProductVariant struct {
 	gorm.Model
@@ -8,6 +12,8 @@ type ProductVariant struct {
     Product   Product
 	Size      string
 	Stock     int
-	SKU       string
+	SKU       string `gorm:"index:idx_product_variants_active_sku,unique,where:deleted_at IS NULL AND sku <> ''"` //NOTE - ห้ามซ้ำเฉพาะ variant ที่ยังขายอยู่และมี SKU
 	Price     float64
+	ReorderThreshold int `gorm:"default:5"` //NOTE - stock เหลือเท่านี้หรือน้อยกว่าถือว่าใกล้หมด
+	LowStockAlertedAt *time.Time //NOTE - แจ้งเตือนไปแล้ว จะแจ้งใหม่เมื่อ stock กลับขึ้นเกิน threshold แล้วลดลงมาอีก
 }
\ No newline at end of file
@@ -6,8 +6,8 @@ type CartItem struct {
 	gorm.Model
 	UserID uint //NOTE FK
 	User User `gorm:"foreignKey:UserID"`
-	ProductID uint //NOTE FK
-	Product Product `gorm:"foreignKey:ProductID"`
+	ProductVariantID uint //NOTE FK ราคาคิดจาก variant ไม่ใช่ product
+	ProductVariant ProductVariant `gorm:"foreignKey:ProductVariantID"`
 	Quantity uint
 }
 
@@ -6,5 +6,6 @@ type Category struct {
 	gorm.Model
 	Name string
 	Slug string
+	ParentID *uint `gorm:"index"`
 }
 
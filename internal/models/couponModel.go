@@ -6,9 +6,35 @@ import (
 	"gorm.io/gorm"
 )
 
+type DiscountType string
+
+const (
+	PercentageDiscount DiscountType = "percentage"
+	FixedDiscount DiscountType = "fixed"
+)
+
 type Coupon struct {
 	gorm.Model
 	Code string
-	DiscountAmount float64
+	DiscountType DiscountType `gorm:"type:varchar(20);default:'fixed'"`
+	DiscountAmount float64 //NOTE - ถ้าเป็น percentage จะเป็นค่า 0-100
+	MaxDiscount float64 //NOTE - เพดานส่วนลดของ percentage, 0 = ไม่จำกัด
+	MinOrderAmount float64
+	MaxRedemptions int //NOTE - 0 = ไม่จำกัด
+	MaxRedemptionsPerUser int //NOTE - 0 = ไม่จำกัด
+	StartAt *time.Time
 	ExpiredAt time.Time
-}
\ No newline at end of file
+	Categories []Category `gorm:"many2many:coupon_categories"`
+	Products []Product `gorm:"many2many:coupon_products"`
+}
+
+type CouponRedemption struct {
+	gorm.Model
+	CouponID uint //NOTE - FK
+	Coupon Coupon `gorm:"foreignKey:CouponID"`
+	OrderID uint //NOTE - FK
+	Order Order `gorm:"foreignKey:OrderID"`
+	UserID uint //NOTE - FK
+	User User `gorm:"foreignKey:UserID"`
+	DiscountAmount float64
+}
@@ -0,0 +1,26 @@
+package models
+
+import "gorm.io/gorm"
+
+type InventoryReason string
+
+const (
+	InventorySale       InventoryReason = "sale"       //NOTE - ตัด stock ตอนสร้าง order
+	InventoryCancel     InventoryReason = "cancel"     //NOTE - คืน stock เมื่อ order ถูกยกเลิก
+	InventoryRestock    InventoryReason = "restock"    //NOTE - เติม stock จากการสร้าง/แก้ไขสินค้า
+	InventoryAdjustment InventoryReason = "adjustment" //NOTE - admin ปรับ stock เอง เช่น นับของแล้วไม่ตรง
+	InventoryReturn     InventoryReason = "return"     //NOTE - ลูกค้าคืนสินค้าและนำกลับเข้า stock
+)
+
+// NOTE - ทุกการเปลี่ยนแปลง stock ของ variant จะถูกบันทึกไว้ เพื่อย้อนดูได้ว่า stock หายไปไหน
+type InventoryMovement struct {
+	gorm.Model
+	ProductVariantID uint `gorm:"index"` //NOTE - FK
+	ProductVariant ProductVariant `gorm:"foreignKey:ProductVariantID"`
+	Delta int
+	StockAfter int
+	Reason InventoryReason `gorm:"type:varchar(20)"`
+	OrderID *uint `gorm:"index"`
+	ActorUserID *uint
+	Note string
+}
@@ -10,4 +10,5 @@ type OrderItem struct {
 	ProductVariant   ProductVariant `gorm:"foreignKey:ProductVariantID"`
 	Quantity uint 
 	PriceAtPurchase float64
+	NetPrice float64 //NOTE - ราคาต่อชิ้นที่จ่ายจริงหลังหักราคาลดและส่วนลด coupon ที่กระจายมา ใช้คิดยอดคืนเงิน
 }
\ No newline at end of file
@@ -14,6 +14,8 @@ const (
 	Shipped Status = "shipped"
 	Cancel Status = "cancel"
 	Complete Status = "complete"
+	Refunded Status = "refunded"
+	PartiallyRefunded Status = "partially_refunded"
 )
 
 
@@ -31,7 +33,10 @@ type Order struct {
 	District string
 	Subdistrict string
 	Zipcode string
+	Discount float64 //NOTE - ส่วนลดจาก coupon ที่หักออกจาก TotalPrice แล้ว
 	TotalPrice float64
 	OrderItem []OrderItem `gorm:"foreignKey:OrderID"`
 	PaymentExpireAt time.Time
+	PaymentIntentID string //NOTE - Stripe PaymentIntent ล่าสุดของ order นี้ ใช้ซ้ำได้ถ้ายังจ่ายไม่เสร็จ
+	Shipments []Shipment `gorm:"foreignKey:OrderID"`
 }
\ No newline at end of file
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// NOTE - ประวัติการเปลี่ยนสถานะของ order บันทึกทุกครั้งที่สถานะเปลี่ยน
+type OrderStatusEvent struct {
+	gorm.Model
+	OrderID uint `gorm:"index"` //NOTE - FK
+	Order Order `gorm:"foreignKey:OrderID"`
+	FromStatus Status `gorm:"type:varchar(30)"`
+	ToStatus Status `gorm:"type:varchar(30)"`
+	ActorUserID *uint //NOTE - user หรือ admin ที่เปลี่ยนสถานะ ถ้าเป็นระบบจะเป็น nil
+	ActorRole string `gorm:"type:varchar(20)"` //NOTE - user, admin, system
+	Source string `gorm:"type:varchar(20)"` //NOTE - user, admin, webhook, job, return
+	Note string
+}
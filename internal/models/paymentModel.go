@@ -11,6 +11,7 @@ type StatusPayment string
 const (
 	Payed StatusPayment = "payed"
 	Failed StatusPayment = "failed"
+	RefundedPayment StatusPayment = "refunded"
 )
 
 
@@ -18,7 +19,10 @@ type Payment struct {
 	gorm.Model
 	OrderID uint //NOTE -
 	Order Order `gorm:"foreignKey:OrderID"`
+	StripePaymentIntentID string `gorm:"index"`
 	Amount float64
+	Currency string
 	Status StatusPayment `gorm:"type:payment_status"`
-	PaidAt time.Time
-}
\ No newline at end of file
+	FailureMessage string
+	PaidAt *time.Time //NOTE - nullable ถ้าจ่ายไม่สำเร็จ
+}
@@ -5,6 +5,21 @@ import "gorm.io/gorm"
 type ProductImage struct {
 	gorm.Model
 	URL string
+	StorageKey string //NOTE - ว่างถ้าเป็นรูปจาก URL ภายนอกที่ไม่ได้อัปโหลดผ่านระบบ
+	Position int //NOTE - ลำดับการแสดง เริ่มที่ 0
+	IsPrimary bool //NOTE - รูปหลักที่ใช้แสดงในหน้ารายการสินค้า มีได้รูปเดียวต่อสินค้า
 	ProductID uint
 	Product Product
-}
\ No newline at end of file
+	Thumbnails []ProductImageThumbnail `gorm:"foreignKey:ProductImageID"`
+}
+
+// NOTE - รูปย่อที่สร้างตอนอัปโหลด แต่ละขนาดเก็บแยกไฟล์
+type ProductImageThumbnail struct {
+	gorm.Model
+	ProductImageID uint `gorm:"index"` //NOTE - FK
+	Size string //NOTE - small, medium, large
+	Width int
+	Height int
+	URL string
+	StorageKey string
+}
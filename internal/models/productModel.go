@@ -5,8 +5,11 @@ import "gorm.io/gorm"
 type Product struct {
 	gorm.Model
 	Name string
+	Slug string `gorm:"index:idx_products_active_slug,unique,where:deleted_at IS NULL AND slug <> ''"` //NOTE - ห้ามซ้ำเฉพาะสินค้าที่ยังขายอยู่
 	Description string `gorm:"type:text"`
 	Title string
+	MetaTitle string
+	MetaDescription string
 	IsFeatured bool
 	IsOnSale bool
 	SalePrice *float64
@@ -14,4 +17,4 @@ type Product struct {
 	Category Category `gorm:"foreignKey:CategoryID"`
 	Variants []ProductVariant `gorm:"foreignKey:ProductID"`
 	Images []ProductImage `gorm:"foreignKey:ProductID"`
-}
\ No newline at end of file
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// NOTE - slug เก่าของสินค้าที่ถูกเปลี่ยนชื่อ เก็บไว้ให้ URL เดิม redirect ไป slug ปัจจุบันได้
+// ไม่ใช้ soft delete เพราะ slug ที่ถูกนำกลับมาใช้ต้องลบแถวเดิมออกจริง ไม่งั้นติด unique index
+type ProductSlugRedirect struct {
+	ID uint `gorm:"primaryKey"`
+	Slug string `gorm:"uniqueIndex"`
+	ProductID uint `gorm:"index"` //NOTE - FK
+	Product Product `gorm:"foreignKey:ProductID"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested"
+	ReturnApproved ReturnStatus = "approved"
+	ReturnRejected ReturnStatus = "rejected"
+)
+
+type ReturnRequest struct {
+	gorm.Model
+	OrderID uint //NOTE - FK
+	Order Order `gorm:"foreignKey:OrderID"`
+	OrderItemID uint //NOTE - FK สินค้าที่ต้องการคืน
+	OrderItem OrderItem `gorm:"foreignKey:OrderItemID"`
+	UserID uint //NOTE - FK
+	User User `gorm:"foreignKey:UserID"`
+	Quantity uint
+	Reason string
+	Status ReturnStatus `gorm:"type:varchar(20);default:'requested'"`
+	AdminNote string
+	RefundAmount float64
+	RefundID string //NOTE - id ของการคืนเงินจาก payment provider
+	ResolvedAt *time.Time //NOTE - เวลาที่ admin อนุมัติหรือปฏิเสธ
+}
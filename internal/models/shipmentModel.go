@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Shipment struct {
+	gorm.Model
+	OrderID uint `gorm:"index"` //NOTE - FK
+	Order Order `gorm:"foreignKey:OrderID"`
+	Carrier string
+	TrackingNumber string
+	ShippedAt *time.Time
+	DeliveredAt *time.Time //NOTE - nil คือยังไม่ถึงมือลูกค้า
+	Items []ShipmentItem `gorm:"foreignKey:ShipmentID"` //NOTE - สินค้าที่อยู่ในพัสดุนี้ แยกส่งหลายพัสดุได้
+}
+
+// NOTE - จำนวนสินค้าแต่ละ OrderItem ที่อยู่ในพัสดุ
+type ShipmentItem struct {
+	gorm.Model
+	ShipmentID uint //NOTE - FK
+	OrderItemID uint //NOTE - FK
+	OrderItem OrderItem `gorm:"foreignKey:OrderItemID"`
+	Quantity uint
+}
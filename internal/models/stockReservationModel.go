@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"    //NOTE - กัน stock ไว้ รอลูกค้าจ่ายเงิน
+	ReservationCommitted ReservationStatus = "committed" //NOTE - จ่ายเงินแล้ว stock ถูกขายจริง
+	ReservationReleased  ReservationStatus = "released"  //NOTE - หมดเวลาหรือยกเลิก คืน stock แล้ว
+)
+
+// NOTE - การกัน stock ของแต่ละ variant ต่อ order ระหว่างรอจ่ายเงิน
+type StockReservation struct {
+	gorm.Model
+	OrderID uint `gorm:"index"` //NOTE - FK
+	Order Order `gorm:"foreignKey:OrderID"`
+	ProductVariantID uint `gorm:"index"` //NOTE - FK
+	ProductVariant ProductVariant `gorm:"foreignKey:ProductVariantID"`
+	Quantity uint
+	Status ReservationStatus `gorm:"type:varchar(20);default:'active'"`
+	ExpiresAt time.Time `gorm:"index"`
+}
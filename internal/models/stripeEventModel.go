@@ -0,0 +1,10 @@
+package models
+
+import "gorm.io/gorm"
+
+// NOTE - เก็บ event ของ Stripe ที่ประมวลผลแล้ว กันไม่ให้ทำซ้ำเวลา Stripe ส่ง event เดิมมาอีกรอบ
+type StripeEvent struct {
+	gorm.Model
+	EventID string `gorm:"uniqueIndex"`
+	Type string
+}
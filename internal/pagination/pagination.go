@@ -0,0 +1,137 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NOTE - แบ่งหน้าแบบ keyset จำ id ของแถวสุดท้ายที่ส่งไปแล้ว หน้าถัดไปเริ่มต่อจาก id นั้น
+// ไม่ใช้ offset เพราะตารางใหญ่ต้องสแกนข้ามแถวทั้งหมด และมีแถวใหม่เข้ามาระหว่างเลื่อนหน้าจะได้ข้อมูลซ้ำ
+type Cursor struct {
+	AfterID uint `json:"afterId"`
+}
+
+type Params struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// NOTE - Total มีเฉพาะหน้าแรก ดู CountTotal
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+func Encode(cursor Cursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func Decode(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(raw, &cursor); err != nil || cursor.AfterID == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// NOTE - อ่าน ?limit= และ ?cursor= limit ที่ไม่ได้ส่งหรือเกินจะถูกปรับให้อยู่ในช่วงที่รับได้
+func ParseParams(c *fiber.Ctx) (Params, error) {
+	params := Params{Limit: c.QueryInt("limit", DefaultLimit)}
+
+	if params.Limit <= 0 {
+		params.Limit = DefaultLimit
+	}
+	if params.Limit > MaxLimit {
+		params.Limit = MaxLimit
+	}
+
+	if value := c.Query("cursor"); value != "" {
+		cursor, err := Decode(value)
+		if err != nil {
+			return Params{}, err
+		}
+		params.Cursor = cursor
+	}
+
+	return params, nil
+}
+
+func (p Params) IsFirstPage() bool {
+	return p.Cursor == nil
+}
+
+// NOTE - เรียงด้วย column ที่เป็น id และดึงเกินมาหนึ่งแถวไว้เช็คว่ายังมีหน้าถัดไปไหม
+func (p Params) Apply(db *gorm.DB, idColumn string, descending bool) *gorm.DB {
+	if descending {
+		if p.Cursor != nil {
+			db = db.Where(idColumn+" < ?", p.Cursor.AfterID)
+		}
+		db = db.Order(idColumn + " DESC")
+	} else {
+		if p.Cursor != nil {
+			db = db.Where(idColumn+" > ?", p.Cursor.AfterID)
+		}
+		db = db.Order(idColumn + " ASC")
+	}
+
+	return db.Limit(p.Limit + 1)
+}
+
+// NOTE - count ทั้งหมดเฉพาะหน้าแรก หน้าถัดไปคืน nil เพื่อไม่ต้องนับทั้งตารางซ้ำ
+func (p Params) CountTotal(db *gorm.DB) (*int64, error) {
+	if !p.IsFirstPage() {
+		return nil, nil
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	return &total, nil
+}
+
+// NOTE - rows มาจาก query ที่ผ่าน Apply แล้ว ถ้าได้เกิน limit แปลว่ายังมีหน้าถัดไป
+func NewPage[T any](rows []T, params Params, idOf func(T) uint, total *int64) Page[T] {
+	page := Page[T]{Items: rows, Total: total}
+
+	if len(rows) > params.Limit {
+		page.Items = rows[:params.Limit]
+		page.NextCursor = Encode(Cursor{AfterID: idOf(page.Items[len(page.Items)-1])})
+	}
+
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	return page
+}
+
+// NOTE - แปลง item ในหน้าเป็น response DTO โดยคง cursor และ total เดิมไว้
+func Map[T any, R any](page Page[T], convert func(T) R) Page[R] {
+	items := make([]R, 0, len(page.Items))
+	for _, item := range page.Items {
+		items = append(items, convert(item))
+	}
+
+	return Page[R]{Items: items, NextCursor: page.NextCursor, Total: page.Total}
+}
@@ -0,0 +1,100 @@
+package pagination_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursor(t *testing.T) {
+	t.Run("Encode and decode", func(t *testing.T) {
+		cursor, err := pagination.Decode(pagination.Encode(pagination.Cursor{AfterID: 42}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(42), cursor.AfterID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := pagination.Decode("not-a-cursor")
+
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+}
+
+func TestParseParams(t *testing.T) {
+	parse := func(target string) (pagination.Params, error) {
+		var params pagination.Params
+		var parseErr error
+
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			params, parseErr = pagination.ParseParams(c)
+			return nil
+		})
+		app.Test(httptest.NewRequest("GET", target, nil))
+
+		return params, parseErr
+	}
+
+	t.Run("Default limit on first page", func(t *testing.T) {
+		params, err := parse("/")
+
+		assert.NoError(t, err)
+		assert.Equal(t, pagination.DefaultLimit, params.Limit)
+		assert.True(t, params.IsFirstPage())
+	})
+
+	t.Run("Limit capped and cursor decoded", func(t *testing.T) {
+		params, err := parse("/?limit=5000&cursor=" + pagination.Encode(pagination.Cursor{AfterID: 9}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, pagination.MaxLimit, params.Limit)
+		assert.Equal(t, uint(9), params.Cursor.AfterID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := parse("/?cursor=%%%")
+
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+}
+
+func TestNewPage(t *testing.T) {
+	idOf := func(id uint) uint { return id }
+
+	t.Run("More rows than limit", func(t *testing.T) {
+		total := int64(10)
+		page := pagination.NewPage([]uint{9, 8, 7}, pagination.Params{Limit: 2}, idOf, &total)
+
+		assert.Equal(t, []uint{9, 8}, page.Items)
+		assert.Equal(t, pagination.Encode(pagination.Cursor{AfterID: 8}), page.NextCursor)
+		assert.Equal(t, int64(10), *page.Total)
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		page := pagination.NewPage([]uint{2, 1}, pagination.Params{Limit: 2}, idOf, nil)
+
+		assert.Equal(t, []uint{2, 1}, page.Items)
+		assert.Empty(t, page.NextCursor)
+		assert.Nil(t, page.Total)
+	})
+
+	t.Run("Empty page", func(t *testing.T) {
+		page := pagination.NewPage[uint](nil, pagination.Params{Limit: 2}, idOf, nil)
+
+		assert.NotNil(t, page.Items)
+		assert.Empty(t, page.Items)
+	})
+
+	t.Run("Map items", func(t *testing.T) {
+		page := pagination.NewPage([]uint{3, 2, 1}, pagination.Params{Limit: 2}, idOf, nil)
+
+		mapped := pagination.Map(page, func(id uint) int { return int(id) * 10 })
+
+		assert.Equal(t, []int{30, 20}, mapped.Items)
+		assert.Equal(t, page.NextCursor, mapped.NextCursor)
+	})
+}
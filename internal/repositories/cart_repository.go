@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type CartRepositoryInterface interface {
+	FindByUserID(userID uint) ([]models.CartItem, error)
+	FindByID(id uint) (*models.CartItem, error)
+	FindByUserAndVariant(userID uint, variantID uint) (*models.CartItem, error)
+	FindProductVariantByID(variantID uint) (*models.ProductVariant, error)
+	Create(item *models.CartItem) error
+	Update(item *models.CartItem) error
+	Delete(id uint) error
+	ClearByUserID(userID uint) error
+}
+
+type CartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+func (r *CartRepository) FindByUserID(userID uint) ([]models.CartItem, error) {
+	var items []models.CartItem
+
+	err := r.db.Preload("ProductVariant.Product").Where("user_id = ?", userID).Order("id ASC").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CartRepository) FindByID(id uint) (*models.CartItem, error) {
+	var item models.CartItem
+
+	err := r.db.First(&item, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *CartRepository) FindByUserAndVariant(userID uint, variantID uint) (*models.CartItem, error) {
+	var item models.CartItem
+
+	err := r.db.Where("user_id = ? AND product_variant_id = ?", userID, variantID).First(&item).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (r *CartRepository) FindProductVariantByID(variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+
+	err := r.db.Preload("Product").First(&variant, variantID).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *CartRepository) Create(item *models.CartItem) error {
+	return r.db.Create(item).Error
+}
+
+func (r *CartRepository) Update(item *models.CartItem) error {
+	return r.db.Model(&models.CartItem{}).Where("id = ?", item.ID).Update("quantity", item.Quantity).Error
+}
+
+func (r *CartRepository) Delete(id uint) error {
+	return r.db.Delete(&models.CartItem{}, id).Error
+}
+
+func (r *CartRepository) ClearByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.CartItem{}).Error
+}
@@ -5,17 +5,40 @@ import (
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CategoryInterface interface {
 	Create(category *models.Category) error 
 	Update(category *models.Category) error
-	Delete(id uint) error
+	DeleteIfEmpty(id uint) (bool, error)
 	FindAll() ([]models.Category,error)
 	FindByName(name string) (*models.Category,error)
 	FindByID(id uint) (*models.Category,error)
+	FindAncestors(id uint) ([]models.Category,error)
+	ReassignProductsAndDelete(id uint, targetID uint) error
+	Merge(sourceID uint, targetID uint) error
 }
 
+// NOTE - id ของ category ที่ส่งมาและ category ลูกหลานทุกชั้น ใช้เป็น subquery ตอนกรองสินค้าตาม category
+// ใช้ UNION เพื่อตัดแถวซ้ำ ถ้าข้อมูลเสียจนวนเป็นวงก็ยังหยุดได้
+const categoryDescendantIDsSQL = `WITH RECURSIVE category_tree AS (
+	SELECT id FROM categories WHERE id IN ? AND deleted_at IS NULL
+	UNION
+	SELECT categories.id FROM categories
+	JOIN category_tree ON categories.parent_id = category_tree.id
+	WHERE categories.deleted_at IS NULL
+) SELECT id FROM category_tree`
+
+// NOTE - ไล่ขึ้นจาก category ไปหา parent ทีละชั้น depth กันวนไม่จบถ้าข้อมูลเสีย
+const categoryAncestorsSQL = `WITH RECURSIVE ancestors AS (
+	SELECT id, name, slug, parent_id, 0 AS depth FROM categories WHERE id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT categories.id, categories.name, categories.slug, categories.parent_id, ancestors.depth + 1 FROM categories
+	JOIN ancestors ON categories.id = ancestors.parent_id
+	WHERE categories.deleted_at IS NULL AND ancestors.depth < 50
+) SELECT id, name, slug, parent_id FROM ancestors ORDER BY depth DESC`
+
 type CategoryRepository struct {
 	db *gorm.DB
 }
@@ -32,8 +55,39 @@ func (r *CategoryRepository) Update(category *models.Category) error {
 	return r.db.Save(category).Error
 }
 
-func (r *CategoryRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Category{}, id).Error
+// NOTE - นับสินค้าและลบใน transaction เดียว ล็อกแถว category ไว้ก่อน การสร้างหรือแก้สินค้าล็อก category แบบ SHARE
+// จึงต้องรอจนลบเสร็จแล้วเห็นว่า category ถูกลบไปแล้ว (ดู lockActiveCategory)
+// คืน false ถ้ายังมีสินค้าอยู่ใน category
+func (r *CategoryRepository) DeleteIfEmpty(id uint) (bool, error) {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	var category models.Category
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&category, id).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var count int64
+	if err := tx.Model(&models.Product{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if count > 0 {
+		tx.Rollback()
+		return false, nil
+	}
+
+	if err := deleteCategory(tx, id); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit().Error
 }
 
 func (r *CategoryRepository) FindAll() ([]models.Category,error) {
@@ -69,4 +123,87 @@ func (r *CategoryRepository) FindByID(id uint) (*models.Category,error) {
 	}
 
 	return 	&category, err
-}
\ No newline at end of file
+}
+
+// NOTE - คืน category จากบนสุดลงมาถึง category ที่ส่งมา ถ้าไม่เจอ category คืน slice ว่าง
+func (r *CategoryRepository) FindAncestors(id uint) ([]models.Category,error) {
+	var categories []models.Category
+	err := r.db.Raw(categoryAncestorsSQL, id).Scan(&categories).Error
+	return categories, err
+}
+
+// NOTE - ย้ายสินค้าไป category ปลายทางแล้วค่อยลบ ทำใน transaction เดียวกันไม่ให้มีสินค้าค้างอยู่ใน category ที่ถูกลบ
+func (r *CategoryRepository) ReassignProductsAndDelete(id uint, targetID uint) error {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Model(&models.Product{}).Where("category_id = ?", id).Update("category_id", targetID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := deleteCategory(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// NOTE - รวม category ที่ซ้ำกัน สินค้า category ลูก และ coupon ที่ผูกกับ source ย้ายไปอยู่ที่ target ทั้งหมดแล้วลบ source
+func (r *CategoryRepository) Merge(sourceID uint, targetID uint) error {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Model(&models.Product{}).Where("category_id = ?", sourceID).Update("category_id", targetID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.Category{}).Where("parent_id = ?", sourceID).Update("parent_id", targetID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// NOTE - coupon ที่ผูกทั้ง source และ target อยู่แล้วไม่ต้อง insert ซ้ำ
+	err := tx.Exec(`INSERT INTO coupon_categories (coupon_id, category_id)
+		SELECT coupon_id, ? FROM coupon_categories
+		WHERE category_id = ? AND coupon_id NOT IN (SELECT coupon_id FROM coupon_categories WHERE category_id = ?)`,
+		targetID, sourceID, targetID).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec("DELETE FROM coupon_categories WHERE category_id = ?", sourceID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Delete(&models.Category{}, sourceID).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// NOTE - category ลูกขยับขึ้นไปอยู่ใต้ parent ของ category ที่ถูกลบ จะได้ไม่หลุดออกจาก tree
+func deleteCategory(tx *gorm.DB, id uint) error {
+	var category models.Category
+	if err := tx.First(&category, id).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.Category{}).Where("parent_id = ?", id).Update("parent_id", category.ParentID).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&models.Category{}, id).Error
+}
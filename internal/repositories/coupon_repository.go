@@ -0,0 +1,161 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type CouponRepositoryInterface interface {
+	Create(coupon *models.Coupon) error
+	Update(coupon *models.Coupon) error
+	Delete(id uint) error
+	FindAll(params pagination.Params) (pagination.Page[models.Coupon], error)
+	FindByID(id uint) (*models.Coupon, error)
+	FindByCode(code string) (*models.Coupon, error)
+	GetRedemptionCounts() (map[uint]int64, error)
+	FindRedemptionsByCouponID(couponID uint, params pagination.Params) (pagination.Page[models.CouponRedemption], error)
+}
+
+type CouponRepository struct {
+	db *gorm.DB
+}
+
+func NewCouponRepository(db *gorm.DB) *CouponRepository {
+	return &CouponRepository{db: db}
+}
+
+func (r *CouponRepository) Create(coupon *models.Coupon) error {
+	// NOTE - ผูกแค่ตารางกลาง ไม่ upsert category/product เดิม
+	return r.db.Omit("Categories.*", "Products.*").Create(coupon).Error
+}
+
+func (r *CouponRepository) Update(coupon *models.Coupon) error {
+	// NOTE - ใช้ transaction เพราะต้องแทนที่ category/product ที่ผูกไว้ด้วย
+	tx := r.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Omit("Categories", "Products").Save(coupon).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(coupon).Association("Categories").Replace(coupon.Categories); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(coupon).Association("Products").Replace(coupon.Products); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *CouponRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Coupon{}, id).Error
+}
+
+func (r *CouponRepository) FindAll(params pagination.Params) (pagination.Page[models.Coupon], error) {
+	var coupons []models.Coupon
+
+	total, err := params.CountTotal(r.db.Model(&models.Coupon{}))
+	if err != nil {
+		return pagination.Page[models.Coupon]{}, err
+	}
+
+	err = params.Apply(r.db.Preload("Categories").Preload("Products"), "id", true).Find(&coupons).Error
+	if err != nil {
+		return pagination.Page[models.Coupon]{}, err
+	}
+
+	return pagination.NewPage(coupons, params, couponID, total), nil
+}
+
+func couponID(coupon models.Coupon) uint {
+	return coupon.ID
+}
+
+func (r *CouponRepository) FindByID(id uint) (*models.Coupon, error) {
+	var coupon models.Coupon
+
+	err := r.db.Preload("Categories").Preload("Products").First(&coupon, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+func (r *CouponRepository) FindByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+
+	err := r.db.Where("UPPER(code) = ?", strings.ToUpper(code)).First(&coupon).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &coupon, nil
+}
+
+// NOTE - จำนวนครั้งที่ใช้ของแต่ละ coupon ไม่นับ order ที่ถูก cancel
+func (r *CouponRepository) GetRedemptionCounts() (map[uint]int64, error) {
+	var rows []struct {
+		CouponID uint
+		Total    int64
+	}
+
+	err := r.db.Model(&models.CouponRedemption{}).
+		Select("coupon_redemptions.coupon_id, COUNT(*) as total").
+		Joins("JOIN orders ON orders.id = coupon_redemptions.order_id").
+		Where("orders.status <> ? AND orders.deleted_at IS NULL", models.Cancel).
+		Group("coupon_redemptions.coupon_id").
+		Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64)
+	for _, row := range rows {
+		counts[row.CouponID] = row.Total
+	}
+
+	return counts, nil
+}
+
+func (r *CouponRepository) FindRedemptionsByCouponID(couponID uint, params pagination.Params) (pagination.Page[models.CouponRedemption], error) {
+	var redemptions []models.CouponRedemption
+
+	total, err := params.CountTotal(r.db.Model(&models.CouponRedemption{}).Where("coupon_id = ?", couponID))
+	if err != nil {
+		return pagination.Page[models.CouponRedemption]{}, err
+	}
+
+	err = params.Apply(r.db.Preload("Order").Where("coupon_id = ?", couponID), "id", true).Find(&redemptions).Error
+	if err != nil {
+		return pagination.Page[models.CouponRedemption]{}, err
+	}
+
+	return pagination.NewPage(redemptions, params, couponRedemptionID, total), nil
+}
+
+func couponRedemptionID(redemption models.CouponRedemption) uint {
+	return redemption.ID
+}
@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryRepositoryInterface interface {
+	FindVariantByID(variantID uint) (*models.ProductVariant, error)
+	FindVariantBySKU(sku string) (*models.ProductVariant, error)
+	LockVariant(tx *gorm.DB, variantID uint) (*models.ProductVariant, error)
+	UpdateVariantFields(tx *gorm.DB, variantID uint, fields map[string]interface{}) error
+	AdjustStock(tx *gorm.DB, variantID uint, delta int) (bool, error)
+	RecordMovement(tx *gorm.DB, movement *models.InventoryMovement) error
+	FindMovementsByVariantID(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error)
+	UpdateReorderThreshold(variantID uint, threshold int) error
+	FindLowStockVariants(soldSince time.Time, onlyUnalerted bool) ([]dto.LowStockVariantDTO, error)
+	MarkLowStockAlerted(variantIDs []uint, alertedAt time.Time) error
+	ClearRecoveredLowStockAlerts() error
+}
+
+type InventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) *InventoryRepository {
+	return &InventoryRepository{db: db}
+}
+
+func (r *InventoryRepository) FindVariantByID(variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+
+	err := r.db.First(&variant, variantID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *InventoryRepository) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	return findVariantBySKU(r.db, sku)
+}
+
+func (r *InventoryRepository) LockVariant(tx *gorm.DB, variantID uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, variantID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *InventoryRepository) UpdateVariantFields(tx *gorm.DB, variantID uint, fields map[string]interface{}) error {
+	return tx.Model(&models.ProductVariant{}).Where("id = ?", variantID).Updates(fields).Error
+}
+
+// NOTE - ปรับ stock แบบ atomic และไม่ยอมให้ stock ติดลบ คืน false ถ้า stock ไม่พอให้ลด
+func (r *InventoryRepository) AdjustStock(tx *gorm.DB, variantID uint, delta int) (bool, error) {
+	result := tx.Model(&models.ProductVariant{}).
+		Where("id = ? AND stock + ? >= 0", variantID, delta).
+		Update("stock", gorm.Expr("stock + ?", delta))
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *InventoryRepository) RecordMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	return recordInventoryMovement(tx, movement)
+}
+
+// NOTE - id เพิ่มตามลำดับที่บันทึก เรียง id ใหม่ไปเก่าจึงได้ลำดับเดียวกับเวลาที่เกิด
+func (r *InventoryRepository) FindMovementsByVariantID(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error) {
+	var movements []models.InventoryMovement
+
+	total, err := params.CountTotal(r.db.Model(&models.InventoryMovement{}).Where("product_variant_id = ?", variantID))
+	if err != nil {
+		return pagination.Page[models.InventoryMovement]{}, err
+	}
+
+	err = params.Apply(r.db.Where("product_variant_id = ?", variantID), "id", true).Find(&movements).Error
+	if err != nil {
+		return pagination.Page[models.InventoryMovement]{}, err
+	}
+
+	return pagination.NewPage(movements, params, inventoryMovementID, total), nil
+}
+
+func inventoryMovementID(movement models.InventoryMovement) uint {
+	return movement.ID
+}
+
+func (r *InventoryRepository) UpdateReorderThreshold(variantID uint, threshold int) error {
+	return r.db.Model(&models.ProductVariant{}).Where("id = ?", variantID).Updates(map[string]interface{}{
+		"reorder_threshold":    threshold,
+		"low_stock_alerted_at": nil,
+	}).Error
+}
+
+// NOTE - variant ที่ stock ต่ำกว่าหรือเท่ากับ threshold (รวมของหมด) เรียงตามยอดขายตั้งแต่ soldSince ไม่นับ order ที่ถูกยกเลิก
+func (r *InventoryRepository) FindLowStockVariants(soldSince time.Time, onlyUnalerted bool) ([]dto.LowStockVariantDTO, error) {
+	var variants []dto.LowStockVariantDTO
+
+	query := r.db.Table("product_variants").
+		Select(`product_variants.id AS variant_id,
+			product_variants.product_id,
+			products.name AS product_name,
+			product_variants.size,
+			product_variants.sku,
+			product_variants.stock,
+			product_variants.reorder_threshold,
+			COALESCE(SUM(sold.quantity), 0) AS units_sold`).
+		Joins("JOIN products ON products.id = product_variants.product_id AND products.deleted_at IS NULL").
+		Joins(`LEFT JOIN (
+			SELECT order_items.product_variant_id, order_items.quantity
+			FROM order_items
+			JOIN orders ON orders.id = order_items.order_id
+			WHERE orders.status <> ? AND orders.created_at >= ? AND order_items.deleted_at IS NULL AND orders.deleted_at IS NULL
+		) AS sold ON sold.product_variant_id = product_variants.id`, models.Cancel, soldSince).
+		Where("product_variants.deleted_at IS NULL AND product_variants.stock <= product_variants.reorder_threshold")
+
+	if onlyUnalerted {
+		query = query.Where("product_variants.low_stock_alerted_at IS NULL")
+	}
+
+	err := query.
+		Group("product_variants.id, products.name").
+		Order("units_sold DESC, product_variants.stock ASC, product_variants.id ASC").
+		Scan(&variants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return variants, nil
+}
+
+func (r *InventoryRepository) MarkLowStockAlerted(variantIDs []uint, alertedAt time.Time) error {
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	return r.db.Model(&models.ProductVariant{}).
+		Where("id IN ? AND low_stock_alerted_at IS NULL", variantIDs).
+		Update("low_stock_alerted_at", alertedAt).Error
+}
+
+// NOTE - stock กลับขึ้นมาเกิน threshold แล้ว ล้างสถานะเพื่อให้แจ้งเตือนได้อีกครั้งเมื่อ stock ลดลงมาใหม่
+func (r *InventoryRepository) ClearRecoveredLowStockAlerts() error {
+	return r.db.Model(&models.ProductVariant{}).
+		Where("low_stock_alerted_at IS NOT NULL AND stock > reorder_threshold").
+		Update("low_stock_alerted_at", nil).Error
+}
+
+// NOTE - SKU ห้ามซ้ำเฉพาะ variant ที่ยังขายอยู่ จึงหาเจอได้ไม่เกินหนึ่งตัว
+func findVariantBySKU(db *gorm.DB, sku string) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+
+	err := db.Preload("Product").Where("sku = ?", sku).First(&variant).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+// NOTE - ใช้ร่วมกันทุก repository ที่แก้ stock ต้องเรียกหลังจากแก้ stock ใน transaction เดียวกัน
+// เพื่อให้ StockAfter ตรงกับ stock จริงหลังการเปลี่ยนแปลงนี้
+func recordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	var variant models.ProductVariant
+	if err := tx.Unscoped().Select("stock").First(&variant, movement.ProductVariantID).Error; err != nil {
+		return err
+	}
+
+	movement.StockAfter = variant.Stock
+	return tx.Omit("ProductVariant").Create(movement).Error
+}
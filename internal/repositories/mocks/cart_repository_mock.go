@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type CartRepositoryMock struct {
+	mock.Mock
+}
+
+func NewCartRepositoryMock() *CartRepositoryMock {
+	return &CartRepositoryMock{}
+}
+
+func (m *CartRepositoryMock) FindByUserID(userID uint) ([]models.CartItem, error) {
+	args := m.Called(userID)
+	if items, ok := args.Get(0).([]models.CartItem); ok {
+		return items, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CartRepositoryMock) FindByID(id uint) (*models.CartItem, error) {
+	args := m.Called(id)
+	if item, ok := args.Get(0).(*models.CartItem); ok {
+		return item, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CartRepositoryMock) FindByUserAndVariant(userID uint, variantID uint) (*models.CartItem, error) {
+	args := m.Called(userID, variantID)
+	if item, ok := args.Get(0).(*models.CartItem); ok {
+		return item, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CartRepositoryMock) FindProductVariantByID(variantID uint) (*models.ProductVariant, error) {
+	args := m.Called(variantID)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CartRepositoryMock) Create(item *models.CartItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+func (m *CartRepositoryMock) Update(item *models.CartItem) error {
+	args := m.Called(item)
+	return args.Error(0)
+}
+
+func (m *CartRepositoryMock) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *CartRepositoryMock) ClearByUserID(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
@@ -23,9 +23,9 @@ func (m *CategoryRepositoryMock) Update(category *models.Category) error {
 	return args.Error(0)
 }
 
-func (m *CategoryRepositoryMock) Delete(id uint) error {
+func (m *CategoryRepositoryMock) DeleteIfEmpty(id uint) (bool, error) {
 	args := m.Called(id)
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *CategoryRepositoryMock) FindAll() ([]models.Category,error) {
@@ -50,4 +50,22 @@ func (m *CategoryRepositoryMock) FindByID(id uint) (*models.Category,error) {
 		return category, args.Error(1)
 	}
 	return nil, args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *CategoryRepositoryMock) FindAncestors(id uint) ([]models.Category,error) {
+	args := m.Called(id)
+	if categories, ok := args.Get(0).([]models.Category); ok {
+		return categories, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CategoryRepositoryMock) ReassignProductsAndDelete(id uint, targetID uint) error {
+	args := m.Called(id, targetID)
+	return args.Error(0)
+}
+
+func (m *CategoryRepositoryMock) Merge(sourceID uint, targetID uint) error {
+	args := m.Called(sourceID, targetID)
+	return args.Error(0)
+}
@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type CouponRepositoryMock struct {
+	mock.Mock
+}
+
+func NewCouponRepositoryMock() *CouponRepositoryMock {
+	return &CouponRepositoryMock{}
+}
+
+func (m *CouponRepositoryMock) Create(coupon *models.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *CouponRepositoryMock) Update(coupon *models.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *CouponRepositoryMock) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *CouponRepositoryMock) FindAll(params pagination.Params) (pagination.Page[models.Coupon], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[models.Coupon]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Coupon]{}, args.Error(1)
+}
+
+func (m *CouponRepositoryMock) FindByID(id uint) (*models.Coupon, error) {
+	args := m.Called(id)
+	if coupon, ok := args.Get(0).(*models.Coupon); ok {
+		return coupon, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CouponRepositoryMock) FindByCode(code string) (*models.Coupon, error) {
+	args := m.Called(code)
+	if coupon, ok := args.Get(0).(*models.Coupon); ok {
+		return coupon, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CouponRepositoryMock) GetRedemptionCounts() (map[uint]int64, error) {
+	args := m.Called()
+	if counts, ok := args.Get(0).(map[uint]int64); ok {
+		return counts, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CouponRepositoryMock) FindRedemptionsByCouponID(couponID uint, params pagination.Params) (pagination.Page[models.CouponRedemption], error) {
+	args := m.Called(couponID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.CouponRedemption]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.CouponRedemption]{}, args.Error(1)
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type InventoryRepositoryMock struct {
+	mock.Mock
+}
+
+func NewInventoryRepositoryMock() *InventoryRepositoryMock {
+	return &InventoryRepositoryMock{}
+}
+
+func (m *InventoryRepositoryMock) FindVariantByID(variantID uint) (*models.ProductVariant, error) {
+	args := m.Called(variantID)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) AdjustStock(tx *gorm.DB, variantID uint, delta int) (bool, error) {
+	args := m.Called(tx, variantID, delta)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) RecordMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	args := m.Called(tx, movement)
+	return args.Error(0)
+}
+
+func (m *InventoryRepositoryMock) FindMovementsByVariantID(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error) {
+	args := m.Called(variantID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.InventoryMovement]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.InventoryMovement]{}, args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) UpdateReorderThreshold(variantID uint, threshold int) error {
+	args := m.Called(variantID, threshold)
+	return args.Error(0)
+}
+
+func (m *InventoryRepositoryMock) FindLowStockVariants(soldSince time.Time, onlyUnalerted bool) ([]dto.LowStockVariantDTO, error) {
+	args := m.Called(soldSince, onlyUnalerted)
+	if variants, ok := args.Get(0).([]dto.LowStockVariantDTO); ok {
+		return variants, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) MarkLowStockAlerted(variantIDs []uint, alertedAt time.Time) error {
+	args := m.Called(variantIDs, alertedAt)
+	return args.Error(0)
+}
+
+func (m *InventoryRepositoryMock) ClearRecoveredLowStockAlerts() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *InventoryRepositoryMock) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	args := m.Called(sku)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) LockVariant(tx *gorm.DB, variantID uint) (*models.ProductVariant, error) {
+	args := m.Called(tx, variantID)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryRepositoryMock) UpdateVariantFields(tx *gorm.DB, variantID uint, fields map[string]interface{}) error {
+	args := m.Called(tx, variantID, fields)
+	return args.Error(0)
+}
@@ -1,8 +1,11 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
@@ -30,9 +33,9 @@ func (m *OrderRepositoryMock)Create(tx *gorm.DB,order *models.Order) error {
 	return args.Error(0)
 }
 
-func (m *OrderRepositoryMock)UpdateProductVariantStock(tx *gorm.DB,productVariantID uint, newStock int) error {
-	args := m.Called(tx,productVariantID,newStock)
-	return args.Error(0)
+func (m *OrderRepositoryMock) DecrementVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) (bool, error) {
+	args := m.Called(tx, productVariantID, quantity)
+	return args.Bool(0), args.Error(1)
 }
 
 func (m *OrderRepositoryMock)FindByIDWithItemsAndProducts(orderID uint) (*models.Order, error) {
@@ -43,11 +46,6 @@ func (m *OrderRepositoryMock)FindByIDWithItemsAndProducts(orderID uint) (*models
 	return nil,args.Error(1)
 }
 
-func (m *OrderRepositoryMock)UpdateStatusOrder(orderId *uint, status models.Status) error {
-	args := m.Called(orderId,status)
-	return args.Error(0)
-}
-
 func (m *OrderRepositoryMock)FindOrderById(orderID uint) (*models.Order, error){
 	args := m.Called(orderID)
 	if order,ok := args.Get(0).(*models.Order);ok {
@@ -56,26 +54,91 @@ func (m *OrderRepositoryMock)FindOrderById(orderID uint) (*models.Order, error){
 	return nil,args.Error(1)
 }
 
-func (m *OrderRepositoryMock)FindAllOrderByUserId(userIDUint uint) ([]models.Order,error){
-	args := m.Called(userIDUint)
-	if order,ok := args.Get(0).([]models.Order);ok {
-		return order,nil
+func (m *OrderRepositoryMock) FindAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order], error) {
+	args := m.Called(userIDUint, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Order]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[models.Order]{}, args.Error(1)
 }
 
 
-func (m *OrderRepositoryMock)UpdateStatusOrderByUserId(orderID uint,status models.Status) error{
-	args := m.Called(orderID,status)
+func (m *OrderRepositoryMock) UpdateStatusFrom(tx *gorm.DB, orderID uint, from models.Status, to models.Status) (bool, error) {
+	args := m.Called(tx, orderID, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *OrderRepositoryMock) RestoreVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) error {
+	args := m.Called(tx, productVariantID, quantity)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) CreateStatusEvent(tx *gorm.DB, event *models.OrderStatusEvent) error {
+	args := m.Called(tx, event)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) CreateReservations(tx *gorm.DB, reservations []models.StockReservation) error {
+	args := m.Called(tx, reservations)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) UpdateReservationStatus(tx *gorm.DB, orderID uint, from models.ReservationStatus, to models.ReservationStatus) error {
+	args := m.Called(tx, orderID, from, to)
 	return args.Error(0)
 }
 
-func (m *OrderRepositoryMock)FindAll() ([]models.Order,error) {
+func (m *OrderRepositoryMock) ExtendReservation(tx *gorm.DB, orderID uint, expiresAt time.Time) (bool, error) {
+	args := m.Called(tx, orderID, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *OrderRepositoryMock) FindExpiredOrders(now time.Time, afterExpireAt time.Time, afterID uint, limit int) ([]models.Order, error) {
+	args := m.Called(now, afterExpireAt, afterID, limit)
+	if orders, ok := args.Get(0).([]models.Order); ok {
+		return orders, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) LockExpiredOrder(tx *gorm.DB, orderID uint, now time.Time) (*models.Order, error) {
+	args := m.Called(tx, orderID, now)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) LockOrderWithShipments(tx *gorm.DB, orderID uint) (*models.Order, error) {
+	args := m.Called(tx, orderID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) FindNextPaymentExpiry() (*time.Time, error) {
 	args := m.Called()
-	if order,ok := args.Get(0).([]models.Order);ok {
-		return order,nil
+	if next, ok := args.Get(0).(*time.Time); ok {
+		return next, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) FindStatusEvents(orderID uint) ([]models.OrderStatusEvent, error) {
+	args := m.Called(orderID)
+	if events, ok := args.Get(0).([]models.OrderStatusEvent); ok {
+		return events, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) FindAll(params pagination.Params) (pagination.Page[models.Order], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[models.Order]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Order]{}, args.Error(1)
 }
 
 func (m *OrderRepositoryMock)GetTop5ProductsBySales() ([]dto.TopProductDTO, error){
@@ -98,10 +161,56 @@ func (m *OrderRepositoryMock)Delete(id uint) error{
 	return args.Error(0)
 }
 
-func (m *OrderRepositoryMock)GetUserDetail() ([]dto.CustomerDTO,error) {
-	args := m.Called()
-	if customer,ok := args.Get(0).([]dto.CustomerDTO);ok {
-		return customer,nil
+func (m *OrderRepositoryMock) GetUserDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[dto.CustomerDTO]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[dto.CustomerDTO]{}, args.Error(1)
+}
+
+func (m *OrderRepositoryMock)FindCouponByCode(code string) (*models.Coupon, error) {
+	args := m.Called(code)
+	if coupon,ok := args.Get(0).(*models.Coupon);ok {
+		return coupon,args.Error(1)
 	}
 	return nil,args.Error(1)
-}
\ No newline at end of file
+}
+
+func (m *OrderRepositoryMock)LockCoupon(tx *gorm.DB, couponID uint) error {
+	args := m.Called(tx,couponID)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock)CountCouponRedemptions(tx *gorm.DB, couponID uint) (int64, error) {
+	args := m.Called(tx,couponID)
+	return args.Get(0).(int64),args.Error(1)
+}
+
+func (m *OrderRepositoryMock)CountCouponRedemptionsByUser(tx *gorm.DB, couponID uint, userID uint) (int64, error) {
+	args := m.Called(tx,couponID,userID)
+	return args.Get(0).(int64),args.Error(1)
+}
+
+func (m *OrderRepositoryMock)CreateCouponRedemption(tx *gorm.DB, redemption *models.CouponRedemption) error {
+	args := m.Called(tx,redemption)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) UpdatePaymentIntentID(orderID uint, paymentIntentID string) error {
+	args := m.Called(orderID, paymentIntentID)
+	return args.Error(0)
+}
+
+func (m *OrderRepositoryMock) FindByPaymentIntentID(paymentIntentID string) (*models.Order, error) {
+	args := m.Called(paymentIntentID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderRepositoryMock) RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	args := m.Called(tx, movement)
+	return args.Error(0)
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentRepositoryMock struct {
+	mock.Mock
+}
+
+func NewPaymentRepositoryMock() *PaymentRepositoryMock {
+	return &PaymentRepositoryMock{}
+}
+
+func (m *PaymentRepositoryMock) Create(payment *models.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *PaymentRepositoryMock) FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error) {
+	args := m.Called(orderID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Payment]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Payment]{}, args.Error(1)
+}
+
+func (m *PaymentRepositoryMock) CreateEventIfNotExists(event *models.StripeEvent) (bool, error) {
+	args := m.Called(event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PaymentRepositoryMock) DeleteEvent(eventID string) error {
+	args := m.Called(eventID)
+	return args.Error(0)
+}
+
+func (m *PaymentRepositoryMock) SumRefundedByOrderID(orderID uint) (float64, error) {
+	args := m.Called(orderID)
+	return args.Get(0).(float64), args.Error(1)
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -27,16 +28,8 @@ func (m *ProductRepositoryMock) FindByID(id uint) (*models.Product, error){
 	return nil, args.Error(1)
 }
 
-func (m *ProductRepositoryMock) FindAll(
-	page uint,
-	limit uint,
-	minPrice int64,
-	maxPrice int64,
-	searchName string,
-	categoryIDs []int,
-	sizeIDs []string,
-) ([]models.Product, int64, error) {
-	args := m.Called(page, limit, minPrice, maxPrice, searchName, categoryIDs, sizeIDs)
+func (m *ProductRepositoryMock) FindAll(query dto.ProductListQueryDTO) ([]models.Product, int64, error) {
+	args := m.Called(query)
 
 	var products []models.Product
 	if res, ok := args.Get(0).([]models.Product); ok {
@@ -54,10 +47,13 @@ func (m *ProductRepositoryMock) FindAll(
 }
 
 
-func (m *ProductRepositoryMock) Update(product *models.Product) error{
-	args := m.Called(product)
+func (m *ProductRepositoryMock) Update(product *models.Product, retiredVariantIDs []uint) ([]models.ProductImage, error){
+	args := m.Called(product, retiredVariantIDs)
 
-	return args.Error(0)
+	if removed, ok := args.Get(0).([]models.ProductImage); ok {
+		return removed, args.Error(1)
+	}
+	return nil, args.Error(1)
 }
 
 func (m *ProductRepositoryMock) Delete(id uint) error{
@@ -66,3 +62,84 @@ func (m *ProductRepositoryMock) Delete(id uint) error{
 	return args.Error(0)
 }
 
+
+func (m *ProductRepositoryMock) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	args := m.Called(sku)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindByIDs(ids []uint) ([]models.Product, error) {
+	args := m.Called(ids)
+	if products, ok := args.Get(0).([]models.Product); ok {
+		return products, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error) {
+	args := m.Called(query)
+	if facets, ok := args.Get(0).(*dto.ProductFacetsDTO); ok {
+		return facets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindBySlug(slug string) (*models.Product, error) {
+	args := m.Called(slug)
+	if product, ok := args.Get(0).(*models.Product); ok {
+		return product, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindProductIDBySlugRedirect(slug string) (uint, error) {
+	args := m.Called(slug)
+	return args.Get(0).(uint), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindSlugsWithPrefix(base string, excludeProductID uint) ([]string, error) {
+	args := m.Called(base, excludeProductID)
+	if slugs, ok := args.Get(0).([]string); ok {
+		return slugs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindWithoutSlug() ([]models.Product, error) {
+	args := m.Called()
+	if products, ok := args.Get(0).([]models.Product); ok {
+		return products, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) UpdateSlug(id uint, slug string) error {
+	args := m.Called(id, slug)
+	return args.Error(0)
+}
+
+func (m *ProductRepositoryMock) AddImage(image *models.ProductImage, maxImages int) (bool, error) {
+	args := m.Called(image, maxImages)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ProductRepositoryMock) FindImages(productID uint) ([]models.ProductImage, error) {
+	args := m.Called(productID)
+	if images, ok := args.Get(0).([]models.ProductImage); ok {
+		return images, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductRepositoryMock) UpdateImageOrder(productID uint, imageIDs []uint, primaryImageID uint) error {
+	args := m.Called(productID, imageIDs, primaryImageID)
+	return args.Error(0)
+}
+
+func (m *ProductRepositoryMock) DeleteImage(productID uint, imageID uint, minImages int) (bool, error) {
+	args := m.Called(productID, imageID, minImages)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type ReturnRepositoryMock struct {
+	mock.Mock
+}
+
+func NewReturnRepositoryMock() *ReturnRepositoryMock {
+	return &ReturnRepositoryMock{}
+}
+
+func (m *ReturnRepositoryMock) Create(returnRequest *models.ReturnRequest) error {
+	args := m.Called(returnRequest)
+	return args.Error(0)
+}
+
+func (m *ReturnRepositoryMock) Resolve(tx *gorm.DB, returnRequest *models.ReturnRequest) (bool, error) {
+	args := m.Called(tx, returnRequest)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) LockPending(tx *gorm.DB, id uint) (bool, error) {
+	args := m.Called(tx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) LockOrder(tx *gorm.DB, orderID uint) (*models.Order, error) {
+	args := m.Called(tx, orderID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) FindByID(id uint) (*models.ReturnRequest, error) {
+	args := m.Called(id)
+	if returnRequest, ok := args.Get(0).(*models.ReturnRequest); ok {
+		return returnRequest, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) FindByUserID(userID uint, params pagination.Params) (pagination.Page[models.ReturnRequest], error) {
+	args := m.Called(userID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.ReturnRequest]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.ReturnRequest]{}, args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) FindAll(params pagination.Params) (pagination.Page[models.ReturnRequest], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[models.ReturnRequest]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.ReturnRequest]{}, args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) FindOrderByID(orderID uint) (*models.Order, error) {
+	args := m.Called(orderID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) SumReturnedQuantity(orderItemID uint) (int64, error) {
+	args := m.Called(orderItemID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) SumRefundedAmount(tx *gorm.DB, orderID uint) (float64, error) {
+	args := m.Called(tx, orderID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *ReturnRepositoryMock) RestockVariant(tx *gorm.DB, variantID uint, quantity uint) error {
+	args := m.Called(tx, variantID, quantity)
+	return args.Error(0)
+}
+
+func (m *ReturnRepositoryMock) CreatePayment(tx *gorm.DB, payment *models.Payment) error {
+	args := m.Called(tx, payment)
+	return args.Error(0)
+}
+
+func (m *ReturnRepositoryMock) RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	args := m.Called(tx, movement)
+	return args.Error(0)
+}
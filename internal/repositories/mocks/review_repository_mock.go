@@ -3,6 +3,7 @@ package repositories
 import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -14,12 +15,12 @@ func NewReviewRepositoryMock() *ReviewRepositoryMock{
 	return &ReviewRepositoryMock{}
 }
 
-func (m *ReviewRepositoryMock) GetUserReviews(userIDUint uint) ([]models.Review, error) {
-	args := m.Called(userIDUint)
-	if userReview,ok := args.Get(0).([]models.Review);ok {
-		return userReview,args.Error(1)
+func (m *ReviewRepositoryMock) GetUserReviews(userIDUint uint, params pagination.Params) (pagination.Page[models.Review], error) {
+	args := m.Called(userIDUint, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Review]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[models.Review]{}, args.Error(1)
 }
 
 
@@ -35,12 +36,12 @@ func (m *ReviewRepositoryMock) HasPurchasedProduct(userID uint, productID uint)
 }
 
 
-func (m *ReviewRepositoryMock) GetReviewAllByProductId(productId uint) ([]dto.ReviewAllProduct,error) {
-	args := m.Called(productId)
-	if reviewByProductID,ok := args.Get(0).([]dto.ReviewAllProduct);ok {
-		return reviewByProductID,args.Error(1)
+func (m *ReviewRepositoryMock) GetReviewAllByProductId(productId uint, params pagination.Params) (pagination.Page[dto.ReviewAllProduct], error) {
+	args := m.Called(productId, params)
+	if page, ok := args.Get(0).(pagination.Page[dto.ReviewAllProduct]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[dto.ReviewAllProduct]{}, args.Error(1)
 }
 
 
@@ -50,3 +51,10 @@ func (m *ReviewRepositoryMock) GetAverageRatingByProductId(productId uint) (floa
 	return args.Get(0).(float64),args.Error(1)
 }
 
+func (m *ReviewRepositoryMock) CountRatingsByProductId(productId uint) (map[int]int, error) {
+	args := m.Called(productId)
+	if countMap, ok := args.Get(0).(map[int]int); ok {
+		return countMap, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type ShipmentRepositoryMock struct {
+	mock.Mock
+}
+
+func NewShipmentRepositoryMock() *ShipmentRepositoryMock {
+	return &ShipmentRepositoryMock{}
+}
+
+func (m *ShipmentRepositoryMock) Create(tx *gorm.DB, shipment *models.Shipment) error {
+	args := m.Called(tx, shipment)
+	return args.Error(0)
+}
+
+func (m *ShipmentRepositoryMock) Update(tx *gorm.DB, shipment *models.Shipment) error {
+	args := m.Called(tx, shipment)
+	return args.Error(0)
+}
+
+func (m *ShipmentRepositoryMock) FindByID(id uint) (*models.Shipment, error) {
+	args := m.Called(id)
+	if shipment, ok := args.Get(0).(*models.Shipment); ok {
+		return shipment, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentRepositoryMock) FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error) {
+	args := m.Called(orderID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Shipment]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Shipment]{}, args.Error(1)
+}
@@ -1,25 +1,48 @@
 package repositories
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepositoryInterface interface {
 	FindProductVariantByID(productVariantIDs []uint)([]models.ProductVariant,error)
 	Create(tx *gorm.DB,order *models.Order) error
-	UpdateProductVariantStock(tx *gorm.DB,productVariantID uint, newStock int) error
+	DecrementVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) (bool, error)
 	FindByIDWithItemsAndProducts(orderID uint) (*models.Order, error)
-	UpdateStatusOrder(orderId *uint, status models.Status) error
 	FindOrderById(orderID uint) (*models.Order, error)
-	FindAllOrderByUserId(userIDUint uint) ([]models.Order,error)
-	UpdateStatusOrderByUserId(orderID uint,status models.Status) error
-	FindAll() ([]models.Order,error)
+	FindAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order],error)
+	UpdateStatusFrom(tx *gorm.DB, orderID uint, from models.Status, to models.Status) (bool, error)
+	RestoreVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) error
+	CreateStatusEvent(tx *gorm.DB, event *models.OrderStatusEvent) error
+	RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error
+	CreateReservations(tx *gorm.DB, reservations []models.StockReservation) error
+	UpdateReservationStatus(tx *gorm.DB, orderID uint, from models.ReservationStatus, to models.ReservationStatus) error
+	ExtendReservation(tx *gorm.DB, orderID uint, expiresAt time.Time) (bool, error)
+	FindExpiredOrders(now time.Time, afterExpireAt time.Time, afterID uint, limit int) ([]models.Order, error)
+	LockExpiredOrder(tx *gorm.DB, orderID uint, now time.Time) (*models.Order, error)
+	LockOrderWithShipments(tx *gorm.DB, orderID uint) (*models.Order, error)
+	FindNextPaymentExpiry() (*time.Time, error)
+	FindStatusEvents(orderID uint) ([]models.OrderStatusEvent, error)
+	FindAll(params pagination.Params) (pagination.Page[models.Order],error)
 	GetTop5ProductsBySales() ([]dto.TopProductDTO, error)
 	GetSalesPerDay() ([]dto.SalesPerMonthDTO, error) 
 	Delete(id uint) error
-	GetUserDetail() ([]dto.CustomerDTO,error)
+	GetUserDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO],error)
+	FindCouponByCode(code string) (*models.Coupon, error)
+	LockCoupon(tx *gorm.DB, couponID uint) error
+	CountCouponRedemptions(tx *gorm.DB, couponID uint) (int64, error)
+	CountCouponRedemptionsByUser(tx *gorm.DB, couponID uint, userID uint) (int64, error)
+	CreateCouponRedemption(tx *gorm.DB, redemption *models.CouponRedemption) error
+	UpdatePaymentIntentID(orderID uint, paymentIntentID string) error
+	FindByPaymentIntentID(paymentIntentID string) (*models.Order, error)
 }
 
 type OrderRepository struct {
@@ -30,6 +53,16 @@ func NewOrderRepository(db *gorm.DB) *OrderRepository{
 	return &OrderRepository{db:db}
 }
 
+func orderID(order models.Order) uint {
+	return order.ID
+}
+
+// NOTE - order เก่าอาจอ้างถึง variant หรือสินค้าที่ถูกเลิกขายไปแล้ว (soft delete) ต้องโหลดมาแสดงด้วย
+func withHistoricalVariants(db *gorm.DB) *gorm.DB {
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	return db.Preload("OrderItem.ProductVariant", unscoped).Preload("OrderItem.ProductVariant.Product", unscoped)
+}
+
 func (r *OrderRepository) FindProductVariantByID(productVariantIDs []uint)([]models.ProductVariant,error) {
 	var productVariants []models.ProductVariant
 
@@ -46,14 +79,22 @@ func (r *OrderRepository) Create(tx *gorm.DB,order *models.Order) error {
 	return tx.Create(order).Error
 }
 
-func (r *OrderRepository) UpdateProductVariantStock(tx *gorm.DB,productVariantID uint, newStock int) error {
-	return tx.Model(&models.ProductVariant{}).Where("id = ?",productVariantID).Update("stock",newStock).Error
+// NOTE - ตัด stock ใน database โดยตรง ถ้า stock ไม่พอจะไม่มีแถวถูกอัปเดต กันสอง order ตัด stock ชิ้นสุดท้ายพร้อมกัน
+func (r *OrderRepository) DecrementVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) (bool, error) {
+	result := tx.Model(&models.ProductVariant{}).
+		Where("id = ? AND stock >= ?", productVariantID, quantity).
+		Update("stock", gorm.Expr("stock - ?", quantity))
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
 }
 
 func (r *OrderRepository) FindByIDWithItemsAndProducts(orderID uint) (*models.Order, error) {
 	var order models.Order
 
-	if	err := r.db.Preload("OrderItem.ProductVariant.Product").Preload("User").Preload("Coupon").First(&order,orderID).Error; err != nil {
+	if	err := r.db.Scopes(withHistoricalVariants).Preload("User").Preload("Coupon").First(&order,orderID).Error; err != nil {
 		return nil,err
 	}
 
@@ -61,17 +102,9 @@ func (r *OrderRepository) FindByIDWithItemsAndProducts(orderID uint) (*models.Or
 
 }
 
-func (r *OrderRepository) UpdateStatusOrder(orderId *uint, status models.Status) error {
-	if err := r.db.Model(&models.Order{}).Where("id = ?",*orderId).Update("status",status).Error; err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (r *OrderRepository) FindOrderById(orderID uint) (*models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("User").Preload("Coupon").Preload("OrderItem.ProductVariant.Product").Where("id = ?", orderID).First(&order).Error
+	err := r.db.Preload("User").Preload("Coupon").Scopes(withHistoricalVariants).Preload("Shipments.Items").Where("id = ?", orderID).First(&order).Error
 
 	if err != nil {
 		return nil, err
@@ -80,32 +113,176 @@ func (r *OrderRepository) FindOrderById(orderID uint) (*models.Order, error) {
 	return &order, nil
 }
 
-func (r *OrderRepository) FindAllOrderByUserId(userIDUint uint) ([]models.Order,error) {
+func (r *OrderRepository) FindAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order],error) {
 	var orderAll []models.Order
 
-	err := r.db.Preload("Coupon").Preload("OrderItem.ProductVariant.Product").Where("user_id = ?",userIDUint).Order("id DESC").Find(&orderAll).Error
+	total, err := params.CountTotal(r.db.Model(&models.Order{}).Where("user_id = ?", userIDUint))
 	if err != nil {
-		return nil,err
+		return pagination.Page[models.Order]{},err
+	}
+
+	err = params.Apply(r.db.Preload("Coupon").Scopes(withHistoricalVariants).Where("user_id = ?",userIDUint), "id", true).Find(&orderAll).Error
+	if err != nil {
+		return pagination.Page[models.Order]{},err
 	}
 
-	return orderAll,nil
+	return pagination.NewPage(orderAll, params, orderID, total),nil
 }
 
-func (r *OrderRepository) UpdateStatusOrderByUserId(orderID uint,status models.Status) error{
-	err := r.db.Model(&models.Order{}).Where("id = ?",orderID).Update("status",status).Error
+// NOTE - update เฉพาะตอนที่สถานะยังเป็น from อยู่ กันสอง request เปลี่ยนสถานะทับกัน
+func (r *OrderRepository) UpdateStatusFrom(tx *gorm.DB, orderID uint, from models.Status, to models.Status) (bool, error) {
+	result := tx.Model(&models.Order{}).Where("id = ? AND status = ?", orderID, from).Update("status", to)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *OrderRepository) RestoreVariantStock(tx *gorm.DB, productVariantID uint, quantity uint) error {
+	return tx.Model(&models.ProductVariant{}).
+		Where("id = ?", productVariantID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (r *OrderRepository) CreateStatusEvent(tx *gorm.DB, event *models.OrderStatusEvent) error {
+	return tx.Create(event).Error
+}
 
+func (r *OrderRepository) RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	return recordInventoryMovement(tx, movement)
+}
+
+func (r *OrderRepository) CreateReservations(tx *gorm.DB, reservations []models.StockReservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+	return tx.Create(&reservations).Error
+}
+
+func (r *OrderRepository) UpdateReservationStatus(tx *gorm.DB, orderID uint, from models.ReservationStatus, to models.ReservationStatus) error {
+	return tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, from).
+		Update("status", to).Error
+}
+
+// NOTE - ต่อเวลาทั้ง order และ reservation ที่ยัง active ให้หมดเวลาพร้อมกัน
+// NOTE - ต่อเวลาได้เฉพาะ order ที่ยัง pending คืน false ถ้า job หมดเวลาหรือ webhook เปลี่ยนสถานะไปก่อนแล้ว
+func (r *OrderRepository) ExtendReservation(tx *gorm.DB, orderID uint, expiresAt time.Time) (bool, error) {
+	result := tx.Model(&models.Order{}).
+		Where("id = ? AND status = ?", orderID, models.Pending).
+		Update("payment_expire_at", expiresAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err := tx.Model(&models.StockReservation{}).
+		Where("order_id = ? AND status = ?", orderID, models.ReservationActive).
+		Update("expires_at", expiresAt).Error
+
+	return err == nil, err
+}
+
+// NOTE - เรียงตาม (payment_expire_at, id) และเริ่มหลัง order ตัวสุดท้ายของ batch ก่อน (afterID = 0 คือเริ่มจากต้น)
+// order ที่ยกเลิกไม่ผ่านจะไม่ถูกดึงซ้ำจนบัง order ที่อยู่ข้างหลัง
+func (r *OrderRepository) FindExpiredOrders(now time.Time, afterExpireAt time.Time, afterID uint, limit int) ([]models.Order, error) {
+	var orders []models.Order
+
+	query := r.db.Model(&models.Order{}).
+		Select("id", "payment_expire_at").
+		Where("status = ? AND payment_expire_at <= ?", models.Pending, now)
+
+	if afterID != 0 {
+		query = query.Where("payment_expire_at > ? OR (payment_expire_at = ? AND id > ?)", afterExpireAt, afterExpireAt, afterID)
+	}
+
+	err := query.Order("payment_expire_at ASC, id ASC").
+		Limit(limit).
+		Find(&orders).Error
+
+	return orders, err
+}
+
+// NOTE - ล็อก order ที่หมดเวลาไว้ใน transaction ถ้า instance อื่นล็อกอยู่แล้วจะข้ามไป (SKIP LOCKED) และคืน nil
+func (r *OrderRepository) LockExpiredOrder(tx *gorm.DB, orderID uint, now time.Time) (*models.Order, error) {
+	var order models.Order
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? AND status = ? AND payment_expire_at <= ?", orderID, models.Pending, now).
+		First(&order).Error
 	if err != nil {
-		return err
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := tx.Where("order_id = ?", order.ID).Find(&order.OrderItem).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// NOTE - ล็อกแถว order ก่อนแล้วค่อยโหลดรายการสินค้ากับพัสดุใน transaction เดียวกัน คืน nil ถ้าไม่เจอ order
+// admin ที่สร้างหรือแก้พัสดุของ order เดียวกันพร้อมกันจะต่อคิวกัน และเห็นพัสดุที่อีกฝั่ง commit ไปแล้ว
+func (r *OrderRepository) LockOrderWithShipments(tx *gorm.DB, orderID uint) (*models.Order, error) {
+	var locked models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&locked, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var order models.Order
+	if err := tx.Preload("OrderItem").Preload("Shipments.Items").First(&order, orderID).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r *OrderRepository) FindNextPaymentExpiry() (*time.Time, error) {
+	var order models.Order
+
+	err := r.db.Where("status = ?", models.Pending).Order("payment_expire_at ASC").First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
 	}
-	return nil
+
+	return &order.PaymentExpireAt, nil
+}
+
+func (r *OrderRepository) FindStatusEvents(orderID uint) ([]models.OrderStatusEvent, error) {
+	var events []models.OrderStatusEvent
+
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&events).Error
+
+	return events, err
 }
 
-func (r *OrderRepository) FindAll() ([]models.Order,error){
+func (r *OrderRepository) FindAll(params pagination.Params) (pagination.Page[models.Order],error){
 	var orders []models.Order
-	
-	err := r.db.Preload("Coupon").Preload("OrderItem.ProductVariant.Product").Order("id DESC").Find(&orders).Error
 
-	return orders,err
+	total, err := params.CountTotal(r.db.Model(&models.Order{}))
+	if err != nil {
+		return pagination.Page[models.Order]{},err
+	}
+
+	err = params.Apply(r.db.Preload("Coupon").Scopes(withHistoricalVariants), "id", true).Find(&orders).Error
+	if err != nil {
+		return pagination.Page[models.Order]{},err
+	}
+
+	return pagination.NewPage(orders, params, orderID, total),nil
 }
 
 func (r *OrderRepository) GetTop5ProductsBySales() ([]dto.TopProductDTO, error) {
@@ -161,20 +338,92 @@ func (r *OrderRepository) Delete(id uint) error {
 	return tx.Commit().Error
 }
 
-func (r *OrderRepository) GetUserDetail() ([]dto.CustomerDTO,error) {
+func (r *OrderRepository) GetUserDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO],error) {
 	var result []dto.CustomerDTO
 
-	err := r.db.
+	// NOTE - ลูกค้าหนึ่งคนคือ user ที่มี order อย่างน้อยหนึ่งรายการ
+	total, err := params.CountTotal(r.db.Table("orders").Distinct("user_id"))
+	if err != nil {
+		return pagination.Page[dto.CustomerDTO]{}, err
+	}
+
+	query := r.db.
 		Table("orders").
 		Select("users.id, users.email,users.phone,users.first_name,users.last_name,COUNT(orders.id) as orders, SUM(orders.total_price) as total_spent, max(orders.created_at) as last_order_date").
 		Joins("JOIN users on users.id = orders.user_id").
-		Group("users.id").
-		Order("users.id asc").
-		Scan(&result).Error
+		Group("users.id")
+
+	err = params.Apply(query, "users.id", false).Scan(&result).Error
+	if err != nil {
+		return pagination.Page[dto.CustomerDTO]{}, err
+	}
+
+	return pagination.NewPage(result, params, func(customer dto.CustomerDTO) uint { return customer.ID }, total), nil
+}
+
+func (r *OrderRepository) FindCouponByCode(code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+
+	err := r.db.Preload("Categories").Preload("Products").Where("UPPER(code) = ?", strings.ToUpper(code)).First(&coupon).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
-}
\ No newline at end of file
+	return &coupon, nil
+}
+
+// NOTE - ล็อกแถว coupon ไว้จน transaction จบ checkout ที่ใช้ coupon เดียวกันจะรอกันนับจำนวนครั้งที่ใช้ทีละคน
+func (r *OrderRepository) LockCoupon(tx *gorm.DB, couponID uint) error {
+	var coupon models.Coupon
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&coupon, couponID).Error
+}
+
+// NOTE - นับเฉพาะ order ที่ยังไม่ถูก cancel
+func (r *OrderRepository) CountCouponRedemptions(tx *gorm.DB, couponID uint) (int64, error) {
+	var count int64
+
+	err := tx.Model(&models.CouponRedemption{}).
+		Joins("JOIN orders ON orders.id = coupon_redemptions.order_id").
+		Where("coupon_redemptions.coupon_id = ? AND orders.status <> ? AND orders.deleted_at IS NULL", couponID, models.Cancel).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *OrderRepository) CountCouponRedemptionsByUser(tx *gorm.DB, couponID uint, userID uint) (int64, error) {
+	var count int64
+
+	err := tx.Model(&models.CouponRedemption{}).
+		Joins("JOIN orders ON orders.id = coupon_redemptions.order_id").
+		Where("coupon_redemptions.coupon_id = ? AND coupon_redemptions.user_id = ? AND orders.status <> ? AND orders.deleted_at IS NULL", couponID, userID, models.Cancel).
+		Count(&count).Error
+
+	return count, err
+}
+
+func (r *OrderRepository) CreateCouponRedemption(tx *gorm.DB, redemption *models.CouponRedemption) error {
+	return tx.Create(redemption).Error
+}
+
+func (r *OrderRepository) UpdatePaymentIntentID(orderID uint, paymentIntentID string) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", orderID).Update("payment_intent_id", paymentIntentID).Error
+}
+
+func (r *OrderRepository) FindByPaymentIntentID(paymentIntentID string) (*models.Order, error) {
+	var order models.Order
+
+	err := r.db.Where("payment_intent_id = ?", paymentIntentID).First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepositoryInterface interface {
+	Create(payment *models.Payment) error
+	FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error)
+	CreateEventIfNotExists(event *models.StripeEvent) (bool, error)
+	DeleteEvent(eventID string) error
+	SumRefundedByOrderID(orderID uint) (float64, error)
+}
+
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+func (r *PaymentRepository) Create(payment *models.Payment) error {
+	return r.db.Create(payment).Error
+}
+
+func (r *PaymentRepository) FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error) {
+	var payments []models.Payment
+
+	total, err := params.CountTotal(r.db.Model(&models.Payment{}).Where("order_id = ?", orderID))
+	if err != nil {
+		return pagination.Page[models.Payment]{}, err
+	}
+
+	// NOTE - เรียงจาก payment แรกไปล่าสุดเหมือนเดิม
+	err = params.Apply(r.db.Where("order_id = ?", orderID), "id", false).Find(&payments).Error
+	if err != nil {
+		return pagination.Page[models.Payment]{}, err
+	}
+
+	return pagination.NewPage(payments, params, paymentID, total), nil
+}
+
+func paymentID(payment models.Payment) uint {
+	return payment.ID
+}
+
+// NOTE - insert event ถ้ายังไม่เคยมี ใช้ unique index ของ event_id กันกรณี Stripe ส่งซ้ำพร้อมกัน
+func (r *PaymentRepository) CreateEventIfNotExists(event *models.StripeEvent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "event_id"}},
+		DoNothing: true,
+	}).Create(event)
+
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PaymentRepository) DeleteEvent(eventID string) error {
+	return r.db.Unscoped().Where("event_id = ?", eventID).Delete(&models.StripeEvent{}).Error
+}
+
+func (r *PaymentRepository) SumRefundedByOrderID(orderID uint) (float64, error) {
+	var total float64
+
+	err := r.db.Model(&models.Payment{}).
+		Where("order_id = ? AND status = ?", orderID, models.RefundedPayment).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+
+	return total, err
+}
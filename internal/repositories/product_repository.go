@@ -2,20 +2,40 @@ package repositories
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepositoryInterface interface {
 	Create(product *models.Product) error
 	FindByID(id uint) (*models.Product, error)
-	FindAll(page uint, limit uint, minPrice int64, maxPrice int64, searchName string, categoryIDs []int,sizeIDs []string) (productList []models.Product ,pageTotal int64,err error) 
-	Update(product *models.Product) error
+	FindAll(query dto.ProductListQueryDTO) (productList []models.Product, pageTotal int64, err error)
+	Update(product *models.Product, retiredVariantIDs []uint) ([]models.ProductImage, error)
 	Delete(id uint) error
+	FindVariantBySKU(sku string) (*models.ProductVariant, error)
+	FindByIDs(ids []uint) ([]models.Product, error)
+	FindFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error)
+	FindBySlug(slug string) (*models.Product, error)
+	FindProductIDBySlugRedirect(slug string) (uint, error)
+	FindSlugsWithPrefix(base string, excludeProductID uint) ([]string, error)
+	FindWithoutSlug() ([]models.Product, error)
+	UpdateSlug(id uint, slug string) error
+	AddImage(image *models.ProductImage, maxImages int) (bool, error)
+	FindImages(productID uint) ([]models.ProductImage, error)
+	UpdateImageOrder(productID uint, imageIDs []uint, primaryImageID uint) error
+	DeleteImage(productID uint, imageID uint, minImages int) (bool, error)
 	// DeleteImageByProductID(productID uint) error
 }
 
+// NOTE - category ถูกลบไปแล้วระหว่างที่กำลังบันทึกสินค้า
+var ErrCategoryNotFound = errors.New("Category not found")
+
 type ProductRepository struct {
 	db *gorm.DB
 }
@@ -25,13 +45,40 @@ func NewProductRepository(db *gorm.DB) *ProductRepository {
 }
 
 func (r *ProductRepository) Create(product *models.Product) error {
-	return r.db.Create(product).Error
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := lockActiveCategory(tx, product.CategoryID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Create(product).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := releaseSlugRedirect(tx, product.Slug); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// NOTE - บันทึก stock ตั้งต้นของแต่ละ variant ลง ledger
+	if err := recordVariantRestock(tx, product.Variants, "initial stock"); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
 }
 
 func (r *ProductRepository) FindByID(id uint) (*models.Product, error){
 	var product models.Product
 
-	err := r.db.Preload("Variants").Preload("Images").First(&product,id).Error
+	err := r.db.Preload("Variants").Preload("Images", orderedImages).Preload("Images.Thumbnails").First(&product,id).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound){
 		return nil,nil
@@ -44,65 +91,650 @@ func (r *ProductRepository) FindByID(id uint) (*models.Product, error){
 	return &product, nil
 }
 
-func (r *ProductRepository) FindAll(page uint, limit uint, minPrice int64, maxPrice int64, searchName string, categoryIDs []int,sizeIDs []string) (productList []models.Product ,pageTotal int64,err error) {
+func (r *ProductRepository) FindBySlug(slug string) (*models.Product, error) {
+	var product models.Product
+
+	err := r.db.Preload("Variants").Preload("Images", orderedImages).Preload("Images.Thumbnails").Where("slug = ?", slug).First(&product).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// NOTE - คืน 0 ถ้า slug นี้ไม่เคยเป็นของสินค้าไหน
+func (r *ProductRepository) FindProductIDBySlugRedirect(slug string) (uint, error) {
+	var redirect models.ProductSlugRedirect
+
+	err := r.db.Where("slug = ?", slug).First(&redirect).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return redirect.ProductID, nil
+}
+
+// NOTE - slug ที่ขึ้นต้นด้วย base ทั้งของสินค้าที่ยังขายอยู่และ slug เก่าที่ยัง redirect ได้ ไม่นับของสินค้า excludeProductID
+// slug.Make ให้แค่ a-z 0-9 และ - จึงไม่ต้อง escape ตัวอักษรพิเศษของ LIKE
+func (r *ProductRepository) FindSlugsWithPrefix(base string, excludeProductID uint) ([]string, error) {
+	var slugs []string
+
+	err := r.db.Model(&models.Product{}).
+		Where("id <> ?", excludeProductID).
+		Where("slug = ? OR slug LIKE ?", base, base+"-%").
+		Pluck("slug", &slugs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var redirectSlugs []string
+
+	err = r.db.Model(&models.ProductSlugRedirect{}).
+		Joins("JOIN products ON products.id = product_slug_redirects.product_id AND products.deleted_at IS NULL").
+		Where("product_slug_redirects.product_id <> ?", excludeProductID).
+		Where("product_slug_redirects.slug = ? OR product_slug_redirects.slug LIKE ?", base, base+"-%").
+		Pluck("product_slug_redirects.slug", &redirectSlugs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return append(slugs, redirectSlugs...), nil
+}
+
+func (r *ProductRepository) FindWithoutSlug() ([]models.Product, error) {
+	var products []models.Product
+
+	err := r.db.Where("slug = ? OR slug IS NULL", "").Order("id ASC").Find(&products).Error
+
+	return products, err
+}
+
+func (r *ProductRepository) UpdateSlug(id uint, slug string) error {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := releaseSlugRedirect(tx, slug); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&models.Product{}).Where("id = ?", id).Update("slug", slug).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// NOTE - ลำดับที่คืนไม่ตรงกับ ids คนเรียกต้องเรียงเอง
+func (r *ProductRepository) FindByIDs(ids []uint) ([]models.Product, error) {
+	var products []models.Product
+
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	err := r.db.Preload("Category").Preload("Variants").Preload("Images", orderedImages).Preload("Images.Thumbnails").Where("id IN ?", ids).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// NOTE - ราคาที่ลูกค้าจ่ายจริงของ variant ถ้าสินค้าลดราคาอยู่จะหักส่วนลดออกและไม่ให้ติดลบ เหมือน services.FinalUnitPrice
+const variantFinalPriceSQL = `CASE WHEN products.is_on_sale AND products.sale_price IS NOT NULL
+	THEN GREATEST(product_variants.price - products.sale_price, 0)
+	ELSE product_variants.price END`
+
+func (r *ProductRepository) FindAll(query dto.ProductListQueryDTO) (productList []models.Product, pageTotal int64, err error) {
 	var products []models.Product
 	var total int64
 
+	productQuery := r.filteredProducts(query, "")
+
+	if err := productQuery.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (query.Page - 1) * query.Limit
+	pageTotal = (total + int64(query.Limit) - 1) / int64(query.Limit)
+
+	err = productQuery.
+		Preload("Category").Preload("Variants").Preload("Images", orderedImages).Preload("Images.Thumbnails").
+		Order(productSortClause(query.Sort)).
+		Offset(int(offset)).Limit(int(query.Limit)).
+		Find(&products).Error
+	return products, pageTotal, err
+}
+
+// NOTE - facet ที่ไม่ต้องกรองด้วย filter ของตัวเอง ส่งว่างคือกรองทุกอย่าง
+const (
+	facetCategory = "category"
+	facetSize     = "size"
+	facetPrice    = "price"
+	facetOnSale   = "onSale"
+	facetRating   = "rating"
+)
+
+// NOTE - คะแนนรีวิวเฉลี่ยของสินค้า ไม่มีรีวิวได้ NULL
+const productAverageRatingSQL = `(SELECT AVG(reviews.rating) FROM reviews
+	WHERE reviews.product_id = products.id AND reviews.deleted_at IS NULL)`
+
+func (r *ProductRepository) filteredProducts(query dto.ProductListQueryDTO, skip string) *gorm.DB {
 	productQuery := r.db.Model(&models.Product{})
 
-	// NOTE - เช็คว่า Category มากกว่า 0 ไหม
-	if len(categoryIDs) >0 {
-        productQuery = productQuery.Where("category_id IN ?", categoryIDs)
+	// NOTE - เช็คว่า Category มากกว่า 0 ไหม เลือก category แม่แล้วได้สินค้าใน category ลูกหลานด้วย
+	if len(query.CategoryIDs) > 0 && skip != facetCategory {
+		productQuery = productQuery.Where("products.category_id IN (?)", r.db.Raw(categoryDescendantIDsSQL, query.CategoryIDs))
+	}
+
+	if query.SearchName != "" {
+		productQuery = productQuery.Where("products.name ILIKE ?", "%"+query.SearchName+"%")
 	}
 
-	if len(sizeIDs) > 0 {
-		productQuery = productQuery.
-			Joins("JOIN product_variants ON product_variants.product_id = products.id").
-			Where("product_variants.size IN ?",sizeIDs).
-			Group("products.id")
+	if query.OnSale != nil && skip != facetOnSale {
+		productQuery = productQuery.Where("products.is_on_sale = ?", *query.OnSale)
 	}
 
-	if searchName != "" {
-		productQuery = productQuery.Where("name ILIKE ?", "%"+searchName+"%")
+	if query.Featured != nil {
+		productQuery = productQuery.Where("products.is_featured = ?", *query.Featured)
 	}
 
-	if err := productQuery.Count(&total).Error; err != nil{
-		return nil,0,err
+	if query.MinRating != nil && skip != facetRating {
+		productQuery = productQuery.Where(productAverageRatingSQL+" >= ?", *query.MinRating)
 	}
-	
-	offset := (page -1 ) * limit
-	pageTotal = (total + int64(limit) - 1) / int64(limit)
 
-	err = productQuery.Preload("Category").Preload("Variants").Preload("Images").Offset(int(offset)).Limit(int(limit)).Order("id desc ").Find(&products).Error
-	return products, pageTotal,err
+	// NOTE - size ราคา และ stock ต้องตรงใน variant ตัวเดียวกัน เช่น ไซส์ M ที่ราคาอยู่ในช่วงและยังมีของ
+	if hasVariantConditions(query, skip) {
+		variantQuery := r.db.Table("product_variants").
+			Select("1").
+			Where("product_variants.product_id = products.id AND product_variants.deleted_at IS NULL")
+
+		productQuery = productQuery.Where("EXISTS (?)", applyVariantConditions(variantQuery, query, skip))
+	}
+
+	return productQuery
+}
+
+func hasVariantConditions(query dto.ProductListQueryDTO, skip string) bool {
+	return (len(query.Sizes) > 0 && skip != facetSize) ||
+		((query.MinPrice != nil || query.MaxPrice != nil) && skip != facetPrice) ||
+		query.InStock
+}
+
+func applyVariantConditions(variantQuery *gorm.DB, query dto.ProductListQueryDTO, skip string) *gorm.DB {
+	if len(query.Sizes) > 0 && skip != facetSize {
+		variantQuery = variantQuery.Where("product_variants.size IN ?", query.Sizes)
+	}
+	if query.MinPrice != nil && skip != facetPrice {
+		variantQuery = variantQuery.Where(variantFinalPriceSQL+" >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil && skip != facetPrice {
+		variantQuery = variantQuery.Where(variantFinalPriceSQL+" <= ?", *query.MaxPrice)
+	}
+	if query.InStock {
+		variantQuery = variantQuery.Where("product_variants.stock > 0")
+	}
+	return variantQuery
+}
+
+// NOTE - ขอบล่างของช่วงราคา ช่วงสุดท้ายไม่มีเพดาน ช่วงละ [ขอบล่าง, ขอบถัดไป)
+var productPriceBucketBounds = []float64{0, 500, 1000, 2000, 5000}
+
+// NOTE - นับดาวแบบ "4 ดาวขึ้นไป" สินค้าหนึ่งชิ้นถูกนับได้หลายช่วง
+var productRatingFacetValues = []int{4, 3, 2, 1}
+
+type facetRow struct {
+	Value string
+	Label string
+	Count int64
+}
+
+func (r *ProductRepository) FindFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error) {
+	facets := &dto.ProductFacetsDTO{
+		Categories:   []dto.FacetCountDTO{},
+		Sizes:        []dto.FacetCountDTO{},
+		PriceBuckets: []dto.PriceBucketFacetDTO{},
+		OnSale:       []dto.FacetCountDTO{},
+		Ratings:      []dto.FacetCountDTO{},
+	}
+
+	var categoryRows []facetRow
+	err := r.filteredProducts(query, facetCategory).
+		Select("CAST(products.category_id AS VARCHAR) AS value, categories.name AS label, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Group("products.category_id, categories.name").
+		Order("count DESC, label ASC").
+		Scan(&categoryRows).Error
+	if err != nil {
+		return nil, err
+	}
+	facets.Categories = toFacetCounts(categoryRows)
+
+	// NOTE - นับจาก variant ที่ผ่านเงื่อนไขราคาและ stock ด้วย ไซส์ที่หมดจะไม่ถูกนับตอนเลือกเฉพาะของที่มี
+	var sizeRows []facetRow
+	err = applyVariantConditions(
+		r.filteredProducts(query, facetSize).
+			Joins("JOIN product_variants ON product_variants.product_id = products.id AND product_variants.deleted_at IS NULL"),
+		query, facetSize).
+		Select("product_variants.size AS value, product_variants.size AS label, COUNT(DISTINCT products.id) AS count").
+		Group("product_variants.size").
+		Order("product_variants.size ASC").
+		Scan(&sizeRows).Error
+	if err != nil {
+		return nil, err
+	}
+	facets.Sizes = toFacetCounts(sizeRows)
+
+	var bucketRows []struct {
+		Bucket int
+		Count  int64
+	}
+	err = applyVariantConditions(
+		r.filteredProducts(query, facetPrice).
+			Joins("JOIN product_variants ON product_variants.product_id = products.id AND product_variants.deleted_at IS NULL"),
+		query, facetPrice).
+		Select(priceBucketSQL() + " AS bucket, COUNT(DISTINCT products.id) AS count").
+		Group("bucket").
+		Scan(&bucketRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	bucketCounts := make(map[int]int64)
+	for _, row := range bucketRows {
+		bucketCounts[row.Bucket] = row.Count
+	}
+	for idx, min := range productPriceBucketBounds {
+		bucket := dto.PriceBucketFacetDTO{Min: min, Count: bucketCounts[idx]}
+		if idx+1 < len(productPriceBucketBounds) {
+			max := productPriceBucketBounds[idx+1]
+			bucket.Max = &max
+		}
+		facets.PriceBuckets = append(facets.PriceBuckets, bucket)
+	}
+
+	var onSaleRows []struct {
+		IsOnSale bool
+		Count    int64
+	}
+	err = r.filteredProducts(query, facetOnSale).
+		Select("products.is_on_sale AS is_on_sale, COUNT(*) AS count").
+		Group("products.is_on_sale").
+		Scan(&onSaleRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	onSaleCounts := make(map[bool]int64)
+	for _, row := range onSaleRows {
+		onSaleCounts[row.IsOnSale] = row.Count
+	}
+	facets.OnSale = []dto.FacetCountDTO{
+		{Value: "true", Label: "On sale", Count: onSaleCounts[true]},
+		{Value: "false", Label: "Regular price", Count: onSaleCounts[false]},
+	}
+
+	var ratingRows []struct {
+		Stars int
+		Count int64
+	}
+	err = r.db.Table("(?) AS rated", r.filteredProducts(query, facetRating).Select(productAverageRatingSQL+" AS avg_rating")).
+		Select("FLOOR(avg_rating) AS stars, COUNT(*) AS count").
+		Where("avg_rating IS NOT NULL").
+		Group("stars").
+		Scan(&ratingRows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, value := range productRatingFacetValues {
+		count := int64(0)
+		for _, row := range ratingRows {
+			if row.Stars >= value {
+				count += row.Count
+			}
+		}
+
+		facets.Ratings = append(facets.Ratings, dto.FacetCountDTO{
+			Value: strconv.Itoa(value),
+			Label: fmt.Sprintf("%d & up", value),
+			Count: count,
+		})
+	}
+
+	return facets, nil
+}
+
+func priceBucketSQL() string {
+	var builder strings.Builder
+	builder.WriteString("CASE")
+	for idx := 1; idx < len(productPriceBucketBounds); idx++ {
+		builder.WriteString(fmt.Sprintf(" WHEN %s < %v THEN %d", variantFinalPriceSQL, productPriceBucketBounds[idx], idx-1))
+	}
+	builder.WriteString(fmt.Sprintf(" ELSE %d END", len(productPriceBucketBounds)-1))
+	return builder.String()
+}
+
+func toFacetCounts(rows []facetRow) []dto.FacetCountDTO {
+	counts := []dto.FacetCountDTO{}
+	for _, row := range rows {
+		counts = append(counts, dto.FacetCountDTO{Value: row.Value, Label: row.Label, Count: row.Count})
+	}
+	return counts
+}
+
+// NOTE - ทุกแบบเรียงด้วย id ต่อท้าย ให้ลำดับคงที่ตอนค่าเท่ากัน แบ่งหน้าแล้วสินค้าไม่ซ้ำหรือหาย
+func productSortClause(sort string) clause.OrderBy {
+	switch sort {
+	case dto.ProductSortPriceAsc, dto.ProductSortPriceDesc:
+		direction := "ASC"
+		if sort == dto.ProductSortPriceDesc {
+			direction = "DESC"
+		}
+		return orderByExpr(`(SELECT MIN(` + variantFinalPriceSQL + `) FROM product_variants
+			WHERE product_variants.product_id = products.id AND product_variants.deleted_at IS NULL) ` + direction + `, products.id DESC`)
+	case dto.ProductSortBestSelling:
+		// NOTE - นับยอดขายรวม variant ที่เลิกขายไปแล้วด้วย ไม่นับ order ที่ถูกยกเลิก
+		return orderByExpr(`(SELECT COALESCE(SUM(order_items.quantity), 0) FROM order_items
+			JOIN orders ON orders.id = order_items.order_id
+			JOIN product_variants ON product_variants.id = order_items.product_variant_id
+			WHERE product_variants.product_id = products.id AND orders.status <> ?
+			AND order_items.deleted_at IS NULL AND orders.deleted_at IS NULL) DESC, products.id DESC`, models.Cancel)
+	case dto.ProductSortTopRated:
+		return orderByExpr("COALESCE(" + productAverageRatingSQL + ", 0) DESC, products.id DESC")
+	default:
+		return orderByExpr("products.id DESC")
+	}
+}
+
+func orderByExpr(sql string, vars ...interface{}) clause.OrderBy {
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: vars, WithoutParentheses: true}}
 }
 
-func (r *ProductRepository) Update(product *models.Product) error {
+// NOTE - แก้ไข variant ในแถวเดิมเพื่อให้ id คงเดิม สร้าง variant ใหม่ และเลิกขาย variant ที่ถูกเอาออก
+// ทุกการเปลี่ยน stock บันทึกลง inventory ledger ใน transaction เดียวกัน
+// NOTE - คืนรูปที่ถูกเอาออกพร้อม thumbnail ให้ service ลบไฟล์ใน storage หลัง commit เหมือนตอนลบรูปทีละรูป
+func (r *ProductRepository) Update(product *models.Product, retiredVariantIDs []uint) ([]models.ProductImage, error) {
 	// NOTE - ใช้ transaction 
 	tx := r.db.Begin()
 
 	if tx.Error != nil{
-		return tx.Error
+		return nil, tx.Error
 	}
 
-	// NOTE - ลบ variants เก่าออกก่อน
-	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductVariant{}).Error; err != nil {
+	if err := lockActiveCategory(tx, product.CategoryID); err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	// NOTE - ลบ image เก่าออกก่อน
-	if err := tx.Where("product_id = ?", product.ID).Delete(&models.ProductImage{}).Error; err != nil {
+	// NOTE - ลบเฉพาะ image ที่ไม่ได้ส่งมาแล้ว image ที่มี id อยู่แล้วคงแถวเดิมไว้ thumbnail จะได้ไม่หาย
+	keptImageIDs := []uint{}
+	for _, image := range product.Images {
+		if image.ID != 0 {
+			keptImageIDs = append(keptImageIDs, image.ID)
+		}
+	}
+
+	removedQuery := tx.Preload("Thumbnails").Where("product_id = ?", product.ID)
+	if len(keptImageIDs) > 0 {
+		removedQuery = removedQuery.Where("id NOT IN ?", keptImageIDs)
+	}
+
+	var removedImages []models.ProductImage
+	if err := removedQuery.Find(&removedImages).Error; err != nil {
 		tx.Rollback()
-		return err
+		return nil, err
 	}
 
-	if err := tx.Save(product).Error; err !=nil {
+	if len(removedImages) > 0 {
+		removedImageIDs := make([]uint, 0, len(removedImages))
+		for _, image := range removedImages {
+			removedImageIDs = append(removedImageIDs, image.ID)
+		}
+
+		if err := tx.Where("product_image_id IN ?", removedImageIDs).Delete(&models.ProductImageThumbnail{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if err := tx.Where("id IN ?", removedImageIDs).Delete(&models.ProductImage{}).Error; err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// NOTE - เปลี่ยน slug แล้วเก็บ slug เก่าไว้ redirect มาที่สินค้านี้
+	var previousSlug string
+	if err := tx.Model(&models.Product{}).Where("id = ?", product.ID).Pluck("slug", &previousSlug).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if previousSlug != product.Slug {
+		if err := releaseSlugRedirect(tx, product.Slug); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		if previousSlug != "" {
+			if err := tx.Create(&models.ProductSlugRedirect{Slug: previousSlug, ProductID: product.ID}).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+
+	// NOTE - variant กับ image จัดการเองด้านล่าง ไม่ให้ Save ไปสร้างหรือแก้ให้
+	if err := tx.Omit("Variants", "Images").Save(product).Error; err !=nil {
 		tx.Rollback()
+		return nil, err
+	}
+
+	for i := range product.Images {
+		image := &product.Images[i]
+
+		if image.ID == 0 {
+			image.ProductID = product.ID
+			if err := tx.Omit("Product").Create(image).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			continue
+		}
+
+		err := tx.Model(&models.ProductImage{}).
+			Where("id = ? AND product_id = ?", image.ID, product.ID).
+			Updates(map[string]interface{}{"position": image.Position, "is_primary": image.IsPrimary}).Error
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// NOTE - variant ที่เลิกขายใช้ soft delete แถวยังอยู่ให้ order เก่าอ้างถึงได้ stock ที่เหลือถือว่าออกจากระบบ
+	// เลิกขายก่อนเพื่อให้ variant อื่นใช้ SKU เดิมต่อได้
+	for _, variantID := range retiredVariantIDs {
+		if err := retireVariant(tx, variantID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := releaseChangedSKUs(tx, product.Variants); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	newVariants := []models.ProductVariant{}
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+
+		if variant.ID == 0 {
+			variant.ProductID = product.ID
+			if err := tx.Omit("Product").Create(variant).Error; err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+			newVariants = append(newVariants, *variant)
+			continue
+		}
+
+		if err := updateVariantInPlace(tx, variant); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := recordVariantRestock(tx, newVariants, "variant created by product update"); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return removedImages, nil
+}
+
+// NOTE - category เป็น soft delete แถวยังอยู่ FK จึงไม่กันสินค้าใหม่ ต้องเช็ค deleted_at IS NULL ใน transaction เอง
+// ล็อกแบบ SHARE ชนกับ FOR UPDATE ของ DeleteIfEmpty ถ้ากำลังลบอยู่จะรอจนลบเสร็จแล้วไม่เจอแถว
+func lockActiveCategory(tx *gorm.DB, categoryID uint) error {
+	var category models.Category
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").First(&category, categoryID).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCategoryNotFound
+	}
+
+	return err
+}
+
+// NOTE - slug ที่กำลังจะใช้เป็น slug ปัจจุบัน ถ้ายังค้างอยู่ในตาราง redirect ต้องลบออก
+// service เช็คแล้วว่าไม่ใช่ slug เก่าของสินค้าอื่นที่ยังขายอยู่ แถวที่เหลือจึงเป็นของสินค้าที่ถูกลบไปแล้วหรือของสินค้าตัวเอง
+func releaseSlugRedirect(tx *gorm.DB, slug string) error {
+	if slug == "" {
+		return nil
+	}
+
+	return tx.Where("slug = ?", slug).Delete(&models.ProductSlugRedirect{}).Error
+}
+
+// NOTE - unique index ของ SKU เช็คทีละแถว variant ที่สลับ SKU กัน หรือ variant ใหม่ที่ใช้ SKU ที่ตัวเดิมกำลังเปลี่ยนออก
+// จะชนกันถ้าทำตามลำดับในฟอร์ม จึงย้าย SKU ของ variant ที่เปลี่ยน SKU ไปเป็นค่าชั่วคราวก่อน แล้วค่อยตั้งค่าจริงใน loop ถัดไป
+func releaseChangedSKUs(tx *gorm.DB, variants []models.ProductVariant) error {
+	variantIDs := []uint{}
+	for _, variant := range variants {
+		if variant.ID != 0 {
+			variantIDs = append(variantIDs, variant.ID)
+		}
+	}
+
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	var current []models.ProductVariant
+	if err := tx.Select("id", "sku").Where("id IN ?", variantIDs).Find(&current).Error; err != nil {
 		return err
 	}
 
-	return tx.Commit().Error
+	currentSKUs := make(map[uint]string, len(current))
+	for _, variant := range current {
+		currentSKUs[variant.ID] = variant.SKU
+	}
+
+	for _, variant := range variants {
+		if variant.ID == 0 || currentSKUs[variant.ID] == "" || currentSKUs[variant.ID] == variant.SKU {
+			continue
+		}
+
+		err := tx.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).Update("sku", fmt.Sprintf("~moving-%d", variant.ID)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NOTE - ไม่แก้ stock ของ variant เดิม stock เปลี่ยนผ่าน order และ inventory adjust เท่านั้น
+// อ่าน stock ปัจจุบันกลับมาให้ handler ตอบกลับค่าจริง
+func updateVariantInPlace(tx *gorm.DB, variant *models.ProductVariant) error {
+	err := tx.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).Updates(map[string]interface{}{
+		"size":  variant.Size,
+		"sku":   variant.SKU,
+		"price": variant.Price,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).Pluck("stock", &variant.Stock).Error
+}
+
+func retireVariant(tx *gorm.DB, variantID uint) error {
+	var current models.ProductVariant
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, variantID).Error; err != nil {
+		return err
+	}
+
+	if current.Stock != 0 {
+		if err := tx.Model(&models.ProductVariant{}).Where("id = ?", variantID).Update("stock", 0).Error; err != nil {
+			return err
+		}
+
+		movement := &models.InventoryMovement{
+			ProductVariantID: variantID,
+			Delta:            -current.Stock,
+			Reason:           models.InventoryAdjustment,
+			Note:             "variant retired by product update",
+		}
+		if err := recordInventoryMovement(tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Delete(&models.ProductVariant{}, variantID).Error
+}
+
+func recordVariantRestock(tx *gorm.DB, variants []models.ProductVariant, note string) error {
+	for _, variant := range variants {
+		if variant.Stock == 0 {
+			continue
+		}
+
+		movement := &models.InventoryMovement{
+			ProductVariantID: variant.ID,
+			Delta:            variant.Stock,
+			Reason:           models.InventoryRestock,
+			Note:             note,
+		}
+		if err := recordInventoryMovement(tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ProductRepository) FindVariantBySKU(sku string) (*models.ProductVariant, error) {
+	return findVariantBySKU(r.db.Preload("Product.Images", orderedImages), sku)
 }
 
 func (r *ProductRepository) Delete(id uint) error {
@@ -128,6 +760,159 @@ func (r *ProductRepository) Delete(id uint) error {
 	return tx.Commit().Error
 }
 
+func orderedImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// NOTE - รูปที่อัปโหลดใหม่ต่อท้ายรูปเดิม ถ้าสินค้ายังไม่มีรูปหลักรูปนี้จะเป็นรูปหลัก thumbnail ถูกสร้างไปพร้อมกัน
+// NOTE - ล็อกแถวสินค้าก่อนนับรูป upload พร้อมกันหลายรูปจะได้ไม่เกิน maxImages คืน false ถ้ารูปเต็มแล้ว
+func (r *ProductRepository) AddImage(image *models.ProductImage, maxImages int) (bool, error) {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&product, image.ProductID).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var imageCount int64
+	if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", image.ProductID).Count(&imageCount).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if imageCount >= int64(maxImages) {
+		tx.Rollback()
+		return false, nil
+	}
+
+	var lastPosition int
+	err := tx.Model(&models.ProductImage{}).
+		Where("product_id = ?", image.ProductID).
+		Select("COALESCE(MAX(position), -1)").
+		Scan(&lastPosition).Error
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var primaryCount int64
+	err = tx.Model(&models.ProductImage{}).
+		Where("product_id = ? AND is_primary = ?", image.ProductID, true).
+		Count(&primaryCount).Error
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	image.Position = lastPosition + 1
+	image.IsPrimary = primaryCount == 0
+
+	if err := tx.Omit("Product").Create(image).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	return true, tx.Commit().Error
+}
+
+func (r *ProductRepository) FindImages(productID uint) ([]models.ProductImage, error) {
+	var images []models.ProductImage
+
+	err := orderedImages(r.db.Preload("Thumbnails")).Where("product_id = ?", productID).Find(&images).Error
+	return images, err
+}
+
+// NOTE - imageIDs เรียงตามลำดับใหม่ service เช็คมาแล้วว่าครบทุกรูปของสินค้า
+func (r *ProductRepository) UpdateImageOrder(productID uint, imageIDs []uint, primaryImageID uint) error {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	for position, imageID := range imageIDs {
+		err := tx.Model(&models.ProductImage{}).
+			Where("id = ? AND product_id = ?", imageID, productID).
+			Updates(map[string]interface{}{"position": position, "is_primary": imageID == primaryImageID}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// NOTE - ลบรูปหลักแล้วให้รูปที่อยู่ลำดับแรกที่เหลือเป็นรูปหลักแทน
+// NOTE - ล็อกแถวสินค้าก่อนนับรูปเหมือน AddImage ลบพร้อมกันหลายรูปจะไม่เหลือน้อยกว่า minImages คืน false ถ้าลบไม่ได้
+func (r *ProductRepository) DeleteImage(productID uint, imageID uint, minImages int) (bool, error) {
+	tx := r.db.Begin()
+
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	var product models.Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&product, productID).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	var imageCount int64
+	if err := tx.Model(&models.ProductImage{}).Where("product_id = ?", productID).Count(&imageCount).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if imageCount <= int64(minImages) {
+		tx.Rollback()
+		return false, nil
+	}
+
+	var image models.ProductImage
+	if err := tx.Where("id = ? AND product_id = ?", imageID, productID).First(&image).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Where("product_image_id = ?", imageID).Delete(&models.ProductImageThumbnail{}).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Delete(&image).Error; err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if image.IsPrimary {
+		var next models.ProductImage
+		err := orderedImages(tx).Where("product_id = ?", productID).Limit(1).Find(&next).Error
+		if err != nil {
+			tx.Rollback()
+			return false, err
+		}
+
+		if next.ID != 0 {
+			if err := tx.Model(&next).Update("is_primary", true).Error; err != nil {
+				tx.Rollback()
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // func (r*ProductRepository) DeleteImageByProductID(productID uint) error {
 // 	return r.db.Where("product_id",productID).Delete(&models.ProductImage{}).Error
 // }
\ No newline at end of file
@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReturnRepositoryInterface interface {
+	Create(returnRequest *models.ReturnRequest) error
+	Resolve(tx *gorm.DB, returnRequest *models.ReturnRequest) (bool, error)
+	LockPending(tx *gorm.DB, id uint) (bool, error)
+	LockOrder(tx *gorm.DB, orderID uint) (*models.Order, error)
+	FindByID(id uint) (*models.ReturnRequest, error)
+	FindByUserID(userID uint, params pagination.Params) (pagination.Page[models.ReturnRequest], error)
+	FindAll(params pagination.Params) (pagination.Page[models.ReturnRequest], error)
+	FindOrderByID(orderID uint) (*models.Order, error)
+	SumReturnedQuantity(orderItemID uint) (int64, error)
+	SumRefundedAmount(tx *gorm.DB, orderID uint) (float64, error)
+	RestockVariant(tx *gorm.DB, variantID uint, quantity uint) error
+	CreatePayment(tx *gorm.DB, payment *models.Payment) error
+	RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error
+}
+
+type ReturnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) *ReturnRepository {
+	return &ReturnRepository{db: db}
+}
+
+func (r *ReturnRepository) Create(returnRequest *models.ReturnRequest) error {
+	return r.db.Create(returnRequest).Error
+}
+
+// NOTE - ปิดคำขอได้เฉพาะที่ยังรอพิจารณาอยู่ คืน false ถ้ามี request อื่นอนุมัติหรือปฏิเสธไปก่อนแล้ว
+func (r *ReturnRepository) Resolve(tx *gorm.DB, returnRequest *models.ReturnRequest) (bool, error) {
+	result := tx.Model(&models.ReturnRequest{}).
+		Where("id = ? AND status = ?", returnRequest.ID, models.ReturnRequested).
+		Updates(map[string]interface{}{
+			"status":        returnRequest.Status,
+			"admin_note":    returnRequest.AdminNote,
+			"refund_amount": returnRequest.RefundAmount,
+			"refund_id":     returnRequest.RefundID,
+			"resolved_at":   returnRequest.ResolvedAt,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// NOTE - ล็อกคำขอที่ยังรอพิจารณาไว้จน transaction จบ admin สองคนกดอนุมัติพร้อมกันคนที่สองจะรอแล้วเห็นว่าปิดไปแล้ว
+func (r *ReturnRepository) LockPending(tx *gorm.DB, id uint) (bool, error) {
+	var ids []uint
+
+	err := tx.Model(&models.ReturnRequest{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND status = ?", id, models.ReturnRequested).
+		Pluck("id", &ids).Error
+
+	return len(ids) > 0, err
+}
+
+// NOTE - ล็อกแถว order ก่อนอ่านยอดที่คืนไปแล้ว คำขอคืนหลายรายการของ order เดียวกันจะคืนเงินทีละรายการ ไม่เกินยอดที่จ่าย
+func (r *ReturnRepository) LockOrder(tx *gorm.DB, orderID uint) (*models.Order, error) {
+	var order models.Order
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := tx.Where("order_id = ?", orderID).Find(&order.OrderItem).Error; err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (r *ReturnRepository) FindByID(id uint) (*models.ReturnRequest, error) {
+	var returnRequest models.ReturnRequest
+
+	err := r.db.Preload("Order").Scopes(withHistoricalVariants).First(&returnRequest, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &returnRequest, nil
+}
+
+func (r *ReturnRepository) FindByUserID(userID uint, params pagination.Params) (pagination.Page[models.ReturnRequest], error) {
+	var returnRequests []models.ReturnRequest
+
+	total, err := params.CountTotal(r.db.Model(&models.ReturnRequest{}).Where("user_id = ?", userID))
+	if err != nil {
+		return pagination.Page[models.ReturnRequest]{}, err
+	}
+
+	err = params.Apply(r.db.Scopes(withHistoricalVariants).Where("user_id = ?", userID), "id", true).Find(&returnRequests).Error
+	if err != nil {
+		return pagination.Page[models.ReturnRequest]{}, err
+	}
+
+	return pagination.NewPage(returnRequests, params, returnRequestID, total), nil
+}
+
+func (r *ReturnRepository) FindAll(params pagination.Params) (pagination.Page[models.ReturnRequest], error) {
+	var returnRequests []models.ReturnRequest
+
+	total, err := params.CountTotal(r.db.Model(&models.ReturnRequest{}))
+	if err != nil {
+		return pagination.Page[models.ReturnRequest]{}, err
+	}
+
+	err = params.Apply(r.db.Scopes(withHistoricalVariants), "id", true).Find(&returnRequests).Error
+	if err != nil {
+		return pagination.Page[models.ReturnRequest]{}, err
+	}
+
+	return pagination.NewPage(returnRequests, params, returnRequestID, total), nil
+}
+
+func returnRequestID(returnRequest models.ReturnRequest) uint {
+	return returnRequest.ID
+}
+
+func (r *ReturnRepository) FindOrderByID(orderID uint) (*models.Order, error) {
+	var order models.Order
+
+	err := r.db.Preload("OrderItem").First(&order, orderID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// NOTE - จำนวนที่ขอคืนไปแล้วของ order item นี้ (ไม่นับที่ถูกปฏิเสธ)
+func (r *ReturnRepository) SumReturnedQuantity(orderItemID uint) (int64, error) {
+	var total int64
+
+	err := r.db.Model(&models.ReturnRequest{}).
+		Where("order_item_id = ? AND status <> ?", orderItemID, models.ReturnRejected).
+		Select("COALESCE(SUM(quantity), 0)").Scan(&total).Error
+
+	return total, err
+}
+
+// NOTE - ยอดที่คืนเงินไปแล้วทั้งหมดของ order นี้ นับจากตาราง payments
+func (r *ReturnRepository) SumRefundedAmount(tx *gorm.DB, orderID uint) (float64, error) {
+	var total float64
+
+	err := tx.Model(&models.Payment{}).
+		Where("order_id = ? AND status = ?", orderID, models.RefundedPayment).
+		Select("COALESCE(SUM(amount), 0)").Scan(&total).Error
+
+	return total, err
+}
+
+func (r *ReturnRepository) RestockVariant(tx *gorm.DB, variantID uint, quantity uint) error {
+	return tx.Model(&models.ProductVariant{}).
+		Where("id = ?", variantID).
+		Update("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (r *ReturnRepository) CreatePayment(tx *gorm.DB, payment *models.Payment) error {
+	return tx.Create(payment).Error
+}
+
+func (r *ReturnRepository) RecordInventoryMovement(tx *gorm.DB, movement *models.InventoryMovement) error {
+	return recordInventoryMovement(tx, movement)
+}
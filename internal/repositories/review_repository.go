@@ -3,14 +3,16 @@ package repositories
 import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"gorm.io/gorm"
 )
 
 type ReviewRepositoryInterface interface{
-	GetUserReviews(userIDUint uint) ([]models.Review, error)
+	GetUserReviews(userIDUint uint, params pagination.Params) (pagination.Page[models.Review], error)
 	Create(review *models.Review ) error 
 	HasPurchasedProduct(userID uint, productID uint) (bool, error)
-	GetReviewAllByProductId(productId uint) ([]dto.ReviewAllProduct,error)
+	GetReviewAllByProductId(productId uint, params pagination.Params) (pagination.Page[dto.ReviewAllProduct],error)
+	CountRatingsByProductId(productId uint) (map[int]int, error)
 	GetAverageRatingByProductId(productId uint) (float64, error)
 }
 
@@ -22,10 +24,20 @@ func NewReviewRepository(db *gorm.DB) *ReviewRepository{
 	return &ReviewRepository{db:db}
 }
 
-func (r *ReviewRepository) GetUserReviews(userIDUint uint) ([]models.Review, error) {
+func (r *ReviewRepository) GetUserReviews(userIDUint uint, params pagination.Params) (pagination.Page[models.Review], error) {
 	var reviews []models.Review
-	err := r.db.Where("user_id = ?", userIDUint).Find(&reviews).Error
-	return reviews, err
+
+	total, err := params.CountTotal(r.db.Model(&models.Review{}).Where("user_id = ?", userIDUint))
+	if err != nil {
+		return pagination.Page[models.Review]{}, err
+	}
+
+	err = params.Apply(r.db.Where("user_id = ?", userIDUint), "id", true).Find(&reviews).Error
+	if err != nil {
+		return pagination.Page[models.Review]{}, err
+	}
+
+	return pagination.NewPage(reviews, params, func(review models.Review) uint { return review.ID }, total), nil
 }
 
 func (r *ReviewRepository) Create(review *models.Review) error {
@@ -45,24 +57,49 @@ func (r *ReviewRepository)HasPurchasedProduct(userID uint, productID uint) (bool
 	return count > 0, err
 }
 
-func (r *ReviewRepository)GetReviewAllByProductId(productId uint) ([]dto.ReviewAllProduct,error){
+// NOTE - รีวิวใหม่ได้ id มากกว่าเสมอ เรียงด้วย reviews.id แทน created_at ได้ลำดับเดียวกันและใช้เป็น cursor ได้
+func (r *ReviewRepository)GetReviewAllByProductId(productId uint, params pagination.Params) (pagination.Page[dto.ReviewAllProduct],error){
 	var reviews []dto.ReviewAllProduct
 
-	err := r.db.Table("products").
-		Select(`users.first_name, users.last_name, reviews.product_id, reviews.rating, reviews.comment, users.avatar, reviews.created_at`).
+	query := r.db.Table("products").
+		Select(`reviews.id, users.first_name, users.last_name, reviews.product_id, reviews.rating, reviews.comment, users.avatar, reviews.created_at`).
 		Joins("JOIN reviews ON products.id = reviews.product_id").
 		Joins("JOIN users ON  reviews.user_id = users.id").
-		Where("products.id = ? ",productId).
-		Order("reviews.created_at DESC").
-		Scan(&reviews).Error
+		Where("products.id = ? ",productId)
 
+	err := params.Apply(query, "reviews.id", true).Scan(&reviews).Error
 	if err != nil {
-		return nil, err
+		return pagination.Page[dto.ReviewAllProduct]{}, err
 	}
-	return reviews,nil
+
+	// NOTE - total ของรีวิวสินค้าคำนวณจาก CountRatingsByProductId อยู่แล้ว ไม่ต้องนับซ้ำ
+	return pagination.NewPage(reviews, params, func(review dto.ReviewAllProduct) uint { return review.ID }, nil),nil
 	
 }
 
+func (r *ReviewRepository) CountRatingsByProductId(productId uint) (map[int]int, error) {
+	var rows []struct {
+		Rating int
+		Count  int
+	}
+
+	err := r.db.Model(&models.Review{}).
+		Select("rating, COUNT(*) as count").
+		Where("product_id = ?", productId).
+		Group("rating").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	countMap := make(map[int]int)
+	for _, row := range rows {
+		countMap[row.Rating] = row.Count
+	}
+
+	return countMap, nil
+}
+
 func (r *ReviewRepository) GetAverageRatingByProductId(productId uint) (float64, error) {
 	var avg float64
 	err := r.db.Model(&models.Review{}).
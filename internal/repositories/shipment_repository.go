@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"gorm.io/gorm"
+)
+
+type ShipmentRepositoryInterface interface {
+	Create(tx *gorm.DB, shipment *models.Shipment) error
+	Update(tx *gorm.DB, shipment *models.Shipment) error
+	FindByID(id uint) (*models.Shipment, error)
+	FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error)
+}
+
+type ShipmentRepository struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) *ShipmentRepository {
+	return &ShipmentRepository{db: db}
+}
+
+func (r *ShipmentRepository) Create(tx *gorm.DB, shipment *models.Shipment) error {
+	return tx.Omit("Order").Create(shipment).Error
+}
+
+func (r *ShipmentRepository) Update(tx *gorm.DB, shipment *models.Shipment) error {
+	return tx.Model(&models.Shipment{}).Where("id = ?", shipment.ID).Updates(map[string]interface{}{
+		"carrier":         shipment.Carrier,
+		"tracking_number": shipment.TrackingNumber,
+		"delivered_at":    shipment.DeliveredAt,
+	}).Error
+}
+
+func (r *ShipmentRepository) FindByID(id uint) (*models.Shipment, error) {
+	var shipment models.Shipment
+
+	err := r.db.Preload("Items").First(&shipment, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &shipment, nil
+}
+
+func (r *ShipmentRepository) FindByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error) {
+	var shipments []models.Shipment
+
+	total, err := params.CountTotal(r.db.Model(&models.Shipment{}).Where("order_id = ?", orderID))
+	if err != nil {
+		return pagination.Page[models.Shipment]{}, err
+	}
+
+	err = params.Apply(r.db.Preload("Items").Where("order_id = ?", orderID), "id", false).Find(&shipments).Error
+	if err != nil {
+		return pagination.Page[models.Shipment]{}, err
+	}
+
+	return pagination.NewPage(shipments, params, shipmentID, total), nil
+}
+
+func shipmentID(shipment models.Shipment) uint {
+	return shipment.ID
+}
@@ -0,0 +1,242 @@
+package search
+
+import (
+	"html"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// NOTE - น้ำหนักเท่ากับค่า default ของ ts_rank ใน Postgres (A=1.0, B=0.4, C=0.2, D=0.1)
+var memoryFieldWeights = []struct {
+	field  string
+	weight float64
+}{
+	{field: "name", weight: 1.0},
+	{field: "title", weight: 0.4},
+	{field: "category", weight: 0.2},
+	{field: "description", weight: 0.1},
+}
+
+const (
+	exactMatchScore  = 1.0
+	prefixMatchScore = 0.8
+	fuzzyMatchScore  = 0.5
+)
+
+// NOTE - index ใน memory สำหรับเทสและเครื่อง dev ไม่ต้องมี Postgres
+// ให้ผลแบบเดียวกับ Postgres คือทุกคำต้องเจอ ตรงแบบขึ้นต้นได้ และพิมพ์ผิดเล็กน้อยได้
+type MemorySearchIndex struct {
+	mu   sync.RWMutex
+	docs map[uint]Document
+}
+
+func NewMemorySearchIndex() *MemorySearchIndex {
+	return &MemorySearchIndex{docs: make(map[uint]Document)}
+}
+
+func (i *MemorySearchIndex) IndexProduct(doc Document) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.docs[doc.ProductID] = doc
+	return nil
+}
+
+func (i *MemorySearchIndex) RemoveProduct(productID uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	delete(i.docs, productID)
+	return nil
+}
+
+func (i *MemorySearchIndex) ReindexCategory(categoryID uint, categoryName string, movedFrom ...uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for productID, doc := range i.docs {
+		if doc.CategoryID != categoryID && !slices.Contains(movedFrom, doc.CategoryID) {
+			continue
+		}
+		doc.CategoryID = categoryID
+		doc.CategoryName = categoryName
+		i.docs[productID] = doc
+	}
+	return nil
+}
+
+func (i *MemorySearchIndex) Search(text string, limit int, offset int) ([]Hit, int64, error) {
+	terms := Tokenize(text)
+	if len(terms) == 0 {
+		return []Hit{}, 0, nil
+	}
+
+	i.mu.RLock()
+	hits := []Hit{}
+	for _, doc := range i.docs {
+		if hit, ok := scoreDocument(doc, terms); ok {
+			hits = append(hits, hit)
+		}
+	}
+	i.mu.RUnlock()
+
+	sort.Slice(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].ProductID > hits[b].ProductID
+	})
+
+	total := int64(len(hits))
+	if offset >= len(hits) {
+		return []Hit{}, total, nil
+	}
+
+	hits = hits[offset:]
+	if limit > 0 && limit < len(hits) {
+		hits = hits[:limit]
+	}
+
+	return hits, total, nil
+}
+
+func scoreDocument(doc Document, terms []string) (Hit, bool) {
+	fields := map[string]string{
+		"name":        doc.Name,
+		"title":       doc.Title,
+		"category":    doc.CategoryName,
+		"description": doc.Description,
+	}
+
+	fieldTokens := map[string][]string{}
+	for field, value := range fields {
+		fieldTokens[field] = Tokenize(value)
+	}
+
+	// NOTE - เก็บคำในเนื้อหาที่ตรงกับคำค้น ใช้ทำ highlight
+	matchedTokens := map[string]map[string]bool{}
+	score := 0.0
+
+	for _, term := range terms {
+		best := 0.0
+		for _, fw := range memoryFieldWeights {
+			for _, token := range fieldTokens[fw.field] {
+				match := matchToken(term, token)
+				if match == 0 {
+					continue
+				}
+
+				if matchedTokens[fw.field] == nil {
+					matchedTokens[fw.field] = map[string]bool{}
+				}
+				matchedTokens[fw.field][token] = true
+
+				if match*fw.weight > best {
+					best = match * fw.weight
+				}
+			}
+		}
+
+		if best == 0 {
+			return Hit{}, false
+		}
+		score += best
+	}
+
+	highlights := map[string]string{}
+	for _, field := range []string{"name", "title", "description"} {
+		if len(matchedTokens[field]) > 0 {
+			highlights[field] = highlightText(fields[field], matchedTokens[field])
+		}
+	}
+
+	return Hit{ProductID: doc.ProductID, Score: score, Highlights: highlights}, true
+}
+
+func matchToken(term string, token string) float64 {
+	if term == token {
+		return exactMatchScore
+	}
+
+	if strings.HasPrefix(token, term) {
+		return prefixMatchScore
+	}
+
+	if maxTypos := allowedTypos(term); maxTypos > 0 && editDistance(term, token) <= maxTypos {
+		return fuzzyMatchScore
+	}
+
+	return 0
+}
+
+// NOTE - คำสั้นต้องตรงเป๊ะ ไม่งั้นจะเจอทุกอย่าง
+func allowedTypos(term string) int {
+	length := len([]rune(term))
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func editDistance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+// NOTE - ครอบคำที่ตรงด้วย <mark> โดยคงตัวพิมพ์และเครื่องหมายของข้อความเดิมไว้ ส่วนอื่น escape HTML
+func highlightText(text string, tokens map[string]bool) string {
+	var builder strings.Builder
+	word := []rune{}
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if tokens[strings.ToLower(string(word))] {
+			builder.WriteString(highlightStart + string(word) + highlightStop)
+		} else {
+			builder.WriteString(string(word))
+		}
+		word = word[:0]
+	}
+
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word = append(word, r)
+			continue
+		}
+		flush()
+		// NOTE - คำมีแต่ตัวหนังสือกับตัวเลข อักขระ HTML อย่าง < > & " จึงมาทางนี้ทั้งหมด
+		builder.WriteString(html.EscapeString(string(r)))
+	}
+	flush()
+
+	return builder.String()
+}
@@ -0,0 +1,122 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndex() *search.MemorySearchIndex {
+	index := search.NewMemorySearchIndex()
+	index.IndexProduct(search.Document{ProductID: 1, Name: "Linen Shirt", Title: "Summer collection", Description: "Breathable linen for hot days", CategoryName: "Shirts"})
+	index.IndexProduct(search.Document{ProductID: 2, Name: "Denim Jacket", Title: "Classic linen-lined jacket", Description: "Heavy denim", CategoryName: "Jackets"})
+	index.IndexProduct(search.Document{ProductID: 3, Name: "Cotton Tee", Title: "Basic", Description: "Soft cotton", CategoryName: "Shirts"})
+	return index
+}
+
+func TestMemorySearchIndex(t *testing.T) {
+	t.Run("Rank name match above title match", func(t *testing.T) {
+		hits, total, err := newTestIndex().Search("linen", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, uint(1), hits[0].ProductID)
+		assert.Equal(t, uint(2), hits[1].ProductID)
+		assert.Greater(t, hits[0].Score, hits[1].Score)
+	})
+
+	t.Run("Match category name", func(t *testing.T) {
+		hits, total, err := newTestIndex().Search("shirts", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.ElementsMatch(t, []uint{1, 3}, []uint{hits[0].ProductID, hits[1].ProductID})
+	})
+
+	t.Run("Prefix and typo tolerance", func(t *testing.T) {
+		hits, _, err := newTestIndex().Search("lin shrt", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1)
+		assert.Equal(t, uint(1), hits[0].ProductID)
+	})
+
+	t.Run("Every term must match", func(t *testing.T) {
+		hits, total, err := newTestIndex().Search("linen cotton", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+		assert.Empty(t, hits)
+	})
+
+	t.Run("Highlight matched words", func(t *testing.T) {
+		hits, _, err := newTestIndex().Search("denim", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<mark>Denim</mark> Jacket", hits[0].Highlights["name"])
+		assert.Equal(t, "Heavy <mark>denim</mark>", hits[0].Highlights["description"])
+		assert.NotContains(t, hits[0].Highlights, "title")
+	})
+
+	t.Run("Highlight escapes HTML", func(t *testing.T) {
+		index := search.NewMemorySearchIndex()
+		index.IndexProduct(search.Document{ProductID: 1, Name: `Denim <img src=x onerror="alert(1)"> & co`})
+
+		hits, _, err := index.Search("denim", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<mark>Denim</mark> &lt;img src=x onerror=&#34;alert(1)&#34;&gt; &amp; co", hits[0].Highlights["name"])
+	})
+
+	t.Run("Paginate results", func(t *testing.T) {
+		hits, total, err := newTestIndex().Search("linen", 1, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, hits, 1)
+		assert.Equal(t, uint(2), hits[0].ProductID)
+	})
+
+	t.Run("Removed product is not found", func(t *testing.T) {
+		index := newTestIndex()
+		index.RemoveProduct(1)
+
+		hits, _, err := index.Search("linen", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1)
+		assert.Equal(t, uint(2), hits[0].ProductID)
+	})
+	t.Run("Reindex renamed category", func(t *testing.T) {
+		index := search.NewMemorySearchIndex()
+		index.IndexProduct(search.Document{ProductID: 1, CategoryID: 4, Name: "Linen Shirt", CategoryName: "Tops"})
+
+		index.ReindexCategory(4, "Summer")
+
+		hits, _, err := index.Search("summer", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1)
+
+		hits, _, err = index.Search("tops", 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, hits)
+	})
+
+	t.Run("Reindex products moved into category", func(t *testing.T) {
+		index := search.NewMemorySearchIndex()
+		index.IndexProduct(search.Document{ProductID: 1, CategoryID: 4, Name: "Linen Shirt", CategoryName: "Tops"})
+		index.IndexProduct(search.Document{ProductID: 2, CategoryID: 5, Name: "Denim Jacket", CategoryName: "Jackets"})
+
+		index.ReindexCategory(6, "Outerwear", 4)
+
+		hits, _, err := index.Search("outerwear", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1)
+		assert.Equal(t, uint(1), hits[0].ProductID)
+
+		hits, _, err = index.Search("jackets", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, hits, 1)
+	})
+}
@@ -0,0 +1,149 @@
+package search
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NOTE - ใช้ config simple ไม่ตัดรากศัพท์ ชื่อสินค้าภาษาไทยหรือชื่อแบรนด์จะไม่ถูกแปลงผิด
+const postgresSearchConfig = "simple"
+
+const postgresSearchVectorSQL = `setweight(to_tsvector('` + postgresSearchConfig + `', coalesce(products.name, '')), 'A') ||
+	setweight(to_tsvector('` + postgresSearchConfig + `', coalesce(products.title, '')), 'B') ||
+	setweight(to_tsvector('` + postgresSearchConfig + `', coalesce((SELECT categories.name FROM categories WHERE categories.id = products.category_id), '')), 'C') ||
+	setweight(to_tsvector('` + postgresSearchConfig + `', coalesce(products.description, '')), 'D')`
+
+// NOTE - ts_headline ไม่ escape ข้อความเดิม ให้ครอบด้วยตัวอักษร private use ก่อน แล้วค่อย escape และเปลี่ยนเป็น <mark> ใน Go
+const (
+	postgresHeadlineStart = "\uE000"
+	postgresHeadlineStop  = "\uE001"
+)
+
+const postgresHeadlineOptions = "StartSel=" + postgresHeadlineStart + ", StopSel=" + postgresHeadlineStop + ", HighlightAll=true"
+
+const postgresDescriptionHeadlineOptions = "StartSel=" + postgresHeadlineStart + ", StopSel=" + postgresHeadlineStop + ", MaxFragments=2, MaxWords=20, MinWords=5"
+
+// NOTE - เก็บ tsvector ไว้ในคอลัมน์ search_vector ของ products พร้อม GIN index
+// ส่วนคำที่พิมพ์ผิดใช้ trigram (pg_trgm) เทียบกับชื่อและ title
+type PostgresSearchIndex struct {
+	db *gorm.DB
+}
+
+func NewPostgresSearchIndex(db *gorm.DB) *PostgresSearchIndex {
+	return &PostgresSearchIndex{db: db}
+}
+
+// NOTE - เรียกตอนเปิด server หลัง AutoMigrate สร้างคอลัมน์ index และเติม vector ให้สินค้าที่ยังไม่เคยถูก index
+func (i *PostgresSearchIndex) Migrate() error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS pg_trgm",
+		"ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector",
+		"CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)",
+		"CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)",
+		"CREATE INDEX IF NOT EXISTS idx_products_title_trgm ON products USING GIN (title gin_trgm_ops)",
+	}
+
+	for _, statement := range statements {
+		if err := i.db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return i.db.Exec("UPDATE products SET search_vector = " + postgresSearchVectorSQL + " WHERE search_vector IS NULL").Error
+}
+
+// NOTE - คำนวณ vector จากข้อมูลใน database ไม่ใช้ doc ที่ส่งมา ให้ชื่อ category ตรงกับของล่าสุดเสมอ
+func (i *PostgresSearchIndex) IndexProduct(doc Document) error {
+	return i.db.Exec("UPDATE products SET search_vector = "+postgresSearchVectorSQL+" WHERE id = ?", doc.ProductID).Error
+}
+
+// NOTE - vector อ่านชื่อ category จาก database อยู่แล้ว สินค้าที่ย้ายมาก็อยู่ใน categoryID แล้ว จึงไม่ต้องใช้ movedFrom
+func (i *PostgresSearchIndex) ReindexCategory(categoryID uint, categoryName string, movedFrom ...uint) error {
+	return i.db.Exec("UPDATE products SET search_vector = "+postgresSearchVectorSQL+" WHERE category_id = ? AND deleted_at IS NULL", categoryID).Error
+}
+
+// NOTE - สินค้าที่ลบเป็น soft delete ค้นหากรอง deleted_at อยู่แล้ว ล้าง vector ทิ้งไม่ให้เปลือง index
+func (i *PostgresSearchIndex) RemoveProduct(productID uint) error {
+	return i.db.Exec("UPDATE products SET search_vector = NULL WHERE id = ?", productID).Error
+}
+
+type postgresSearchRow struct {
+	ProductID            uint
+	Score                float64
+	NameHighlight        string
+	TitleHighlight       string
+	DescriptionHighlight string
+}
+
+func (i *PostgresSearchIndex) Search(text string, limit int, offset int) ([]Hit, int64, error) {
+	tsQuery := postgresPrefixQuery(text)
+	if tsQuery == "" {
+		return []Hit{}, 0, nil
+	}
+	phrase := strings.Join(Tokenize(text), " ")
+
+	// NOTE - ตรงด้วย full-text หรือใกล้เคียงด้วย trigram อย่างใดอย่างหนึ่งก็นับว่าเจอ
+	matchSQL := `products.deleted_at IS NULL AND (
+		products.search_vector @@ to_tsquery('` + postgresSearchConfig + `', @query)
+		OR @phrase <% products.name
+		OR @phrase <% products.title)`
+	args := map[string]interface{}{
+		"query":  tsQuery,
+		"phrase": phrase,
+	}
+
+	var total int64
+	if err := i.db.Table("products").Where(matchSQL, args).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []postgresSearchRow
+	err := i.db.Table("products").
+		Select(`products.id AS product_id,
+			ts_rank_cd(coalesce(products.search_vector, ''::tsvector), to_tsquery('`+postgresSearchConfig+`', @query))
+				+ word_similarity(@phrase, products.name) AS score,
+			ts_headline('`+postgresSearchConfig+`', products.name, to_tsquery('`+postgresSearchConfig+`', @query), '`+postgresHeadlineOptions+`') AS name_highlight,
+			ts_headline('`+postgresSearchConfig+`', products.title, to_tsquery('`+postgresSearchConfig+`', @query), '`+postgresHeadlineOptions+`') AS title_highlight,
+			ts_headline('`+postgresSearchConfig+`', products.description, to_tsquery('`+postgresSearchConfig+`', @query), '`+postgresDescriptionHeadlineOptions+`') AS description_highlight`, args).
+		Where(matchSQL, args).
+		Order("score DESC, products.id DESC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]Hit, 0, len(rows))
+	for _, row := range rows {
+		highlights := map[string]string{}
+		addHighlight(highlights, "name", row.NameHighlight)
+		addHighlight(highlights, "title", row.TitleHighlight)
+		addHighlight(highlights, "description", row.DescriptionHighlight)
+
+		hits = append(hits, Hit{
+			ProductID:  row.ProductID,
+			Score:      row.Score,
+			Highlights: highlights,
+		})
+	}
+
+	return hits, total, nil
+}
+
+// NOTE - ทุกคำต้องเจอ และให้ตรงแบบขึ้นต้นได้ เช่น "lin shi" เจอ "linen shirt"
+// คำผ่าน Tokenize แล้วเหลือแต่ตัวหนังสือกับตัวเลข จึงไม่มีเครื่องหมายของ tsquery หลุดเข้าไป
+func postgresPrefixQuery(text string) string {
+	terms := Tokenize(text)
+	for idx, term := range terms {
+		terms[idx] = term + ":*"
+	}
+	return strings.Join(terms, " & ")
+}
+
+// NOTE - ts_headline คืนข้อความเดิมมาแม้ไม่เจอคำค้น เก็บเฉพาะ field ที่มีการ highlight จริง
+func addHighlight(highlights map[string]string, field string, value string) {
+	if strings.Contains(value, postgresHeadlineStart) {
+		highlights[field] = escapeHighlight(value, postgresHeadlineStart, postgresHeadlineStop)
+	}
+}
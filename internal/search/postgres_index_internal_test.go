@@ -0,0 +1,25 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPostgresHighlight(t *testing.T) {
+	t.Run("Escape HTML before adding marks", func(t *testing.T) {
+		highlights := map[string]string{}
+
+		addHighlight(highlights, "name", postgresHeadlineStart+"Denim"+postgresHeadlineStop+` <script>alert("x")</script>`)
+
+		assert.Equal(t, `<mark>Denim</mark> &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;`, highlights["name"])
+	})
+
+	t.Run("Skip field without match", func(t *testing.T) {
+		highlights := map[string]string{}
+
+		addHighlight(highlights, "title", "<b>Classic</b> jacket")
+
+		assert.NotContains(t, highlights, "title")
+	})
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+)
+
+// NOTE - แยกการค้นหาสินค้าออกจาก repository เพื่อเปลี่ยน backend ได้ production ใช้ Postgres เทสใช้ in-memory
+type SearchIndex interface {
+	IndexProduct(doc Document) error
+	RemoveProduct(productID uint) error
+	// NOTE - ชื่อ category อยู่ใน index ด้วย เรียกหลังเปลี่ยนชื่อ category หรือย้ายสินค้าจาก movedFrom มาไว้ใน categoryID
+	ReindexCategory(categoryID uint, categoryName string, movedFrom ...uint) error
+	Search(text string, limit int, offset int) ([]Hit, int64, error)
+}
+
+// NOTE - ข้อมูลสินค้าที่ใช้ค้นหา น้ำหนัก Name > Title > CategoryName > Description
+type Document struct {
+	ProductID    uint
+	CategoryID   uint
+	Name         string
+	Title        string
+	Description  string
+	CategoryName string
+}
+
+// NOTE - Highlights เก็บเฉพาะ field ที่เจอคำค้น คำที่ตรงครอบด้วย <mark></mark>
+// ข้อความที่เหลือ escape HTML แล้ว หน้าเว็บเอาไปแสดงเป็น HTML ได้เลย
+type Hit struct {
+	ProductID  uint
+	Score      float64
+	Highlights map[string]string
+}
+
+const (
+	highlightStart = "<mark>"
+	highlightStop  = "</mark>"
+)
+
+func DocumentFromProduct(product *models.Product) Document {
+	return Document{
+		ProductID:    product.ID,
+		CategoryID:   product.CategoryID,
+		Name:         product.Name,
+		Title:        product.Title,
+		Description:  product.Description,
+		CategoryName: product.Category.Name,
+	}
+}
+
+// NOTE - ตัดคำด้วยตัวอักษรที่ไม่ใช่ตัวหนังสือหรือตัวเลข ใช้ทั้งคำค้นและเนื้อหาให้ตัดเหมือนกัน
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NOTE - escape ข้อความเดิมก่อนแล้วค่อยเปลี่ยนตัวคั่นเป็น <mark> กัน HTML ในชื่อหรือรายละเอียดสินค้าหลุดไปถึงหน้าเว็บ
+func escapeHighlight(text string, startSel string, stopSel string) string {
+	escaped := html.EscapeString(text)
+	escaped = strings.ReplaceAll(escaped, startSel, highlightStart)
+	return strings.ReplaceAll(escaped, stopSel, highlightStop)
+}
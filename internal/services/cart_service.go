@@ -0,0 +1,229 @@
+package services
+
+import (
+	"errors"
+	"log"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+)
+
+type CartServiceInterface interface {
+	GetCart(userID uint) (*dto.CartResponseDTO, error)
+	AddItem(userID uint, req dto.AddCartItemDTO) error
+	UpdateQuantity(userID uint, itemID uint, quantity uint) error
+	RemoveItem(userID uint, itemID uint) error
+	ClearCart(userID uint) error
+	Checkout(userID uint, req dto.CartCheckoutDTO) (*models.Order, error)
+}
+
+type CartService struct {
+	cartRepo     repositories.CartRepositoryInterface
+	orderService OrderServiceInterface
+}
+
+func NewCartService(cartRepo repositories.CartRepositoryInterface, orderService OrderServiceInterface) *CartService {
+	return &CartService{
+		cartRepo:     cartRepo,
+		orderService: orderService,
+	}
+}
+
+func (s *CartService) GetCart(userID uint) (*dto.CartResponseDTO, error) {
+	items, err := s.cartRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("Error to get cart")
+	}
+
+	cart := &dto.CartResponseDTO{
+		Items: []dto.CartItemResponseDTO{},
+	}
+
+	for _, item := range items {
+		finalPrice := FinalUnitPrice(item.ProductVariant.Price, item.ProductVariant.Product)
+		lineTotal := finalPrice * float64(item.Quantity)
+
+		cart.Items = append(cart.Items, dto.CartItemResponseDTO{
+			ID:          item.ID,
+			VariantID:   item.ProductVariantID,
+			ProductID:   item.ProductVariant.ProductID,
+			ProductName: item.ProductVariant.Product.Name,
+			Size:        item.ProductVariant.Size,
+			SKU:         item.ProductVariant.SKU,
+			Stock:       item.ProductVariant.Stock,
+			Quantity:    item.Quantity,
+			Price:       item.ProductVariant.Price,
+			FinalPrice:  finalPrice,
+			LineTotal:   lineTotal,
+		})
+
+		cart.TotalItems += item.Quantity
+		cart.TotalPrice += lineTotal
+	}
+
+	return cart, nil
+}
+
+func (s *CartService) AddItem(userID uint, req dto.AddCartItemDTO) error {
+	if req.Quantity == 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+
+	// NOTE - เช็คว่ามี variant นี้อยู่จริงไหม
+	variant, err := s.cartRepo.FindProductVariantByID(req.VariantID)
+	if err != nil {
+		return errors.New("Error finding product variant")
+	}
+
+	if variant == nil {
+		return errors.New("productVariant not found")
+	}
+
+	// NOTE - ถ้ามีของใน cart อยู่แล้วให้บวกจำนวนเพิ่ม
+	existingItem, err := s.cartRepo.FindByUserAndVariant(userID, req.VariantID)
+	if err != nil {
+		return errors.New("Error finding cart item")
+	}
+
+	if existingItem != nil {
+		quantity := existingItem.Quantity + req.Quantity
+		if variant.Stock < int(quantity) {
+			return errors.New("stock not enough")
+		}
+
+		existingItem.Quantity = quantity
+		if err := s.cartRepo.Update(existingItem); err != nil {
+			return errors.New("Error updating cart item")
+		}
+		return nil
+	}
+
+	if variant.Stock < int(req.Quantity) {
+		return errors.New("stock not enough")
+	}
+
+	item := &models.CartItem{
+		UserID:           userID,
+		ProductVariantID: req.VariantID,
+		Quantity:         req.Quantity,
+	}
+
+	if err := s.cartRepo.Create(item); err != nil {
+		return errors.New("Error adding item to cart")
+	}
+
+	return nil
+}
+
+func (s *CartService) UpdateQuantity(userID uint, itemID uint, quantity uint) error {
+	if quantity == 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+
+	item, err := s.findOwnedItem(userID, itemID)
+	if err != nil {
+		return err
+	}
+
+	variant, err := s.cartRepo.FindProductVariantByID(item.ProductVariantID)
+	if err != nil {
+		return errors.New("Error finding product variant")
+	}
+
+	if variant == nil {
+		return errors.New("productVariant not found")
+	}
+
+	if variant.Stock < int(quantity) {
+		return errors.New("stock not enough")
+	}
+
+	item.Quantity = quantity
+	if err := s.cartRepo.Update(item); err != nil {
+		return errors.New("Error updating cart item")
+	}
+
+	return nil
+}
+
+func (s *CartService) RemoveItem(userID uint, itemID uint) error {
+	item, err := s.findOwnedItem(userID, itemID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.cartRepo.Delete(item.ID); err != nil {
+		return errors.New("Error removing cart item")
+	}
+
+	return nil
+}
+
+func (s *CartService) ClearCart(userID uint) error {
+	if err := s.cartRepo.ClearByUserID(userID); err != nil {
+		return errors.New("Error clearing cart")
+	}
+
+	return nil
+}
+
+func (s *CartService) Checkout(userID uint, req dto.CartCheckoutDTO) (*models.Order, error) {
+	items, err := s.cartRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, errors.New("Error to get cart")
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("cart is empty")
+	}
+
+	// NOTE - แปลง cart เป็น order request แล้วส่งต่อให้ OrderService
+	orderReq := dto.CreateOrderRequestDTO{
+		CouponCode:  req.CouponCode,
+		FullName:    req.FullName,
+		Phone:       req.Phone,
+		Address:     req.Address,
+		Province:    req.Province,
+		District:    req.District,
+		Subdistrict: req.Subdistrict,
+		Zipcode:     req.Zipcode,
+	}
+
+	for _, item := range items {
+		orderReq.Items = append(orderReq.Items, dto.CreateOrderItemDTO{
+			VariantID: item.ProductVariantID,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	order, err := s.orderService.CreateOrder(userID, orderReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE - order สร้างแล้ว ถ้าล้าง cart ไม่ได้ก็ไม่ต้อง fail ทั้ง request
+	if err := s.cartRepo.ClearByUserID(userID); err != nil {
+		log.Printf("Failed to clear cart of user %d after checkout: %v", userID, err)
+	}
+
+	return order, nil
+}
+
+func (s *CartService) findOwnedItem(userID uint, itemID uint) (*models.CartItem, error) {
+	item, err := s.cartRepo.FindByID(itemID)
+	if err != nil {
+		return nil, errors.New("Error finding cart item")
+	}
+
+	if item == nil {
+		return nil, errors.New("cart item not found")
+	}
+
+	// NOTE - เช็คว่า userID ตรงกับ item.UserID ไหม
+	if item.UserID != userID {
+		return nil, errors.New("unauthorized to update this cart item")
+	}
+
+	return item, nil
+}
@@ -0,0 +1,294 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	servicesMock "github.com/Beluga-Whale/ecommerce-api/internal/services/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestGetCart(t *testing.T) {
+	t.Run("GetCart Success", func(t *testing.T) {
+		salePrice := 10.0
+		cartItems := []models.CartItem{
+			{
+				Model:            gorm.Model{ID: 1},
+				UserID:           1,
+				ProductVariantID: 1,
+				Quantity:         2,
+				ProductVariant: models.ProductVariant{
+					Model:     gorm.Model{ID: 1},
+					ProductID: 1,
+					Size:      "M",
+					Stock:     10,
+					Price:     100,
+					Product:   models.Product{Name: "T-Shirt", IsOnSale: true, SalePrice: &salePrice},
+				},
+			},
+			{
+				Model:            gorm.Model{ID: 2},
+				UserID:           1,
+				ProductVariantID: 2,
+				Quantity:         1,
+				ProductVariant: models.ProductVariant{
+					Model:     gorm.Model{ID: 2},
+					ProductID: 2,
+					Size:      "L",
+					Stock:     5,
+					Price:     50,
+					Product:   models.Product{Name: "Cap"},
+				},
+			},
+		}
+
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByUserID", uint(1)).Return(cartItems, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		cart, err := cartService.GetCart(1)
+
+		assert.NoError(t, err)
+		assert.Len(t, cart.Items, 2)
+		assert.Equal(t, 90.0, cart.Items[0].FinalPrice)
+		assert.Equal(t, 180.0, cart.Items[0].LineTotal)
+		assert.Equal(t, 50.0, cart.Items[1].FinalPrice)
+		assert.Equal(t, uint(3), cart.TotalItems)
+		assert.Equal(t, 230.0, cart.TotalPrice)
+
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error to get cart", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByUserID", uint(1)).Return(nil, errors.New("db error"))
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		cart, err := cartService.GetCart(1)
+
+		assert.EqualError(t, err, "Error to get cart")
+		assert.Nil(t, cart)
+
+		cartRepo.AssertExpectations(t)
+	})
+}
+
+func TestAddItem(t *testing.T) {
+	variantMock := &models.ProductVariant{
+		Model: gorm.Model{ID: 1},
+		Stock: 5,
+		Price: 100,
+	}
+
+	t.Run("Add new item success", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindProductVariantByID", uint(1)).Return(variantMock, nil)
+		cartRepo.On("FindByUserAndVariant", uint(1), uint(1)).Return(nil, nil)
+		cartRepo.On("Create", mock.AnythingOfType("*models.CartItem")).Return(nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.AddItem(1, dto.AddCartItemDTO{VariantID: 1, Quantity: 2})
+
+		assert.NoError(t, err)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Add existing item increase quantity", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		existingItem := &models.CartItem{Model: gorm.Model{ID: 3}, UserID: 1, ProductVariantID: 1, Quantity: 2}
+
+		cartRepo.On("FindProductVariantByID", uint(1)).Return(variantMock, nil)
+		cartRepo.On("FindByUserAndVariant", uint(1), uint(1)).Return(existingItem, nil)
+		cartRepo.On("Update", existingItem).Return(nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.AddItem(1, dto.AddCartItemDTO{VariantID: 1, Quantity: 3})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(5), existingItem.Quantity)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stock not enough", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindProductVariantByID", uint(1)).Return(variantMock, nil)
+		cartRepo.On("FindByUserAndVariant", uint(1), uint(1)).Return(nil, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.AddItem(1, dto.AddCartItemDTO{VariantID: 1, Quantity: 6})
+
+		assert.EqualError(t, err, "stock not enough")
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Variant not found", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindProductVariantByID", uint(9)).Return(nil, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.AddItem(1, dto.AddCartItemDTO{VariantID: 9, Quantity: 1})
+
+		assert.EqualError(t, err, "productVariant not found")
+		cartRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateQuantity(t *testing.T) {
+	t.Run("Update quantity success", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		item := &models.CartItem{Model: gorm.Model{ID: 1}, UserID: 1, ProductVariantID: 2, Quantity: 1}
+
+		cartRepo.On("FindByID", uint(1)).Return(item, nil)
+		cartRepo.On("FindProductVariantByID", uint(2)).Return(&models.ProductVariant{Stock: 10}, nil)
+		cartRepo.On("Update", item).Return(nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.UpdateQuantity(1, 1, 4)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), item.Quantity)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Item of other user", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByID", uint(1)).Return(&models.CartItem{Model: gorm.Model{ID: 1}, UserID: 2}, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.UpdateQuantity(1, 1, 4)
+
+		assert.EqualError(t, err, "unauthorized to update this cart item")
+		cartRepo.AssertExpectations(t)
+	})
+}
+
+func TestRemoveItem(t *testing.T) {
+	t.Run("Remove item success", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByID", uint(1)).Return(&models.CartItem{Model: gorm.Model{ID: 1}, UserID: 1}, nil)
+		cartRepo.On("Delete", uint(1)).Return(nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.RemoveItem(1, 1)
+
+		assert.NoError(t, err)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cart item not found", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByID", uint(1)).Return(nil, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		err := cartService.RemoveItem(1, 1)
+
+		assert.EqualError(t, err, "cart item not found")
+		cartRepo.AssertExpectations(t)
+	})
+}
+
+func TestCheckout(t *testing.T) {
+	req := dto.CartCheckoutDTO{
+		FullName:    "John Doe",
+		Phone:       "0988888888",
+		Address:     "123 Main St",
+		Province:    "Bangkok",
+		District:    "District",
+		Subdistrict: "Subdistrict",
+		Zipcode:     "10200",
+	}
+
+	t.Run("Checkout success", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartItems := []models.CartItem{
+			{Model: gorm.Model{ID: 1}, UserID: 1, ProductVariantID: 3, Quantity: 2},
+			{Model: gorm.Model{ID: 2}, UserID: 1, ProductVariantID: 4, Quantity: 1},
+		}
+		orderMock := &models.Order{Model: gorm.Model{ID: 10}, UserID: 1}
+
+		cartRepo.On("FindByUserID", uint(1)).Return(cartItems, nil)
+		orderService.On("CreateOrder", uint(1), mock.MatchedBy(func(r dto.CreateOrderRequestDTO) bool {
+			return len(r.Items) == 2 && r.Items[0].VariantID == 3 && r.Items[0].Quantity == 2 && r.FullName == "John Doe"
+		})).Return(orderMock, nil)
+		cartRepo.On("ClearByUserID", uint(1)).Return(nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		order, err := cartService.Checkout(1, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(10), order.ID)
+		cartRepo.AssertExpectations(t)
+		orderService.AssertExpectations(t)
+	})
+
+	t.Run("Cart is empty", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByUserID", uint(1)).Return([]models.CartItem{}, nil)
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		order, err := cartService.Checkout(1, req)
+
+		assert.EqualError(t, err, "cart is empty")
+		assert.Nil(t, order)
+		cartRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create order failed keep cart", func(t *testing.T) {
+		cartRepo := repositories.NewCartRepositoryMock()
+		orderService := servicesMock.NewOrderServiceMock()
+
+		cartRepo.On("FindByUserID", uint(1)).Return([]models.CartItem{{ProductVariantID: 3, Quantity: 2}}, nil)
+		orderService.On("CreateOrder", uint(1), mock.Anything).Return(nil, errors.New("stock not enough"))
+
+		cartService := services.NewCartService(cartRepo, orderService)
+
+		order, err := cartService.Checkout(1, req)
+
+		assert.EqualError(t, err, "stock not enough")
+		assert.Nil(t, order)
+		cartRepo.AssertNotCalled(t, "ClearByUserID", uint(1))
+		orderService.AssertExpectations(t)
+	})
+}
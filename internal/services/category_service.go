@@ -2,25 +2,31 @@ package services
 
 import (
 	"errors"
+	"log"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/gosimple/slug"
 )
 
 type CategoryServiceInterface interface {
 	CreateCategory(category *models.Category) error
 	UpdateCategory(id uint, category *models.Category) error
-	DeleteCategory(id uint) error
+	DeleteCategory(id uint, targetID *uint) error
+	MergeCategories(sourceID uint, targetID uint) error
 	GetAllCategories() ([]models.Category, error)
+	GetCategoryTree() ([]dto.CategoryTreeDTO, error)
 }
 
 type CategoryService struct {
 	categoryRepo repositories.CategoryInterface
+	searchIndex  search.SearchIndex
 }
 
-func NewCategoryService(categoryRepo repositories.CategoryInterface) *CategoryService{
-	return &CategoryService{categoryRepo: categoryRepo}
+func NewCategoryService(categoryRepo repositories.CategoryInterface, searchIndex search.SearchIndex) *CategoryService{
+	return &CategoryService{categoryRepo: categoryRepo, searchIndex: searchIndex}
 }
 
 func (s *CategoryService) CreateCategory(category *models.Category) error {
@@ -41,6 +47,18 @@ func (s *CategoryService) CreateCategory(category *models.Category) error {
 		return errors.New("Category already exists")
 	}
 
+	// NOTE - เช็คว่า parent category มีอยู่ในระบบไหม
+	if category.ParentID != nil {
+		parent, err := s.categoryRepo.FindByID(*category.ParentID)
+		if err != nil {
+			return errors.New("Error finding parent category")
+		}
+
+		if parent == nil {
+			return errors.New("Parent category not found")
+		}
+	}
+
 	// NOTE - สร้าง slug
 	category.Slug = slug.Make(category.Name)
 
@@ -70,9 +88,19 @@ func (s *CategoryService) UpdateCategory(id uint, category *models.Category) err
 		return errors.New("Category not found")
 	}
 
+	// NOTE - เช็คว่าย้ายไปอยู่ใต้ parent ใหม่แล้วไม่เกิดวน
+	if category.ParentID != nil {
+		if err := s.validateParent(id, *category.ParentID); err != nil {
+			return err
+		}
+	}
+
+	renamed := existingCategory.Name != category.Name
+
 	// NOTE - Update Fields ของ category เป็นค่าใหม่
 	existingCategory.Slug = slug.Make(category.Name)
 	existingCategory.Name = category.Name
+	existingCategory.ParentID = category.ParentID
 
 	err = s.categoryRepo.Update(existingCategory)
 
@@ -80,10 +108,15 @@ func (s *CategoryService) UpdateCategory(id uint, category *models.Category) err
 		return errors.New("Error updating category")
 	}
 
+	if renamed {
+		s.reindexCategory(existingCategory)
+	}
+
 	return nil
 }
 
-func (s *CategoryService) DeleteCategory(id uint) error {
+// NOTE - ถ้ายังมีสินค้าอยู่ใน category ต้องส่ง targetID มาเพื่อย้ายสินค้าไปก่อน ไม่งั้นสินค้าจะชี้ไป category ที่ถูกลบ
+func (s *CategoryService) DeleteCategory(id uint, targetID *uint) error {
 	// NOTE - เช็คว่า category มีอยู่ในระบบไหม
 	existingCategory, err := s.categoryRepo.FindByID(id)
 	if err != nil {
@@ -94,14 +127,77 @@ func (s *CategoryService) DeleteCategory(id uint) error {
 		return errors.New("Category not found")
 	}
 
-	err = s.categoryRepo.Delete(id)
+	if targetID != nil {
+		target, err := s.validateTarget(id, *targetID)
+		if err != nil {
+			return err
+		}
+
+		err = s.categoryRepo.ReassignProductsAndDelete(id, *targetID)
+		if err != nil {
+			return errors.New("Error deleting category")
+		}
+
+		s.reindexCategory(target, id)
+		return nil
+	}
+
+	// NOTE - ไม่ได้ส่ง target มา ลบได้เฉพาะ category ที่ไม่มีสินค้า repository นับและลบใน transaction เดียวกัน
+	deleted, err := s.categoryRepo.DeleteIfEmpty(id)
 	if err != nil {
 		return errors.New("Error deleting category")
 	}
 
+	if !deleted {
+		return errors.New("Category still has products, choose a target category to move them to")
+	}
+
+	return nil
+}
+
+// NOTE - รวม category ซ้ำเข้าด้วยกัน target ต้องไม่อยู่ใต้ source ไม่งั้น category ลูกของ source ที่ย้ายไปจะวนกลับมาหาตัวเอง
+func (s *CategoryService) MergeCategories(sourceID uint, targetID uint) error {
+	existingCategory, err := s.categoryRepo.FindByID(sourceID)
+	if err != nil {
+		return errors.New("Error finding category")
+	}
+
+	if existingCategory == nil {
+		return errors.New("Category not found")
+	}
+
+	target, err := s.validateTarget(sourceID, targetID)
+	if err != nil {
+		return err
+	}
+
+	ancestors, err := s.categoryRepo.FindAncestors(targetID)
+	if err != nil {
+		return errors.New("Error finding target category")
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID == sourceID {
+			return errors.New("Cannot merge a category into its own subcategory")
+		}
+	}
+
+	err = s.categoryRepo.Merge(sourceID, targetID)
+	if err != nil {
+		return errors.New("Error merging categories")
+	}
+
+	s.reindexCategory(target, sourceID)
 	return nil
 }
 
+// NOTE - index พังไม่ทำให้แก้ category ล้มเหลว เหมือนตอน index สินค้า ชื่อ category ในผลค้นหาจะตามมาเมื่อแก้สินค้าครั้งถัดไป
+func (s *CategoryService) reindexCategory(category *models.Category, movedFrom ...uint) {
+	if err := s.searchIndex.ReindexCategory(category.ID, category.Name, movedFrom...); err != nil {
+		log.Printf("Error reindexing products of category %d for search: %v", category.ID, err)
+	}
+}
+
 func (s *CategoryService) GetAllCategories() ([]models.Category, error) {
 	categories, err := s.categoryRepo.FindAll()
 	if err != nil {
@@ -109,4 +205,86 @@ func (s *CategoryService) GetAllCategories() ([]models.Category, error) {
 	}
 
 	return categories, nil
-}
\ No newline at end of file
+}
+
+// NOTE - parent ต้องไม่ใช่ตัวเองและไม่ใช่ category ลูกหลานของตัวเอง
+// ไล่ ancestors ของ parent ใหม่ขึ้นไป ถ้าเจอ id ตัวเองแปลว่าย้ายไปอยู่ใต้ลูกของตัวเอง
+func (s *CategoryService) validateParent(id uint, parentID uint) error {
+	if parentID == id {
+		return errors.New("Category cannot be its own parent")
+	}
+
+	ancestors, err := s.categoryRepo.FindAncestors(parentID)
+	if err != nil {
+		return errors.New("Error finding parent category")
+	}
+
+	if len(ancestors) == 0 {
+		return errors.New("Parent category not found")
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID == id {
+			return errors.New("Category cannot be moved under its own subcategory")
+		}
+	}
+
+	return nil
+}
+
+// NOTE - category ปลายทางที่จะรับสินค้าไปต้องมีอยู่และไม่ใช่ตัวที่กำลังจะถูกลบ
+func (s *CategoryService) validateTarget(id uint, targetID uint) (*models.Category, error) {
+	if targetID == id {
+		return nil, errors.New("Target category must be different from the category being removed")
+	}
+
+	target, err := s.categoryRepo.FindByID(targetID)
+	if err != nil {
+		return nil, errors.New("Error finding target category")
+	}
+
+	if target == nil {
+		return nil, errors.New("Target category not found")
+	}
+
+	return target, nil
+}
+
+// NOTE - ดึง category ทั้งหมดครั้งเดียวแล้วต่อเป็น tree category ที่ parent ถูกลบไปแล้วจะขึ้นเป็นระดับบนสุด
+func (s *CategoryService) GetCategoryTree() ([]dto.CategoryTreeDTO, error) {
+	categories, err := s.categoryRepo.FindAll()
+	if err != nil {
+		return nil, errors.New("Error retrieving categories")
+	}
+
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+
+	var roots []models.Category
+	children := make(map[uint][]models.Category)
+	for _, category := range categories {
+		if category.ParentID != nil && exists[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+			continue
+		}
+		roots = append(roots, category)
+	}
+
+	return buildCategoryTree(roots, children), nil
+}
+
+func buildCategoryTree(categories []models.Category, children map[uint][]models.Category) []dto.CategoryTreeDTO {
+	nodes := make([]dto.CategoryTreeDTO, 0, len(categories))
+	for _, category := range categories {
+		nodes = append(nodes, dto.CategoryTreeDTO{
+			ID:       category.ID,
+			Name:     category.Name,
+			Slug:     category.Slug,
+			Children: buildCategoryTree(children[category.ID], children),
+		})
+	}
+
+	return nodes
+}
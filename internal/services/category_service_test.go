@@ -6,8 +6,10 @@ import (
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 )
 
@@ -23,7 +25,7 @@ func TestCreateCategory(t *testing.T) {
 		categoryRepo.On("FindByName",reqMock.Name).Return(nil,nil)
 		categoryRepo.On("Create",reqMock).Return(nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.CreateCategory(reqMock)
 
@@ -41,7 +43,7 @@ func TestCreateCategory(t *testing.T) {
 
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.CreateCategory(reqMock)
 
@@ -61,7 +63,7 @@ func TestCreateCategory(t *testing.T) {
 
 		categoryRepo.On("FindByName",reqMock.Name).Return(nil,errors.New("Error checking for existing category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.CreateCategory(reqMock)
 
@@ -81,7 +83,7 @@ func TestCreateCategory(t *testing.T) {
 
 		categoryRepo.On("FindByName",reqMock.Name).Return(reqMock,nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.CreateCategory(reqMock)
 
@@ -102,7 +104,7 @@ func TestCreateCategory(t *testing.T) {
 		categoryRepo.On("FindByName",reqMock.Name).Return(nil,nil)
 		categoryRepo.On("Create",reqMock).Return(errors.New("Error creating category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.CreateCategory(reqMock)
 
@@ -111,6 +113,27 @@ func TestCreateCategory(t *testing.T) {
 		categoryRepo.AssertExpectations(t)
 
 	})
+	t.Run("Parent Category Not Found",func(t *testing.T) {
+		parentID := uint(9)
+		reqMock := &models.Category{
+			Name: "TEST A",
+			ParentID: &parentID,
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByName",reqMock.Name).Return(nil,nil)
+		categoryRepo.On("FindByID",parentID).Return(nil,nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.CreateCategory(reqMock)
+
+		assert.EqualError(t,err,"Parent category not found")
+
+		categoryRepo.AssertNotCalled(t,"Create",reqMock)
+		categoryRepo.AssertExpectations(t)
+	})
 }
 
 func TestUpdateCategory(t *testing.T) {
@@ -126,7 +149,7 @@ func TestUpdateCategory(t *testing.T) {
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(reqMock,nil)
 		categoryRepo.On("Update",reqMock).Return(nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.UpdateCategory(id,reqMock)
 
@@ -136,6 +159,28 @@ func TestUpdateCategory(t *testing.T) {
 
 	})
 
+	t.Run("Rename Category Reindexes Products",func(t *testing.T) {
+		searchIndex := search.NewMemorySearchIndex()
+		searchIndex.IndexProduct(search.Document{ProductID: 7, CategoryID: 1, Name: "Linen Shirt", CategoryName: "Tops"})
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}, Name: "Tops"},nil)
+		categoryRepo.On("Update",mock.AnythingOfType("*models.Category")).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, searchIndex)
+
+		err := categoryService.UpdateCategory(1,&models.Category{Name: "Summer Wear"})
+
+		assert.NoError(t,err)
+
+		hits, _, _ := searchIndex.Search("summer", 10, 0)
+		assert.Len(t,hits,1)
+
+		hits, _, _ = searchIndex.Search("tops", 10, 0)
+		assert.Empty(t,hits)
+	})
+
 	t.Run("Category Name is Empty",func(t *testing.T) {
 		id := uint(1)
 		reqMock := &models.Category{
@@ -146,7 +191,7 @@ func TestUpdateCategory(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.UpdateCategory(id,reqMock)
 
@@ -167,7 +212,7 @@ func TestUpdateCategory(t *testing.T) {
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(nil,errors.New("Error finding category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.UpdateCategory(id,reqMock)
 
@@ -188,7 +233,7 @@ func TestUpdateCategory(t *testing.T) {
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(nil,nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.UpdateCategory(id,reqMock)
 
@@ -210,7 +255,7 @@ func TestUpdateCategory(t *testing.T) {
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(reqMock,nil)
 		categoryRepo.On("Update",reqMock).Return(errors.New("Error updating category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		err := categoryService.UpdateCategory(id,reqMock)
 
@@ -219,6 +264,103 @@ func TestUpdateCategory(t *testing.T) {
 		categoryRepo.AssertExpectations(t)
 
 	})
+
+	t.Run("Move Category Under New Parent",func(t *testing.T) {
+		parentID := uint(2)
+		existing := &models.Category{
+			Model: gorm.Model{ID: 1},
+			Name: "TEST A",
+		}
+		reqMock := &models.Category{
+			Name: "TEST B",
+			ParentID: &parentID,
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(existing,nil)
+		categoryRepo.On("FindAncestors",parentID).Return([]models.Category{
+			{Model: gorm.Model{ID: 5}},
+			{Model: gorm.Model{ID: 2}},
+		},nil)
+		categoryRepo.On("Update",existing).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.UpdateCategory(1,reqMock)
+
+		assert.NoError(t,err)
+		assert.Equal(t,&parentID,existing.ParentID)
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Parent Is Itself",func(t *testing.T) {
+		parentID := uint(1)
+		reqMock := &models.Category{
+			Name: "TEST B",
+			ParentID: &parentID,
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.UpdateCategory(1,reqMock)
+
+		assert.EqualError(t,err,"Category cannot be its own parent")
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Parent Is Subcategory",func(t *testing.T) {
+		parentID := uint(3)
+		reqMock := &models.Category{
+			Name: "TEST B",
+			ParentID: &parentID,
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindAncestors",parentID).Return([]models.Category{
+			{Model: gorm.Model{ID: 1}},
+			{Model: gorm.Model{ID: 2}},
+			{Model: gorm.Model{ID: 3}},
+		},nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.UpdateCategory(1,reqMock)
+
+		assert.EqualError(t,err,"Category cannot be moved under its own subcategory")
+
+		categoryRepo.AssertNotCalled(t,"Update",mock.Anything)
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Parent Not Found",func(t *testing.T) {
+		parentID := uint(9)
+		reqMock := &models.Category{
+			Name: "TEST B",
+			ParentID: &parentID,
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindAncestors",parentID).Return([]models.Category{},nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.UpdateCategory(1,reqMock)
+
+		assert.EqualError(t,err,"Parent category not found")
+
+		categoryRepo.AssertExpectations(t)
+	})
 }
 
 func TestDeleteCategory(t *testing.T) {
@@ -232,11 +374,11 @@ func TestDeleteCategory(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(reqMock,nil)
-		categoryRepo.On("Delete",id).Return(nil)
+		categoryRepo.On("DeleteIfEmpty",id).Return(true,nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
-		err := categoryService.DeleteCategory(id)
+		err := categoryService.DeleteCategory(id,nil)
 
 		assert.NoError(t,err)
 
@@ -255,9 +397,9 @@ func TestDeleteCategory(t *testing.T) {
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(nil,errors.New("Error finding category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
-		err := categoryService.DeleteCategory(id)
+		err := categoryService.DeleteCategory(id,nil)
 
 		assert.EqualError(t,err,"Error finding category")
 
@@ -276,9 +418,9 @@ func TestDeleteCategory(t *testing.T) {
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(nil,nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
-		err := categoryService.DeleteCategory(id)
+		err := categoryService.DeleteCategory(id,nil)
 
 		assert.EqualError(t,err,"Category not found")
 
@@ -296,17 +438,205 @@ func TestDeleteCategory(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 		categoryRepo.On("FindByID",reqMock.Model.ID).Return(reqMock,nil)
-		categoryRepo.On("Delete",id).Return(errors.New("Error deleting category"))
+		categoryRepo.On("DeleteIfEmpty",id).Return(false,errors.New("Error deleting category"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
-		err := categoryService.DeleteCategory(id)
+		err := categoryService.DeleteCategory(id,nil)
 
 		assert.EqualError(t,err,"Error deleting category")
 
 		categoryRepo.AssertExpectations(t)
 
 	})
+
+	t.Run("Category Still Has Products",func(t *testing.T) {
+		id := uint(1)
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",id).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("DeleteIfEmpty",id).Return(false,nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.DeleteCategory(id,nil)
+
+		assert.EqualError(t,err,"Category still has products, choose a target category to move them to")
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Delete And Move Products To Target",func(t *testing.T) {
+		id := uint(1)
+		targetID := uint(2)
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",id).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindByID",targetID).Return(&models.Category{Model: gorm.Model{ID: 2}},nil)
+		categoryRepo.On("ReassignProductsAndDelete",id,targetID).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.DeleteCategory(id,&targetID)
+
+		assert.NoError(t,err)
+
+		categoryRepo.AssertNotCalled(t,"DeleteIfEmpty",id)
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Moved Products Use Target Category Name In Search",func(t *testing.T) {
+		id := uint(1)
+		targetID := uint(2)
+
+		searchIndex := search.NewMemorySearchIndex()
+		searchIndex.IndexProduct(search.Document{ProductID: 7, CategoryID: 1, Name: "Linen Shirt", CategoryName: "Tops"})
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",id).Return(&models.Category{Model: gorm.Model{ID: 1}, Name: "Tops"},nil)
+		categoryRepo.On("FindByID",targetID).Return(&models.Category{Model: gorm.Model{ID: 2}, Name: "Shirts"},nil)
+		categoryRepo.On("ReassignProductsAndDelete",id,targetID).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, searchIndex)
+
+		err := categoryService.DeleteCategory(id,&targetID)
+
+		assert.NoError(t,err)
+
+		hits, _, _ := searchIndex.Search("shirts", 10, 0)
+		assert.Len(t,hits,1)
+
+		hits, _, _ = searchIndex.Search("tops", 10, 0)
+		assert.Empty(t,hits)
+	})
+
+	t.Run("Target Is Same Category",func(t *testing.T) {
+		id := uint(1)
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",id).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.DeleteCategory(id,&id)
+
+		assert.EqualError(t,err,"Target category must be different from the category being removed")
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Target Category Not Found",func(t *testing.T) {
+		id := uint(1)
+		targetID := uint(9)
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",id).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindByID",targetID).Return(nil,nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.DeleteCategory(id,&targetID)
+
+		assert.EqualError(t,err,"Target category not found")
+
+		categoryRepo.AssertExpectations(t)
+	})
+}
+
+func TestMergeCategories(t *testing.T) {
+	t.Run("Merge Success",func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindByID",uint(2)).Return(&models.Category{Model: gorm.Model{ID: 2}},nil)
+		categoryRepo.On("FindAncestors",uint(2)).Return([]models.Category{{Model: gorm.Model{ID: 2}}},nil)
+		categoryRepo.On("Merge",uint(1),uint(2)).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.MergeCategories(1,2)
+
+		assert.NoError(t,err)
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Merged Products Use Target Category Name In Search",func(t *testing.T) {
+		searchIndex := search.NewMemorySearchIndex()
+		searchIndex.IndexProduct(search.Document{ProductID: 7, CategoryID: 1, Name: "Linen Shirt", CategoryName: "Tees"})
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}, Name: "Tees"},nil)
+		categoryRepo.On("FindByID",uint(2)).Return(&models.Category{Model: gorm.Model{ID: 2}, Name: "Shirts"},nil)
+		categoryRepo.On("FindAncestors",uint(2)).Return([]models.Category{{Model: gorm.Model{ID: 2}}},nil)
+		categoryRepo.On("Merge",uint(1),uint(2)).Return(nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, searchIndex)
+
+		err := categoryService.MergeCategories(1,2)
+
+		assert.NoError(t,err)
+
+		hits, _, _ := searchIndex.Search("tees", 10, 0)
+		assert.Empty(t,hits)
+	})
+
+	t.Run("Merge Into Own Subcategory",func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindByID",uint(3)).Return(&models.Category{Model: gorm.Model{ID: 3}},nil)
+		categoryRepo.On("FindAncestors",uint(3)).Return([]models.Category{
+			{Model: gorm.Model{ID: 1}},
+			{Model: gorm.Model{ID: 3}},
+		},nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.MergeCategories(1,3)
+
+		assert.EqualError(t,err,"Cannot merge a category into its own subcategory")
+
+		categoryRepo.AssertNotCalled(t,"Merge",mock.Anything,mock.Anything)
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Source Category Not Found",func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(nil,nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.MergeCategories(1,2)
+
+		assert.EqualError(t,err,"Category not found")
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error To Merge",func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID",uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}},nil)
+		categoryRepo.On("FindByID",uint(2)).Return(&models.Category{Model: gorm.Model{ID: 2}},nil)
+		categoryRepo.On("FindAncestors",uint(2)).Return([]models.Category{{Model: gorm.Model{ID: 2}}},nil)
+		categoryRepo.On("Merge",uint(1),uint(2)).Return(errors.New("db error"))
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		err := categoryService.MergeCategories(1,2)
+
+		assert.EqualError(t,err,"Error merging categories")
+
+		categoryRepo.AssertExpectations(t)
+	})
 }
 
 func TestGetAllCategories(t *testing.T) {
@@ -328,7 +658,7 @@ func TestGetAllCategories(t *testing.T) {
 
 		categoryRepo.On("FindAll").Return(categorys,nil)
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		categoryList,err := categoryService.GetAllCategories()
 
@@ -345,7 +675,7 @@ func TestGetAllCategories(t *testing.T) {
 
 		categoryRepo.On("FindAll").Return(nil,errors.New("Error retrieving categories"))
 
-		categoryService := services.NewCategoryService(categoryRepo)
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
 
 		categoryList,err := categoryService.GetAllCategories()
 
@@ -354,4 +684,51 @@ func TestGetAllCategories(t *testing.T) {
 
 		categoryRepo.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+}
+
+func TestGetCategoryTree(t *testing.T) {
+	t.Run("Build Category Tree",func(t *testing.T) {
+		menID := uint(1)
+		shirtsID := uint(2)
+		deletedID := uint(99)
+		categories := []models.Category{
+			{Model: gorm.Model{ID: 1}, Name: "Men", Slug: "men"},
+			{Model: gorm.Model{ID: 2}, Name: "Shirts", Slug: "shirts", ParentID: &menID},
+			{Model: gorm.Model{ID: 3}, Name: "Polo", Slug: "polo", ParentID: &shirtsID},
+			{Model: gorm.Model{ID: 4}, Name: "Sale", Slug: "sale", ParentID: &deletedID},
+		}
+
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindAll").Return(categories,nil)
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		tree,err := categoryService.GetCategoryTree()
+
+		assert.NoError(t,err)
+		assert.Len(t,tree,2)
+		assert.Equal(t,"Men",tree[0].Name)
+		assert.Equal(t,"Shirts",tree[0].Children[0].Name)
+		assert.Equal(t,"Polo",tree[0].Children[0].Children[0].Name)
+		assert.Empty(t,tree[0].Children[0].Children[0].Children)
+		assert.Equal(t,"Sale",tree[1].Name)
+
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error To Get Category Tree",func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindAll").Return(nil,errors.New("db error"))
+
+		categoryService := services.NewCategoryService(categoryRepo, search.NewMemorySearchIndex())
+
+		tree,err := categoryService.GetCategoryTree()
+
+		assert.EqualError(t,err,"Error retrieving categories")
+		assert.Nil(t,tree)
+
+		categoryRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+)
+
+type CouponServiceInterface interface {
+	CreateCoupon(coupon *models.Coupon) error
+	UpdateCoupon(id uint, coupon *models.Coupon) error
+	DeleteCoupon(id uint) error
+	GetAllCoupons(params pagination.Params) (pagination.Page[dto.CouponResponseDTO], error)
+	GetCouponByID(id uint) (*dto.CouponResponseDTO, error)
+	GetRedemptions(couponID uint, params pagination.Params) (pagination.Page[dto.CouponRedemptionResponseDTO], error)
+}
+
+type CouponService struct {
+	couponRepo repositories.CouponRepositoryInterface
+}
+
+func NewCouponService(couponRepo repositories.CouponRepositoryInterface) *CouponService {
+	return &CouponService{couponRepo: couponRepo}
+}
+
+func validateCoupon(coupon *models.Coupon) error {
+	if coupon.Code == "" {
+		return errors.New("Coupon code cannot be empty")
+	}
+
+	if coupon.DiscountAmount <= 0 {
+		return errors.New("Discount amount must be greater than 0")
+	}
+
+	switch coupon.DiscountType {
+	case models.PercentageDiscount:
+		if coupon.DiscountAmount > 100 {
+			return errors.New("Percentage discount cannot be more than 100")
+		}
+	case models.FixedDiscount:
+	default:
+		return errors.New("Discount type must be percentage or fixed")
+	}
+
+	if coupon.MaxRedemptions < 0 || coupon.MaxRedemptionsPerUser < 0 {
+		return errors.New("Max redemptions cannot be negative")
+	}
+
+	if coupon.MinOrderAmount < 0 || coupon.MaxDiscount < 0 {
+		return errors.New("Amount cannot be negative")
+	}
+
+	if coupon.ExpiredAt.IsZero() {
+		return errors.New("Please provide expired date")
+	}
+
+	if coupon.StartAt != nil && !coupon.StartAt.Before(coupon.ExpiredAt) {
+		return errors.New("Start date must be before expired date")
+	}
+
+	return nil
+}
+
+func (s *CouponService) CreateCoupon(coupon *models.Coupon) error {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(coupon.Code))
+
+	if err := validateCoupon(coupon); err != nil {
+		return err
+	}
+
+	// NOTE - เช็คว่ามี code ซ้ำไหม
+	existingCoupon, err := s.couponRepo.FindByCode(coupon.Code)
+	if err != nil {
+		return errors.New("Error checking for existing coupon")
+	}
+
+	if existingCoupon != nil {
+		return errors.New("Coupon code already exists")
+	}
+
+	if err := s.couponRepo.Create(coupon); err != nil {
+		return errors.New("Error creating coupon")
+	}
+
+	return nil
+}
+
+func (s *CouponService) UpdateCoupon(id uint, coupon *models.Coupon) error {
+	coupon.Code = strings.ToUpper(strings.TrimSpace(coupon.Code))
+
+	if err := validateCoupon(coupon); err != nil {
+		return err
+	}
+
+	existingCoupon, err := s.couponRepo.FindByID(id)
+	if err != nil {
+		return errors.New("Error finding coupon")
+	}
+
+	if existingCoupon == nil {
+		return errors.New("Coupon not found")
+	}
+
+	// NOTE - ถ้าเปลี่ยน code ต้องไม่ซ้ำกับ coupon อื่น
+	sameCode, err := s.couponRepo.FindByCode(coupon.Code)
+	if err != nil {
+		return errors.New("Error checking for existing coupon")
+	}
+
+	if sameCode != nil && sameCode.ID != existingCoupon.ID {
+		return errors.New("Coupon code already exists")
+	}
+
+	existingCoupon.Code = coupon.Code
+	existingCoupon.DiscountType = coupon.DiscountType
+	existingCoupon.DiscountAmount = coupon.DiscountAmount
+	existingCoupon.MaxDiscount = coupon.MaxDiscount
+	existingCoupon.MinOrderAmount = coupon.MinOrderAmount
+	existingCoupon.MaxRedemptions = coupon.MaxRedemptions
+	existingCoupon.MaxRedemptionsPerUser = coupon.MaxRedemptionsPerUser
+	existingCoupon.StartAt = coupon.StartAt
+	existingCoupon.ExpiredAt = coupon.ExpiredAt
+	existingCoupon.Categories = coupon.Categories
+	existingCoupon.Products = coupon.Products
+
+	if err := s.couponRepo.Update(existingCoupon); err != nil {
+		return errors.New("Error updating coupon")
+	}
+
+	return nil
+}
+
+func (s *CouponService) DeleteCoupon(id uint) error {
+	existingCoupon, err := s.couponRepo.FindByID(id)
+	if err != nil {
+		return errors.New("Error finding coupon")
+	}
+
+	if existingCoupon == nil {
+		return errors.New("Coupon not found")
+	}
+
+	if err := s.couponRepo.Delete(id); err != nil {
+		return errors.New("Error deleting coupon")
+	}
+
+	return nil
+}
+
+func (s *CouponService) GetAllCoupons(params pagination.Params) (pagination.Page[dto.CouponResponseDTO], error) {
+	coupons, err := s.couponRepo.FindAll(params)
+	if err != nil {
+		return pagination.Page[dto.CouponResponseDTO]{}, errors.New("Error retrieving coupons")
+	}
+
+	counts, err := s.couponRepo.GetRedemptionCounts()
+	if err != nil {
+		return pagination.Page[dto.CouponResponseDTO]{}, errors.New("Error retrieving coupon usage")
+	}
+
+	return pagination.Map(coupons, func(coupon models.Coupon) dto.CouponResponseDTO {
+		return toCouponResponseDTO(coupon, counts[coupon.ID])
+	}), nil
+}
+
+func (s *CouponService) GetCouponByID(id uint) (*dto.CouponResponseDTO, error) {
+	coupon, err := s.couponRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("Error finding coupon")
+	}
+
+	if coupon == nil {
+		return nil, errors.New("Coupon not found")
+	}
+
+	counts, err := s.couponRepo.GetRedemptionCounts()
+	if err != nil {
+		return nil, errors.New("Error retrieving coupon usage")
+	}
+
+	response := toCouponResponseDTO(*coupon, counts[coupon.ID])
+	return &response, nil
+}
+
+func (s *CouponService) GetRedemptions(couponID uint, params pagination.Params) (pagination.Page[dto.CouponRedemptionResponseDTO], error) {
+	coupon, err := s.couponRepo.FindByID(couponID)
+	if err != nil {
+		return pagination.Page[dto.CouponRedemptionResponseDTO]{}, errors.New("Error finding coupon")
+	}
+
+	if coupon == nil {
+		return pagination.Page[dto.CouponRedemptionResponseDTO]{}, errors.New("Coupon not found")
+	}
+
+	redemptions, err := s.couponRepo.FindRedemptionsByCouponID(couponID, params)
+	if err != nil {
+		return pagination.Page[dto.CouponRedemptionResponseDTO]{}, errors.New("Error retrieving coupon redemptions")
+	}
+
+	return pagination.Map(redemptions, toCouponRedemptionResponseDTO), nil
+}
+
+func toCouponRedemptionResponseDTO(r models.CouponRedemption) dto.CouponRedemptionResponseDTO {
+	return dto.CouponRedemptionResponseDTO{
+		OrderID:        r.OrderID,
+		UserID:         r.UserID,
+		DiscountAmount: r.DiscountAmount,
+		OrderStatus:    r.Order.Status,
+		CreatedAt:      r.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toCouponResponseDTO(coupon models.Coupon, redemptionCount int64) dto.CouponResponseDTO {
+	response := dto.CouponResponseDTO{
+		ID:                    coupon.ID,
+		Code:                  coupon.Code,
+		DiscountType:          coupon.DiscountType,
+		DiscountAmount:        coupon.DiscountAmount,
+		MaxDiscount:           coupon.MaxDiscount,
+		MinOrderAmount:        coupon.MinOrderAmount,
+		MaxRedemptions:        coupon.MaxRedemptions,
+		MaxRedemptionsPerUser: coupon.MaxRedemptionsPerUser,
+		StartAt:               coupon.StartAt,
+		ExpiredAt:             coupon.ExpiredAt,
+		CategoryIDs:           []uint{},
+		ProductIDs:            []uint{},
+		RedemptionCount:       redemptionCount,
+	}
+
+	for _, c := range coupon.Categories {
+		response.CategoryIDs = append(response.CategoryIDs, c.ID)
+	}
+
+	for _, p := range coupon.Products {
+		response.ProductIDs = append(response.ProductIDs, p.ID)
+	}
+
+	return response
+}
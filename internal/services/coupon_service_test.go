@@ -0,0 +1,229 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestCreateCoupon(t *testing.T) {
+	t.Run("CreateCoupon Success", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		coupon := &models.Coupon{
+			Code:           " sale10 ",
+			DiscountType:   models.PercentageDiscount,
+			DiscountAmount: 10,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		couponRepo.On("FindByCode", "SALE10").Return(nil, nil)
+		couponRepo.On("Create", coupon).Return(nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.CreateCoupon(coupon)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "SALE10", coupon.Code)
+		couponRepo.AssertExpectations(t)
+	})
+
+	t.Run("Coupon code already exists", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		coupon := &models.Coupon{
+			Code:           "SALE10",
+			DiscountType:   models.FixedDiscount,
+			DiscountAmount: 10,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		couponRepo.On("FindByCode", "SALE10").Return(&models.Coupon{Model: gorm.Model{ID: 1}}, nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.CreateCoupon(coupon)
+
+		assert.EqualError(t, err, "Coupon code already exists")
+		couponRepo.AssertExpectations(t)
+	})
+
+	t.Run("Percentage more than 100", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		coupon := &models.Coupon{
+			Code:           "SALE10",
+			DiscountType:   models.PercentageDiscount,
+			DiscountAmount: 150,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.CreateCoupon(coupon)
+
+		assert.EqualError(t, err, "Percentage discount cannot be more than 100")
+	})
+
+	t.Run("Start date after expired date", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		startAt := time.Now().Add(48 * time.Hour)
+		coupon := &models.Coupon{
+			Code:           "SALE10",
+			DiscountType:   models.FixedDiscount,
+			DiscountAmount: 10,
+			StartAt:        &startAt,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.CreateCoupon(coupon)
+
+		assert.EqualError(t, err, "Start date must be before expired date")
+	})
+}
+
+func TestUpdateCoupon(t *testing.T) {
+	t.Run("UpdateCoupon Success", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		existing := &models.Coupon{Model: gorm.Model{ID: 1}, Code: "OLD"}
+		coupon := &models.Coupon{
+			Code:           "NEW",
+			DiscountType:   models.FixedDiscount,
+			DiscountAmount: 20,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+			Products:       []models.Product{{Model: gorm.Model{ID: 3}}},
+		}
+
+		couponRepo.On("FindByID", uint(1)).Return(existing, nil)
+		couponRepo.On("FindByCode", "NEW").Return(nil, nil)
+		couponRepo.On("Update", existing).Return(nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.UpdateCoupon(1, coupon)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "NEW", existing.Code)
+		assert.Equal(t, 20.0, existing.DiscountAmount)
+		assert.Len(t, existing.Products, 1)
+		couponRepo.AssertExpectations(t)
+	})
+
+	t.Run("Coupon not found", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		coupon := &models.Coupon{
+			Code:           "NEW",
+			DiscountType:   models.FixedDiscount,
+			DiscountAmount: 20,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		couponRepo.On("FindByID", uint(1)).Return(nil, nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.UpdateCoupon(1, coupon)
+
+		assert.EqualError(t, err, "Coupon not found")
+		couponRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetAllCoupons(t *testing.T) {
+	t.Run("GetAllCoupons Success", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		coupons := []models.Coupon{
+			{Model: gorm.Model{ID: 1}, Code: "A", Categories: []models.Category{{Model: gorm.Model{ID: 5}}}},
+			{Model: gorm.Model{ID: 2}, Code: "B"},
+		}
+
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		total := int64(2)
+		couponRepo.On("FindAll", params).Return(pagination.Page[models.Coupon]{Items: coupons, Total: &total}, nil)
+		couponRepo.On("GetRedemptionCounts").Return(map[uint]int64{1: 4}, nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		result, err := couponService.GetAllCoupons(params)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 2)
+		assert.Equal(t, int64(4), result.Items[0].RedemptionCount)
+		assert.Equal(t, []uint{5}, result.Items[0].CategoryIDs)
+		assert.Equal(t, int64(0), result.Items[1].RedemptionCount)
+		assert.Equal(t, &total, result.Total)
+		couponRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error retrieving coupons", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		couponRepo.On("FindAll", params).Return(nil, errors.New("db error"))
+
+		couponService := services.NewCouponService(couponRepo)
+
+		result, err := couponService.GetAllCoupons(params)
+
+		assert.EqualError(t, err, "Error retrieving coupons")
+		assert.Empty(t, result.Items)
+	})
+}
+
+func TestDeleteCoupon(t *testing.T) {
+	t.Run("DeleteCoupon Success", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		couponRepo.On("FindByID", uint(1)).Return(&models.Coupon{Model: gorm.Model{ID: 1}}, nil)
+		couponRepo.On("Delete", uint(1)).Return(nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		err := couponService.DeleteCoupon(1)
+
+		assert.NoError(t, err)
+		couponRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetRedemptions(t *testing.T) {
+	t.Run("GetRedemptions Success", func(t *testing.T) {
+		couponRepo := repositories.NewCouponRepositoryMock()
+
+		redemptions := []models.CouponRedemption{
+			{CouponID: 1, OrderID: 10, UserID: 2, DiscountAmount: 30, Order: models.Order{Status: models.Paid}},
+		}
+
+		couponRepo.On("FindByID", uint(1)).Return(&models.Coupon{Model: gorm.Model{ID: 1}}, nil)
+		params := pagination.Params{Limit: 1}
+		couponRepo.On("FindRedemptionsByCouponID", uint(1), params).Return(pagination.Page[models.CouponRedemption]{
+			Items:      redemptions,
+			NextCursor: pagination.Encode(pagination.Cursor{AfterID: 7}),
+		}, nil)
+
+		couponService := services.NewCouponService(couponRepo)
+
+		result, err := couponService.GetRedemptions(1, params)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 1)
+		assert.Equal(t, uint(10), result.Items[0].OrderID)
+		assert.Equal(t, models.Paid, result.Items[0].OrderStatus)
+		assert.Equal(t, pagination.Encode(pagination.Cursor{AfterID: 7}), result.NextCursor)
+		couponRepo.AssertExpectations(t)
+	})
+}
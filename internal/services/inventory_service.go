@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+type InventoryServiceInterface interface {
+	GetMovements(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error)
+	AdjustStock(adminID uint, variantID uint, req dto.AdjustStockDTO) (*models.InventoryMovement, error)
+	UpdateReorderThreshold(variantID uint, threshold int) error
+	UpdateVariantBySKU(adminID uint, sku string, req dto.UpdateVariantBySKUDTO) (*models.ProductVariant, error)
+	GetLowStockReport() ([]dto.LowStockVariantDTO, error)
+	SendLowStockAlerts(notifier utils.StockAlertNotifierInterface) (int, error)
+}
+
+// NOTE - ใช้ยอดขายย้อนหลังช่วงนี้คำนวณความเร็วในการขาย
+const salesVelocityWindowDays = 30
+
+type InventoryService struct {
+	db            *gorm.DB
+	inventoryRepo repositories.InventoryRepositoryInterface
+}
+
+func NewInventoryService(db *gorm.DB, inventoryRepo repositories.InventoryRepositoryInterface) *InventoryService {
+	return &InventoryService{
+		db:            db,
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+func (s *InventoryService) GetMovements(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error) {
+	variant, err := s.inventoryRepo.FindVariantByID(variantID)
+	if err != nil {
+		return pagination.Page[models.InventoryMovement]{}, fmt.Errorf("Error finding product variant: %w", err)
+	}
+
+	if variant == nil {
+		return pagination.Page[models.InventoryMovement]{}, errors.New("product variant not found")
+	}
+
+	movements, err := s.inventoryRepo.FindMovementsByVariantID(variantID, params)
+	if err != nil {
+		return pagination.Page[models.InventoryMovement]{}, errors.New("Error finding inventory movements")
+	}
+
+	return movements, nil
+}
+
+// NOTE - admin ปรับ stock เอง เช่น นับของจริงแล้วไม่ตรงกับระบบ ต้องใส่เหตุผลทุกครั้ง
+func (s *InventoryService) AdjustStock(adminID uint, variantID uint, req dto.AdjustStockDTO) (*models.InventoryMovement, error) {
+	if req.Delta == 0 {
+		return nil, errors.New("delta must not be zero")
+	}
+
+	variant, err := s.inventoryRepo.FindVariantByID(variantID)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding product variant: %w", err)
+	}
+
+	if variant == nil {
+		return nil, errors.New("product variant not found")
+	}
+
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	adjusted, err := s.inventoryRepo.AdjustStock(tx, variantID, req.Delta)
+	if err != nil {
+		tx.Rollback()
+		return nil, errors.New("Error adjusting stock")
+	}
+
+	if !adjusted {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: stock cannot be negative", ErrOutOfStock)
+	}
+
+	movement := &models.InventoryMovement{
+		ProductVariantID: variantID,
+		Delta:            req.Delta,
+		Reason:           models.InventoryAdjustment,
+		ActorUserID:      &adminID,
+		Note:             req.Note,
+	}
+	if err := s.inventoryRepo.RecordMovement(tx, movement); err != nil {
+		tx.Rollback()
+		return nil, errors.New("Error recording inventory movement")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return movement, nil
+}
+
+func (s *InventoryService) UpdateReorderThreshold(variantID uint, threshold int) error {
+	if threshold < 0 {
+		return errors.New("reorder threshold cannot be negative")
+	}
+
+	variant, err := s.inventoryRepo.FindVariantByID(variantID)
+	if err != nil {
+		return fmt.Errorf("Error finding product variant: %w", err)
+	}
+
+	if variant == nil {
+		return errors.New("product variant not found")
+	}
+
+	if err := s.inventoryRepo.UpdateReorderThreshold(variantID, threshold); err != nil {
+		return errors.New("Error updating reorder threshold")
+	}
+
+	return nil
+}
+
+func (s *InventoryService) lowStockVariants(onlyUnalerted bool) ([]dto.LowStockVariantDTO, error) {
+	since := time.Now().AddDate(0, 0, -salesVelocityWindowDays)
+
+	variants, err := s.inventoryRepo.FindLowStockVariants(since, onlyUnalerted)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range variants {
+		variants[i].OutOfStock = variants[i].Stock <= 0
+		variants[i].DailyVelocity = float64(variants[i].UnitsSold) / salesVelocityWindowDays
+	}
+
+	return variants, nil
+}
+
+// NOTE - variant ที่ใกล้หมดและหมดแล้ว เรียงจากขายเร็วสุดก่อน เพื่อให้สั่งของตัวที่ขายดีก่อน
+func (s *InventoryService) GetLowStockReport() ([]dto.LowStockVariantDTO, error) {
+	variants, err := s.lowStockVariants(false)
+	if err != nil {
+		return nil, errors.New("Error finding low stock variants")
+	}
+
+	return variants, nil
+}
+
+// NOTE - แจ้งเตือนเฉพาะ variant ที่ยังไม่เคยแจ้ง ส่งไม่สำเร็จจะไม่ mark เพื่อให้รอบถัดไปส่งใหม่
+func (s *InventoryService) SendLowStockAlerts(notifier utils.StockAlertNotifierInterface) (int, error) {
+	if err := s.inventoryRepo.ClearRecoveredLowStockAlerts(); err != nil {
+		return 0, fmt.Errorf("inventoryRepo.ClearRecoveredLowStockAlerts failed: %w", err)
+	}
+
+	variants, err := s.lowStockVariants(true)
+	if err != nil {
+		return 0, fmt.Errorf("inventoryRepo.FindLowStockVariants failed: %w", err)
+	}
+
+	if len(variants) == 0 {
+		return 0, nil
+	}
+
+	if err := notifier.NotifyLowStock(variants); err != nil {
+		return 0, fmt.Errorf("notify low stock failed: %w", err)
+	}
+
+	variantIDs := []uint{}
+	for _, variant := range variants {
+		variantIDs = append(variantIDs, variant.VariantID)
+	}
+
+	if err := s.inventoryRepo.MarkLowStockAlerted(variantIDs, time.Now()); err != nil {
+		return 0, fmt.Errorf("inventoryRepo.MarkLowStockAlerted failed: %w", err)
+	}
+
+	return len(variants), nil
+}
+
+// NOTE - ระบบคลังสินค้าอ้างถึงสินค้าด้วย SKU ตั้ง stock เป็นค่าใหม่และบันทึกส่วนต่างลง ledger
+func (s *InventoryService) UpdateVariantBySKU(adminID uint, sku string, req dto.UpdateVariantBySKUDTO) (*models.ProductVariant, error) {
+	if req.Stock == nil && req.Price == nil {
+		return nil, errors.New("please provide stock or price")
+	}
+
+	variant, err := s.inventoryRepo.FindVariantBySKU(sku)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding product variant: %w", err)
+	}
+
+	if variant == nil {
+		return nil, errors.New("product variant not found")
+	}
+
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	current, err := s.inventoryRepo.LockVariant(tx, variant.ID)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("Error finding product variant: %w", err)
+	}
+
+	// NOTE - ใช้ stock ที่อ่านหลังล็อกแถวแล้ว ส่วนต่างจะไม่คลาดกับ order ที่ตัด stock ไปพร้อมกัน
+	previousStock := current.Stock
+
+	fields := map[string]interface{}{}
+	if req.Stock != nil {
+		fields["stock"] = *req.Stock
+		current.Stock = *req.Stock
+	}
+	if req.Price != nil {
+		fields["price"] = *req.Price
+		current.Price = *req.Price
+	}
+
+	if err := s.inventoryRepo.UpdateVariantFields(tx, variant.ID, fields); err != nil {
+		tx.Rollback()
+		return nil, errors.New("Error updating product variant")
+	}
+
+	if req.Stock != nil && *req.Stock != previousStock {
+		note := req.Note
+		if note == "" {
+			note = "stock set by SKU"
+		}
+
+		movement := &models.InventoryMovement{
+			ProductVariantID: variant.ID,
+			Delta:            *req.Stock - previousStock,
+			Reason:           models.InventoryAdjustment,
+			ActorUserID:      &adminID,
+			Note:             note,
+		}
+		if err := s.inventoryRepo.RecordMovement(tx, movement); err != nil {
+			tx.Rollback()
+			return nil, errors.New("Error recording inventory movement")
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	current.Product = variant.Product
+	return current, nil
+}
@@ -0,0 +1,290 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	utils "github.com/Beluga-Whale/ecommerce-api/internal/utils/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestAdjustStock(t *testing.T) {
+	variant := &models.ProductVariant{Model: gorm.Model{ID: 5}, Stock: 40}
+
+	t.Run("AdjustStock Success", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(variant, nil)
+		inventoryRepo.On("AdjustStock", mock.Anything, uint(5), -3).Return(true, nil)
+		inventoryRepo.On("RecordMovement", mock.Anything, mock.MatchedBy(func(m *models.InventoryMovement) bool {
+			return m.ProductVariantID == 5 && m.Delta == -3 && m.Reason == models.InventoryAdjustment &&
+				*m.ActorUserID == 9 && m.Note == "damaged in warehouse"
+		})).Return(nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		movement, err := inventoryService.AdjustStock(9, 5, dto.AdjustStockDTO{Delta: -3, Note: "damaged in warehouse"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, -3, movement.Delta)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stock cannot go negative", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(variant, nil)
+		inventoryRepo.On("AdjustStock", mock.Anything, uint(5), -41).Return(false, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.AdjustStock(9, 5, dto.AdjustStockDTO{Delta: -41, Note: "x"})
+
+		assert.ErrorIs(t, err, services.ErrOutOfStock)
+		inventoryRepo.AssertNotCalled(t, "RecordMovement", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Variant not found", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(nil, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.AdjustStock(9, 5, dto.AdjustStockDTO{Delta: 1, Note: "x"})
+
+		assert.EqualError(t, err, "product variant not found")
+	})
+
+	t.Run("Record movement failed", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(variant, nil)
+		inventoryRepo.On("AdjustStock", mock.Anything, uint(5), 10).Return(true, nil)
+		inventoryRepo.On("RecordMovement", mock.Anything, mock.Anything).Return(errors.New("db error"))
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.AdjustStock(9, 5, dto.AdjustStockDTO{Delta: 10, Note: "restock count"})
+
+		assert.EqualError(t, err, "Error recording inventory movement")
+	})
+}
+
+func TestGetMovements(t *testing.T) {
+	t.Run("GetMovements Success", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		orderID := uint(1)
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(&models.ProductVariant{Model: gorm.Model{ID: 5}}, nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		inventoryRepo.On("FindMovementsByVariantID", uint(5), params).Return(pagination.Page[models.InventoryMovement]{Items: []models.InventoryMovement{
+			{ProductVariantID: 5, Delta: 2, StockAfter: 40, Reason: models.InventoryCancel, OrderID: &orderID},
+			{ProductVariantID: 5, Delta: -2, StockAfter: 38, Reason: models.InventorySale, OrderID: &orderID},
+		}}, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		movements, err := inventoryService.GetMovements(5, params)
+
+		assert.NoError(t, err)
+		assert.Len(t, movements.Items, 2)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Variant not found", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(nil, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.GetMovements(5, pagination.Params{Limit: pagination.DefaultLimit})
+
+		assert.EqualError(t, err, "product variant not found")
+		inventoryRepo.AssertNotCalled(t, "FindMovementsByVariantID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSendLowStockAlerts(t *testing.T) {
+	lowStock := func() []dto.LowStockVariantDTO {
+		return []dto.LowStockVariantDTO{
+			{VariantID: 2, ProductName: "T-Shirt", Size: "L", Stock: 0, ReorderThreshold: 5, UnitsSold: 60},
+			{VariantID: 1, ProductName: "T-Shirt", Size: "M", Stock: 3, ReorderThreshold: 5, UnitsSold: 3},
+		}
+	}
+
+	t.Run("Notify and mark alerted", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+		notifier := utils.NewFakeStockAlertNotifier()
+
+		inventoryRepo.On("ClearRecoveredLowStockAlerts").Return(nil)
+		inventoryRepo.On("FindLowStockVariants", mock.Anything, true).Return(lowStock(), nil)
+		inventoryRepo.On("MarkLowStockAlerted", []uint{2, 1}, mock.Anything).Return(nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		sent, err := inventoryService.SendLowStockAlerts(notifier)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, sent)
+		assert.Len(t, notifier.Notified, 1)
+		assert.True(t, notifier.Notified[0][0].OutOfStock)
+		assert.Equal(t, 2.0, notifier.Notified[0][0].DailyVelocity)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Nothing to alert", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+		notifier := utils.NewFakeStockAlertNotifier()
+
+		inventoryRepo.On("ClearRecoveredLowStockAlerts").Return(nil)
+		inventoryRepo.On("FindLowStockVariants", mock.Anything, true).Return([]dto.LowStockVariantDTO{}, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		sent, err := inventoryService.SendLowStockAlerts(notifier)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Empty(t, notifier.Notified)
+	})
+
+	t.Run("Notifier failed does not mark alerted", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+		notifier := utils.NewFakeStockAlertNotifier()
+		notifier.Err = errors.New("webhook down")
+
+		inventoryRepo.On("ClearRecoveredLowStockAlerts").Return(nil)
+		inventoryRepo.On("FindLowStockVariants", mock.Anything, true).Return(lowStock(), nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.SendLowStockAlerts(notifier)
+
+		assert.EqualError(t, err, "notify low stock failed: webhook down")
+		inventoryRepo.AssertNotCalled(t, "MarkLowStockAlerted", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetLowStockReport(t *testing.T) {
+	t.Run("GetLowStockReport Success", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindLowStockVariants", mock.MatchedBy(func(since time.Time) bool {
+			return since.Before(time.Now().AddDate(0, 0, -29))
+		}), false).Return([]dto.LowStockVariantDTO{
+			{VariantID: 1, Stock: 2, ReorderThreshold: 5, UnitsSold: 15},
+		}, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		variants, err := inventoryService.GetLowStockReport()
+
+		assert.NoError(t, err)
+		assert.False(t, variants[0].OutOfStock)
+		assert.Equal(t, 0.5, variants[0].DailyVelocity)
+		inventoryRepo.AssertExpectations(t)
+	})
+}
+
+func TestUpdateReorderThreshold(t *testing.T) {
+	t.Run("UpdateReorderThreshold Success", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryRepo.On("FindVariantByID", uint(5)).Return(&models.ProductVariant{Model: gorm.Model{ID: 5}}, nil)
+		inventoryRepo.On("UpdateReorderThreshold", uint(5), 10).Return(nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		err := inventoryService.UpdateReorderThreshold(5, 10)
+
+		assert.NoError(t, err)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Negative threshold", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		err := inventoryService.UpdateReorderThreshold(5, -1)
+
+		assert.EqualError(t, err, "reorder threshold cannot be negative")
+	})
+}
+
+func TestUpdateVariantBySKU(t *testing.T) {
+	variant := &models.ProductVariant{Model: gorm.Model{ID: 5}, SKU: "TS-M", Stock: 40, Price: 100}
+
+	t.Run("UpdateVariantBySKU Success", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		stock := 25
+		price := 120.0
+		inventoryRepo.On("FindVariantBySKU", "TS-M").Return(variant, nil)
+		inventoryRepo.On("LockVariant", mock.Anything, uint(5)).Return(&models.ProductVariant{Model: gorm.Model{ID: 5}, SKU: "TS-M", Stock: 30, Price: 100}, nil)
+		inventoryRepo.On("UpdateVariantFields", mock.Anything, uint(5), map[string]interface{}{"stock": 25, "price": 120.0}).Return(nil)
+		inventoryRepo.On("RecordMovement", mock.Anything, mock.MatchedBy(func(m *models.InventoryMovement) bool {
+			return m.ProductVariantID == 5 && m.Delta == -5 && m.Reason == models.InventoryAdjustment &&
+				*m.ActorUserID == 9 && m.Note == "stock set by SKU"
+		})).Return(nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		updated, err := inventoryService.UpdateVariantBySKU(9, "TS-M", dto.UpdateVariantBySKUDTO{Stock: &stock, Price: &price})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 25, updated.Stock)
+		assert.Equal(t, 120.0, updated.Price)
+		inventoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Price only does not record movement", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		price := 90.0
+		inventoryRepo.On("FindVariantBySKU", "TS-M").Return(variant, nil)
+		inventoryRepo.On("LockVariant", mock.Anything, uint(5)).Return(&models.ProductVariant{Model: gorm.Model{ID: 5}, Stock: 40, Price: 100}, nil)
+		inventoryRepo.On("UpdateVariantFields", mock.Anything, uint(5), map[string]interface{}{"price": 90.0}).Return(nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		updated, err := inventoryService.UpdateVariantBySKU(9, "TS-M", dto.UpdateVariantBySKUDTO{Price: &price})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 40, updated.Stock)
+		inventoryRepo.AssertNotCalled(t, "RecordMovement", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Nothing to update", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.UpdateVariantBySKU(9, "TS-M", dto.UpdateVariantBySKUDTO{})
+
+		assert.EqualError(t, err, "please provide stock or price")
+	})
+
+	t.Run("SKU not found", func(t *testing.T) {
+		inventoryRepo := repositories.NewInventoryRepositoryMock()
+
+		stock := 1
+		inventoryRepo.On("FindVariantBySKU", "NOPE").Return(nil, nil)
+
+		inventoryService := services.NewInventoryService(InitializeDB(t), inventoryRepo)
+
+		_, err := inventoryService.UpdateVariantBySKU(9, "NOPE", dto.UpdateVariantBySKUDTO{Stock: &stock})
+
+		assert.EqualError(t, err, "product variant not found")
+	})
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type CartServiceMock struct {
+	mock.Mock
+}
+
+func NewCartServiceMock() *CartServiceMock {
+	return &CartServiceMock{}
+}
+
+func (m *CartServiceMock) GetCart(userID uint) (*dto.CartResponseDTO, error) {
+	args := m.Called(userID)
+	if cart, ok := args.Get(0).(*dto.CartResponseDTO); ok {
+		return cart, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CartServiceMock) AddItem(userID uint, req dto.AddCartItemDTO) error {
+	args := m.Called(userID, req)
+	return args.Error(0)
+}
+
+func (m *CartServiceMock) UpdateQuantity(userID uint, itemID uint, quantity uint) error {
+	args := m.Called(userID, itemID, quantity)
+	return args.Error(0)
+}
+
+func (m *CartServiceMock) RemoveItem(userID uint, itemID uint) error {
+	args := m.Called(userID, itemID)
+	return args.Error(0)
+}
+
+func (m *CartServiceMock) ClearCart(userID uint) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
+func (m *CartServiceMock) Checkout(userID uint, req dto.CartCheckoutDTO) (*models.Order, error) {
+	args := m.Called(userID, req)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,8 +24,13 @@ func (m *CategoryServiceMock) UpdateCategory(id uint, category *models.Category)
 	return args.Error(0)
 }
 
-func (m *CategoryServiceMock) DeleteCategory(id uint) error{
-	args := m.Called(id)
+func (m *CategoryServiceMock) DeleteCategory(id uint, targetID *uint) error{
+	args := m.Called(id,targetID)
+	return args.Error(0)
+}
+
+func (m *CategoryServiceMock) MergeCategories(sourceID uint, targetID uint) error{
+	args := m.Called(sourceID,targetID)
 	return args.Error(0)
 }
 
@@ -37,3 +43,13 @@ func (m *CategoryServiceMock) GetAllCategories() ([]models.Category, error){
 
 	return nil,args.Error(1)
 }
+
+func (m *CategoryServiceMock) GetCategoryTree() ([]dto.CategoryTreeDTO, error){
+	args := m.Called()
+
+	if tree, ok := args.Get(0).([]dto.CategoryTreeDTO); ok {
+		return tree,args.Error(1)
+	}
+
+	return nil,args.Error(1)
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type CouponServiceMock struct {
+	mock.Mock
+}
+
+func NewCouponServiceMock() *CouponServiceMock {
+	return &CouponServiceMock{}
+}
+
+func (m *CouponServiceMock) CreateCoupon(coupon *models.Coupon) error {
+	args := m.Called(coupon)
+	return args.Error(0)
+}
+
+func (m *CouponServiceMock) UpdateCoupon(id uint, coupon *models.Coupon) error {
+	args := m.Called(id, coupon)
+	return args.Error(0)
+}
+
+func (m *CouponServiceMock) DeleteCoupon(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *CouponServiceMock) GetAllCoupons(params pagination.Params) (pagination.Page[dto.CouponResponseDTO], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[dto.CouponResponseDTO]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[dto.CouponResponseDTO]{}, args.Error(1)
+}
+
+func (m *CouponServiceMock) GetCouponByID(id uint) (*dto.CouponResponseDTO, error) {
+	args := m.Called(id)
+	if coupon, ok := args.Get(0).(*dto.CouponResponseDTO); ok {
+		return coupon, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CouponServiceMock) GetRedemptions(couponID uint, params pagination.Params) (pagination.Page[dto.CouponRedemptionResponseDTO], error) {
+	args := m.Called(couponID, params)
+	if page, ok := args.Get(0).(pagination.Page[dto.CouponRedemptionResponseDTO]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[dto.CouponRedemptionResponseDTO]{}, args.Error(1)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+	"github.com/stretchr/testify/mock"
+)
+
+type InventoryServiceMock struct {
+	mock.Mock
+}
+
+func NewInventoryServiceMock() *InventoryServiceMock {
+	return &InventoryServiceMock{}
+}
+
+func (m *InventoryServiceMock) GetMovements(variantID uint, params pagination.Params) (pagination.Page[models.InventoryMovement], error) {
+	args := m.Called(variantID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.InventoryMovement]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.InventoryMovement]{}, args.Error(1)
+}
+
+func (m *InventoryServiceMock) AdjustStock(adminID uint, variantID uint, req dto.AdjustStockDTO) (*models.InventoryMovement, error) {
+	args := m.Called(adminID, variantID, req)
+	if movement, ok := args.Get(0).(*models.InventoryMovement); ok {
+		return movement, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryServiceMock) UpdateReorderThreshold(variantID uint, threshold int) error {
+	args := m.Called(variantID, threshold)
+	return args.Error(0)
+}
+
+func (m *InventoryServiceMock) GetLowStockReport() ([]dto.LowStockVariantDTO, error) {
+	args := m.Called()
+	if variants, ok := args.Get(0).([]dto.LowStockVariantDTO); ok {
+		return variants, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *InventoryServiceMock) SendLowStockAlerts(notifier utils.StockAlertNotifierInterface) (int, error) {
+	args := m.Called(notifier)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *InventoryServiceMock) UpdateVariantBySKU(adminID uint, sku string, req dto.UpdateVariantBySKUDTO) (*models.ProductVariant, error) {
+	args := m.Called(adminID, sku, req)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"time"
+
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -24,8 +28,8 @@ func (m *OrderServiceMock) CreateOrder(userID uint, req dto.CreateOrderRequestDT
 	return nil,args.Error(1)
 }
 
-func (m *OrderServiceMock) CancelOrderAndRestoreStock( orderID uint) error{
-	args := m.Called(orderID)
+func (m *OrderServiceMock) CancelOrderAndRestoreStock( orderID uint, source servicesPkg.StatusSource) error{
+	args := m.Called(orderID, source)
 
 	return args.Error(0)
 }
@@ -45,13 +49,12 @@ func (m *OrderServiceMock) GetOrderByID(orderID uint, userIDUint uint) (*models.
 	return nil,args.Error(1)
 }
 
-func (m *OrderServiceMock) GetAllOrderByUserId(userIDUint uint) ([]models.Order,error) {
-	args := m.Called(userIDUint)
-
-	if order,ok := args.Get(0).([]models.Order); ok {
-		return order,args.Error(1)
+func (m *OrderServiceMock) GetAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order], error) {
+	args := m.Called(userIDUint, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Order]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[models.Order]{}, args.Error(1)
 }
 
 func (m *OrderServiceMock) UpdateStatusByUser(userIDUint uint,orderID *uint, status models.Status) error {
@@ -60,19 +63,17 @@ func (m *OrderServiceMock) UpdateStatusByUser(userIDUint uint,orderID *uint, sta
 	return args.Error(0)
 }
 
-func (m *OrderServiceMock) GetAllOrdersAdmin() ([]models.Order,error) {
-	args := m.Called()
-
-	if order,ok := args.Get(0).([]models.Order) ; ok {
-		return order,args.Error(1)
+func (m *OrderServiceMock) GetAllOrdersAdmin(params pagination.Params) (pagination.Page[models.Order], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[models.Order]); ok {
+		return page, args.Error(1)
 	}
-
-	return nil,args.Error(1)
+	return pagination.Page[models.Order]{}, args.Error(1)
 }
 
 
-func (m *OrderServiceMock) UpdateStatusByAdmin(orderID *uint, status models.Status) error {
-	args := m.Called(orderID,status)
+func (m *OrderServiceMock) UpdateStatusByAdmin(adminID uint, orderID *uint, status models.Status, note string) error {
+	args := m.Called(adminID,orderID,status,note)
 
 	return args.Error(0)
 }
@@ -111,13 +112,12 @@ func (m *OrderServiceMock)DeleteOrder(id uint) error {
 	return args.Error(0)
 }
 
-func (m *OrderServiceMock)GetCustomerDetail() ([]dto.CustomerDTO,error) {
-	args := m.Called()
-
-	if customer,ok := args.Get(0).([]dto.CustomerDTO);ok{
-		return customer,args.Error(1)
+func (m *OrderServiceMock) GetCustomerDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[dto.CustomerDTO]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[dto.CustomerDTO]{}, args.Error(1)
 }
 
 func (m *OrderServiceMock)ValidateAndCalculate(
@@ -131,4 +131,64 @@ func (m *OrderServiceMock)ValidateAndCalculate(
 	}
 
 	return nil,0.0,args.Error(2)
-}
\ No newline at end of file
+}
+
+func (m *OrderServiceMock) GetPayableOrder(orderID uint, userID uint) (*models.Order, error) {
+	args := m.Called(orderID, userID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderServiceMock) SetPaymentIntentID(orderID uint, paymentIntentID string) error {
+	args := m.Called(orderID, paymentIntentID)
+	return args.Error(0)
+}
+
+func (m *OrderServiceMock) GetOrderByPaymentIntentID(paymentIntentID string) (*models.Order, error) {
+	args := m.Called(paymentIntentID)
+	if order, ok := args.Get(0).(*models.Order); ok {
+		return order, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderServiceMock) UpdateStatusBySystem(orderID uint, status models.Status, source servicesPkg.StatusSource, note string) error {
+	args := m.Called(orderID, status, source, note)
+	return args.Error(0)
+}
+
+func (m *OrderServiceMock) GetOrderTimeline(orderID uint) ([]dto.OrderStatusEventDTO, error) {
+	args := m.Called(orderID)
+	if timeline, ok := args.Get(0).([]dto.OrderStatusEventDTO); ok {
+		return timeline, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderServiceMock) GetOrderTimelineByUser(userID uint, orderID uint) ([]dto.OrderStatusEventDTO, error) {
+	args := m.Called(userID, orderID)
+	if timeline, ok := args.Get(0).([]dto.OrderStatusEventDTO); ok {
+		return timeline, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *OrderServiceMock) ExtendReservation(orderID uint) error {
+	args := m.Called(orderID)
+	return args.Error(0)
+}
+
+func (m *OrderServiceMock) ExpireOrders(now time.Time, after servicesPkg.ExpirationCursor, limit int) (servicesPkg.ExpirationResult, error) {
+	args := m.Called(now, after, limit)
+	return args.Get(0).(servicesPkg.ExpirationResult), args.Error(1)
+}
+
+func (m *OrderServiceMock) NextPaymentExpiry() (*time.Time, error) {
+	args := m.Called()
+	if next, ok := args.Get(0).(*time.Time); ok {
+		return next, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
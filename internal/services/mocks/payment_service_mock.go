@@ -0,0 +1,48 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type PaymentServiceMock struct {
+	mock.Mock
+}
+
+func NewPaymentServiceMock() *PaymentServiceMock {
+	return &PaymentServiceMock{}
+}
+
+func (m *PaymentServiceMock) RecordPayment(payment *models.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
+
+func (m *PaymentServiceMock) GetPaymentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error) {
+	args := m.Called(orderID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Payment]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Payment]{}, args.Error(1)
+}
+
+func (m *PaymentServiceMock) BeginEvent(eventID string, eventType string) (bool, error) {
+	args := m.Called(eventID, eventType)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *PaymentServiceMock) ReleaseEvent(eventID string) error {
+	args := m.Called(eventID)
+	return args.Error(0)
+}
+
+func (m *PaymentServiceMock) GetRefundedAmount(orderID uint) (float64, error) {
+	args := m.Called(orderID)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *PaymentServiceMock) RefundUnpayablePayment(payment *models.Payment) error {
+	args := m.Called(payment)
+	return args.Error(0)
+}
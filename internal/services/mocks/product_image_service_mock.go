@@ -0,0 +1,35 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/stretchr/testify/mock"
+)
+
+type ProductImageServiceMock struct {
+	mock.Mock
+}
+
+func NewProductImageServiceMock() *ProductImageServiceMock {
+	return &ProductImageServiceMock{}
+}
+
+func (m *ProductImageServiceMock) UploadProductImage(productID uint, data []byte) (*models.ProductImage, error) {
+	args := m.Called(productID, data)
+	if image, ok := args.Get(0).(*models.ProductImage); ok {
+		return image, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductImageServiceMock) ReorderProductImages(productID uint, imageIDs []uint, primaryImageID uint) ([]models.ProductImage, error) {
+	args := m.Called(productID, imageIDs, primaryImageID)
+	if images, ok := args.Get(0).([]models.ProductImage); ok {
+		return images, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductImageServiceMock) DeleteProductImage(productID uint, imageID uint) error {
+	args := m.Called(productID, imageID)
+	return args.Error(0)
+}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	servicesPkg "github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -36,8 +38,8 @@ func (m *ProductServiceMock) GetProductByID(id uint) (*models.Product, error) {
 	return  nil,args.Error(1)
 }
 
-func (m *ProductServiceMock) GetAllProducts(page uint, limit uint, minPrice int64, maxPrice int64, searchName string, categoryIDs []int,sizeIDs []string) ([]models.Product, int64, error)  {
-	args := m.Called(page, limit,minPrice, maxPrice, searchName, categoryIDs,sizeIDs)
+func (m *ProductServiceMock) GetAllProducts(query dto.ProductListQueryDTO) ([]models.Product, int64, error) {
+	args := m.Called(query)
 
 	var products []models.Product
 	if res,ok := args.Get(0).([]models.Product);ok {
@@ -50,4 +52,57 @@ func (m *ProductServiceMock) GetAllProducts(page uint, limit uint, minPrice int6
 	}
 
 	return  products, pageTotal,args.Error(2)
-}
\ No newline at end of file
+}
+
+func (m *ProductServiceMock) GetVariantBySKU(sku string) (*models.ProductVariant, error) {
+	args := m.Called(sku)
+	if variant, ok := args.Get(0).(*models.ProductVariant); ok {
+		return variant, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductServiceMock) SearchProducts(text string, page uint, limit uint) ([]servicesPkg.ProductSearchResult, int64, error) {
+	args := m.Called(text, page, limit)
+
+	var results []servicesPkg.ProductSearchResult
+	if res, ok := args.Get(0).([]servicesPkg.ProductSearchResult); ok {
+		results = res
+	}
+
+	var pageTotal int64
+	if pt, ok := args.Get(1).(int64); ok {
+		pageTotal = pt
+	}
+
+	return results, pageTotal, args.Error(2)
+}
+
+func (m *ProductServiceMock) GetProductFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error) {
+	args := m.Called(query)
+	if facets, ok := args.Get(0).(*dto.ProductFacetsDTO); ok {
+		return facets, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductServiceMock) GetProductBySlug(slug string) (*models.Product, error) {
+	args := m.Called(slug)
+	if product, ok := args.Get(0).(*models.Product); ok {
+		return product, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductServiceMock) GetCategoryBreadcrumbs(categoryID uint) ([]dto.CategoryBreadcrumbDTO, error) {
+	args := m.Called(categoryID)
+	if breadcrumbs, ok := args.Get(0).([]dto.CategoryBreadcrumbDTO); ok {
+		return breadcrumbs, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ProductServiceMock) BackfillProductSlugs() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReturnServiceMock struct {
+	mock.Mock
+}
+
+func NewReturnServiceMock() *ReturnServiceMock {
+	return &ReturnServiceMock{}
+}
+
+func (m *ReturnServiceMock) CreateReturn(userID uint, orderID uint, req dto.CreateReturnRequestDTO) (*models.ReturnRequest, error) {
+	args := m.Called(userID, orderID, req)
+	if returnRequest, ok := args.Get(0).(*models.ReturnRequest); ok {
+		return returnRequest, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ReturnServiceMock) GetReturnsByUser(userID uint, params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error) {
+	args := m.Called(userID, params)
+	if page, ok := args.Get(0).(pagination.Page[dto.ReturnResponseDTO]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[dto.ReturnResponseDTO]{}, args.Error(1)
+}
+
+func (m *ReturnServiceMock) GetAllReturns(params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error) {
+	args := m.Called(params)
+	if page, ok := args.Get(0).(pagination.Page[dto.ReturnResponseDTO]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[dto.ReturnResponseDTO]{}, args.Error(1)
+}
+
+func (m *ReturnServiceMock) ApproveReturn(adminID uint, id uint, req dto.ApproveReturnDTO) error {
+	args := m.Called(adminID, id, req)
+	return args.Error(0)
+}
+
+func (m *ReturnServiceMock) RejectReturn(id uint, req dto.RejectReturnDTO) error {
+	args := m.Called(id, req)
+	return args.Error(0)
+}
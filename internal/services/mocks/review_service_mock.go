@@ -3,6 +3,7 @@ package services
 import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -14,12 +15,12 @@ func NewReviewServiceMock() *ReviewServiceMock {
 	return &ReviewServiceMock{}
 }
 
-func (m *ReviewServiceMock) GetReviewsByUserID(userIDUint uint) ([]models.Review , error) {
-	args := m.Called(userIDUint)
-	if reviews,ok := args.Get(0).([]models.Review);ok {
-		return reviews,args.Error(1)
+func (m *ReviewServiceMock) GetReviewsByUserID(userIDUint uint, params pagination.Params) (pagination.Page[models.Review], error) {
+	args := m.Called(userIDUint, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Review]); ok {
+		return page, args.Error(1)
 	}
-	return nil,args.Error(1)
+	return pagination.Page[models.Review]{}, args.Error(1)
 }
 
 func (m *ReviewServiceMock)CreateReview(userIDUint uint, req dto.CreateReviewDTO) error {
@@ -28,10 +29,10 @@ func (m *ReviewServiceMock)CreateReview(userIDUint uint, req dto.CreateReviewDTO
 	return args.Error(0)
 }
 
-func (m *ReviewServiceMock) GetReviewAll(productId uint ) (dto.ReviewAllProductSummaryResponse,error) {
-	args := m.Called(productId)
+func (m *ReviewServiceMock) GetReviewAll(productId uint, params pagination.Params) (dto.ReviewAllProductSummaryResponse,error) {
+	args := m.Called(productId, params)
 	if reviews,ok := args.Get(0).(dto.ReviewAllProductSummaryResponse);ok {
 		return reviews,args.Error(1)
 	}
 	return dto.ReviewAllProductSummaryResponse{},args.Error(1)
-}
\ No newline at end of file
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/stretchr/testify/mock"
+)
+
+type ShipmentServiceMock struct {
+	mock.Mock
+}
+
+func NewShipmentServiceMock() *ShipmentServiceMock {
+	return &ShipmentServiceMock{}
+}
+
+func (m *ShipmentServiceMock) CreateShipment(adminID uint, orderID uint, req dto.CreateShipmentDTO) (*models.Shipment, error) {
+	args := m.Called(adminID, orderID, req)
+	if shipment, ok := args.Get(0).(*models.Shipment); ok {
+		return shipment, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentServiceMock) UpdateShipment(adminID uint, shipmentID uint, req dto.UpdateShipmentDTO) (*models.Shipment, error) {
+	args := m.Called(adminID, shipmentID, req)
+	if shipment, ok := args.Get(0).(*models.Shipment); ok {
+		return shipment, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ShipmentServiceMock) GetShipmentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error) {
+	args := m.Called(orderID, params)
+	if page, ok := args.Get(0).(pagination.Page[models.Shipment]); ok {
+		return page, args.Error(1)
+	}
+	return pagination.Page[models.Shipment]{}, args.Error(1)
+}
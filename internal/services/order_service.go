@@ -3,10 +3,13 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"gorm.io/gorm"
@@ -14,24 +17,65 @@ import (
 
 type OrderServiceInterface interface {
 	CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (*models.Order, error)
-	CancelOrderAndRestoreStock( orderID uint) error
+	CancelOrderAndRestoreStock( orderID uint, source StatusSource) error
 	UpdateStatusOrder(orderID *uint, status models.Status,userId uint) error
 	GetOrderByID(orderID uint, userIDUint uint) (*models.Order, error)
-	GetAllOrderByUserId(userIDUint uint) ([]models.Order,error)
+	GetAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order],error)
 	UpdateStatusByUser(userIDUint uint,orderID *uint, status models.Status) error
-	GetAllOrdersAdmin() ([]models.Order,error)
-	UpdateStatusByAdmin(orderID *uint, status models.Status) error
+	GetAllOrdersAdmin(params pagination.Params) (pagination.Page[models.Order],error)
+	UpdateStatusByAdmin(adminID uint, orderID *uint, status models.Status, note string) error
+	UpdateStatusBySystem(orderID uint, status models.Status, source StatusSource, note string) error
+	GetOrderTimeline(orderID uint) ([]dto.OrderStatusEventDTO, error)
+	GetOrderTimelineByUser(userID uint, orderID uint) ([]dto.OrderStatusEventDTO, error)
 	GetDashboardSummary() (*dto.DashboardSummaryDTO, error)
 	GetProductTop() ([]dto.TopProductDTO,error)
 	GetSalesChartData() ([]dto.SalesPerMonthDTO, error)
 	DeleteOrder(id uint) error
-	GetCustomerDetail() ([]dto.CustomerDTO,error)
+	GetCustomerDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO],error)
+	GetPayableOrder(orderID uint, userID uint) (*models.Order, error)
+	SetPaymentIntentID(orderID uint, paymentIntentID string) error
+	GetOrderByPaymentIntentID(paymentIntentID string) (*models.Order, error)
+	ExtendReservation(orderID uint) error
+	ExpireOrders(now time.Time, after ExpirationCursor, limit int) (ExpirationResult, error)
+	NextPaymentExpiry() (*time.Time, error)
 	ValidateAndCalculate(
 	items []dto.CreateOrderItemDTO,
 	variants []models.ProductVariant,
 ) ([]models.OrderItem, float64, error)
 }
 
+var ErrOutOfStock = errors.New("stock not enough")
+
+// NOTE - order ถูกจ่ายหรือยกเลิกไปแล้ว ต่อเวลากัน stock หรือรับชำระเงินไม่ได้
+var ErrOrderNotPending = errors.New("order is not pending")
+
+// NOTE - coupon ใช้กับ order นี้ไม่ได้ handler ตอบเป็น error ของ request ไม่ใช่ 500
+var (
+	ErrCouponNotFound      = errors.New("coupon not found")
+	ErrCouponNotActive     = errors.New("coupon is not yet active")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponMinSubtotal   = errors.New("order subtotal is below the coupon minimum")
+	ErrCouponNotApplicable = errors.New("coupon is not applicable to items in this order")
+	ErrCouponUsageLimit    = errors.New("coupon usage limit reached")
+	ErrCouponUserLimit     = errors.New("coupon usage limit per user reached")
+)
+
+// NOTE - ผลการยกเลิก order ที่หมดเวลาจ่ายในแต่ละรอบ Skipped คือ order ที่ instance อื่นล็อกอยู่หรือถูกจ่ายไปแล้ว
+// Next คือตำแหน่งของ order ตัวสุดท้ายที่ดึงมา ส่งกลับมาใน batch ถัดไปเพื่อข้าม order ที่ทำไปแล้ว
+type ExpirationResult struct {
+	Found     int
+	Cancelled int
+	Skipped   int
+	Failed    int
+	Next      ExpirationCursor
+}
+
+// NOTE - ExpirationCursor ค่าว่างคือเริ่มจาก order ที่หมดเวลานานที่สุด
+type ExpirationCursor struct {
+	PaymentExpireAt time.Time
+	ID              uint
+}
+
 type OrderService struct {
 	db 		    *gorm.DB
 	orderRepo   repositories.OrderRepositoryInterface
@@ -70,6 +114,14 @@ func (s *OrderService) CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (
 		return nil, err
 	}
 
+	//NOTE - ใช้ coupon (ถ้ามี) หักส่วนลดจากยอดรวม
+	coupon, discount, err := s.applyCoupon(req.CouponCode, req.Items, variants, total)
+	if err != nil {
+		return nil, err
+	}
+	total -= discount
+	s.allocateDiscount(orderItems, variants, coupon, discount)
+
 	//NOTE - transaction
 	tx := s.db.Begin()
 	if tx.Error != nil {
@@ -83,17 +135,33 @@ func (s *OrderService) CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (
 		}
 	}()
 
-	//NOTE - ตัด stock ทีละ variant
+	//NOTE - เช็คสิทธิ์ใช้ coupon ที่เหลือใน transaction ก่อนตัด stock
+	if coupon != nil {
+		if err := s.checkCouponLimits(tx, coupon, userID); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	//NOTE - ตัด stock ทีละ variant ใน transaction ถ้ามี order อื่นตัดไปก่อนจน stock ไม่พอจะ rollback ทั้ง order
 	for _, item := range req.Items {
 		productV := s.productUtil.FindProductVariantID(variants, item.VariantID)
-		productV.Stock -= int(item.Quantity)
 
-		if err := s.orderRepo.UpdateProductVariantStock(tx, productV.ID, productV.Stock); err != nil {
+		decremented, err := s.orderRepo.DecrementVariantStock(tx, productV.ID, item.Quantity)
+		if err != nil {
 			tx.Rollback()
 			return nil, errors.New("failed to update product stock")
 		}
+
+		if !decremented {
+			tx.Rollback()
+			return nil, fmt.Errorf("%w: %s size %s", ErrOutOfStock, productV.Product.Name, productV.Size)
+		}
 	}
 
+	//NOTE - กัน stock ไว้ให้ order นี้ตามเวลาที่ตั้งไว้
+	expiresAt := time.Now().Add(ReservationHoldDuration())
+
 	//NOTE - สร้าง order struct
 	order := models.Order{
 		UserID:          userID,
@@ -104,10 +172,15 @@ func (s *OrderService) CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (
 		District:        req.District,
 		Subdistrict:     req.Subdistrict,
 		Zipcode:         req.Zipcode,
+		Discount:        discount,
 		TotalPrice:      total,
 		Status:          models.Pending,
 		OrderItem:       orderItems,
-		PaymentExpireAt: time.Now().Add(10 * time.Second),
+		PaymentExpireAt: expiresAt,
+	}
+
+	if coupon != nil {
+		order.CouponID = &coupon.ID
 	}
 
 	//NOTE - บันทึก order
@@ -116,6 +189,51 @@ func (s *OrderService) CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (
 		return nil, err
 	}
 
+	//NOTE - บันทึกการตัด stock ลง ledger หลังได้ order ID แล้ว
+	for _, item := range order.OrderItem {
+		movement := &models.InventoryMovement{
+			ProductVariantID: item.ProductVariantID,
+			Delta:            -int(item.Quantity),
+			Reason:           models.InventorySale,
+			OrderID:          &order.ID,
+			ActorUserID:      &userID,
+		}
+		if err := s.orderRepo.RecordInventoryMovement(tx, movement); err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record inventory movement")
+		}
+	}
+
+	//NOTE - บันทึกการกัน stock ของแต่ละ variant
+	reservations := []models.StockReservation{}
+	for _, item := range order.OrderItem {
+		reservations = append(reservations, models.StockReservation{
+			OrderID:          order.ID,
+			ProductVariantID: item.ProductVariantID,
+			Quantity:         item.Quantity,
+			Status:           models.ReservationActive,
+			ExpiresAt:        expiresAt,
+		})
+	}
+	if err := s.orderRepo.CreateReservations(tx, reservations); err != nil {
+		tx.Rollback()
+		return nil, errors.New("failed to reserve stock")
+	}
+
+	//NOTE - บันทึกการใช้ coupon ต่อ order เพื่อนับจำนวนครั้งที่ใช้
+	if coupon != nil {
+		redemption := models.CouponRedemption{
+			CouponID:       coupon.ID,
+			OrderID:        order.ID,
+			UserID:         userID,
+			DiscountAmount: discount,
+		}
+		if err := s.orderRepo.CreateCouponRedemption(tx, &redemption); err != nil {
+			tx.Rollback()
+			return nil, errors.New("failed to record coupon redemption")
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
@@ -129,27 +247,105 @@ func (s *OrderService) CreateOrder(userID uint, req dto.CreateOrderRequestDTO) (
 }
 
 
-func (s *OrderService) CancelOrderAndRestoreStock( orderID uint) error {
-	var order models.Order
+// NOTE - ยกเลิก order ที่ยัง pending (หมดเวลาจ่าย หรือ Stripe ยกเลิก intent) แล้วคืน stock
+func (s *OrderService) CancelOrderAndRestoreStock( orderID uint, source StatusSource) error {
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil {
+		return fmt.Errorf("orderRepo.FindOrderById failed: %w", err)
+	}
+
+	if order == nil {
+		return errors.New("order not found")
+	}
 
-	if err := s.db.Preload("OrderItem").First(&order, orderID).Error; err != nil {
+	return s.transitionStatus(order, models.Cancel, statusChange{actor: SystemActor, source: source})
+}
+
+// NOTE - ทุกการเปลี่ยนสถานะ order ต้องผ่านฟังก์ชันนี้ เพื่อเช็คตาราง transition บันทึก timeline และคืน stock เมื่อ order ถูกยกเลิก
+func (s *OrderService) transitionStatus(order *models.Order, to models.Status, change statusChange) error {
+	if err := checkOrderTransition(order.Status, to, change.actor); err != nil {
 		return err
 	}
 
-	if order.Status == models.Pending {
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := applyStatusTransition(tx, s.orderRepo, order, to, change); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	order.Status = to
+	return nil
+}
+
+// NOTE - เปลี่ยนสถานะภายใน transaction ที่ส่งเข้ามา ใช้ร่วมกับ service อื่นที่ต้องเปลี่ยนสถานะพร้อมบันทึกข้อมูลของตัวเอง เช่น shipment
+func applyStatusTransition(tx *gorm.DB, orderRepo repositories.OrderRepositoryInterface, order *models.Order, to models.Status, change statusChange) error {
+	if err := checkOrderTransition(order.Status, to, change.actor); err != nil {
+		return err
+	}
+
+	updated, err := orderRepo.UpdateStatusFrom(tx, order.ID, order.Status, to)
+	if err != nil {
+		return err
+	}
+
+	// NOTE - มี request อื่นเปลี่ยนสถานะไปก่อนแล้ว
+	if !updated {
+		return fmt.Errorf("%w: order status was changed by another request", ErrInvalidStatusTransition)
+	}
+
+	event := &models.OrderStatusEvent{
+		OrderID:     order.ID,
+		FromStatus:  order.Status,
+		ToStatus:    to,
+		ActorUserID: change.actorUserID,
+		ActorRole:   string(change.actor),
+		Source:      string(change.source),
+		Note:        change.note,
+	}
+	if err := orderRepo.CreateStatusEvent(tx, event); err != nil {
+		return err
+	}
+
+	// NOTE - จ่ายเงินแล้ว stock ที่กันไว้ถือว่าขายจริง
+	if to == models.Paid {
+		if err := orderRepo.UpdateReservationStatus(tx, order.ID, models.ReservationActive, models.ReservationCommitted); err != nil {
+			return err
+		}
+	}
+
+	if to == models.Cancel {
 		for _, item := range order.OrderItem {
-			// NOTE - หา order จาก orderID จากนั้นจะทำการ update stock ตาม Quantity ของ oderID ตัวนั้นๆ
-			if err := s.db.Model(&models.ProductVariant{}).
-				Where("id = ?",item.ProductVariantID).
-				Update("stock", gorm.Expr("stock + ?",item.Quantity)).Error; err != nil {
-					return err
-				}
+			if err := orderRepo.RestoreVariantStock(tx, item.ProductVariantID, item.Quantity); err != nil {
+				return err
+			}
+
+			movement := &models.InventoryMovement{
+				ProductVariantID: item.ProductVariantID,
+				Delta:            int(item.Quantity),
+				Reason:           models.InventoryCancel,
+				OrderID:          &order.ID,
+				ActorUserID:      change.actorUserID,
+				Note:             string(change.source),
+			}
+			if err := orderRepo.RecordInventoryMovement(tx, movement); err != nil {
+				return err
+			}
 		}
-		// NOTE -เปลี่ยนสถานะเป็น cancel
-		if err := s.db.Model(&order).Update("status",models.Cancel).Error; err != nil{
+
+		if err := orderRepo.UpdateReservationStatus(tx, order.ID, models.ReservationActive, models.ReservationReleased); err != nil {
 			return err
 		}
 	}
+
 	return nil
 }
 
@@ -174,7 +370,8 @@ func (s *OrderService) UpdateStatusOrder(orderID *uint, status models.Status,use
 		return errors.New("unauthorized to update this order")
 	}
 
-	if err := s.orderRepo.UpdateStatusOrder(orderID,status); err != nil {
+	// NOTE - path นี้ใช้โดย Stripe webhook เท่านั้น จึงเป็น SystemActor
+	if err := s.transitionStatus(order, status, statusChange{actor: SystemActor, source: SourceWebhook}); err != nil {
 		return fmt.Errorf("orderRepo.UpdateStatusOrder failed: %w", err)
 	}
 
@@ -199,12 +396,12 @@ func (s *OrderService) GetOrderByID(orderID uint, userIDUint uint) (*models.Orde
 	return order, nil
 }
 
-func (s *OrderService) GetAllOrderByUserId(userIDUint uint) ([]models.Order,error) {
+func (s *OrderService) GetAllOrderByUserId(userIDUint uint, params pagination.Params) (pagination.Page[models.Order],error) {
 
-	orderAll, err := s.orderRepo.FindAllOrderByUserId(userIDUint)
+	orderAll, err := s.orderRepo.FindAllOrderByUserId(userIDUint, params)
 
 	if err != nil {
-		return nil,errors.New("Error to find to order")
+		return pagination.Page[models.Order]{},errors.New("Error to find to order")
 	}
 
 	return orderAll,nil
@@ -229,7 +426,10 @@ func (s *OrderService) UpdateStatusByUser(userIDUint uint,orderID *uint, status
 		return  errors.New("unauthorized to update this order")
 	}
 
-	if err = s.orderRepo.UpdateStatusOrderByUserId(uint(*orderID),status); err !=nil{
+	if err = s.transitionStatus(order, status, statusChange{actor: UserActor, actorUserID: &userIDUint, source: SourceUser}); err !=nil{
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return err
+		}
 		return errors.New("Order can not update status")
 	}
 
@@ -237,17 +437,17 @@ func (s *OrderService) UpdateStatusByUser(userIDUint uint,orderID *uint, status
 	
 }
 
-func (s *OrderService) GetAllOrdersAdmin() ([]models.Order,error) {
-	orders,err := s.orderRepo.FindAll()
+func (s *OrderService) GetAllOrdersAdmin(params pagination.Params) (pagination.Page[models.Order],error) {
+	orders,err := s.orderRepo.FindAll(params)
 
 	if err != nil {
-		return nil,err
+		return pagination.Page[models.Order]{},err
 	}
 
 	return orders,nil
 }
 
-func (s *OrderService) UpdateStatusByAdmin(orderID *uint, status models.Status) error {
+func (s *OrderService) UpdateStatusByAdmin(adminID uint, orderID *uint, status models.Status, note string) error {
 	if orderID == nil {
 		return errors.New("no order id")
 	}
@@ -262,13 +462,129 @@ func (s *OrderService) UpdateStatusByAdmin(orderID *uint, status models.Status)
 		return errors.New("order not found")
 	}
 
-	if err = s.orderRepo.UpdateStatusOrderByUserId(uint(*orderID),status); err !=nil{
+	if err = s.transitionStatus(order, status, statusChange{actor: AdminActor, actorUserID: &adminID, source: SourceAdmin, note: note}); err !=nil{
+		if errors.Is(err, ErrInvalidStatusTransition) {
+			return err
+		}
 		return errors.New("Order can not update status")
 	}
 
 	return nil
 }
 
+// NOTE - ใช้กับ event จากระบบ เช่น Stripe แจ้งคืนเงิน
+func (s *OrderService) UpdateStatusBySystem(orderID uint, status models.Status, source StatusSource, note string) error {
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil {
+		return fmt.Errorf("orderRepo.FindOrderById failed: %w", err)
+	}
+
+	if order == nil {
+		return errors.New("order not found")
+	}
+
+	// NOTE - Stripe ส่ง event ซ้ำหรือสถานะถูกเปลี่ยนไปแล้ว ไม่ต้องทำอะไร
+	if order.Status == status {
+		return nil
+	}
+
+	return s.transitionStatus(order, status, statusChange{actor: SystemActor, source: source, note: note})
+}
+
+// NOTE - timeline ของ order สำหรับ admin
+func (s *OrderService) GetOrderTimeline(orderID uint) ([]dto.OrderStatusEventDTO, error) {
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil {
+		return nil, fmt.Errorf("orderRepo.FindOrderById failed: %w", err)
+	}
+
+	if order == nil {
+		return nil, errors.New("order not found")
+	}
+
+	return s.buildTimeline(order)
+}
+
+// NOTE - timeline ของ order สำหรับลูกค้า ดูได้เฉพาะ order ของตัวเอง
+func (s *OrderService) GetOrderTimelineByUser(userID uint, orderID uint) ([]dto.OrderStatusEventDTO, error) {
+	order, err := s.GetOrderByID(orderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildTimeline(order)
+}
+
+func (s *OrderService) buildTimeline(order *models.Order) ([]dto.OrderStatusEventDTO, error) {
+	events, err := s.orderRepo.FindStatusEvents(order.ID)
+	if err != nil {
+		return nil, errors.New("Error to get order timeline")
+	}
+
+	// NOTE - ตอนสร้าง order ไม่มี event ให้ใช้เวลาสร้าง order เป็นจุดเริ่มของ timeline
+	timeline := []dto.OrderStatusEventDTO{
+		{
+			ToStatus:    models.Pending,
+			ActorUserID: &order.UserID,
+			ActorRole:   string(UserActor),
+			Source:      string(SourceUser),
+			Note:        "order created",
+			CreatedAt:   order.CreatedAt.Format("2006-01-02 15:04:05"),
+		},
+	}
+
+	for _, event := range events {
+		timeline = append(timeline, dto.OrderStatusEventDTO{
+			FromStatus:  event.FromStatus,
+			ToStatus:    event.ToStatus,
+			ActorUserID: event.ActorUserID,
+			ActorRole:   event.ActorRole,
+			Source:      event.Source,
+			Note:        event.Note,
+			CreatedAt:   event.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return timeline, nil
+}
+
+func (s *OrderService) averageHoursToShip(since time.Time) float64 {
+	var shippedEvents []models.OrderStatusEvent
+	s.db.Where("to_status = ? AND created_at >= ?", models.Shipped, since).Find(&shippedEvents)
+
+	if len(shippedEvents) == 0 {
+		return 0
+	}
+
+	orderIDs := make([]uint, 0, len(shippedEvents))
+	for _, e := range shippedEvents {
+		orderIDs = append(orderIDs, e.OrderID)
+	}
+
+	var paidEvents []models.OrderStatusEvent
+	s.db.Where("to_status = ? AND order_id IN ?", models.Paid, orderIDs).Find(&paidEvents)
+
+	paidAt := make(map[uint]time.Time)
+	for _, e := range paidEvents {
+		paidAt[e.OrderID] = e.CreatedAt
+	}
+
+	var totalHours float64
+	var count int
+	for _, e := range shippedEvents {
+		if paid, ok := paidAt[e.OrderID]; ok {
+			totalHours += e.CreatedAt.Sub(paid).Hours()
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0
+	}
+
+	return totalHours / float64(count)
+}
+
 func percentDiff(current, previous float64) float64 {
 	if previous == 0 {
 		if current > 0 {
@@ -346,6 +662,9 @@ func (s *OrderService) GetDashboardSummary() (*dto.DashboardSummaryDTO, error) {
 		Distinct("user_id").Count(&customersLastMonth)
 
 
+	//NOTE - เวลาเฉลี่ยตั้งแต่จ่ายเงินจนส่งของ ของ order ที่ส่งในเดือนนี้
+	avgHoursToShip := s.averageHoursToShip(startOfThisMonth)
+
 	//NOTE - Growth percent
 	orderGrowth := percentDiff(float64(ordersThisMonth), float64(ordersLastMonth))
 	revenueGrowth := percentDiff(revenueThisMonth, revenueLastMonth)
@@ -366,6 +685,7 @@ func (s *OrderService) GetDashboardSummary() (*dto.DashboardSummaryDTO, error) {
 		StatusPaid:            int(statusPaid),
 		StatusShipped:         int(statusShipped),
 		StatusCancel:          int(statusCancel),
+		AvgHoursToShip:        avgHoursToShip,
 	}
 	
 	return summary, nil
@@ -409,16 +729,183 @@ func ( s *OrderService) DeleteOrder(id uint) error {
 
 }
 
-func (s *OrderService) GetCustomerDetail() ([]dto.CustomerDTO,error){
-	customers,err := s.orderRepo.GetUserDetail()
+func (s *OrderService) GetCustomerDetail(params pagination.Params) (pagination.Page[dto.CustomerDTO],error){
+	customers,err := s.orderRepo.GetUserDetail(params)
 
 	if err != nil {
-		return nil,errors.New("Error to query customer detail")
+		return pagination.Page[dto.CustomerDTO]{},errors.New("Error to query customer detail")
 	}
 
 	return customers,nil
 }
 
+// NOTE - หา order ที่ user คนนี้จ่ายเงินได้ ต้องเป็นเจ้าของ, ยัง pending และยังไม่หมดเวลาจ่าย
+func (s *OrderService) GetPayableOrder(orderID uint, userID uint) (*models.Order, error) {
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil || order == nil {
+		return nil, errors.New("order not found")
+	}
+
+	if order.UserID != userID {
+		return nil, errors.New("unauthorized to pay this order")
+	}
+
+	if order.Status != models.Pending {
+		return nil, errors.New("order is not pending")
+	}
+
+	if !order.PaymentExpireAt.IsZero() && !time.Now().Before(order.PaymentExpireAt) {
+		return nil, errors.New("order payment has expired")
+	}
+
+	return order, nil
+}
+
+func (s *OrderService) SetPaymentIntentID(orderID uint, paymentIntentID string) error {
+	if paymentIntentID == "" {
+		return errors.New("no payment intent id")
+	}
+
+	if err := s.orderRepo.UpdatePaymentIntentID(orderID, paymentIntentID); err != nil {
+		return errors.New("Error saving payment intent")
+	}
+
+	return nil
+}
+
+func (s *OrderService) GetOrderByPaymentIntentID(paymentIntentID string) (*models.Order, error) {
+	if paymentIntentID == "" {
+		return nil, errors.New("no payment intent id")
+	}
+
+	order, err := s.orderRepo.FindByPaymentIntentID(paymentIntentID)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding order: %w", err)
+	}
+
+	// NOTE - ห่อ gorm.ErrRecordNotFound ไว้ให้ webhook แยกกรณีไม่เจอ order ออกจาก error ของ database
+	if order == nil {
+		return nil, fmt.Errorf("order not found: %w", gorm.ErrRecordNotFound)
+	}
+
+	return order, nil
+}
+
+// NOTE - ลูกค้าเริ่มจ่ายเงินผ่าน Stripe ต่อเวลากัน stock ให้ แต่รวมแล้วไม่เกินเวลากันปกติ + เวลาที่ต่อให้
+func (s *OrderService) ExtendReservation(orderID uint) error {
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil || order == nil {
+		return errors.New("order not found")
+	}
+
+	if order.Status != models.Pending {
+		return ErrOrderNotPending
+	}
+
+	extension := CheckoutExtensionDuration()
+	expiresAt := time.Now().Add(extension)
+
+	maxExpiresAt := order.CreatedAt.Add(ReservationHoldDuration() + extension)
+	if expiresAt.After(maxExpiresAt) {
+		expiresAt = maxExpiresAt
+	}
+
+	// NOTE - เวลาเดิมยังเหลือมากกว่า ไม่ต้องต่อ
+	if !expiresAt.After(order.PaymentExpireAt) {
+		return nil
+	}
+
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	extended, err := s.orderRepo.ExtendReservation(tx, order.ID, expiresAt)
+	if err != nil {
+		tx.Rollback()
+		return errors.New("Error extending reservation")
+	}
+
+	// NOTE - job หมดเวลายกเลิกหรือ webhook จ่ายเงินไปก่อนระหว่างที่อ่าน order มา
+	if !extended {
+		tx.Rollback()
+		return ErrOrderNotPending
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	order.PaymentExpireAt = expiresAt
+	return nil
+}
+
+// NOTE - ยกเลิก order ที่หมดเวลาจ่ายทีละไม่เกิน limit รายการ แต่ละ order ใช้ transaction ของตัวเอง
+// order ไหนพังจะไม่ลาก order อื่น rollback ไปด้วย
+func (s *OrderService) ExpireOrders(now time.Time, after ExpirationCursor, limit int) (ExpirationResult, error) {
+	result := ExpirationResult{Next: after}
+
+	orders, err := s.orderRepo.FindExpiredOrders(now, after.PaymentExpireAt, after.ID, limit)
+	if err != nil {
+		return result, fmt.Errorf("orderRepo.FindExpiredOrders failed: %w", err)
+	}
+	result.Found = len(orders)
+
+	for _, order := range orders {
+		result.Next = ExpirationCursor{PaymentExpireAt: order.PaymentExpireAt, ID: order.ID}
+
+		cancelled, err := s.expireOrder(order.ID, now)
+		switch {
+		case err != nil:
+			result.Failed++
+			log.Printf("Failed to cancel expired order %d: %v", order.ID, err)
+		case cancelled:
+			result.Cancelled++
+		default:
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+func (s *OrderService) expireOrder(orderID uint, now time.Time) (bool, error) {
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	order, err := s.orderRepo.LockExpiredOrder(tx, orderID, now)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	// NOTE - instance อื่นกำลังจัดการ order นี้อยู่ หรือ order ถูกจ่าย/ต่อเวลาไปแล้ว
+	if order == nil {
+		tx.Rollback()
+		return false, nil
+	}
+
+	change := statusChange{actor: SystemActor, source: SourceJob, note: "payment window expired"}
+	if err := applyStatusTransition(tx, s.orderRepo, order, models.Cancel, change); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (s *OrderService) NextPaymentExpiry() (*time.Time, error) {
+	return s.orderRepo.FindNextPaymentExpiry()
+}
+
 func (s *OrderService) ValidateAndCalculate(
 	items []dto.CreateOrderItemDTO,
 	variants []models.ProductVariant,
@@ -432,17 +919,167 @@ func (s *OrderService) ValidateAndCalculate(
 			return nil, 0, errors.New("productVariant not found")
 		}
 		if productV.Stock < int(item.Quantity) {
-			return nil, 0, errors.New("stock not enough")
+			return nil, 0, ErrOutOfStock
 		}
 
-		total += (productV.Price - *productV.Product.SalePrice) * float64(item.Quantity)
+		total += FinalUnitPrice(productV.Price, productV.Product) * float64(item.Quantity)
 
 		orderItems = append(orderItems, models.OrderItem{
 			ProductVariantID: productV.ID,
 			Quantity:         item.Quantity,
 			PriceAtPurchase:  productV.Price,
+			NetPrice:         FinalUnitPrice(productV.Price, productV.Product),
 		})
 	}
 
 	return orderItems, total, nil
 }
+
+// NOTE - หา coupon จาก code เช็คเงื่อนไขทั้งหมด แล้วคืนส่วนลดที่หักได้จริง (ไม่เกินยอดของสินค้าที่ร่วมรายการ)
+func (s *OrderService) applyCoupon(
+	code string,
+	items []dto.CreateOrderItemDTO,
+	variants []models.ProductVariant,
+	subtotal float64,
+) (*models.Coupon, float64, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, 0, nil
+	}
+
+	coupon, err := s.orderRepo.FindCouponByCode(code)
+	if err != nil {
+		return nil, 0, errors.New("fail to find coupon")
+	}
+
+	if coupon == nil {
+		return nil, 0, ErrCouponNotFound
+	}
+
+	now := time.Now()
+	if coupon.StartAt != nil && now.Before(*coupon.StartAt) {
+		return nil, 0, ErrCouponNotActive
+	}
+
+	if !coupon.ExpiredAt.IsZero() && now.After(coupon.ExpiredAt) {
+		return nil, 0, ErrCouponExpired
+	}
+
+	if subtotal < coupon.MinOrderAmount {
+		return nil, 0, fmt.Errorf("%w: must be at least %.2f", ErrCouponMinSubtotal, coupon.MinOrderAmount)
+	}
+
+	// NOTE - ถ้า coupon จำกัด category/product ให้คิดส่วนลดจากเฉพาะสินค้าที่ร่วมรายการ
+	eligible := subtotal
+	if len(coupon.Categories) > 0 || len(coupon.Products) > 0 {
+		eligible = 0
+		for _, item := range items {
+			productV := s.productUtil.FindProductVariantID(variants, item.VariantID)
+			if productV == nil || !couponAppliesTo(coupon, productV.Product) {
+				continue
+			}
+
+			eligible += FinalUnitPrice(productV.Price, productV.Product) * float64(item.Quantity)
+		}
+
+		if eligible <= 0 {
+			return nil, 0, ErrCouponNotApplicable
+		}
+	}
+
+	var discount float64
+	switch coupon.DiscountType {
+	case models.PercentageDiscount:
+		discount = eligible * coupon.DiscountAmount / 100
+		if coupon.MaxDiscount > 0 && discount > coupon.MaxDiscount {
+			discount = coupon.MaxDiscount
+		}
+	default:
+		discount = coupon.DiscountAmount
+	}
+
+	if discount > eligible {
+		discount = eligible
+	}
+
+	return coupon, discount, nil
+}
+
+// NOTE - กระจายส่วนลด coupon ลงแต่ละรายการที่ coupon ใช้ได้ตามสัดส่วนยอด NetPrice จึงเป็นราคาต่อชิ้นที่ลูกค้าจ่ายจริง
+func (s *OrderService) allocateDiscount(orderItems []models.OrderItem, variants []models.ProductVariant, coupon *models.Coupon, discount float64) {
+	if coupon == nil || discount <= 0 {
+		return
+	}
+
+	restricted := len(coupon.Categories) > 0 || len(coupon.Products) > 0
+
+	applies := make([]bool, len(orderItems))
+	var eligible float64
+	for i, item := range orderItems {
+		productV := s.productUtil.FindProductVariantID(variants, item.ProductVariantID)
+		applies[i] = productV != nil && (!restricted || couponAppliesTo(coupon, productV.Product))
+		if applies[i] {
+			eligible += item.NetPrice * float64(item.Quantity)
+		}
+	}
+
+	if eligible <= 0 {
+		return
+	}
+
+	for i := range orderItems {
+		if applies[i] {
+			orderItems[i].NetPrice -= orderItems[i].NetPrice * discount / eligible
+		}
+	}
+}
+
+// NOTE - นับจำนวนครั้งที่ใช้ใน transaction เดียวกับที่บันทึก redemption หลังล็อกแถว coupon แล้ว
+// ถ้านับก่อนเริ่ม transaction สอง checkout ที่มาพร้อมกันจะเห็นว่ายังเหลือสิทธิ์และใช้ครั้งสุดท้ายซ้ำกันได้
+func (s *OrderService) checkCouponLimits(tx *gorm.DB, coupon *models.Coupon, userID uint) error {
+	if coupon.MaxRedemptions <= 0 && coupon.MaxRedemptionsPerUser <= 0 {
+		return nil
+	}
+
+	if err := s.orderRepo.LockCoupon(tx, coupon.ID); err != nil {
+		return errors.New("fail to check coupon usage")
+	}
+
+	if coupon.MaxRedemptions > 0 {
+		used, err := s.orderRepo.CountCouponRedemptions(tx, coupon.ID)
+		if err != nil {
+			return errors.New("fail to check coupon usage")
+		}
+		if used >= int64(coupon.MaxRedemptions) {
+			return ErrCouponUsageLimit
+		}
+	}
+
+	if coupon.MaxRedemptionsPerUser > 0 {
+		used, err := s.orderRepo.CountCouponRedemptionsByUser(tx, coupon.ID, userID)
+		if err != nil {
+			return errors.New("fail to check coupon usage")
+		}
+		if used >= int64(coupon.MaxRedemptionsPerUser) {
+			return ErrCouponUserLimit
+		}
+	}
+
+	return nil
+}
+
+func couponAppliesTo(coupon *models.Coupon, product models.Product) bool {
+	for _, p := range coupon.Products {
+		if p.ID == product.ID {
+			return true
+		}
+	}
+
+	for _, c := range coupon.Categories {
+		if c.ID == product.CategoryID {
+			return true
+		}
+	}
+
+	return false
+}
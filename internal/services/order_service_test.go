@@ -8,6 +8,7 @@ import (
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	utils "github.com/Beluga-Whale/ecommerce-api/internal/utils/mocks"
@@ -37,7 +38,7 @@ func TestCreateOrder(t *testing.T) {
 	t.Run("Create order success",func(t *testing.T) {
 		
 		salePrice := 10.0
-		productMock := &models.Product{SalePrice: &salePrice}
+		productMock := &models.Product{IsOnSale: true, SalePrice: &salePrice}
 		
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
@@ -71,8 +72,15 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.On("FindProductVariantByID",mock.Anything).Return([]models.ProductVariant{variantMock})
 		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
 
-		orderRepo.On("UpdateProductVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("DecrementVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(true,nil)
+		orderRepo.On("RecordInventoryMovement",mock.Anything,mock.MatchedBy(func(m *models.InventoryMovement) bool {
+			return m.ProductVariantID == 1 && m.Delta == -2 && m.Reason == models.InventorySale && m.ActorUserID != nil && *m.ActorUserID == 1
+		})).Return(nil)
 		orderRepo.On("Create",mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("CreateReservations",mock.Anything,mock.MatchedBy(func(r []models.StockReservation) bool {
+			return len(r) == 1 && r[0].ProductVariantID == 1 && r[0].Quantity == 2 &&
+				r[0].Status == models.ReservationActive && r[0].ExpiresAt.After(time.Now().Add(14*time.Minute))
+		})).Return(nil)
 		orderRepo.On("FindByIDWithItemsAndProducts",mock.Anything).Return(mockOrder,nil)
 
 		req :=dto.CreateOrderRequestDTO{
@@ -102,6 +110,40 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.AssertExpectations(t)
 	})
 
+	t.Run("Product not on sale is charged full price", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		// NOTE - ไม่ได้ลดราคาและไม่มี sale price ต้องไม่ panic และคิดราคาเต็ม
+		variantMock := models.ProductVariant{
+			Model:   gorm.Model{ID: 1},
+			Stock:   10,
+			Size:    "S",
+			Price:   100.0,
+			Product: models.Product{IsOnSale: false},
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("DecrementVariantStock", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.TotalPrice == 200
+		})).Return(nil)
+		orderRepo.On("CreateReservations", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("FindByIDWithItemsAndProducts", mock.Anything).Return(&models.Order{TotalPrice: 200}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, dto.CreateOrderRequestDTO{
+			Items: []dto.CreateOrderItemDTO{{VariantID: 1, Quantity: 2}},
+		})
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
 	t.Run("Req items is zero",func(t *testing.T) {
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
@@ -161,7 +203,7 @@ func TestCreateOrder(t *testing.T) {
 	t.Run("Error to update productVariant",func(t *testing.T) {
 		
 		salePrice := 10.0
-		productMock := &models.Product{SalePrice: &salePrice}
+		productMock := &models.Product{IsOnSale: true, SalePrice: &salePrice}
 		
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
@@ -183,7 +225,7 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.On("FindProductVariantByID",mock.Anything).Return([]models.ProductVariant{variantMock})
 		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
 
-		orderRepo.On("UpdateProductVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(errors.New("failed to update product stock"))
+		orderRepo.On("DecrementVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(false,errors.New("failed to update product stock"))
 		
 
 		req :=dto.CreateOrderRequestDTO{
@@ -211,10 +253,48 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.AssertExpectations(t)
 	})
 	
+	t.Run("Stock taken by another order",func(t *testing.T) {
+		salePrice := 10.0
+		productMock := &models.Product{Name: "T-Shirt", SalePrice: &salePrice}
+
+		db  := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		// NOTE - ตอนอ่าน stock ยังพอ แต่ตอนตัด stock มี order อื่นตัดไปก่อนแล้ว
+		variantMock := models.ProductVariant{
+			Model:gorm.Model{ID: 1},
+			Stock: 1,
+			Size: "S",
+			Price: 100.0,
+			Product: *productMock,
+		}
+
+		orderService := services.NewOrderService(db,orderRepo,productUtil)
+
+		orderRepo.On("FindProductVariantByID",mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("DecrementVariantStock",mock.Anything,uint(1),uint(1)).Return(false,nil)
+
+		req :=dto.CreateOrderRequestDTO{
+			FullName:    "John Doe",
+			Items: []dto.CreateOrderItemDTO{
+				{VariantID: 1, Quantity: 1},
+			},
+		}
+
+		order,err := orderService.CreateOrder(1,req)
+
+		assert.ErrorIs(t,err,services.ErrOutOfStock)
+		assert.EqualError(t,err,"stock not enough: T-Shirt size S")
+		assert.Nil(t,order)
+		orderRepo.AssertNotCalled(t,"Create",mock.Anything,mock.Anything)
+	})
+
 	t.Run("Error to create order",func(t *testing.T) {
 		
 		salePrice := 10.0
-		productMock := &models.Product{SalePrice: &salePrice}
+		productMock := &models.Product{IsOnSale: true, SalePrice: &salePrice}
 		
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
@@ -235,7 +315,7 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.On("FindProductVariantByID",mock.Anything).Return([]models.ProductVariant{variantMock})
 		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
 
-		orderRepo.On("UpdateProductVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("DecrementVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(true,nil)
 		orderRepo.On("Create",mock.Anything,mock.Anything).Return(errors.New("Error to create order"))
 
 
@@ -267,7 +347,7 @@ func TestCreateOrder(t *testing.T) {
 	t.Run("Error to findOrderById",func(t *testing.T) {
 		
 		salePrice := 10.0
-		productMock := &models.Product{SalePrice: &salePrice}
+		productMock := &models.Product{IsOnSale: true, SalePrice: &salePrice}
 		
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
@@ -288,8 +368,10 @@ func TestCreateOrder(t *testing.T) {
 		orderRepo.On("FindProductVariantByID",mock.Anything).Return([]models.ProductVariant{variantMock})
 		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
 
-		orderRepo.On("UpdateProductVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("DecrementVariantStock",mock.Anything,mock.Anything,mock.Anything).Return(true,nil)
 		orderRepo.On("Create",mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("CreateReservations",mock.Anything,mock.Anything).Return(nil)
+		orderRepo.On("RecordInventoryMovement",mock.Anything,mock.Anything).Return(nil)
 		orderRepo.On("FindByIDWithItemsAndProducts",mock.Anything).Return(nil,errors.New("Error to find order by id"))
 
 		req :=dto.CreateOrderRequestDTO{
@@ -333,11 +415,13 @@ func TestUpdateStatusOrder(t *testing.T) {
 		}
 
 		orderRepo.On("FindOrderById",orderID).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrder",&orderID,models.Status("pending")).Return(nil)
+		orderRepo.On("UpdateStatusFrom",mock.Anything,orderID,models.Pending,models.Paid).Return(true,nil)
+		orderRepo.On("UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		err := orderService.UpdateStatusOrder(&orderID,"pending",1)
+		err := orderService.UpdateStatusOrder(&orderID,"paid",1)
 
 		assert.NoError(t,err)
 
@@ -428,11 +512,11 @@ func TestUpdateStatusOrder(t *testing.T) {
 		}
 
 		orderRepo.On("FindOrderById",orderID).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrder",&orderID,models.Status("pending")).Return(errors.New("orderRepo.UpdateStatusOrder failed"))
+		orderRepo.On("UpdateStatusFrom",mock.Anything,orderID,models.Pending,models.Paid).Return(false,errors.New("orderRepo.UpdateStatusOrder failed"))
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		err := orderService.UpdateStatusOrder(&orderID,"pending",1)
+		err := orderService.UpdateStatusOrder(&orderID,"paid",1)
 
 		assert.Error(t,err)
 		assert.EqualError(t,err,"orderRepo.UpdateStatusOrder failed: orderRepo.UpdateStatusOrder failed")
@@ -554,16 +638,17 @@ func TestGetAllOrderByUserId(t *testing.T) {
 			},
 		}
 
-		orderRepo.On("FindAllOrderByUserId",userId).Return(orderAll,nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("FindAllOrderByUserId",userId,params).Return(pagination.Page[models.Order]{Items: orderAll},nil)
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		orders,err :=orderService.GetAllOrderByUserId(uint(1))
+		orders,err :=orderService.GetAllOrderByUserId(uint(1),params)
 
 		assert.NoError(t,err)
-		assert.Len(t,orders,2)
-		assert.Equal(t, models.Status("pending") , orders[0].Status)
-		assert.Equal(t, models.Status("paid"), orders[1].Status)
+		assert.Len(t,orders.Items,2)
+		assert.Equal(t, models.Status("pending") , orders.Items[0].Status)
+		assert.Equal(t, models.Status("paid"), orders.Items[1].Status)
 
 		orderRepo.AssertExpectations(t)
 	})
@@ -573,11 +658,12 @@ func TestGetAllOrderByUserId(t *testing.T) {
 		productUtil := utils.NewProductUtilMock()
 		orderRepo := repositories.NewOrderRepositoryMock()
 
-		orderRepo.On("FindAllOrderByUserId",userId).Return(nil,errors.New("Error to find to order"))
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("FindAllOrderByUserId",userId,params).Return(nil,errors.New("Error to find to order"))
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		_,err :=orderService.GetAllOrderByUserId(uint(1))
+		_,err :=orderService.GetAllOrderByUserId(uint(1),params)
 
 		assert.EqualError(t,err,"Error to find to order")
 
@@ -599,11 +685,13 @@ func TestUpdateStatusByUser(t *testing.T) {
 		}
 
 		orderRepo.On("FindOrderById",orderId).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrderByUserId",orderId,mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatusFrom",mock.Anything,orderId,models.Pending,models.Cancel).Return(true,nil)
+		orderRepo.On("UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		err := orderService.UpdateStatusByUser(1,&orderId,models.Status("pending"))
+		err := orderService.UpdateStatusByUser(1,&orderId,models.Status("cancel"))
 		assert.NoError(t,err)
 
 		orderRepo.AssertExpectations(t)
@@ -691,11 +779,11 @@ func TestUpdateStatusByUser(t *testing.T) {
 		}
 
 		orderRepo.On("FindOrderById",orderId).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrderByUserId",orderId,models.Status("pending")).Return(errors.New("Order can not update status"))
+		orderRepo.On("UpdateStatusFrom",mock.Anything,orderId,models.Pending,models.Cancel).Return(false,errors.New("db error"))
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		err := orderService.UpdateStatusByUser(1,&orderId,models.Status("pending"))
+		err := orderService.UpdateStatusByUser(1,&orderId,models.Status("cancel"))
 		assert.EqualError(t,err,"Order can not update status")
 
 		orderRepo.AssertExpectations(t)
@@ -714,25 +802,27 @@ func TestGetAllOrdersAdmin(t *testing.T) {
 		productUtil := utils.NewProductUtilMock()
 		orderRepo := repositories.NewOrderRepositoryMock()
 
-		orderRepo.On("FindAll").Return(orderMock,nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("FindAll",params).Return(pagination.Page[models.Order]{Items: orderMock},nil)
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		orders,err := orderService.GetAllOrdersAdmin()
+		orders,err := orderService.GetAllOrdersAdmin(params)
 
 		assert.NoError(t,err)
-		assert.Equal(t,uint(1),orders[0].UserID)
+		assert.Equal(t,uint(1),orders.Items[0].UserID)
 	})
 	t.Run("Error to getAllOrderAdmin",func(t *testing.T) {
 		db  := InitializeDB(t)
 		productUtil := utils.NewProductUtilMock()
 		orderRepo := repositories.NewOrderRepositoryMock()
 
-		orderRepo.On("FindAll").Return(nil,errors.New("Error to find order"))
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("FindAll",params).Return(nil,errors.New("Error to find order"))
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		_,err := orderService.GetAllOrdersAdmin()
+		_,err := orderService.GetAllOrdersAdmin(params)
 
 		assert.EqualError(t,err,"Error to find order")
 	})
@@ -741,12 +831,12 @@ func TestGetAllOrdersAdmin(t *testing.T) {
 func TestUpdateStatusByAdmin(t *testing.T) {
 	t.Run("UpdateStatusByAdmin Success",func(t *testing.T) {
 		orderId := uint(1)
-		status := models.Status("paid")
+		status := models.Status("shipped")
 
 		orderMock := models.Order{
 			Model: gorm.Model{ID: 1},
 			UserID: 1,
-			Status: "complete",
+			Status: "paid",
 		}
 
 		db  := InitializeDB(t)
@@ -754,11 +844,15 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 		orderRepo := repositories.NewOrderRepositoryMock()
 
 		orderRepo.On("FindOrderById",orderId).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrderByUserId",orderId,status).Return(nil)
+		orderRepo.On("UpdateStatusFrom",mock.Anything,orderId,models.Paid,status).Return(true,nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.MatchedBy(func(e *models.OrderStatusEvent) bool {
+			return e.OrderID == orderId && e.FromStatus == models.Paid && e.ToStatus == status &&
+				e.ActorUserID != nil && *e.ActorUserID == 9 && e.ActorRole == "admin" && e.Source == "admin" && e.Note == "sent by Kerry"
+		})).Return(nil)
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		err := orderService.UpdateStatusByAdmin(&orderId,status)
+		err := orderService.UpdateStatusByAdmin(9, &orderId, status, "sent by Kerry")
 
 		assert.NoError(t,err)
 
@@ -772,7 +866,7 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
 		status := models.Status("paid")
-		err := orderService.UpdateStatusByAdmin(nil,status)
+		err := orderService.UpdateStatusByAdmin(1, nil, status, "")
 
 		assert.EqualError(t,err,"no order id")
 
@@ -790,7 +884,7 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 
 		orderId := uint(1)
 		status := models.Status("paid")
-		err := orderService.UpdateStatusByAdmin(&orderId,status)
+		err := orderService.UpdateStatusByAdmin(1, &orderId, status, "")
 
 		assert.EqualError(t,err,"orderRepo.FindByIDWithItemsAndProducts failed: orderRepo.FindByIDWithItemsAndProducts failed")
 
@@ -808,7 +902,7 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 
 		orderId := uint(1)
 		status := models.Status("paid")
-		err := orderService.UpdateStatusByAdmin(&orderId,status)
+		err := orderService.UpdateStatusByAdmin(1, &orderId, status, "")
 
 		assert.EqualError(t,err,"order not found")
 
@@ -819,7 +913,7 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 		orderMock := models.Order{
 			Model: gorm.Model{ID: 1},
 			UserID: 1,
-			Status: "complete",
+			Status: "paid",
 		}
 
 		db  := InitializeDB(t)
@@ -827,13 +921,13 @@ func TestUpdateStatusByAdmin(t *testing.T) {
 		orderRepo := repositories.NewOrderRepositoryMock()
 
 		orderRepo.On("FindOrderById",mock.Anything).Return(&orderMock,nil)
-		orderRepo.On("UpdateStatusOrderByUserId",mock.Anything,mock.Anything).Return(errors.New("Order can not update status"))
+		orderRepo.On("UpdateStatusFrom",mock.Anything,mock.Anything,mock.Anything,mock.Anything).Return(false,errors.New("Order can not update status"))
 
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
 		orderId := uint(1)
-		status := models.Status("paid")
-		err := orderService.UpdateStatusByAdmin(&orderId,status)
+		status := models.Status("shipped")
+		err := orderService.UpdateStatusByAdmin(1, &orderId, status, "")
 
 		assert.EqualError(t,err,"Order can not update status")
 		orderRepo.AssertExpectations(t)
@@ -1052,15 +1146,16 @@ func TestGetCustomerDetail(t *testing.T) {
 		productUtil := utils.NewProductUtilMock()
 		orderRepo := repositories.NewOrderRepositoryMock()
 
-		orderRepo.On("GetUserDetail").Return(customers,nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("GetUserDetail",params).Return(pagination.Page[dto.CustomerDTO]{Items: customers},nil)
 		
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		result,err := orderService.GetCustomerDetail()
+		result,err := orderService.GetCustomerDetail(params)
 
 		assert.NoError(t,err)
-		assert.Equal(t,result[0].Name,"TEST A")
-		assert.Equal(t,result[1].Name,"TEST B")
+		assert.Equal(t,result.Items[0].Name,"TEST A")
+		assert.Equal(t,result.Items[1].Name,"TEST B")
 	})
 
 	t.Run("Error To GetCustomerDetail",func(t *testing.T) {
@@ -1068,13 +1163,775 @@ func TestGetCustomerDetail(t *testing.T) {
 		productUtil := utils.NewProductUtilMock()
 		orderRepo := repositories.NewOrderRepositoryMock()
 
-		orderRepo.On("GetUserDetail").Return(nil,errors.New("Error to query customer detail"))
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		orderRepo.On("GetUserDetail",params).Return(nil,errors.New("Error to query customer detail"))
 		
 		orderService := services.NewOrderService(db,orderRepo,productUtil)
 
-		result,err := orderService.GetCustomerDetail()
+		result,err := orderService.GetCustomerDetail(params)
 
 		assert.EqualError(t,err,"Error to query customer detail")
-		assert.Nil(t,result)
+		assert.Empty(t,result.Items)
+	})
+}
+func TestCreateOrderWithCoupon(t *testing.T) {
+	newReq := func(code string) dto.CreateOrderRequestDTO {
+		return dto.CreateOrderRequestDTO{
+			CouponCode:  code,
+			FullName:    "John Doe",
+			Phone:       "0988888888",
+			Address:     "123 Main St",
+			Province:    "Bangkok",
+			District:    "District",
+			Subdistrict: "Subdistrict",
+			Zipcode:     "10200",
+			Items: []dto.CreateOrderItemDTO{
+				{
+					VariantID: 1,
+					Quantity:  2,
+				},
+			},
+		}
+	}
+
+	newVariant := func() models.ProductVariant {
+		salePrice := 10.0
+		return models.ProductVariant{
+			Model:   gorm.Model{ID: 1},
+			Stock:   10,
+			Size:    "S",
+			Price:   100.0,
+			Product: models.Product{IsOnSale: true, SalePrice: &salePrice},
+		}
+	}
+
+	t.Run("Apply coupon success", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		couponMock := &models.Coupon{
+			Model:          gorm.Model{ID: 7},
+			Code:           "SALE30",
+			DiscountAmount: 30,
+			ExpiredAt:      time.Now().Add(24 * time.Hour),
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "SALE30").Return(couponMock, nil)
+		orderRepo.On("DecrementVariantStock", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		orderRepo.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			// NOTE - ส่วนลด 30 กระจายลงสองชิ้น ราคาที่จ่ายจริงชิ้นละ 90 - 15
+			return o.CouponID != nil && *o.CouponID == 7 && o.Discount == 30 && o.TotalPrice == 150 &&
+				o.OrderItem[0].PriceAtPurchase == 100 && o.OrderItem[0].NetPrice == 75
+		})).Return(nil)
+		orderRepo.On("CreateReservations", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateCouponRedemption", mock.Anything, mock.MatchedBy(func(r *models.CouponRedemption) bool {
+			return r.CouponID == 7 && r.UserID == 1 && r.DiscountAmount == 30
+		})).Return(nil)
+		orderRepo.On("FindByIDWithItemsAndProducts", mock.Anything).Return(&models.Order{TotalPrice: 150}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		order, err := orderService.CreateOrder(1, newReq("SALE30"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 150.0, order.TotalPrice)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Coupon not found", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "UNKNOWN").Return(nil, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		order, err := orderService.CreateOrder(1, newReq("UNKNOWN"))
+
+		assert.EqualError(t, err, "coupon not found")
+		assert.Nil(t, order)
+		orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Coupon has expired", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		couponMock := &models.Coupon{
+			Model:          gorm.Model{ID: 7},
+			Code:           "OLD",
+			DiscountAmount: 30,
+			ExpiredAt:      time.Now().Add(-time.Hour),
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "OLD").Return(couponMock, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		order, err := orderService.CreateOrder(1, newReq("OLD"))
+
+		assert.EqualError(t, err, "coupon has expired")
+		assert.Nil(t, order)
+		orderRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Percentage coupon with max discount", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		couponMock := &models.Coupon{
+			Model:          gorm.Model{ID: 8},
+			Code:           "PCT50",
+			DiscountType:   models.PercentageDiscount,
+			DiscountAmount: 50,
+			MaxDiscount:    40,
+			ExpiredAt:      time.Now().Add(time.Hour),
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "PCT50").Return(couponMock, nil)
+		orderRepo.On("DecrementVariantStock", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		orderRepo.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.Discount == 40 && o.TotalPrice == 140
+		})).Return(nil)
+		orderRepo.On("CreateReservations", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateCouponRedemption", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("FindByIDWithItemsAndProducts", mock.Anything).Return(&models.Order{TotalPrice: 140}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, newReq("PCT50"))
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Minimum order amount not reached", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		couponMock := &models.Coupon{
+			Code:           "BIG",
+			DiscountAmount: 30,
+			MinOrderAmount: 500,
+			ExpiredAt:      time.Now().Add(time.Hour),
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "BIG").Return(couponMock, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, newReq("BIG"))
+
+		assert.ErrorIs(t, err, services.ErrCouponMinSubtotal)
+		assert.EqualError(t, err, "order subtotal is below the coupon minimum: must be at least 500.00")
+	})
+
+	t.Run("Usage limit reached", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		couponMock := &models.Coupon{
+			Model:          gorm.Model{ID: 9},
+			Code:           "ONCE",
+			DiscountAmount: 30,
+			MaxRedemptions: 100,
+			MaxRedemptionsPerUser: 1,
+			ExpiredAt:      time.Now().Add(time.Hour),
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "ONCE").Return(couponMock, nil)
+		orderRepo.On("LockCoupon", mock.Anything, uint(9)).Return(nil)
+		orderRepo.On("CountCouponRedemptions", mock.Anything, uint(9)).Return(int64(3), nil)
+		orderRepo.On("CountCouponRedemptionsByUser", mock.Anything, uint(9), uint(1)).Return(int64(1), nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, newReq("ONCE"))
+
+		assert.EqualError(t, err, "coupon usage limit per user reached")
+		orderRepo.AssertExpectations(t)
+		orderRepo.AssertNotCalled(t, "DecrementVariantStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Category coupon uses full price when product is not on sale", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		variantMock.Product.IsOnSale = false
+		variantMock.Product.CategoryID = 2
+		couponMock := &models.Coupon{
+			Model:          gorm.Model{ID: 7},
+			Code:           "SHIRT10",
+			DiscountType:   models.PercentageDiscount,
+			DiscountAmount: 10,
+			ExpiredAt:      time.Now().Add(time.Hour),
+			Categories:     []models.Category{{Model: gorm.Model{ID: 2}}},
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "SHIRT10").Return(couponMock, nil)
+		orderRepo.On("DecrementVariantStock", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		orderRepo.On("Create", mock.Anything, mock.MatchedBy(func(o *models.Order) bool {
+			return o.Discount == 20 && o.TotalPrice == 180
+		})).Return(nil)
+		orderRepo.On("CreateReservations", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateCouponRedemption", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("FindByIDWithItemsAndProducts", mock.Anything).Return(&models.Order{TotalPrice: 180}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, newReq("SHIRT10"))
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Coupon restricted to other category", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		variantMock := newVariant()
+		variantMock.Product.CategoryID = 1
+		couponMock := &models.Coupon{
+			Code:           "SHOES",
+			DiscountAmount: 30,
+			ExpiredAt:      time.Now().Add(time.Hour),
+			Categories:     []models.Category{{Model: gorm.Model{ID: 2}}},
+		}
+
+		orderRepo.On("FindProductVariantByID", mock.Anything).Return([]models.ProductVariant{variantMock})
+		productUtil.On("FindProductVariantID", mock.Anything, mock.Anything).Return(&variantMock)
+		orderRepo.On("FindCouponByCode", "SHOES").Return(couponMock, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.CreateOrder(1, newReq("SHOES"))
+
+		assert.EqualError(t, err, "coupon is not applicable to items in this order")
 	})
-}
\ No newline at end of file
+}
+
+func TestGetPayableOrder(t *testing.T) {
+	newOrder := func() *models.Order {
+		return &models.Order{
+			Model:           gorm.Model{ID: 1},
+			UserID:          1,
+			Status:          models.Pending,
+			TotalPrice:      250,
+			PaymentExpireAt: time.Now().Add(10 * time.Minute),
+		}
+	}
+
+	t.Run("GetPayableOrder Success", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(newOrder(), nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		order, err := orderService.GetPayableOrder(1, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 250.0, order.TotalPrice)
+	})
+
+	t.Run("Order of other user", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(newOrder(), nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.GetPayableOrder(1, 2)
+
+		assert.EqualError(t, err, "unauthorized to pay this order")
+	})
+
+	t.Run("Order is not pending", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		order := newOrder()
+		order.Status = models.Paid
+		orderRepo.On("FindOrderById", uint(1)).Return(order, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.GetPayableOrder(1, 1)
+
+		assert.EqualError(t, err, "order is not pending")
+	})
+
+	t.Run("Order payment has expired", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		order := newOrder()
+		order.PaymentExpireAt = time.Now().Add(-time.Minute)
+		orderRepo.On("FindOrderById", uint(1)).Return(order, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.GetPayableOrder(1, 1)
+
+		assert.EqualError(t, err, "order payment has expired")
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(9)).Return(nil, errors.New("record not found"))
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.GetPayableOrder(9, 1)
+
+		assert.EqualError(t, err, "order not found")
+	})
+}
+
+func TestSetPaymentIntentID(t *testing.T) {
+	t.Run("SetPaymentIntentID Success", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("UpdatePaymentIntentID", uint(1), "pi_123").Return(nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.SetPaymentIntentID(1, "pi_123")
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("No payment intent id", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.SetPaymentIntentID(1, "")
+
+		assert.EqualError(t, err, "no payment intent id")
+	})
+}
+
+func TestOrderStatusTransition(t *testing.T) {
+	t.Run("User cannot mark own order paid", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderId := uint(1)
+		orderRepo.On("FindOrderById", orderId).Return(&models.Order{Model: gorm.Model{ID: 1}, UserID: 1, Status: models.Pending}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.UpdateStatusByUser(1, &orderId, models.Paid)
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+		orderRepo.AssertNotCalled(t, "UpdateStatusFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Admin cannot reopen cancelled order", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderId := uint(1)
+		orderRepo.On("FindOrderById", orderId).Return(&models.Order{Model: gorm.Model{ID: 1}, Status: models.Cancel}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.UpdateStatusByAdmin(1, &orderId, models.Shipped, "")
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Status changed by another request", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderId := uint(1)
+		orderRepo.On("FindOrderById", orderId).Return(&models.Order{Model: gorm.Model{ID: 1}, Status: models.Paid}, nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, orderId, models.Paid, models.Shipped).Return(false, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.UpdateStatusByAdmin(1, &orderId, models.Shipped, "")
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+	})
+
+	t.Run("Admin cancel paid order restores stock", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderId := uint(1)
+		orderRepo.On("FindOrderById", orderId).Return(&models.Order{
+			Model:  gorm.Model{ID: 1},
+			Status: models.Paid,
+			OrderItem: []models.OrderItem{
+				{ProductVariantID: 3, Quantity: 2},
+				{ProductVariantID: 4, Quantity: 1},
+			},
+		}, nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, orderId, models.Paid, models.Cancel).Return(true, nil)
+		orderRepo.On("UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("RestoreVariantStock", mock.Anything, uint(3), uint(2)).Return(nil)
+		orderRepo.On("RestoreVariantStock", mock.Anything, uint(4), uint(1)).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.UpdateStatusByAdmin(1, &orderId, models.Cancel, "")
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Expired order cancelled by system", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{
+			Model:     gorm.Model{ID: 1},
+			Status:    models.Pending,
+			OrderItem: []models.OrderItem{{ProductVariantID: 3, Quantity: 2}},
+		}, nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Pending, models.Cancel).Return(true, nil)
+		orderRepo.On("UpdateReservationStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("RestoreVariantStock", mock.Anything, uint(3), uint(2)).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.CancelOrderAndRestoreStock(1, services.SourceJob)
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Paid order is not cancelled by expiration", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1}, Status: models.Paid}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.CancelOrderAndRestoreStock(1, services.SourceJob)
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+		orderRepo.AssertNotCalled(t, "RestoreVariantStock", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("System update with same status is a no-op", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1}, Status: models.Refunded}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.UpdateStatusBySystem(1, models.Refunded, services.SourceWebhook, "")
+
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "UpdateStatusFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetOrderTimeline(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	paidAt := createdAt.Add(5 * time.Minute)
+
+	t.Run("Timeline starts with order creation", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1, CreatedAt: createdAt}, UserID: 2, Status: models.Paid}, nil)
+		orderRepo.On("FindStatusEvents", uint(1)).Return([]models.OrderStatusEvent{
+			{Model: gorm.Model{ID: 1, CreatedAt: paidAt}, OrderID: 1, FromStatus: models.Pending, ToStatus: models.Paid, ActorRole: "system", Source: "webhook"},
+		}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		timeline, err := orderService.GetOrderTimelineByUser(2, 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, timeline, 2)
+		assert.Equal(t, models.Pending, timeline[0].ToStatus)
+		assert.Equal(t, "2025-01-01 10:00:00", timeline[0].CreatedAt)
+		assert.Equal(t, models.Paid, timeline[1].ToStatus)
+		assert.Equal(t, "webhook", timeline[1].Source)
+		assert.Equal(t, "2025-01-01 10:05:00", timeline[1].CreatedAt)
+	})
+
+	t.Run("User cannot see timeline of another user", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1}, UserID: 2}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		timeline, err := orderService.GetOrderTimelineByUser(3, 1)
+
+		assert.Error(t, err)
+		assert.Nil(t, timeline)
+		orderRepo.AssertNotCalled(t, "FindStatusEvents", mock.Anything)
+	})
+
+	t.Run("Admin order not found", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(nil, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		timeline, err := orderService.GetOrderTimeline(1)
+
+		assert.EqualError(t, err, "order not found")
+		assert.Nil(t, timeline)
+	})
+}
+
+func TestExtendReservation(t *testing.T) {
+	t.Run("Extend when checkout starts", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		t.Setenv("CHECKOUT_RESERVATION_EXTENSION", "10m")
+
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		now := time.Now()
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{
+			Model:           gorm.Model{ID: 1, CreatedAt: now.Add(-12 * time.Minute)},
+			Status:          models.Pending,
+			PaymentExpireAt: now.Add(3 * time.Minute),
+		}, nil)
+		orderRepo.On("ExtendReservation", mock.Anything, uint(1), mock.MatchedBy(func(at time.Time) bool {
+			return at.After(now.Add(9*time.Minute)) && !at.After(now.Add(10*time.Minute+time.Second))
+		})).Return(true, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.ExtendReservation(1)
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Extension is capped at hold plus extension", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		t.Setenv("CHECKOUT_RESERVATION_EXTENSION", "10m")
+
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		createdAt := time.Now().Add(-20 * time.Minute)
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{
+			Model:           gorm.Model{ID: 1, CreatedAt: createdAt},
+			Status:          models.Pending,
+			PaymentExpireAt: time.Now().Add(time.Minute),
+		}, nil)
+		orderRepo.On("ExtendReservation", mock.Anything, uint(1), createdAt.Add(25*time.Minute)).Return(true, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.ExtendReservation(1)
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already has more time left", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		t.Setenv("CHECKOUT_RESERVATION_EXTENSION", "10m")
+
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{
+			Model:           gorm.Model{ID: 1, CreatedAt: time.Now()},
+			Status:          models.Pending,
+			PaymentExpireAt: time.Now().Add(15 * time.Minute),
+		}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.ExtendReservation(1)
+
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "ExtendReservation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Order cancelled while extending", func(t *testing.T) {
+		t.Setenv("STOCK_RESERVATION_HOLD", "15m")
+		t.Setenv("CHECKOUT_RESERVATION_EXTENSION", "10m")
+
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		// NOTE - อ่านมายัง pending แต่ job หมดเวลายกเลิกไปก่อนจะ update
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{
+			Model:           gorm.Model{ID: 1, CreatedAt: time.Now().Add(-14 * time.Minute)},
+			Status:          models.Pending,
+			PaymentExpireAt: time.Now().Add(time.Minute),
+		}, nil)
+		orderRepo.On("ExtendReservation", mock.Anything, uint(1), mock.Anything).Return(false, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.ExtendReservation(1)
+
+		assert.ErrorIs(t, err, services.ErrOrderNotPending)
+	})
+
+	t.Run("Paid order is not extended", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1}, Status: models.Paid}, nil)
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		err := orderService.ExtendReservation(1)
+
+		assert.EqualError(t, err, "order is not pending")
+	})
+}
+
+func TestExpireOrders(t *testing.T) {
+	now := time.Now()
+
+	expiredOrder := func(id uint) *models.Order {
+		return &models.Order{
+			Model:           gorm.Model{ID: id},
+			Status:          models.Pending,
+			PaymentExpireAt: now.Add(-time.Minute),
+			OrderItem: []models.OrderItem{
+				{ProductVariantID: id + 10, Quantity: 1},
+			},
+		}
+	}
+
+	t.Run("Cancel, skip locked and keep going after failure", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindExpiredOrders", now, time.Time{}, uint(0), 10).Return([]models.Order{
+			{Model: gorm.Model{ID: 1}, PaymentExpireAt: now.Add(-3 * time.Minute)},
+			{Model: gorm.Model{ID: 2}, PaymentExpireAt: now.Add(-2 * time.Minute)},
+			{Model: gorm.Model{ID: 3}, PaymentExpireAt: now.Add(-time.Minute)},
+		}, nil)
+
+		orderRepo.On("LockExpiredOrder", mock.Anything, uint(1), now).Return(expiredOrder(1), nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Pending, models.Cancel).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.MatchedBy(func(e *models.OrderStatusEvent) bool {
+			return e.OrderID == 1 && e.Source == string(services.SourceJob) && e.ActorRole == string(services.SystemActor)
+		})).Return(nil)
+		orderRepo.On("RestoreVariantStock", mock.Anything, uint(11), uint(1)).Return(nil)
+		orderRepo.On("RecordInventoryMovement", mock.Anything, mock.MatchedBy(func(m *models.InventoryMovement) bool {
+			return m.ProductVariantID == 11 && m.Delta == 1 && m.Reason == models.InventoryCancel && *m.OrderID == 1
+		})).Return(nil)
+		orderRepo.On("UpdateReservationStatus", mock.Anything, uint(1), models.ReservationActive, models.ReservationReleased).Return(nil)
+
+		// NOTE - instance อื่นล็อก order นี้อยู่
+		orderRepo.On("LockExpiredOrder", mock.Anything, uint(2), now).Return(nil, nil)
+
+		orderRepo.On("LockExpiredOrder", mock.Anything, uint(3), now).Return(expiredOrder(3), nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(3), models.Pending, models.Cancel).Return(false, errors.New("db error"))
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		result, err := orderService.ExpireOrders(now, services.ExpirationCursor{}, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, services.ExpirationResult{
+			Found:     3,
+			Cancelled: 1,
+			Skipped:   1,
+			Failed:    1,
+			Next:      services.ExpirationCursor{PaymentExpireAt: now.Add(-time.Minute), ID: 3},
+		}, result)
+		orderRepo.AssertExpectations(t)
+		orderRepo.AssertNotCalled(t, "RestoreVariantStock", mock.Anything, uint(13), mock.Anything)
+	})
+
+	t.Run("Find expired orders failed", func(t *testing.T) {
+		db := InitializeDB(t)
+		productUtil := utils.NewProductUtilMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		after := services.ExpirationCursor{PaymentExpireAt: now.Add(-time.Hour), ID: 4}
+		orderRepo.On("FindExpiredOrders", now, after.PaymentExpireAt, uint(4), 10).Return(nil, errors.New("db error"))
+
+		orderService := services.NewOrderService(db, orderRepo, productUtil)
+
+		_, err := orderService.ExpireOrders(now, after, 10)
+
+		assert.Error(t, err)
+		orderRepo.AssertNotCalled(t, "LockExpiredOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+)
+
+type OrderActor string
+
+const (
+	UserActor   OrderActor = "user"
+	AdminActor  OrderActor = "admin"
+	SystemActor OrderActor = "system" // NOTE - Stripe webhook, cron job และระบบคืนเงิน
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid order status transition")
+
+// NOTE - ตารางว่าสถานะไหนเปลี่ยนไปสถานะไหนได้ และใครเป็นคนเปลี่ยนได้บ้าง ถ้าไม่อยู่ในตารางถือว่าเปลี่ยนไม่ได้
+var orderStatusTransitions = map[models.Status]map[models.Status][]OrderActor{
+	models.Pending: {
+		models.Paid:   {SystemActor},
+		models.Cancel: {UserActor, AdminActor, SystemActor},
+	},
+	models.Paid: {
+		models.Shipped:           {AdminActor},
+		models.Cancel:            {AdminActor},
+		models.Refunded:          {SystemActor},
+		models.PartiallyRefunded: {SystemActor},
+	},
+	models.Shipped: {
+		models.Complete:          {UserActor, AdminActor},
+		models.Refunded:          {SystemActor},
+		models.PartiallyRefunded: {SystemActor},
+	},
+	models.Complete: {
+		models.Refunded:          {SystemActor},
+		models.PartiallyRefunded: {SystemActor},
+	},
+	models.PartiallyRefunded: {
+		models.Refunded:          {SystemActor},
+		models.PartiallyRefunded: {SystemActor},
+	},
+}
+
+func CanTransitionOrder(from models.Status, to models.Status, actor OrderActor) bool {
+	for _, allowed := range orderStatusTransitions[from][to] {
+		if allowed == actor {
+			return true
+		}
+	}
+	return false
+}
+
+func checkOrderTransition(from models.Status, to models.Status, actor OrderActor) error {
+	if !CanTransitionOrder(from, to, actor) {
+		return fmt.Errorf("%w: %s cannot change order from %s to %s", ErrInvalidStatusTransition, actor, from, to)
+	}
+	return nil
+}
+
+// NOTE - ที่มาของการเปลี่ยนสถานะ เก็บไว้ใน timeline ของ order
+type StatusSource string
+
+const (
+	SourceUser    StatusSource = "user"
+	SourceAdmin   StatusSource = "admin"
+	SourceWebhook StatusSource = "webhook"
+	SourceJob     StatusSource = "job"
+	SourceReturn  StatusSource = "return"
+)
+
+// NOTE - ข้อมูลว่าใครเปลี่ยนสถานะ order ผ่านช่องทางไหน
+type statusChange struct {
+	actor       OrderActor
+	actorUserID *uint
+	source      StatusSource
+	note        string
+}
@@ -0,0 +1,39 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    models.Status
+		to      models.Status
+		actor   services.OrderActor
+		allowed bool
+	}{
+		{"system marks pending order paid", models.Pending, models.Paid, services.SystemActor, true},
+		{"user cannot mark order paid", models.Pending, models.Paid, services.UserActor, false},
+		{"admin cannot mark order paid", models.Pending, models.Paid, services.AdminActor, false},
+		{"user cancels pending order", models.Pending, models.Cancel, services.UserActor, true},
+		{"user cannot complete pending order", models.Pending, models.Complete, services.UserActor, false},
+		{"admin ships paid order", models.Paid, models.Shipped, services.AdminActor, true},
+		{"user cannot cancel paid order", models.Paid, models.Cancel, services.UserActor, false},
+		{"user completes shipped order", models.Shipped, models.Complete, services.UserActor, true},
+		{"admin cannot reopen cancelled order", models.Cancel, models.Shipped, services.AdminActor, false},
+		{"admin cannot mark refunded without refund", models.Complete, models.Refunded, services.AdminActor, false},
+		{"system refunds completed order", models.Complete, models.Refunded, services.SystemActor, true},
+		{"system refunds again after partial refund", models.PartiallyRefunded, models.PartiallyRefunded, services.SystemActor, true},
+		{"refunded is final", models.Refunded, models.Complete, services.SystemActor, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, services.CanTransitionOrder(tt.from, tt.to, tt.actor))
+		})
+	}
+}
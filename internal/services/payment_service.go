@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+)
+
+type PaymentServiceInterface interface {
+	RecordPayment(payment *models.Payment) error
+	GetPaymentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error)
+	BeginEvent(eventID string, eventType string) (bool, error)
+	ReleaseEvent(eventID string) error
+	GetRefundedAmount(orderID uint) (float64, error)
+	RefundUnpayablePayment(payment *models.Payment) error
+}
+
+type PaymentService struct {
+	paymentRepo     repositories.PaymentRepositoryInterface
+	orderRepo       repositories.OrderRepositoryInterface
+	paymentProvider utils.PaymentProviderInterface
+}
+
+func NewPaymentService(paymentRepo repositories.PaymentRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, paymentProvider utils.PaymentProviderInterface) *PaymentService {
+	return &PaymentService{
+		paymentRepo:     paymentRepo,
+		orderRepo:       orderRepo,
+		paymentProvider: paymentProvider,
+	}
+}
+
+func (s *PaymentService) RecordPayment(payment *models.Payment) error {
+	if payment.OrderID == 0 {
+		return errors.New("no order id")
+	}
+
+	if payment.StripePaymentIntentID == "" {
+		return errors.New("no payment intent id")
+	}
+
+	if err := s.paymentRepo.Create(payment); err != nil {
+		return errors.New("Error recording payment")
+	}
+
+	return nil
+}
+
+func (s *PaymentService) GetPaymentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Payment], error) {
+	// NOTE - เช็คว่า order มีอยู่จริงไหม
+	order, err := s.orderRepo.FindOrderById(orderID)
+	if err != nil || order == nil {
+		return pagination.Page[models.Payment]{}, errors.New("order not found")
+	}
+
+	payments, err := s.paymentRepo.FindByOrderID(orderID, params)
+	if err != nil {
+		return pagination.Page[models.Payment]{}, errors.New("Error to get payments")
+	}
+
+	return payments, nil
+}
+
+// NOTE - คืนค่า false ถ้า event นี้เคยประมวลผลไปแล้ว
+func (s *PaymentService) BeginEvent(eventID string, eventType string) (bool, error) {
+	if eventID == "" {
+		return false, errors.New("no event id")
+	}
+
+	created, err := s.paymentRepo.CreateEventIfNotExists(&models.StripeEvent{
+		EventID: eventID,
+		Type:    eventType,
+	})
+	if err != nil {
+		return false, errors.New("Error recording stripe event")
+	}
+
+	return created, nil
+}
+
+// NOTE - ลบ event ออกถ้าประมวลผลไม่สำเร็จ เพื่อให้ Stripe retry ได้
+func (s *PaymentService) ReleaseEvent(eventID string) error {
+	if err := s.paymentRepo.DeleteEvent(eventID); err != nil {
+		return errors.New("Error releasing stripe event")
+	}
+
+	return nil
+}
+
+func (s *PaymentService) GetRefundedAmount(orderID uint) (float64, error) {
+	total, err := s.paymentRepo.SumRefundedByOrderID(orderID)
+	if err != nil {
+		return 0, errors.New("Error to get refunded amount")
+	}
+
+	return total, nil
+}
+
+// NOTE - ลูกค้าจ่ายเงินสำเร็จหลัง order หมดเวลาหรือถูกยกเลิกไปแล้ว stock ถูกคืนไปแล้ว ต้องคืนเงินเต็มจำนวน
+// บันทึกทั้งการจ่ายและการคืนเงินไว้ ถ้าคืนเงินไม่สำเร็จให้บันทึกการจ่ายพร้อมเหตุผลไว้ให้ admin คืนเงินเอง
+func (s *PaymentService) RefundUnpayablePayment(payment *models.Payment) error {
+	if payment.OrderID == 0 {
+		return errors.New("no order id")
+	}
+
+	if payment.StripePaymentIntentID == "" {
+		return errors.New("no payment intent id")
+	}
+
+	refundID, err := s.paymentProvider.Refund(payment.StripePaymentIntentID, int64(math.Round(payment.Amount*100)), map[string]string{
+		"orderId": strconv.Itoa(int(payment.OrderID)),
+		"reason":  "order_not_payable",
+	})
+	if err != nil {
+		payment.FailureMessage = fmt.Sprintf("order was not awaiting payment, refund failed: %v", err)
+		if err := s.paymentRepo.Create(payment); err != nil {
+			return errors.New("Error recording payment")
+		}
+		return nil
+	}
+
+	payment.FailureMessage = "order was not awaiting payment, refunded " + refundID
+	if err := s.paymentRepo.Create(payment); err != nil {
+		return errors.New("Error recording payment")
+	}
+
+	// NOTE - บันทึกยอดคืนไว้ก่อน charge.refunded จะได้ไม่บันทึกซ้ำ
+	refund := &models.Payment{
+		OrderID:               payment.OrderID,
+		StripePaymentIntentID: payment.StripePaymentIntentID,
+		Amount:                payment.Amount,
+		Currency:              payment.Currency,
+		Status:                models.RefundedPayment,
+	}
+	if err := s.paymentRepo.Create(refund); err != nil {
+		return errors.New("Error recording refund")
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	utils "github.com/Beluga-Whale/ecommerce-api/internal/utils/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestRecordPayment(t *testing.T) {
+	t.Run("RecordPayment Success", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		payment := &models.Payment{OrderID: 1, StripePaymentIntentID: "pi_123", Amount: 100, Status: models.Payed}
+		paymentRepo.On("Create", payment).Return(nil)
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.RecordPayment(payment)
+
+		assert.NoError(t, err)
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("No order id", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.RecordPayment(&models.Payment{StripePaymentIntentID: "pi_123"})
+
+		assert.EqualError(t, err, "no order id")
+		paymentRepo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("No payment intent id", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.RecordPayment(&models.Payment{OrderID: 1})
+
+		assert.EqualError(t, err, "no payment intent id")
+	})
+
+	t.Run("Error recording payment", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		payment := &models.Payment{OrderID: 1, StripePaymentIntentID: "pi_123"}
+		paymentRepo.On("Create", payment).Return(errors.New("db error"))
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.RecordPayment(payment)
+
+		assert.EqualError(t, err, "Error recording payment")
+	})
+}
+
+func TestGetPaymentsByOrderID(t *testing.T) {
+	t.Run("GetPaymentsByOrderID Success", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		payments := []models.Payment{
+			{Model: gorm.Model{ID: 1}, OrderID: 1, StripePaymentIntentID: "pi_1", Status: models.Failed},
+			{Model: gorm.Model{ID: 2}, OrderID: 1, StripePaymentIntentID: "pi_1", Status: models.Payed},
+		}
+
+		orderRepo.On("FindOrderById", uint(1)).Return(&models.Order{Model: gorm.Model{ID: 1}}, nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		paymentRepo.On("FindByOrderID", uint(1), params).Return(pagination.Page[models.Payment]{Items: payments}, nil)
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		result, err := paymentService.GetPaymentsByOrderID(1, params)
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Items, 2)
+		paymentRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Order not found", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("FindOrderById", uint(9)).Return(nil, errors.New("record not found"))
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		result, err := paymentService.GetPaymentsByOrderID(9, pagination.Params{Limit: pagination.DefaultLimit})
+
+		assert.EqualError(t, err, "order not found")
+		assert.Empty(t, result.Items)
+		paymentRepo.AssertNotCalled(t, "FindByOrderID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBeginEvent(t *testing.T) {
+	t.Run("New event", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentRepo.On("CreateEventIfNotExists", &models.StripeEvent{EventID: "evt_1", Type: "payment_intent.succeeded"}).Return(true, nil)
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		isNew, err := paymentService.BeginEvent("evt_1", "payment_intent.succeeded")
+
+		assert.NoError(t, err)
+		assert.True(t, isNew)
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Duplicate event", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentRepo.On("CreateEventIfNotExists", &models.StripeEvent{EventID: "evt_1", Type: "payment_intent.succeeded"}).Return(false, nil)
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		isNew, err := paymentService.BeginEvent("evt_1", "payment_intent.succeeded")
+
+		assert.NoError(t, err)
+		assert.False(t, isNew)
+	})
+
+	t.Run("Error recording stripe event", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentRepo.On("CreateEventIfNotExists", &models.StripeEvent{EventID: "evt_1", Type: "charge.refunded"}).Return(false, errors.New("db error"))
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		isNew, err := paymentService.BeginEvent("evt_1", "charge.refunded")
+
+		assert.EqualError(t, err, "Error recording stripe event")
+		assert.False(t, isNew)
+	})
+}
+
+func TestReleaseEvent(t *testing.T) {
+	t.Run("ReleaseEvent Success", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		paymentRepo.On("DeleteEvent", "evt_1").Return(nil)
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.ReleaseEvent("evt_1")
+
+		assert.NoError(t, err)
+		paymentRepo.AssertExpectations(t)
+	})
+}
+
+func TestRefundUnpayablePayment(t *testing.T) {
+	t.Run("Refund and record both payments", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		paymentProvider := utils.NewFakePaymentProvider()
+
+		payment := &models.Payment{OrderID: 1, StripePaymentIntentID: "pi_123", Amount: 49.99, Currency: "usd", Status: models.Payed}
+		paymentRepo.On("Create", payment).Return(nil).Once()
+		paymentRepo.On("Create", mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.RefundedPayment && p.Amount == 49.99 && p.StripePaymentIntentID == "pi_123"
+		})).Return(nil).Once()
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, paymentProvider)
+
+		err := paymentService.RefundUnpayablePayment(payment)
+
+		assert.NoError(t, err)
+		assert.Len(t, paymentProvider.Refunds, 1)
+		assert.Equal(t, "pi_123", paymentProvider.Refunds[0].PaymentIntentID)
+		assert.Equal(t, int64(4999), paymentProvider.Refunds[0].Amount)
+		assert.Equal(t, "1", paymentProvider.Refunds[0].Metadata["orderId"])
+		assert.Contains(t, payment.FailureMessage, "refunded re_fake_1")
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refund failed is recorded for admin", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		paymentProvider := utils.NewFakePaymentProvider()
+		paymentProvider.Err = errors.New("gateway down")
+
+		payment := &models.Payment{OrderID: 1, StripePaymentIntentID: "pi_123", Amount: 49.99, Status: models.Payed}
+		paymentRepo.On("Create", payment).Return(nil).Once()
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, paymentProvider)
+
+		err := paymentService.RefundUnpayablePayment(payment)
+
+		assert.NoError(t, err)
+		assert.Contains(t, payment.FailureMessage, "refund failed: gateway down")
+		paymentRepo.AssertNumberOfCalls(t, "Create", 1)
+	})
+
+	t.Run("Error recording payment", func(t *testing.T) {
+		paymentRepo := repositories.NewPaymentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		payment := &models.Payment{OrderID: 1, StripePaymentIntentID: "pi_123", Amount: 10}
+		paymentRepo.On("Create", payment).Return(errors.New("db error"))
+
+		paymentService := services.NewPaymentService(paymentRepo, orderRepo, utils.NewFakePaymentProvider())
+
+		err := paymentService.RefundUnpayablePayment(payment)
+
+		assert.EqualError(t, err, "Error recording payment")
+	})
+}
@@ -0,0 +1,14 @@
+package services
+
+import "github.com/Beluga-Whale/ecommerce-api/internal/models"
+
+// NOTE - ราคาต่อชิ้นที่ลูกค้าจ่ายจริง หักส่วนลดเฉพาะตอนสินค้าลดราคาอยู่และไม่ให้ติดลบ
+// ใช้ที่เดียวทั้งตะกร้า checkout coupon และ response ของสินค้า ราคาจะได้ตรงกันทุกที่
+// เงื่อนไขเดียวกับ variantFinalPriceSQL ใน ProductRepository
+func FinalUnitPrice(price float64, product models.Product) float64 {
+	if !product.IsOnSale || product.SalePrice == nil {
+		return price
+	}
+
+	return max(price-*product.SalePrice, 0)
+}
@@ -0,0 +1,29 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFinalUnitPrice(t *testing.T) {
+	salePrice := 30.0
+
+	t.Run("On sale", func(t *testing.T) {
+		assert.Equal(t, 70.0, services.FinalUnitPrice(100, models.Product{IsOnSale: true, SalePrice: &salePrice}))
+	})
+
+	t.Run("Sale price ignored when not on sale", func(t *testing.T) {
+		assert.Equal(t, 100.0, services.FinalUnitPrice(100, models.Product{IsOnSale: false, SalePrice: &salePrice}))
+	})
+
+	t.Run("On sale without sale price", func(t *testing.T) {
+		assert.Equal(t, 100.0, services.FinalUnitPrice(100, models.Product{IsOnSale: true}))
+	})
+
+	t.Run("Never negative", func(t *testing.T) {
+		assert.Equal(t, 0.0, services.FinalUnitPrice(20, models.Product{IsOnSale: true, SalePrice: &salePrice}))
+	})
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"net/http"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+)
+
+const (
+	defaultProductImagesMin  = 1
+	defaultProductImagesMax  = 10
+	defaultProductImageBytes = 5 << 20
+	maxProductImagePixels    = 40_000_000
+	thumbnailJPEGQuality     = 85
+)
+
+// NOTE - upload ล้มเพราะ storage หรือ database ไม่ใช่เพราะไฟล์ที่ส่งมาผิด handler ตอบ 500
+var ErrImageStorage = errors.New("image storage error")
+
+type thumbnailSize struct {
+	Name  string
+	Width int
+}
+
+// NOTE - ความกว้างของรูปย่อแต่ละขนาด ความสูงคำนวณตามสัดส่วนรูปจริง
+var productThumbnailSizes = []thumbnailSize{
+	{Name: "small", Width: 150},
+	{Name: "medium", Width: 400},
+	{Name: "large", Width: 800},
+}
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// NOTE - จำนวนรูปต่อสินค้า ตั้งได้ด้วย PRODUCT_IMAGES_MIN และ PRODUCT_IMAGES_MAX
+func ProductImageLimits() (int, int) {
+	minImages := utils.IntFromEnv("PRODUCT_IMAGES_MIN", defaultProductImagesMin)
+	maxImages := utils.IntFromEnv("PRODUCT_IMAGES_MAX", defaultProductImagesMax)
+
+	if maxImages < minImages {
+		log.Printf("PRODUCT_IMAGES_MAX %d is less than PRODUCT_IMAGES_MIN %d, using %d", maxImages, minImages, minImages)
+		maxImages = minImages
+	}
+
+	return minImages, maxImages
+}
+
+// NOTE - ขนาดไฟล์รูปที่อัปโหลดได้ ตั้งได้ด้วย PRODUCT_IMAGE_MAX_BYTES
+func ProductImageMaxBytes() int {
+	return utils.IntFromEnv("PRODUCT_IMAGE_MAX_BYTES", defaultProductImageBytes)
+}
+
+type ProductImageServiceInterface interface {
+	UploadProductImage(productID uint, data []byte) (*models.ProductImage, error)
+	ReorderProductImages(productID uint, imageIDs []uint, primaryImageID uint) ([]models.ProductImage, error)
+	DeleteProductImage(productID uint, imageID uint) error
+}
+
+type ProductImageService struct {
+	productRepo repositories.ProductRepositoryInterface
+	storage     storage.Storage
+}
+
+func NewProductImageService(productRepo repositories.ProductRepositoryInterface, imageStorage storage.Storage) *ProductImageService {
+	return &ProductImageService{
+		productRepo: productRepo,
+		storage:     imageStorage,
+	}
+}
+
+// NOTE - ดูชนิดไฟล์จากเนื้อไฟล์จริงไม่เชื่อ Content-Type ที่ client ส่งมา เก็บรูปต้นฉบับกับรูปย่อทุกขนาดแล้วค่อยบันทึกลง database
+func (s *ProductImageService) UploadProductImage(productID uint, data []byte) (*models.ProductImage, error) {
+	if len(data) == 0 {
+		return nil, errors.New("Image file is empty")
+	}
+
+	if maxBytes := ProductImageMaxBytes(); len(data) > maxBytes {
+		return nil, fmt.Errorf("Image must not be larger than %d bytes", maxBytes)
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := allowedImageTypes[contentType]
+	if !ok {
+		return nil, errors.New("Only JPEG and PNG images are allowed")
+	}
+
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("Error finding product")
+	}
+
+	if product == nil {
+		return nil, errors.New("Product not found")
+	}
+
+	// NOTE - เช็คก่อนรอบหนึ่งจะได้ไม่ต้องย่อรูปและเก็บไฟล์ทิ้งเปล่า ๆ repository เช็คซ้ำใต้ lock ตอนบันทึก
+	_, maxImages := ProductImageLimits()
+	if len(product.Images) >= maxImages {
+		return nil, fmt.Errorf("A product can have at most %d images", maxImages)
+	}
+
+	// NOTE - เช็คขนาดจาก header ก่อน decode ทั้งรูป กันไฟล์เล็กที่ขยายเป็นรูปใหญ่มากจน memory เต็ม
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("Invalid image file")
+	}
+
+	if config.Width*config.Height > maxProductImagePixels {
+		return nil, errors.New("Image dimensions are too large")
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("Invalid image file")
+	}
+
+	name, err := randomImageName()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+	}
+	keyPrefix := fmt.Sprintf("products/%d/%s", productID, name)
+
+	productImage := &models.ProductImage{
+		ProductID:  productID,
+		StorageKey: keyPrefix + extension,
+	}
+
+	var storedKeys []string
+
+	productImage.URL, err = s.storage.Put(productImage.StorageKey, data, contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+	}
+	storedKeys = append(storedKeys, productImage.StorageKey)
+
+	for _, size := range productThumbnailSizes {
+		thumbnail := utils.ResizeImage(decoded, size.Width)
+
+		encoded, err := encodeImage(thumbnail, contentType)
+		if err != nil {
+			deleteStoredKeys(s.storage, storedKeys)
+			return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+		}
+
+		key := keyPrefix + "-" + size.Name + extension
+		url, err := s.storage.Put(key, encoded, contentType)
+		if err != nil {
+			deleteStoredKeys(s.storage, storedKeys)
+			return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+		}
+		storedKeys = append(storedKeys, key)
+
+		productImage.Thumbnails = append(productImage.Thumbnails, models.ProductImageThumbnail{
+			Size:       size.Name,
+			Width:      thumbnail.Bounds().Dx(),
+			Height:     thumbnail.Bounds().Dy(),
+			URL:        url,
+			StorageKey: key,
+		})
+	}
+
+	added, err := s.productRepo.AddImage(productImage, maxImages)
+	if err != nil {
+		deleteStoredKeys(s.storage, storedKeys)
+		return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+	}
+
+	if !added {
+		deleteStoredKeys(s.storage, storedKeys)
+		return nil, fmt.Errorf("A product can have at most %d images", maxImages)
+	}
+
+	return productImage, nil
+}
+
+// NOTE - imageIDs ต้องมีครบทุกรูปของสินค้าและไม่ซ้ำ primaryImageID เป็น 0 ให้รูปแรกเป็นรูปหลัก
+func (s *ProductImageService) ReorderProductImages(productID uint, imageIDs []uint, primaryImageID uint) ([]models.ProductImage, error) {
+	images, err := s.productRepo.FindImages(productID)
+	if err != nil {
+		return nil, errors.New("Error finding product images")
+	}
+
+	if len(images) == 0 {
+		return nil, errors.New("Product has no images")
+	}
+
+	imagesByID := make(map[uint]models.ProductImage, len(images))
+	for _, image := range images {
+		imagesByID[image.ID] = image
+	}
+
+	seen := make(map[uint]bool, len(imageIDs))
+	for _, imageID := range imageIDs {
+		if _, ok := imagesByID[imageID]; !ok || seen[imageID] {
+			return nil, errors.New("Image list must contain every image of the product exactly once")
+		}
+		seen[imageID] = true
+	}
+
+	if len(imageIDs) != len(images) {
+		return nil, errors.New("Image list must contain every image of the product exactly once")
+	}
+
+	if primaryImageID == 0 {
+		primaryImageID = imageIDs[0]
+	}
+
+	if _, ok := imagesByID[primaryImageID]; !ok {
+		return nil, errors.New("Primary image must be one of the product images")
+	}
+
+	if err := s.productRepo.UpdateImageOrder(productID, imageIDs, primaryImageID); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageStorage, err)
+	}
+
+	reordered := make([]models.ProductImage, 0, len(imageIDs))
+	for position, imageID := range imageIDs {
+		image := imagesByID[imageID]
+		image.Position = position
+		image.IsPrimary = imageID == primaryImageID
+		reordered = append(reordered, image)
+	}
+
+	return reordered, nil
+}
+
+// NOTE - ลบแถวใน database ก่อนค่อยลบไฟล์ ถ้าลบไฟล์ไม่สำเร็จจะเหลือแค่ไฟล์ค้าง ไม่มีสินค้าชี้ไปรูปที่หายไป
+func (s *ProductImageService) DeleteProductImage(productID uint, imageID uint) error {
+	images, err := s.productRepo.FindImages(productID)
+	if err != nil {
+		return errors.New("Error finding product images")
+	}
+
+	var target *models.ProductImage
+	for i := range images {
+		if images[i].ID == imageID {
+			target = &images[i]
+		}
+	}
+
+	if target == nil {
+		return errors.New("Image not found")
+	}
+
+	minImages, _ := ProductImageLimits()
+	if len(images) <= minImages {
+		return fmt.Errorf("A product must keep at least %d images", minImages)
+	}
+
+	// NOTE - repository นับซ้ำใต้ lock ของสินค้า การลบอีกรูปที่ทำพร้อมกันอาจทำให้เหลือถึงขั้นต่ำไปก่อนแล้ว
+	deleted, err := s.productRepo.DeleteImage(productID, imageID, minImages)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrImageStorage, err)
+	}
+
+	if !deleted {
+		return fmt.Errorf("A product must keep at least %d images", minImages)
+	}
+
+	deleteStoredKeys(s.storage, imageStorageKeys([]models.ProductImage{*target}))
+
+	return nil
+}
+
+// NOTE - key ของไฟล์ต้นฉบับและรูปย่อทุกขนาด รูปที่เป็น URL จากภายนอกไม่มี key ก็ข้ามไป
+func imageStorageKeys(images []models.ProductImage) []string {
+	var keys []string
+	for _, image := range images {
+		if image.StorageKey != "" {
+			keys = append(keys, image.StorageKey)
+		}
+		for _, thumbnail := range image.Thumbnails {
+			if thumbnail.StorageKey != "" {
+				keys = append(keys, thumbnail.StorageKey)
+			}
+		}
+	}
+
+	return keys
+}
+
+// NOTE - เรียกหลัง commit แล้วเท่านั้น ลบไฟล์ไม่สำเร็จแค่ log ไว้ ไฟล์ค้างไม่ทำให้สินค้าชี้ไปรูปที่หายไป
+func deleteStoredKeys(imageStorage storage.Storage, keys []string) {
+	for _, key := range keys {
+		if err := imageStorage.Delete(key); err != nil {
+			log.Printf("Failed to delete image %s from storage: %v", key, err)
+		}
+	}
+}
+
+// NOTE - รูปย่อใช้ชนิดเดียวกับต้นฉบับ PNG ยังเก็บพื้นหลังโปร่งใสได้
+func encodeImage(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailJPEGQuality})
+	}
+
+	return buf.Bytes(), err
+}
+
+func randomImageName() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(raw), nil
+}
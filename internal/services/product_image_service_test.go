@@ -0,0 +1,299 @@
+package services_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func testPNG(t *testing.T, width int, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("failed to encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadProductImage(t *testing.T) {
+	product := &models.Product{Model: gorm.Model{ID: 7}, Images: []models.ProductImage{{URL: "a"}}}
+
+	t.Run("Upload success with thumbnails", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		imageStorage := storage.NewMemoryStorage()
+
+		productRepo.On("FindByID", uint(7)).Return(product, nil)
+		productRepo.On("AddImage", mock.AnythingOfType("*models.ProductImage"), 10).Return(true, nil)
+
+		imageService := services.NewProductImageService(productRepo, imageStorage)
+
+		uploaded, err := imageService.UploadProductImage(7, testPNG(t, 1000, 500))
+
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(uploaded.StorageKey, "products/7/"))
+		assert.True(t, strings.HasSuffix(uploaded.StorageKey, ".png"))
+		assert.Equal(t, "/uploads/"+uploaded.StorageKey, uploaded.URL)
+		assert.Len(t, uploaded.Thumbnails, 3)
+		assert.Equal(t, "small", uploaded.Thumbnails[0].Size)
+		assert.Equal(t, 150, uploaded.Thumbnails[0].Width)
+		assert.Equal(t, 75, uploaded.Thumbnails[0].Height)
+		assert.Equal(t, 800, uploaded.Thumbnails[2].Width)
+		assert.Len(t, imageStorage.Keys(), 4)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Small image is not upscaled", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindByID", uint(7)).Return(product, nil)
+		productRepo.On("AddImage", mock.AnythingOfType("*models.ProductImage"), 10).Return(true, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		uploaded, err := imageService.UploadProductImage(7, testPNG(t, 100, 40))
+
+		assert.NoError(t, err)
+		for _, thumbnail := range uploaded.Thumbnails {
+			assert.Equal(t, 100, thumbnail.Width)
+			assert.Equal(t, 40, thumbnail.Height)
+		}
+	})
+
+	t.Run("Only JPEG and PNG allowed", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.UploadProductImage(7, []byte("GIF89a not really an image"))
+
+		assert.EqualError(t, err, "Only JPEG and PNG images are allowed")
+		productRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	t.Run("Image too large", func(t *testing.T) {
+		t.Setenv("PRODUCT_IMAGE_MAX_BYTES", "10")
+		productRepo := repositories.NewProductRepositoryMock()
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.UploadProductImage(7, testPNG(t, 10, 10))
+
+		assert.EqualError(t, err, "Image must not be larger than 10 bytes")
+	})
+
+	t.Run("Product not found", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindByID", uint(7)).Return(nil, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.UploadProductImage(7, testPNG(t, 10, 10))
+
+		assert.EqualError(t, err, "Product not found")
+	})
+
+	t.Run("Max images reached", func(t *testing.T) {
+		t.Setenv("PRODUCT_IMAGES_MAX", "1")
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindByID", uint(7)).Return(product, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.UploadProductImage(7, testPNG(t, 10, 10))
+
+		assert.EqualError(t, err, "A product can have at most 1 images")
+		productRepo.AssertNotCalled(t, "AddImage", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Max images reached by another upload", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		imageStorage := storage.NewMemoryStorage()
+
+		// NOTE - ตอนอ่านสินค้ายังไม่เต็ม แต่ upload อีกตัวบันทึกไปก่อน repository นับใต้ lock แล้วเจอว่าเต็ม
+		productRepo.On("FindByID", uint(7)).Return(product, nil)
+		productRepo.On("AddImage", mock.AnythingOfType("*models.ProductImage"), 10).Return(false, nil)
+
+		imageService := services.NewProductImageService(productRepo, imageStorage)
+
+		_, err := imageService.UploadProductImage(7, testPNG(t, 300, 300))
+
+		assert.EqualError(t, err, "A product can have at most 10 images")
+		assert.Empty(t, imageStorage.Keys())
+	})
+
+	t.Run("Stored files removed when saving fails", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		imageStorage := storage.NewMemoryStorage()
+
+		productRepo.On("FindByID", uint(7)).Return(product, nil)
+		productRepo.On("AddImage", mock.AnythingOfType("*models.ProductImage"), 10).Return(false, errors.New("db down"))
+
+		imageService := services.NewProductImageService(productRepo, imageStorage)
+
+		_, err := imageService.UploadProductImage(7, testPNG(t, 300, 300))
+
+		assert.ErrorIs(t, err, services.ErrImageStorage)
+		assert.Empty(t, imageStorage.Keys())
+	})
+}
+
+func TestReorderProductImages(t *testing.T) {
+	images := []models.ProductImage{
+		{Model: gorm.Model{ID: 1}, Position: 0, IsPrimary: true},
+		{Model: gorm.Model{ID: 2}, Position: 1},
+		{Model: gorm.Model{ID: 3}, Position: 2},
+	}
+
+	t.Run("Reorder success", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+		productRepo.On("UpdateImageOrder", uint(7), []uint{3, 1, 2}, uint(2)).Return(nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		reordered, err := imageService.ReorderProductImages(7, []uint{3, 1, 2}, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), reordered[0].ID)
+		assert.Equal(t, 0, reordered[0].Position)
+		assert.False(t, reordered[1].IsPrimary)
+		assert.True(t, reordered[2].IsPrimary)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("First image becomes primary by default", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+		productRepo.On("UpdateImageOrder", uint(7), []uint{2, 3, 1}, uint(2)).Return(nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		reordered, err := imageService.ReorderProductImages(7, []uint{2, 3, 1}, 0)
+
+		assert.NoError(t, err)
+		assert.True(t, reordered[0].IsPrimary)
+	})
+
+	t.Run("Missing or duplicate image", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.ReorderProductImages(7, []uint{1, 2}, 0)
+		assert.EqualError(t, err, "Image list must contain every image of the product exactly once")
+
+		_, err = imageService.ReorderProductImages(7, []uint{1, 1, 2}, 0)
+		assert.EqualError(t, err, "Image list must contain every image of the product exactly once")
+
+		_, err = imageService.ReorderProductImages(7, []uint{1, 2, 9}, 0)
+		assert.EqualError(t, err, "Image list must contain every image of the product exactly once")
+
+		productRepo.AssertNotCalled(t, "UpdateImageOrder", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Primary image from another product", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		_, err := imageService.ReorderProductImages(7, []uint{1, 2, 3}, 9)
+
+		assert.EqualError(t, err, "Primary image must be one of the product images")
+	})
+}
+
+func TestDeleteProductImage(t *testing.T) {
+	images := []models.ProductImage{
+		{Model: gorm.Model{ID: 1}, StorageKey: "products/7/a.png", Thumbnails: []models.ProductImageThumbnail{{StorageKey: "products/7/a-small.png"}}},
+		{Model: gorm.Model{ID: 2}, StorageKey: "products/7/b.png"},
+	}
+
+	t.Run("Delete success removes stored files", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		imageStorage := storage.NewMemoryStorage()
+		imageStorage.Put("products/7/a.png", []byte("a"), "image/png")
+		imageStorage.Put("products/7/a-small.png", []byte("a"), "image/png")
+		imageStorage.Put("products/7/b.png", []byte("b"), "image/png")
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+		productRepo.On("DeleteImage", uint(7), uint(1), 1).Return(true, nil)
+
+		imageService := services.NewProductImageService(productRepo, imageStorage)
+
+		err := imageService.DeleteProductImage(7, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"products/7/b.png"}, imageStorage.Keys())
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Image not found", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		err := imageService.DeleteProductImage(7, 9)
+
+		assert.EqualError(t, err, "Image not found")
+	})
+
+	t.Run("Must keep minimum images", func(t *testing.T) {
+		t.Setenv("PRODUCT_IMAGES_MIN", "2")
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+
+		imageService := services.NewProductImageService(productRepo, storage.NewMemoryStorage())
+
+		err := imageService.DeleteProductImage(7, 1)
+
+		assert.EqualError(t, err, "A product must keep at least 2 images")
+		productRepo.AssertNotCalled(t, "DeleteImage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Minimum reached by another delete", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		imageStorage := storage.NewMemoryStorage()
+		imageStorage.Put("products/7/a.png", []byte("a"), "image/png")
+
+		// NOTE - ตอนอ่านยังมี 2 รูป แต่อีก request ลบไปก่อน repository นับใต้ lock แล้วเหลือถึงขั้นต่ำ
+		productRepo.On("FindImages", uint(7)).Return(images, nil)
+		productRepo.On("DeleteImage", uint(7), uint(1), 1).Return(false, nil)
+
+		imageService := services.NewProductImageService(productRepo, imageStorage)
+
+		err := imageService.DeleteProductImage(7, 1)
+
+		assert.EqualError(t, err, "A product must keep at least 1 images")
+		assert.Equal(t, []string{"products/7/a.png"}, imageStorage.Keys())
+	})
+
+}
@@ -3,9 +3,15 @@ package services
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
+	"github.com/gosimple/slug"
 )
 
 type ProductServiceInterface interface{
@@ -13,18 +19,35 @@ type ProductServiceInterface interface{
 	UpdateProduct(id uint, product *models.Product) error
 	DeleteProduct(id uint) error
 	GetProductByID(id uint) (*models.Product, error) 
-	GetAllProducts( page uint, limit uint, minPrice int64, maxPrice int64, searchName string, categoryIDs []int,sizeIDs []string) ([]models.Product, int64,error) 
+	GetProductBySlug(slug string) (*models.Product, error)
+	GetCategoryBreadcrumbs(categoryID uint) ([]dto.CategoryBreadcrumbDTO, error)
+	BackfillProductSlugs() error
+	GetVariantBySKU(sku string) (*models.ProductVariant, error)
+	GetAllProducts(query dto.ProductListQueryDTO) ([]models.Product, int64, error)
+	GetProductFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error)
+	SearchProducts(text string, page uint, limit uint) ([]ProductSearchResult, int64, error)
 }
 
 type ProductService struct {
 	productRepo repositories.ProductRepositoryInterface
 	categoryRepo repositories.CategoryInterface
+	searchIndex search.SearchIndex
+	imageStorage storage.Storage
 }
 
-func NewProductService(productRepo repositories.ProductRepositoryInterface, categoryRepo repositories.CategoryInterface) *ProductService {
+// NOTE - สินค้าที่ค้นเจอพร้อมคะแนนความเกี่ยวข้องและข้อความที่ highlight คำค้นไว้
+type ProductSearchResult struct {
+	Product    models.Product
+	Score      float64
+	Highlights map[string]string
+}
+
+func NewProductService(productRepo repositories.ProductRepositoryInterface, categoryRepo repositories.CategoryInterface, searchIndex search.SearchIndex, imageStorage storage.Storage) *ProductService {
 	return &ProductService{
 		productRepo: productRepo,
 		categoryRepo: categoryRepo,
+		searchIndex: searchIndex,
+		imageStorage: imageStorage,
 	}
 }
 
@@ -69,8 +92,8 @@ func (s *ProductService) CreateProduct(product *models.Product) error {
 	}
 
 
-	if len(product.Images) != 3 {
-		return errors.New("You must upload exactly 3 product images")
+	if err := normalizeProductImages(product.Images); err != nil {
+		return err
 	}
 
 	category,err := s.categoryRepo.FindByID(product.CategoryID)
@@ -83,10 +106,26 @@ func (s *ProductService) CreateProduct(product *models.Product) error {
 		return errors.New("Category not found")
 	}
 
+	if err := s.validateSKUs(0, product.Variants); err != nil {
+		return err
+	}
+
+	product.Slug, err = s.uniqueProductSlug(product.Name, 0)
+	if err != nil {
+		return err
+	}
+
 	err = s.productRepo.Create(product)
+	if errors.Is(err, repositories.ErrCategoryNotFound) {
+		return err
+	}
+
 	if err != nil {
 		return fmt.Errorf("Error creating product: %w", err)
 	}
+
+	product.Category = *category
+	s.indexProduct(product)
 	return nil
 }
 
@@ -99,8 +138,8 @@ func (s *ProductService) UpdateProduct(id uint, product *models.Product) error {
 		return errors.New("Please provide title product")
 	}
 
-	if len(product.Images) != 3 {
-		return errors.New("You must upload exactly 3 product images")
+	if err := normalizeProductImages(product.Images); err != nil {
+		return err
 	}
 
 	if product.IsOnSale {
@@ -153,28 +192,31 @@ func (s *ProductService) UpdateProduct(id uint, product *models.Product) error {
 		return errors.New("Category not found")
 	}
 
-	var variantsUpdate = []models.ProductVariant{}
+	variantsUpdate, retiredVariantIDs, err := diffProductVariants(existingProduct.Variants, product.Variants)
+	if err != nil {
+		return err
+	}
 
-	for _, v := range product.Variants {
-		variantsUpdate = append(variantsUpdate, models.ProductVariant{
-			Size: v.Size,
-			Stock: v.Stock,
-			SKU: v.SKU,
-			Price: v.Price,
-		})
+	if err := s.validateSKUs(id, product.Variants); err != nil {
+		return err
 	}
 
-	var urlUpdate =[]models.ProductImage{}
-	for _, i := range product.Images{
-		urlUpdate = append(urlUpdate, models.ProductImage{
-			URL: i.URL,
-		})
+	// NOTE - สร้าง slug ใหม่เฉพาะตอนชื่อเปลี่ยน แก้อย่างอื่นแล้ว URL ต้องคงเดิม
+	if existingProduct.Slug == "" || slug.Make(product.Name) != slug.Make(existingProduct.Name) {
+		existingProduct.Slug, err = s.uniqueProductSlug(product.Name, id)
+		if err != nil {
+			return err
+		}
 	}
 
+	imagesUpdate := diffProductImages(existingProduct.Images, product.Images)
+
 	existingProduct.Name = product.Name
 	existingProduct.Title = product.Title
 	existingProduct.Description = product.Description
-	existingProduct.Images = urlUpdate
+	existingProduct.MetaTitle = product.MetaTitle
+	existingProduct.MetaDescription = product.MetaDescription
+	existingProduct.Images = imagesUpdate
 	existingProduct.IsFeatured = product.IsFeatured
 	existingProduct.IsOnSale = product.IsOnSale
 	existingProduct.SalePrice = product.SalePrice
@@ -182,13 +224,288 @@ func (s *ProductService) UpdateProduct(id uint, product *models.Product) error {
 	existingProduct.Variants = variantsUpdate
 	
 
-	err = s.productRepo.Update(existingProduct)
+	removedImages, err := s.productRepo.Update(existingProduct, retiredVariantIDs)
+	if errors.Is(err, repositories.ErrCategoryNotFound) {
+		return err
+	}
+
 	if err != nil {
 		return errors.New("Error updating product")
 	}
+
+	// NOTE - รูปที่ไม่ได้ส่งมาถูกลบออกจาก database แล้ว ลบไฟล์ต้นฉบับกับรูปย่อตามแบบเดียวกับ DeleteProductImage
+	deleteStoredKeys(s.imageStorage, imageStorageKeys(removedImages))
+
+	existingProduct.Category = *category
+	s.indexProduct(existingProduct)
+
+	// NOTE - ส่ง variant ที่มี id แล้วกลับไปให้ handler ตอบกลับ
+	product.Variants = existingProduct.Variants
+	product.Images = existingProduct.Images
+	product.Slug = existingProduct.Slug
+	return nil
+}
+
+// NOTE - slug จากชื่อสินค้า ถ้าซ้ำกับสินค้าอื่นหรือ slug เก่าที่ยัง redirect อยู่จะต่อท้ายด้วย -2, -3, ...
+// productID เป็น 0 ตอนสร้างสินค้าใหม่ ตอนแก้ไขสินค้าเอา slug เดิมของตัวเองกลับมาใช้ได้
+func (s *ProductService) uniqueProductSlug(name string, productID uint) (string, error) {
+	base := slug.Make(name)
+	if base == "" {
+		base = "product"
+	}
+
+	existing, err := s.productRepo.FindSlugsWithPrefix(base, productID)
+	if err != nil {
+		return "", fmt.Errorf("Error generating product slug: %w", err)
+	}
+
+	taken := make(map[string]bool)
+	for _, v := range existing {
+		taken[v] = true
+	}
+
+	candidate := base
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	return candidate, nil
+}
+
+// NOTE - หาจาก slug ปัจจุบันก่อน ถ้าไม่เจอค่อยหาจาก slug เก่า product ที่ได้มี Slug เป็นค่าปัจจุบันให้ handler เช็คว่าต้อง redirect ไหม
+func (s *ProductService) GetProductBySlug(productSlug string) (*models.Product, error) {
+	product, err := s.productRepo.FindBySlug(productSlug)
+	if err != nil {
+		return nil, errors.New("Error finding product")
+	}
+
+	if product != nil {
+		return product, nil
+	}
+
+	productID, err := s.productRepo.FindProductIDBySlugRedirect(productSlug)
+	if err != nil {
+		return nil, errors.New("Error finding product")
+	}
+
+	if productID == 0 {
+		return nil, nil
+	}
+
+	product, err = s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("Error finding product")
+	}
+
+	return product, nil
+}
+
+// NOTE - breadcrumbs ของหน้าสินค้า เรียงจาก category บนสุดลงมาถึง category ของสินค้า
+func (s *ProductService) GetCategoryBreadcrumbs(categoryID uint) ([]dto.CategoryBreadcrumbDTO, error) {
+	categories, err := s.categoryRepo.FindAncestors(categoryID)
+	if err != nil {
+		return nil, errors.New("Error retrieving category breadcrumbs")
+	}
+
+	breadcrumbs := make([]dto.CategoryBreadcrumbDTO, 0, len(categories))
+	for _, category := range categories {
+		breadcrumbs = append(breadcrumbs, dto.CategoryBreadcrumbDTO{
+			ID:   category.ID,
+			Name: category.Name,
+			Slug: category.Slug,
+		})
+	}
+
+	return breadcrumbs, nil
+}
+
+// NOTE - สินค้าที่สร้างก่อนมี slug ให้สร้าง slug ย้อนหลังตอน start server
+func (s *ProductService) BackfillProductSlugs() error {
+	products, err := s.productRepo.FindWithoutSlug()
+	if err != nil {
+		return fmt.Errorf("Error finding products without slug: %w", err)
+	}
+
+	for _, product := range products {
+		productSlug, err := s.uniqueProductSlug(product.Name, product.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.productRepo.UpdateSlug(product.ID, productSlug); err != nil {
+			return fmt.Errorf("Error updating product slug: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NOTE - SKU ห้ามซ้ำกันเองในสินค้าเดียวกัน และห้ามซ้ำกับ variant ที่ยังขายอยู่ของสินค้าอื่น
+// productID เป็น 0 ตอนสร้างสินค้าใหม่
+func (s *ProductService) validateSKUs(productID uint, variants []models.ProductVariant) error {
+	seen := make(map[string]bool)
+
+	for _, v := range variants {
+		if v.SKU == "" {
+			continue
+		}
+
+		if seen[v.SKU] {
+			return fmt.Errorf("Duplicate SKU '%s' in variants", v.SKU)
+		}
+		seen[v.SKU] = true
+
+		existing, err := s.productRepo.FindVariantBySKU(v.SKU)
+		if err != nil {
+			return fmt.Errorf("Error finding SKU: %w", err)
+		}
+
+		if existing != nil && (productID == 0 || existing.ProductID != productID) {
+			return fmt.Errorf("SKU '%s' already exists", v.SKU)
+		}
+	}
+
+	return nil
+}
+
+func (s *ProductService) GetVariantBySKU(sku string) (*models.ProductVariant, error) {
+	variant, err := s.productRepo.FindVariantBySKU(sku)
+	if err != nil {
+		return nil, errors.New("Error finding product variant")
+	}
+
+	return variant, nil
+}
+
+// NOTE - จับคู่ variant ที่ส่งมากับของเดิมด้วย VariantId ก่อน ถ้าไม่ได้ส่ง id มาจะจับคู่ด้วย SKU
+// ตัวที่จับคู่ได้จะแก้ไขในแถวเดิมเพื่อให้ id คงเดิม order/cart/review ที่อ้างถึงยังใช้ได้
+// ตัวที่ไม่มีคู่จะสร้างใหม่ ส่วนของเดิมที่ไม่ได้ส่งมาจะถูกเลิกขาย
+// stock ที่ส่งมาใช้เป็น stock เริ่มต้นของ variant ใหม่เท่านั้น variant เดิมแก้ stock ผ่าน inventory adjust ที่ใช้ส่วนต่าง
+// ไม่อย่างนั้นของที่ขายหรือจองไประหว่างที่ admin เปิดฟอร์มค้างไว้จะถูกทับ
+func diffProductVariants(existing []models.ProductVariant, incoming []models.ProductVariant) ([]models.ProductVariant, []uint, error) {
+	existingByID := make(map[uint]models.ProductVariant)
+	for _, v := range existing {
+		existingByID[v.ID] = v
+	}
+
+	matched := make(map[uint]bool)
+	variants := make([]models.ProductVariant, len(incoming))
+
+	for i, v := range incoming {
+		if v.ID == 0 {
+			continue
+		}
+
+		current, ok := existingByID[v.ID]
+		if !ok {
+			return nil, nil, fmt.Errorf("Variant %d does not belong to this product", v.ID)
+		}
+
+		if matched[v.ID] {
+			return nil, nil, fmt.Errorf("Variant %d is duplicated", v.ID)
+		}
+		matched[v.ID] = true
+
+		current.Size = v.Size
+		current.SKU = v.SKU
+		current.Price = v.Price
+		variants[i] = current
+	}
+
+	for i, v := range incoming {
+		if v.ID != 0 {
+			continue
+		}
+
+		variants[i] = models.ProductVariant{
+			Size:  v.Size,
+			Stock: v.Stock,
+			SKU:   v.SKU,
+			Price: v.Price,
+		}
+
+		if v.SKU == "" {
+			continue
+		}
+
+		for _, current := range existing {
+			if current.ID == 0 || matched[current.ID] || current.SKU != v.SKU {
+				continue
+			}
+
+			matched[current.ID] = true
+			current.Size = v.Size
+			current.Price = v.Price
+			variants[i] = current
+			break
+		}
+	}
+
+	retired := []uint{}
+	for _, v := range existing {
+		if v.ID != 0 && !matched[v.ID] {
+			retired = append(retired, v.ID)
+		}
+	}
+
+	return variants, retired, nil
+}
+
+// NOTE - จำนวนรูปต้องอยู่ในช่วงที่ตั้งไว้ ลำดับใน list คือลำดับแสดง และต้องมีรูปหลักรูปเดียว
+func normalizeProductImages(images []models.ProductImage) error {
+	minImages, maxImages := ProductImageLimits()
+	if len(images) < minImages || len(images) > maxImages {
+		return fmt.Errorf("You must upload between %d and %d product images", minImages, maxImages)
+	}
+
+	primaryCount := 0
+	for i := range images {
+		images[i].Position = i
+		if images[i].IsPrimary {
+			primaryCount++
+		}
+	}
+
+	if primaryCount > 1 {
+		return errors.New("Only one product image can be the primary image")
+	}
+
+	if primaryCount == 0 {
+		images[0].IsPrimary = true
+	}
+
 	return nil
 }
 
+// NOTE - รูปที่ URL ตรงกับรูปเดิมใช้แถวเดิม รูปที่อัปโหลดผ่านระบบจะไม่เสีย thumbnail กับ storage key ตอนแก้สินค้า
+func diffProductImages(existing []models.ProductImage, incoming []models.ProductImage) []models.ProductImage {
+	existingByURL := make(map[string][]models.ProductImage)
+	for _, image := range existing {
+		existingByURL[image.URL] = append(existingByURL[image.URL], image)
+	}
+
+	images := make([]models.ProductImage, 0, len(incoming))
+	for _, image := range incoming {
+		if matches := existingByURL[image.URL]; len(matches) > 0 {
+			matched := matches[0]
+			existingByURL[image.URL] = matches[1:]
+
+			matched.Position = image.Position
+			matched.IsPrimary = image.IsPrimary
+			images = append(images, matched)
+			continue
+		}
+
+		images = append(images, models.ProductImage{
+			URL:       image.URL,
+			Position:  image.Position,
+			IsPrimary: image.IsPrimary,
+		})
+	}
+
+	return images
+}
+
 func (s *ProductService) DeleteProduct(id uint) error {
 	existingProduct, err := s.productRepo.FindByID(id)
 	if err != nil {
@@ -202,9 +519,20 @@ func (s *ProductService) DeleteProduct(id uint) error {
 	if err != nil {
 		return errors.New("Error deleting product")
 	}
+
+	if err := s.searchIndex.RemoveProduct(id); err != nil {
+		log.Printf("Error removing product %d from search index: %v", id, err)
+	}
 	return nil
 }
 
+// NOTE - index พังไม่ทำให้บันทึกสินค้าล้มเหลว สินค้ายังอยู่ใน database และ index ใหม่ได้ตอนแก้ไขครั้งถัดไป
+func (s *ProductService) indexProduct(product *models.Product) {
+	if err := s.searchIndex.IndexProduct(search.DocumentFromProduct(product)); err != nil {
+		log.Printf("Error indexing product %d for search: %v", product.ID, err)
+	}
+}
+
 func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
 	existingProduct, err := s.productRepo.FindByID(id)
 	if err != nil {
@@ -216,11 +544,67 @@ func (s *ProductService) GetProductByID(id uint) (*models.Product, error) {
 	return existingProduct, nil
 }
 
-func (s *ProductService) GetAllProducts( page uint, limit uint, minPrice int64, maxPrice int64, searchName string, categoryIDs []int,sizeIDs []string) ([]models.Product, int64,error) {
-	products,pageTotal, err := s.productRepo.FindAll(page,limit,minPrice,maxPrice,searchName ,categoryIDs,sizeIDs)
+func (s *ProductService) GetAllProducts(query dto.ProductListQueryDTO) ([]models.Product, int64, error) {
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return nil, 0, errors.New("minPrice must be less than maxPrice")
+	}
+
+	products, pageTotal, err := s.productRepo.FindAll(query)
+	if err != nil {
+		return nil, 0, errors.New("Error retrieving products")
+	}
+	return products, pageTotal, nil
+}
+
+func (s *ProductService) GetProductFacets(query dto.ProductListQueryDTO) (*dto.ProductFacetsDTO, error) {
+	facets, err := s.productRepo.FindFacets(query)
 	if err != nil {
-		return nil, 0,errors.New("Error retrieving products")
+		return nil, errors.New("Error retrieving product facets")
 	}
-	return products,pageTotal, nil
+	return facets, nil
 }
 
+func (s *ProductService) SearchProducts(text string, page uint, limit uint) ([]ProductSearchResult, int64, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, 0, errors.New("Please provide search query")
+	}
+
+	offset := (page - 1) * limit
+	hits, total, err := s.searchIndex.Search(text, int(limit), int(offset))
+	if err != nil {
+		return nil, 0, errors.New("Error searching products")
+	}
+
+	ids := make([]uint, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.ProductID)
+	}
+
+	products, err := s.productRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, 0, errors.New("Error retrieving products")
+	}
+
+	productByID := make(map[uint]models.Product)
+	for _, product := range products {
+		productByID[product.ID] = product
+	}
+
+	// NOTE - เรียงตามคะแนนจาก index ข้ามสินค้าที่ถูกลบไปแล้วแต่ index ยังไม่อัปเดต
+	results := []ProductSearchResult{}
+	for _, hit := range hits {
+		product, ok := productByID[hit.ProductID]
+		if !ok {
+			continue
+		}
+
+		results = append(results, ProductSearchResult{
+			Product:    product,
+			Score:      hit.Score,
+			Highlights: hit.Highlights,
+		})
+	}
+
+	pageTotal := (total + int64(limit) - 1) / int64(limit)
+	return results, pageTotal, nil
+}
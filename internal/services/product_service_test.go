@@ -4,9 +4,13 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	repositoryPkg "github.com/Beluga-Whale/ecommerce-api/internal/repositories"
 	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
@@ -49,13 +53,17 @@ func TestCreateProduct(t *testing.T) {
 			Name: "Test Category",
 		}, nil)
 
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
 		productRepo.On("Create", product).Return(nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
 		assert.NoError(t, err)
+		assert.True(t, product.Images[0].IsPrimary)
+		assert.Equal(t, 2, product.Images[2].Position)
 
 		// NOTE - เช็คว่ามีการ Call function ไหม
 		productRepo.AssertExpectations(t)
@@ -93,7 +101,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -135,7 +143,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -176,7 +184,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -218,7 +226,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -287,7 +295,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		for _, item := range cases {
 			t.Run(item.name,func(t *testing.T) {
@@ -323,17 +331,13 @@ func TestCreateProduct(t *testing.T) {
 		}
 	})
 
-	t.Run("Image less than 3 ", func(t *testing.T) {
+	t.Run("No images", func(t *testing.T) {
 		salePrice := 50.0
 		product := &models.Product{
 			Name: "T-shirt",
 			Title: "Title",
 			Description: "Test Description",
-			Images: []models.ProductImage{
-				{
-					URL: "test",
-				},
-			},
+			Images: []models.ProductImage{},
 			IsOnSale:    true,
 			SalePrice:   &salePrice,
 			CategoryID:  1,
@@ -349,12 +353,12 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
 		assert.Error(t, err)
-		assert.EqualError(t,err,"You must upload exactly 3 product images")
+		assert.EqualError(t,err,"You must upload between 1 and 10 product images")
 
 		// NOTE - เช็คว่ามีการ Call function ไหม
 		productRepo.AssertExpectations(t)
@@ -362,6 +366,36 @@ func TestCreateProduct(t *testing.T) {
 
 	})
 
+	t.Run("More than one primary image", func(t *testing.T) {
+		salePrice := 50.0
+		product := &models.Product{
+			Name: "T-shirt",
+			Title: "Title",
+			Description: "Test Description",
+			Images: []models.ProductImage{{URL: "a", IsPrimary: true}, {URL: "b", IsPrimary: true}},
+			IsOnSale:    true,
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+			Variants: []models.ProductVariant{
+				{
+					Size:  "M",
+					Price: 100.0,
+					Stock: 10,
+				},
+			},
+		}
+
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.CreateProduct(product)
+
+		assert.EqualError(t,err,"Only one product image can be the primary image")
+		productRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
 	t.Run("Can't to find category",func(t *testing.T) {
 		productCategoryID := uint(1)
 		salePrice := 50.0
@@ -395,7 +429,7 @@ func TestCreateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		categoryRepo.On("FindByID", productCategoryID ).Return(&models.Category{
 			Name: "Test Category",
@@ -446,7 +480,7 @@ func TestCreateProduct(t *testing.T) {
 		categoryRepo.On("FindByID", productCategoryID ).Return(nil, nil)
 
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -493,9 +527,11 @@ func TestCreateProduct(t *testing.T) {
 			Name: "Test Category",
 		}, nil)
 
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
 		productRepo.On("Create", product).Return(errors.New("Error creating product"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.CreateProduct(product)
 
@@ -507,6 +543,39 @@ func TestCreateProduct(t *testing.T) {
 		categoryRepo.AssertExpectations(t)
 
 	})
+
+	t.Run("Category deleted before product is saved", func(t *testing.T) {
+		salePrice := 50.0
+		product := &models.Product{
+			Name: "T-shirt",
+			Title: "Title",
+			Description: "Test Description",
+			Images: []models.ProductImage{
+				{URL: "test"}, {URL: "test"}, {URL: "test"},
+			},
+			IsOnSale:   true,
+			SalePrice:  &salePrice,
+			CategoryID: 1,
+			Variants: []models.ProductVariant{
+				{Size: "M", Price: 100.0, Stock: 10},
+			},
+		}
+
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Name: "Test Category"}, nil)
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Create", product).Return(repositoryPkg.ErrCategoryNotFound)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.CreateProduct(product)
+
+		assert.EqualError(t, err, "Category not found")
+		productRepo.AssertExpectations(t)
+	})
 }
 
 func TestUpdateProduct(t *testing.T) {
@@ -548,9 +617,11 @@ func TestUpdateProduct(t *testing.T) {
 			Name: "Test Category",
 		}, nil)
 
-		productRepo.On("Update", product).Return(nil)
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Update", product, mock.Anything).Return(nil, nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -562,6 +633,57 @@ func TestUpdateProduct(t *testing.T) {
 
 	})
 
+	t.Run("Removed images are deleted from storage", func(t *testing.T) {
+		salePrice := 0.0
+		existing := &models.Product{
+			Model:       gorm.Model{ID: 1},
+			Name:        "T-shirt",
+			Title:       "Title",
+			Description: "Test Description",
+			Slug:        "t-shirt",
+			Images: []models.ProductImage{
+				{Model: gorm.Model{ID: 1}, URL: "/uploads/products/1/a.png", StorageKey: "products/1/a.png"},
+				{Model: gorm.Model{ID: 2}, URL: "/uploads/products/1/b.png", StorageKey: "products/1/b.png",
+					Thumbnails: []models.ProductImageThumbnail{{StorageKey: "products/1/b-small.png"}}},
+			},
+			SalePrice:  &salePrice,
+			CategoryID: 1,
+			Variants:   []models.ProductVariant{{Model: gorm.Model{ID: 10}, Size: "M", Price: 100, Stock: 10}},
+		}
+
+		imageStorage := storage.NewMemoryStorage()
+		imageStorage.Put("products/1/a.png", []byte("a"), "image/png")
+		imageStorage.Put("products/1/b.png", []byte("b"), "image/png")
+		imageStorage.Put("products/1/b-small.png", []byte("b"), "image/png")
+
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindByID", uint(1)).Return(existing, nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		// NOTE - repository คืนรูปที่ลบออกจาก database พร้อม thumbnail มาให้ลบไฟล์
+		productRepo.On("Update", mock.MatchedBy(func(p *models.Product) bool {
+			return len(p.Images) == 1 && p.Images[0].ID == 1
+		}), []uint{}).Return([]models.ProductImage{existing.Images[1]}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), imageStorage)
+
+		err := productService.UpdateProduct(1, &models.Product{
+			Name:        "T-shirt",
+			Title:       "Title",
+			Description: "Test Description",
+			Images:      []models.ProductImage{{URL: "/uploads/products/1/a.png"}},
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+			Variants:    []models.ProductVariant{{Model: gorm.Model{ID: 10}, Size: "M", Price: 100, Stock: 10}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"products/1/a.png"}, imageStorage.Keys())
+		productRepo.AssertExpectations(t)
+	})
+
 	t.Run("Please provide product name and description", func(t *testing.T) {
 		salePrice := 50.0
 		product := &models.Product{
@@ -592,7 +714,7 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -634,7 +756,7 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -646,17 +768,15 @@ func TestUpdateProduct(t *testing.T) {
 
 	})
 
-	t.Run("Image is equal 3", func(t *testing.T) {
+	t.Run("More images than configured max", func(t *testing.T) {
+		t.Setenv("PRODUCT_IMAGES_MAX", "2")
+
 		salePrice := 50.0
 		product := &models.Product{
 			Name: "T-shirt",
 			Title: "Title",
 			Description: "Test Description",
-			Images: []models.ProductImage{
-				{
-					URL: "test",
-				},
-			},
+			Images: []models.ProductImage{{URL: "a"}, {URL: "b"}, {URL: "c"}},
 			IsOnSale:    true,
 			SalePrice:   &salePrice,
 			CategoryID:  1,
@@ -672,11 +792,11 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
-		assert.EqualError(t, err,"You must upload exactly 3 product images")
+		assert.EqualError(t, err,"You must upload between 1 and 2 product images")
 
 	})
 
@@ -709,7 +829,7 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -751,7 +871,7 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -820,7 +940,7 @@ func TestUpdateProduct(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		for _, item := range cases {
 			t.Run(item.name,func(t *testing.T) {
@@ -888,7 +1008,7 @@ func TestUpdateProduct(t *testing.T) {
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,errors.New("Error finding product"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -933,7 +1053,7 @@ func TestUpdateProduct(t *testing.T) {
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -980,7 +1100,7 @@ func TestUpdateProduct(t *testing.T) {
 
 		categoryRepo.On("FindByID", mock.Anything ).Return(nil, errors.New("Error finding category"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -1027,7 +1147,7 @@ func TestUpdateProduct(t *testing.T) {
 
 		categoryRepo.On("FindByID", mock.Anything ).Return(nil, nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -1072,9 +1192,11 @@ func TestUpdateProduct(t *testing.T) {
 			Name: "Test Category",
 		}, nil)
 
-		productRepo.On("Update", mock.Anything).Return(errors.New(" deleting product"))
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Update", mock.Anything, mock.Anything).Return(nil, errors.New(" deleting product"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.UpdateProduct(1,product)
 
@@ -1087,6 +1209,387 @@ func TestUpdateProduct(t *testing.T) {
 	})
 }
 
+func TestUpdateProductVariants(t *testing.T) {
+	newProduct := func(variants ...models.ProductVariant) *models.Product {
+		salePrice := 0.0
+		return &models.Product{
+			Model:       gorm.Model{ID: 1},
+			Name:        "T-shirt",
+			Title:       "Title",
+			Description: "Test Description",
+			Images:      []models.ProductImage{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+			Variants:    variants,
+		}
+	}
+
+	existing := func() *models.Product {
+		return newProduct(
+			models.ProductVariant{Model: gorm.Model{ID: 10}, ProductID: 1, Size: "S", SKU: "TS-S", Stock: 3, Price: 100},
+			models.ProductVariant{Model: gorm.Model{ID: 11}, ProductID: 1, Size: "M", SKU: "TS-M", Stock: 5, Price: 100},
+			models.ProductVariant{Model: gorm.Model{ID: 12}, ProductID: 1, Size: "L", SKU: "TS-L", Stock: 1, Price: 100},
+		)
+	}
+
+	t.Run("Update in place by id and sku, add new and retire removed", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindByID", uint(1)).Return(existing(), nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Name: "Test Category"}, nil)
+		productRepo.On("FindVariantBySKU", mock.Anything).Return(nil, nil).Maybe()
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Update", mock.MatchedBy(func(p *models.Product) bool {
+			return len(p.Variants) == 3 &&
+				p.Variants[0].ID == 10 && p.Variants[0].Stock == 3 &&
+				p.Variants[1].ID == 11 && p.Variants[1].Price == 120 &&
+				p.Variants[2].ID == 0 && p.Variants[2].SKU == "TS-XL"
+		}), []uint{12}).Return(nil, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product := newProduct(
+			models.ProductVariant{Model: gorm.Model{ID: 10}, Size: "S", SKU: "TS-S", Stock: 7, Price: 100},
+			models.ProductVariant{Size: "M", SKU: "TS-M", Stock: 5, Price: 120},
+			models.ProductVariant{Size: "XL", SKU: "TS-XL", Stock: 2, Price: 100},
+		)
+
+		err := productService.UpdateProduct(1, product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(10), product.Variants[0].ID)
+		assert.Equal(t, uint(11), product.Variants[1].ID)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Variant of other product", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindByID", uint(1)).Return(existing(), nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Name: "Test Category"}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.UpdateProduct(1, newProduct(
+			models.ProductVariant{Model: gorm.Model{ID: 99}, Size: "S", SKU: "TS-S", Stock: 1, Price: 100},
+		))
+
+		assert.EqualError(t, err, "Variant 99 does not belong to this product")
+		productRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Same variant sent twice", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindByID", uint(1)).Return(existing(), nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Name: "Test Category"}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.UpdateProduct(1, newProduct(
+			models.ProductVariant{Model: gorm.Model{ID: 10}, Size: "S", SKU: "TS-S", Stock: 1, Price: 100},
+			models.ProductVariant{Model: gorm.Model{ID: 10}, Size: "S", SKU: "TS-S", Stock: 2, Price: 100},
+		))
+
+		assert.EqualError(t, err, "Variant 10 is duplicated")
+	})
+}
+
+func TestProductSKU(t *testing.T) {
+	newProduct := func(skus ...string) *models.Product {
+		salePrice := 0.0
+		product := &models.Product{
+			Name:        "T-shirt",
+			Title:       "Title",
+			Description: "Test Description",
+			Images:      []models.ProductImage{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+		}
+		for _, sku := range skus {
+			product.Variants = append(product.Variants, models.ProductVariant{Size: sku, SKU: sku, Price: 100, Stock: 5})
+		}
+		return product
+	}
+
+	t.Run("Duplicate SKU in request", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindVariantBySKU", "TS-M").Return(nil, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.CreateProduct(newProduct("TS-M", "TS-M"))
+
+		assert.EqualError(t, err, "Duplicate SKU 'TS-M' in variants")
+		productRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("SKU already used by another product", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindVariantBySKU", "TS-M").Return(&models.ProductVariant{Model: gorm.Model{ID: 3}, ProductID: 2, SKU: "TS-M"}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.CreateProduct(newProduct("TS-M"))
+
+		assert.EqualError(t, err, "SKU 'TS-M' already exists")
+		productRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+
+	t.Run("Update keeps SKU of its own variant", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		existing := newProduct("TS-M")
+		existing.ID = 1
+		existing.Variants[0].ID = 3
+		existing.Variants[0].ProductID = 1
+
+		productRepo.On("FindByID", uint(1)).Return(existing, nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindVariantBySKU", "TS-M").Return(&models.ProductVariant{Model: gorm.Model{ID: 3}, ProductID: 1, SKU: "TS-M"}, nil)
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Update", mock.Anything, []uint{}).Return(nil, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.UpdateProduct(1, newProduct("TS-M"))
+
+		assert.NoError(t, err)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update rejects SKU of another product", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		existing := newProduct()
+		existing.ID = 1
+
+		productRepo.On("FindByID", uint(1)).Return(existing, nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindVariantBySKU", "TS-L").Return(&models.ProductVariant{Model: gorm.Model{ID: 8}, ProductID: 2, SKU: "TS-L"}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.UpdateProduct(1, newProduct("TS-L"))
+
+		assert.EqualError(t, err, "SKU 'TS-L' already exists")
+		productRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("GetVariantBySKU Success", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindVariantBySKU", "TS-M").Return(&models.ProductVariant{Model: gorm.Model{ID: 3}, SKU: "TS-M"}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		variant, err := productService.GetVariantBySKU("TS-M")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), variant.ID)
+	})
+
+	t.Run("GetVariantBySKU Error", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindVariantBySKU", "TS-M").Return(nil, errors.New("db error"))
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		_, err := productService.GetVariantBySKU("TS-M")
+
+		assert.EqualError(t, err, "Error finding product variant")
+	})
+}
+
+func TestProductSlug(t *testing.T) {
+	newProduct := func(name string) *models.Product {
+		salePrice := 0.0
+		return &models.Product{
+			Name:        name,
+			Title:       "Title",
+			Description: "Test Description",
+			Images:      []models.ProductImage{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+		}
+	}
+
+	t.Run("Create adds suffix when slug is taken", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindSlugsWithPrefix", "classic-tee", uint(0)).Return([]string{"classic-tee", "classic-tee-2", "classic-tee-blue"}, nil)
+		productRepo.On("Create", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Slug == "classic-tee-3"
+		})).Return(nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.CreateProduct(newProduct("Classic Tee"))
+
+		assert.NoError(t, err)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update keeps slug when name is unchanged", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		existing := newProduct("Classic Tee")
+		existing.ID = 1
+		existing.Slug = "classic-tee-2"
+
+		productRepo.On("FindByID", uint(1)).Return(existing, nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("Update", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Slug == "classic-tee-2"
+		}), []uint{}).Return(nil, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product := newProduct("Classic  TEE")
+		err := productService.UpdateProduct(1, product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "classic-tee-2", product.Slug)
+		productRepo.AssertNotCalled(t, "FindSlugsWithPrefix", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Update regenerates slug on rename", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		existing := newProduct("Classic Tee")
+		existing.ID = 1
+		existing.Slug = "classic-tee"
+
+		productRepo.On("FindByID", uint(1)).Return(existing, nil)
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}}, nil)
+		productRepo.On("FindSlugsWithPrefix", "oversized-tee", uint(1)).Return([]string{}, nil)
+		productRepo.On("Update", mock.MatchedBy(func(p *models.Product) bool {
+			return p.Slug == "oversized-tee"
+		}), []uint{}).Return(nil, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product := newProduct("Oversized Tee")
+		err := productService.UpdateProduct(1, product)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "oversized-tee", product.Slug)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Get by current slug", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindBySlug", "classic-tee").Return(&models.Product{Model: gorm.Model{ID: 1}, Slug: "classic-tee"}, nil)
+
+		productService := services.NewProductService(productRepo, repositories.NewCategoryRepositoryMock(), search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product, err := productService.GetProductBySlug("classic-tee")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), product.ID)
+		productRepo.AssertNotCalled(t, "FindProductIDBySlugRedirect", mock.Anything)
+	})
+
+	t.Run("Get by old slug", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindBySlug", "classic-tee").Return(nil, nil)
+		productRepo.On("FindProductIDBySlugRedirect", "classic-tee").Return(uint(1), nil)
+		productRepo.On("FindByID", uint(1)).Return(&models.Product{Model: gorm.Model{ID: 1}, Slug: "oversized-tee"}, nil)
+
+		productService := services.NewProductService(productRepo, repositories.NewCategoryRepositoryMock(), search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product, err := productService.GetProductBySlug("classic-tee")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "oversized-tee", product.Slug)
+	})
+
+	t.Run("Unknown slug", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindBySlug", "missing").Return(nil, nil)
+		productRepo.On("FindProductIDBySlugRedirect", "missing").Return(uint(0), nil)
+
+		productService := services.NewProductService(productRepo, repositories.NewCategoryRepositoryMock(), search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		product, err := productService.GetProductBySlug("missing")
+
+		assert.NoError(t, err)
+		assert.Nil(t, product)
+		productRepo.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	t.Run("Backfill products without slug", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+
+		productRepo.On("FindWithoutSlug").Return([]models.Product{{Model: gorm.Model{ID: 4}, Name: "Classic Tee"}}, nil)
+		productRepo.On("FindSlugsWithPrefix", "classic-tee", uint(4)).Return([]string{"classic-tee"}, nil)
+		productRepo.On("UpdateSlug", uint(4), "classic-tee-2").Return(nil)
+
+		productService := services.NewProductService(productRepo, repositories.NewCategoryRepositoryMock(), search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		err := productService.BackfillProductSlugs()
+
+		assert.NoError(t, err)
+		productRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetCategoryBreadcrumbs(t *testing.T) {
+	t.Run("Breadcrumbs from root to product category", func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindAncestors", uint(3)).Return([]models.Category{
+			{Model: gorm.Model{ID: 1}, Name: "Men", Slug: "men"},
+			{Model: gorm.Model{ID: 3}, Name: "Shirts", Slug: "shirts"},
+		}, nil)
+
+		productService := services.NewProductService(repositories.NewProductRepositoryMock(), categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		breadcrumbs, err := productService.GetCategoryBreadcrumbs(3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []dto.CategoryBreadcrumbDTO{
+			{ID: 1, Name: "Men", Slug: "men"},
+			{ID: 3, Name: "Shirts", Slug: "shirts"},
+		}, breadcrumbs)
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Error to find ancestors", func(t *testing.T) {
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		categoryRepo.On("FindAncestors", uint(3)).Return(nil, errors.New("db error"))
+
+		productService := services.NewProductService(repositories.NewProductRepositoryMock(), categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		breadcrumbs, err := productService.GetCategoryBreadcrumbs(3)
+
+		assert.EqualError(t, err, "Error retrieving category breadcrumbs")
+		assert.Nil(t, breadcrumbs)
+	})
+}
+
 func TestDeleteProduct(t *testing.T) {
 	t.Run("Delete Success", func(t *testing.T) {
 		salePrice := 50.0
@@ -1121,7 +1624,7 @@ func TestDeleteProduct(t *testing.T) {
 		productRepo.On("FindByID", mock.Anything).Return(product,nil)
 		productRepo.On("Delete", uint(1)).Return(nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.DeleteProduct(1)
 
@@ -1139,7 +1642,7 @@ func TestDeleteProduct(t *testing.T) {
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,errors.New("Error finding product"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.DeleteProduct(1)
 
@@ -1158,7 +1661,7 @@ func TestDeleteProduct(t *testing.T) {
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,nil)
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.DeleteProduct(1)
 
@@ -1204,7 +1707,7 @@ func TestDeleteProduct(t *testing.T) {
 		productRepo.On("FindByID", mock.Anything).Return(product,nil)
 		productRepo.On("Delete", mock.Anything).Return(errors.New("Error deleting product"))
 
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		err := productService.DeleteProduct(1)
 
@@ -1250,7 +1753,7 @@ func TestGetProductByID(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 		productRepo.On("FindByID", mock.Anything).Return(product,nil)
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		_,err := productService.GetProductByID(1)
 
@@ -1266,7 +1769,7 @@ func TestGetProductByID(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,errors.New("Error finding product"))
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		_,err := productService.GetProductByID(1)
 
@@ -1284,7 +1787,7 @@ func TestGetProductByID(t *testing.T) {
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 
 		productRepo.On("FindByID", mock.Anything).Return(nil,nil)
-		productService := services.NewProductService(productRepo, categoryRepo)
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
 		_,err := productService.GetProductByID(1)
 
@@ -1300,6 +1803,19 @@ func TestGetProductByID(t *testing.T) {
 }
 
 func TestGetAllProduct(t *testing.T) {
+	minPrice := 0.0
+	maxPrice := 1000.0
+	query := dto.ProductListQueryDTO{
+		Page:        1,
+		Limit:       10,
+		MinPrice:    &minPrice,
+		MaxPrice:    &maxPrice,
+		SearchName:  "shirt",
+		CategoryIDs: []int{1, 2},
+		Sizes:       []string{"M", "L"},
+		Sort:        dto.ProductSortPriceAsc,
+	}
+
 	t.Run("GetAllProducts Success", func(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
@@ -1310,12 +1826,12 @@ func TestGetAllProduct(t *testing.T) {
 		mockPageTotal := int64(5)
 
 		productRepo.
-			On("FindAll", uint(1), uint(10), int64(0), int64(1000), "shirt", []int{1, 2}, []string{"M", "L"}).
+			On("FindAll", query).
 			Return(mockProducts, mockPageTotal, nil)
 
-		service := services.NewProductService(productRepo, categoryRepo)
+		service := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
-		products, pageTotal, err := service.GetAllProducts(1, 10, 0, 1000, "shirt", []int{1, 2}, []string{"M", "L"})
+		products, pageTotal, err := service.GetAllProducts(query)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockProducts, products)
@@ -1324,19 +1840,167 @@ func TestGetAllProduct(t *testing.T) {
 		productRepo.AssertExpectations(t)
 	})
 
-	t.Run("GetAllProducts Success", func(t *testing.T) {
+	t.Run("GetAllProducts Error", func(t *testing.T) {
 		productRepo := repositories.NewProductRepositoryMock()
 		categoryRepo := repositories.NewCategoryRepositoryMock()
 		productRepo.
-			On("FindAll", uint(1), uint(10), int64(0), int64(1000), "shirt", []int{1, 2}, []string{"M", "L"}).
+			On("FindAll", query).
 			Return(nil, nil, errors.New("Error retrieving products"))
 
-		service := services.NewProductService(productRepo, categoryRepo)
+		service := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
 
-		_, _, err := service.GetAllProducts(1, 10, 0, 1000, "shirt", []int{1, 2}, []string{"M", "L"})
+		_, _, err := service.GetAllProducts(query)
 
 		assert.EqualError(t, err, "Error retrieving products")
 
 		productRepo.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+
+	t.Run("Min price greater than max price", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		service := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		invalidMin := 2000.0
+		invalidQuery := query
+		invalidQuery.MinPrice = &invalidMin
+
+		_, _, err := service.GetAllProducts(invalidQuery)
+
+		assert.EqualError(t, err, "minPrice must be less than maxPrice")
+		productRepo.AssertNotCalled(t, "FindAll", mock.Anything)
+	})
+}
+
+func TestGetProductFacets(t *testing.T) {
+	query := dto.ProductListQueryDTO{Page: 1, Limit: 12, Sizes: []string{"M"}}
+
+	t.Run("GetProductFacets Success", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		facets := &dto.ProductFacetsDTO{
+			Categories: []dto.FacetCountDTO{{Value: "1", Label: "Shoes", Count: 30}},
+			Sizes:      []dto.FacetCountDTO{{Value: "M", Label: "M", Count: 12}},
+		}
+		productRepo.On("FindFacets", query).Return(facets, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		result, err := productService.GetProductFacets(query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, facets, result)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("GetProductFacets Error", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productRepo.On("FindFacets", query).Return(nil, errors.New("db error"))
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		_, err := productService.GetProductFacets(query)
+
+		assert.EqualError(t, err, "Error retrieving product facets")
+	})
+}
+
+func TestSearchProducts(t *testing.T) {
+	t.Run("SearchProducts ordered by relevance", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		searchIndex := search.NewMemorySearchIndex()
+		searchIndex.IndexProduct(search.Document{ProductID: 1, Name: "Denim Jacket", Description: "Linen lining"})
+		searchIndex.IndexProduct(search.Document{ProductID: 2, Name: "Linen Shirt"})
+
+		// NOTE - repository คืนลำดับไม่ตรงกับ index service ต้องเรียงตามคะแนนเอง
+		productRepo.On("FindByIDs", []uint{2, 1}).Return([]models.Product{
+			{Model: gorm.Model{ID: 1}, Name: "Denim Jacket"},
+			{Model: gorm.Model{ID: 2}, Name: "Linen Shirt"},
+		}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, searchIndex, storage.NewMemoryStorage())
+
+		results, pageTotal, err := productService.SearchProducts("linen", 1, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), pageTotal)
+		assert.Len(t, results, 2)
+		assert.Equal(t, uint(2), results[0].Product.ID)
+		assert.Equal(t, "<mark>Linen</mark> Shirt", results[0].Highlights["name"])
+		assert.Equal(t, uint(1), results[1].Product.ID)
+		productRepo.AssertExpectations(t)
+	})
+
+	t.Run("Skip product missing from database", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		searchIndex := search.NewMemorySearchIndex()
+		searchIndex.IndexProduct(search.Document{ProductID: 7, Name: "Linen Shirt"})
+
+		productRepo.On("FindByIDs", []uint{7}).Return([]models.Product{}, nil)
+
+		productService := services.NewProductService(productRepo, categoryRepo, searchIndex, storage.NewMemoryStorage())
+
+		results, _, err := productService.SearchProducts("linen", 1, 10)
+
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("Empty query", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		productService := services.NewProductService(productRepo, categoryRepo, search.NewMemorySearchIndex(), storage.NewMemoryStorage())
+
+		_, _, err := productService.SearchProducts("   ", 1, 10)
+
+		assert.EqualError(t, err, "Please provide search query")
+		productRepo.AssertNotCalled(t, "FindByIDs", mock.Anything)
+	})
+
+	t.Run("Created product is indexed and deleted product is removed", func(t *testing.T) {
+		productRepo := repositories.NewProductRepositoryMock()
+		categoryRepo := repositories.NewCategoryRepositoryMock()
+
+		salePrice := 0.0
+		product := &models.Product{
+			Name:        "Linen Shirt",
+			Title:       "Summer",
+			Description: "Breathable shirt",
+			Images:      []models.ProductImage{{URL: "a"}, {URL: "b"}, {URL: "c"}},
+			SalePrice:   &salePrice,
+			CategoryID:  1,
+			Variants:    []models.ProductVariant{{Size: "M", Price: 100, Stock: 5}},
+		}
+
+		categoryRepo.On("FindByID", uint(1)).Return(&models.Category{Model: gorm.Model{ID: 1}, Name: "Tops"}, nil)
+		productRepo.On("FindSlugsWithPrefix", mock.Anything, mock.Anything).Return([]string{}, nil).Maybe()
+		productRepo.On("Create", product).Run(func(args mock.Arguments) {
+			args.Get(0).(*models.Product).ID = 4
+		}).Return(nil)
+		productRepo.On("FindByID", uint(4)).Return(product, nil)
+		productRepo.On("Delete", uint(4)).Return(nil)
+
+		searchIndex := search.NewMemorySearchIndex()
+		productService := services.NewProductService(productRepo, categoryRepo, searchIndex, storage.NewMemoryStorage())
+
+		assert.NoError(t, productService.CreateProduct(product))
+
+		hits, _, _ := searchIndex.Search("tops", 10, 0)
+		assert.Len(t, hits, 1)
+		assert.Equal(t, uint(4), hits[0].ProductID)
+
+		assert.NoError(t, productService.DeleteProduct(4))
+
+		hits, _, _ = searchIndex.Search("linen", 10, 0)
+		assert.Empty(t, hits)
+	})
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+)
+
+const (
+	defaultReservationHold   = 15 * time.Minute
+	defaultCheckoutExtension = 10 * time.Minute
+)
+
+// NOTE - ระยะเวลาที่กัน stock ไว้ให้ order ที่ยังไม่จ่ายเงิน ตั้งได้ด้วย STOCK_RESERVATION_HOLD
+func ReservationHoldDuration() time.Duration {
+	return utils.DurationFromEnv("STOCK_RESERVATION_HOLD", defaultReservationHold)
+}
+
+// NOTE - เวลาที่ต่อให้เมื่อลูกค้าเริ่มจ่ายเงินผ่าน Stripe ตั้งได้ด้วย CHECKOUT_RESERVATION_EXTENSION
+func CheckoutExtensionDuration() time.Duration {
+	return utils.DurationFromEnv("CHECKOUT_RESERVATION_EXTENSION", defaultCheckoutExtension)
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+	"gorm.io/gorm"
+)
+
+type ReturnServiceInterface interface {
+	CreateReturn(userID uint, orderID uint, req dto.CreateReturnRequestDTO) (*models.ReturnRequest, error)
+	GetReturnsByUser(userID uint, params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error)
+	GetAllReturns(params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error)
+	ApproveReturn(adminID uint, id uint, req dto.ApproveReturnDTO) error
+	RejectReturn(id uint, req dto.RejectReturnDTO) error
+}
+
+type ReturnService struct {
+	db              *gorm.DB
+	returnRepo      repositories.ReturnRepositoryInterface
+	orderRepo       repositories.OrderRepositoryInterface
+	paymentProvider utils.PaymentProviderInterface
+}
+
+func NewReturnService(db *gorm.DB, returnRepo repositories.ReturnRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, paymentProvider utils.PaymentProviderInterface) *ReturnService {
+	return &ReturnService{
+		db:              db,
+		returnRepo:      returnRepo,
+		orderRepo:       orderRepo,
+		paymentProvider: paymentProvider,
+	}
+}
+
+// NOTE - คืนสินค้าได้เฉพาะ order ที่จ่ายเงินแล้ว
+func isReturnableStatus(status models.Status) bool {
+	switch status {
+	case models.Paid, models.Shipped, models.Complete, models.PartiallyRefunded:
+		return true
+	}
+	return false
+}
+
+func (s *ReturnService) CreateReturn(userID uint, orderID uint, req dto.CreateReturnRequestDTO) (*models.ReturnRequest, error) {
+	if req.Quantity == 0 {
+		return nil, errors.New("quantity must be greater than 0")
+	}
+
+	order, err := s.returnRepo.FindOrderByID(orderID)
+	if err != nil {
+		return nil, errors.New("Error finding order")
+	}
+
+	if order == nil {
+		return nil, errors.New("order not found")
+	}
+
+	// NOTE - เช็คว่า userID ตรงกับ order.UserID ไหม
+	if order.UserID != userID {
+		return nil, errors.New("unauthorized to return this order")
+	}
+
+	if !isReturnableStatus(order.Status) {
+		return nil, errors.New("order is not eligible for return")
+	}
+
+	var orderItem *models.OrderItem
+	for i := range order.OrderItem {
+		if order.OrderItem[i].ID == req.OrderItemID {
+			orderItem = &order.OrderItem[i]
+			break
+		}
+	}
+
+	if orderItem == nil {
+		return nil, errors.New("order item not found in this order")
+	}
+
+	// NOTE - คืนรวมกันได้ไม่เกินจำนวนที่ซื้อ
+	returnedQty, err := s.returnRepo.SumReturnedQuantity(orderItem.ID)
+	if err != nil {
+		return nil, errors.New("Error checking returned quantity")
+	}
+
+	if int64(req.Quantity)+returnedQty > int64(orderItem.Quantity) {
+		return nil, fmt.Errorf("can return at most %d of this item", int64(orderItem.Quantity)-returnedQty)
+	}
+
+	returnRequest := &models.ReturnRequest{
+		OrderID:     order.ID,
+		OrderItemID: orderItem.ID,
+		UserID:      userID,
+		Quantity:    req.Quantity,
+		Reason:      req.Reason,
+		Status:      models.ReturnRequested,
+	}
+
+	if err := s.returnRepo.Create(returnRequest); err != nil {
+		return nil, errors.New("Error creating return request")
+	}
+
+	return returnRequest, nil
+}
+
+func (s *ReturnService) GetReturnsByUser(userID uint, params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error) {
+	returnRequests, err := s.returnRepo.FindByUserID(userID, params)
+	if err != nil {
+		return pagination.Page[dto.ReturnResponseDTO]{}, errors.New("Error retrieving return requests")
+	}
+
+	return pagination.Map(returnRequests, toReturnResponseDTO), nil
+}
+
+func (s *ReturnService) GetAllReturns(params pagination.Params) (pagination.Page[dto.ReturnResponseDTO], error) {
+	returnRequests, err := s.returnRepo.FindAll(params)
+	if err != nil {
+		return pagination.Page[dto.ReturnResponseDTO]{}, errors.New("Error retrieving return requests")
+	}
+
+	return pagination.Map(returnRequests, toReturnResponseDTO), nil
+}
+
+func (s *ReturnService) ApproveReturn(adminID uint, id uint, req dto.ApproveReturnDTO) error {
+	returnRequest, err := s.findPendingReturn(id)
+	if err != nil {
+		return err
+	}
+
+	if returnRequest.Order.PaymentIntentID == "" {
+		return errors.New("order has no payment to refund")
+	}
+
+	tx := s.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// NOTE - ล็อกคำขอก่อนทำอะไร admin อีกคนที่กดอนุมัติพร้อมกันจะรอจนคนแรกเสร็จแล้วได้ error ว่าปิดไปแล้ว ไม่คืนเงินซ้ำ
+	pending, err := s.returnRepo.LockPending(tx, returnRequest.ID)
+	if err != nil {
+		tx.Rollback()
+		return errors.New("Error finding return request")
+	}
+
+	if !pending {
+		tx.Rollback()
+		return errors.New("return request already resolved")
+	}
+
+	// NOTE - อ่าน order กับยอดที่คืนไปแล้วใหม่หลังล็อก คำขออื่นของ order เดียวกันอาจคืนเงินไปก่อนหน้านี้
+	order, err := s.returnRepo.LockOrder(tx, returnRequest.OrderID)
+	if err != nil || order == nil {
+		tx.Rollback()
+		return errors.New("Error finding order")
+	}
+
+	refundedAmount, err := s.returnRepo.SumRefundedAmount(tx, order.ID)
+	if err != nil {
+		tx.Rollback()
+		return errors.New("Error checking refunded amount")
+	}
+
+	// NOTE - ถ้า admin ไม่ระบุยอดให้คืนตามราคาที่ลูกค้าจ่ายจริง x จำนวนที่คืน
+	amount := paidUnitPrice(order, returnRequest.OrderItemID) * float64(returnRequest.Quantity)
+	if req.RefundAmount != nil {
+		amount = *req.RefundAmount
+	}
+
+	remaining := order.TotalPrice - refundedAmount
+	if amount <= 0 {
+		tx.Rollback()
+		return errors.New("refund amount must be greater than 0")
+	}
+
+	if amount > remaining+0.005 {
+		tx.Rollback()
+		return fmt.Errorf("refund amount cannot be more than %.2f", remaining)
+	}
+
+	newStatus := models.PartiallyRefunded
+	if refundedAmount+amount >= order.TotalPrice-0.005 {
+		newStatus = models.Refunded
+	}
+
+	// NOTE - บันทึก timeline ว่า admin คนไหนอนุมัติคืนเงิน
+	change := statusChange{
+		actor:       SystemActor,
+		actorUserID: &adminID,
+		source:      SourceReturn,
+		note:        fmt.Sprintf("return request #%d refunded %.2f", returnRequest.ID, amount),
+	}
+	if err := applyStatusTransition(tx, s.orderRepo, order, newStatus, change); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// NOTE - คืน stock ของ variant ที่ถูกส่งคืน
+	if err := s.returnRepo.RestockVariant(tx, returnRequest.OrderItem.ProductVariantID, returnRequest.Quantity); err != nil {
+		tx.Rollback()
+		return errors.New("failed to restock product")
+	}
+
+	movement := &models.InventoryMovement{
+		ProductVariantID: returnRequest.OrderItem.ProductVariantID,
+		Delta:            int(returnRequest.Quantity),
+		Reason:           models.InventoryReturn,
+		OrderID:          &order.ID,
+		ActorUserID:      &adminID,
+		Note:             fmt.Sprintf("return request #%d", returnRequest.ID),
+	}
+	if err := s.returnRepo.RecordInventoryMovement(tx, movement); err != nil {
+		tx.Rollback()
+		return errors.New("failed to record inventory movement")
+	}
+
+	// NOTE - เรียก payment provider หลังล็อกและเขียนข้อมูลอื่นครบแล้ว ถ้าคืนเงินไม่ได้จะ rollback ทั้งหมด
+	refundID, err := s.paymentProvider.Refund(order.PaymentIntentID, int64(math.Round(amount*100)), map[string]string{
+		"orderId":         strconv.Itoa(int(order.ID)),
+		"returnRequestId": strconv.Itoa(int(returnRequest.ID)),
+	})
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("refund failed: %w", err)
+	}
+
+	now := time.Now()
+	returnRequest.Status = models.ReturnApproved
+	returnRequest.AdminNote = req.AdminNote
+	returnRequest.RefundAmount = amount
+	returnRequest.RefundID = refundID
+	returnRequest.ResolvedAt = &now
+
+	resolved, err := s.returnRepo.Resolve(tx, returnRequest)
+	if err != nil || !resolved {
+		tx.Rollback()
+		log.Printf("Refund %s issued but return request %d was not saved: %v", refundID, returnRequest.ID, err)
+		return errors.New("Error updating return request")
+	}
+
+	payment := &models.Payment{
+		OrderID:               order.ID,
+		StripePaymentIntentID: order.PaymentIntentID,
+		Amount:                amount,
+		Status:                models.RefundedPayment,
+	}
+	if err := s.returnRepo.CreatePayment(tx, payment); err != nil {
+		tx.Rollback()
+		log.Printf("Refund %s issued but payment of order %d was not saved: %v", refundID, order.ID, err)
+		return errors.New("Error recording refund")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		log.Printf("Refund %s issued but return request %d was not committed: %v", refundID, returnRequest.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// NOTE - ราคาต่อชิ้นที่ลูกค้าจ่ายจริงของรายการนี้ order เก่าที่ยังไม่มี NetPrice ให้เฉลี่ยยอดที่จ่ายจริงของ order ตามสัดส่วนราคาเต็ม
+func paidUnitPrice(order *models.Order, orderItemID uint) float64 {
+	var item *models.OrderItem
+	var gross float64
+	for i := range order.OrderItem {
+		gross += order.OrderItem[i].PriceAtPurchase * float64(order.OrderItem[i].Quantity)
+		if order.OrderItem[i].ID == orderItemID {
+			item = &order.OrderItem[i]
+		}
+	}
+
+	if item == nil {
+		return 0
+	}
+
+	if item.NetPrice > 0 {
+		return item.NetPrice
+	}
+
+	if gross <= 0 {
+		return 0
+	}
+
+	return item.PriceAtPurchase * order.TotalPrice / gross
+}
+
+func (s *ReturnService) RejectReturn(id uint, req dto.RejectReturnDTO) error {
+	returnRequest, err := s.findPendingReturn(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	returnRequest.Status = models.ReturnRejected
+	returnRequest.AdminNote = req.AdminNote
+	returnRequest.ResolvedAt = &now
+
+	resolved, err := s.returnRepo.Resolve(s.db, returnRequest)
+	if err != nil {
+		return errors.New("Error updating return request")
+	}
+
+	if !resolved {
+		return errors.New("return request already resolved")
+	}
+
+	return nil
+}
+
+func (s *ReturnService) findPendingReturn(id uint) (*models.ReturnRequest, error) {
+	returnRequest, err := s.returnRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("Error finding return request")
+	}
+
+	if returnRequest == nil {
+		return nil, errors.New("return request not found")
+	}
+
+	if returnRequest.Status != models.ReturnRequested {
+		return nil, errors.New("return request already resolved")
+	}
+
+	return returnRequest, nil
+}
+
+func toReturnResponseDTO(r models.ReturnRequest) dto.ReturnResponseDTO {
+	resolvedAt := ""
+	if r.ResolvedAt != nil {
+		resolvedAt = r.ResolvedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return dto.ReturnResponseDTO{
+		ID:           r.ID,
+		OrderID:      r.OrderID,
+		OrderItemID:  r.OrderItemID,
+		UserID:       r.UserID,
+		ProductName:  r.OrderItem.ProductVariant.Product.Name,
+		Size:         r.OrderItem.ProductVariant.Size,
+		Quantity:     r.Quantity,
+		Reason:       r.Reason,
+		Status:       r.Status,
+		AdminNote:    r.AdminNote,
+		RefundAmount: r.RefundAmount,
+		CreatedAt:    r.CreatedAt.Format("2006-01-02 15:04:05"),
+		ResolvedAt:   resolvedAt,
+	}
+}
@@ -0,0 +1,379 @@
+package services_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	utils "github.com/Beluga-Whale/ecommerce-api/internal/utils/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestCreateReturn(t *testing.T) {
+	newOrder := func(status models.Status) *models.Order {
+		return &models.Order{
+			Model:      gorm.Model{ID: 1},
+			UserID:     1,
+			Status:     status,
+			TotalPrice: 300,
+			OrderItem: []models.OrderItem{
+				{Model: gorm.Model{ID: 10}, OrderID: 1, ProductVariantID: 5, Quantity: 3, PriceAtPurchase: 100},
+			},
+		}
+	}
+
+	req := dto.CreateReturnRequestDTO{OrderItemID: 10, Quantity: 2, Reason: "Wrong size"}
+
+	t.Run("CreateReturn Success", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindOrderByID", uint(1)).Return(newOrder(models.Paid), nil)
+		returnRepo.On("SumReturnedQuantity", uint(10)).Return(int64(0), nil)
+		returnRepo.On("Create", mock.MatchedBy(func(r *models.ReturnRequest) bool {
+			return r.OrderItemID == 10 && r.Quantity == 2 && r.Status == models.ReturnRequested
+		})).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		returnRequest, err := returnService.CreateReturn(1, 1, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Wrong size", returnRequest.Reason)
+		returnRepo.AssertExpectations(t)
+	})
+
+	t.Run("Order of other user", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindOrderByID", uint(1)).Return(newOrder(models.Paid), nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		_, err := returnService.CreateReturn(2, 1, req)
+
+		assert.EqualError(t, err, "unauthorized to return this order")
+	})
+
+	t.Run("Order not paid", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindOrderByID", uint(1)).Return(newOrder(models.Pending), nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		_, err := returnService.CreateReturn(1, 1, req)
+
+		assert.EqualError(t, err, "order is not eligible for return")
+	})
+
+	t.Run("Item not in order", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindOrderByID", uint(1)).Return(newOrder(models.Complete), nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		_, err := returnService.CreateReturn(1, 1, dto.CreateReturnRequestDTO{OrderItemID: 99, Quantity: 1, Reason: "x"})
+
+		assert.EqualError(t, err, "order item not found in this order")
+	})
+
+	t.Run("Return more than purchased", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindOrderByID", uint(1)).Return(newOrder(models.Shipped), nil)
+		returnRepo.On("SumReturnedQuantity", uint(10)).Return(int64(2), nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		_, err := returnService.CreateReturn(1, 1, req)
+
+		assert.EqualError(t, err, "can return at most 1 of this item")
+		returnRepo.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestApproveReturn(t *testing.T) {
+	newReturn := func() *models.ReturnRequest {
+		return &models.ReturnRequest{
+			Model:       gorm.Model{ID: 7},
+			OrderID:     1,
+			Order:       models.Order{Model: gorm.Model{ID: 1}, TotalPrice: 300, PaymentIntentID: "pi_123", Status: models.Paid},
+			OrderItemID: 10,
+			OrderItem:   models.OrderItem{Model: gorm.Model{ID: 10}, ProductVariantID: 5, Quantity: 3, PriceAtPurchase: 100, NetPrice: 100},
+			Quantity:    2,
+			Status:      models.ReturnRequested,
+		}
+	}
+
+	newOrder := func(status models.Status) *models.Order {
+		return &models.Order{
+			Model:           gorm.Model{ID: 1},
+			TotalPrice:      300,
+			PaymentIntentID: "pi_123",
+			Status:          status,
+			OrderItem: []models.OrderItem{
+				{Model: gorm.Model{ID: 10}, ProductVariantID: 5, Quantity: 3, PriceAtPurchase: 100, NetPrice: 100},
+			},
+		}
+	}
+
+	t.Run("Partial refund and restock", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(newOrder(models.Paid), nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(0.0, nil)
+		returnRepo.On("RestockVariant", mock.Anything, uint(5), uint(2)).Return(nil)
+		returnRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		returnRepo.On("Resolve", mock.Anything, mock.MatchedBy(func(r *models.ReturnRequest) bool {
+			return r.Status == models.ReturnApproved && r.RefundAmount == 200 && r.RefundID == "re_fake_1" && r.ResolvedAt != nil
+		})).Return(true, nil)
+		returnRepo.On("CreatePayment", mock.Anything, mock.MatchedBy(func(p *models.Payment) bool {
+			return p.Status == models.RefundedPayment && p.Amount == 200
+		})).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.PartiallyRefunded).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.MatchedBy(func(e *models.OrderStatusEvent) bool {
+			return e.ToStatus == models.PartiallyRefunded && *e.ActorUserID == 1 && e.Source == string(services.SourceReturn)
+		})).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.NoError(t, err)
+		assert.Len(t, provider.Refunds, 1)
+		assert.Equal(t, "pi_123", provider.Refunds[0].PaymentIntentID)
+		assert.Equal(t, int64(20000), provider.Refunds[0].Amount)
+		returnRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Default refund uses price actually paid", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		// NOTE - ราคาเต็ม 100 ลดราคาเหลือ 80 แล้วหัก coupon อีก เหลือจ่ายจริงชิ้นละ 72
+		order := newOrder(models.Paid)
+		order.TotalPrice = 216
+		order.OrderItem[0].NetPrice = 72
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(order, nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(0.0, nil)
+		returnRepo.On("RestockVariant", mock.Anything, uint(5), uint(2)).Return(nil)
+		returnRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		returnRepo.On("Resolve", mock.Anything, mock.Anything).Return(true, nil)
+		returnRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.PartiallyRefunded).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(14400), provider.Refunds[0].Amount)
+	})
+
+	t.Run("Order without net price refunds its share of the total", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		order := newOrder(models.Paid)
+		order.TotalPrice = 240
+		order.OrderItem[0].NetPrice = 0
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(order, nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(0.0, nil)
+		returnRepo.On("RestockVariant", mock.Anything, uint(5), uint(2)).Return(nil)
+		returnRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		returnRepo.On("Resolve", mock.Anything, mock.Anything).Return(true, nil)
+		returnRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.PartiallyRefunded).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(16000), provider.Refunds[0].Amount)
+	})
+
+	t.Run("Full refund with custom amount", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		amount := 100.0
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(newOrder(models.PartiallyRefunded), nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(200.0, nil)
+		returnRepo.On("RestockVariant", mock.Anything, uint(5), uint(2)).Return(nil)
+		returnRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		returnRepo.On("Resolve", mock.Anything, mock.Anything).Return(true, nil)
+		returnRepo.On("CreatePayment", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.PartiallyRefunded, models.Refunded).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{RefundAmount: &amount})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10000), provider.Refunds[0].Amount)
+		returnRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Refund more than remaining", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(newOrder(models.PartiallyRefunded), nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(250.0, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.EqualError(t, err, "refund amount cannot be more than 50.00")
+		assert.Empty(t, provider.Refunds)
+	})
+
+	t.Run("Provider error rolls back", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+		provider.Err = errors.New("card_declined")
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(newOrder(models.Paid), nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(0.0, nil)
+		returnRepo.On("RestockVariant", mock.Anything, uint(5), uint(2)).Return(nil)
+		returnRepo.On("RecordInventoryMovement", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.PartiallyRefunded).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.EqualError(t, err, "refund failed: card_declined")
+		returnRepo.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything)
+		returnRepo.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Approved by another admin first", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		// NOTE - อ่านตอนแรกยังรอพิจารณา แต่พอล็อกได้ admin อีกคนอนุมัติไปแล้ว
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(false, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.EqualError(t, err, "return request already resolved")
+		assert.Empty(t, provider.Refunds)
+		returnRepo.AssertNotCalled(t, "RestockVariant", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Order status changed by another request", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		returnRepo.On("FindByID", uint(7)).Return(newReturn(), nil)
+		returnRepo.On("LockPending", mock.Anything, uint(7)).Return(true, nil)
+		returnRepo.On("LockOrder", mock.Anything, uint(1)).Return(newOrder(models.Paid), nil)
+		returnRepo.On("SumRefundedAmount", mock.Anything, uint(1)).Return(0.0, nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.PartiallyRefunded).Return(false, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, orderRepo, provider)
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+		assert.Empty(t, provider.Refunds)
+	})
+
+	t.Run("Already resolved", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		resolved := newReturn()
+		resolved.Status = models.ReturnRejected
+		returnRepo.On("FindByID", uint(7)).Return(resolved, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		err := returnService.ApproveReturn(1, 7, dto.ApproveReturnDTO{})
+
+		assert.EqualError(t, err, "return request already resolved")
+	})
+}
+
+func TestRejectReturn(t *testing.T) {
+	t.Run("RejectReturn Success", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+		provider := utils.NewFakePaymentProvider()
+
+		returnRepo.On("FindByID", uint(7)).Return(&models.ReturnRequest{Model: gorm.Model{ID: 7}, Status: models.ReturnRequested}, nil)
+		returnRepo.On("Resolve", mock.Anything, mock.MatchedBy(func(r *models.ReturnRequest) bool {
+			return r.Status == models.ReturnRejected && r.AdminNote == "Item was used"
+		})).Return(true, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), provider)
+
+		err := returnService.RejectReturn(7, dto.RejectReturnDTO{AdminNote: "Item was used"})
+
+		assert.NoError(t, err)
+		assert.Empty(t, provider.Refunds)
+		returnRepo.AssertExpectations(t)
+	})
+
+	t.Run("Approved by another admin first", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindByID", uint(7)).Return(&models.ReturnRequest{Model: gorm.Model{ID: 7}, Status: models.ReturnRequested}, nil)
+		returnRepo.On("Resolve", mock.Anything, mock.Anything).Return(false, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		err := returnService.RejectReturn(7, dto.RejectReturnDTO{AdminNote: "x"})
+
+		assert.EqualError(t, err, "return request already resolved")
+	})
+
+	t.Run("Return request not found", func(t *testing.T) {
+		returnRepo := repositories.NewReturnRepositoryMock()
+
+		returnRepo.On("FindByID", uint(7)).Return(nil, nil)
+
+		returnService := services.NewReturnService(InitializeDB(t), returnRepo, repositories.NewOrderRepositoryMock(), utils.NewFakePaymentProvider())
+
+		err := returnService.RejectReturn(7, dto.RejectReturnDTO{AdminNote: "x"})
+
+		assert.EqualError(t, err, "return request not found")
+	})
+}
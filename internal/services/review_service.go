@@ -5,13 +5,14 @@ import (
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
 )
 
 type ReviewServiceInterface interface {
-	GetReviewsByUserID(userIDUint uint) ([]models.Review , error) 
+	GetReviewsByUserID(userIDUint uint, params pagination.Params) (pagination.Page[models.Review] , error) 
 	CreateReview(userIDUint uint, req dto.CreateReviewDTO) error
-	GetReviewAll(productId uint ) (dto.ReviewAllProductSummaryResponse,error)
+	GetReviewAll(productId uint, params pagination.Params) (dto.ReviewAllProductSummaryResponse,error)
 }
 
 type ReviewService struct{
@@ -22,11 +23,11 @@ func NewReviewService(reviewRepo repositories.ReviewRepositoryInterface)*ReviewS
 	return &ReviewService{reviewRepo:reviewRepo}
 }
 
-func (s *ReviewService) GetReviewsByUserID(userIDUint uint) ([]models.Review , error) {
-	reviews,err := s.reviewRepo.GetUserReviews(userIDUint)
+func (s *ReviewService) GetReviewsByUserID(userIDUint uint, params pagination.Params) (pagination.Page[models.Review] , error) {
+	reviews,err := s.reviewRepo.GetUserReviews(userIDUint, params)
 
 	if err != nil {
-		return nil,errors.New("Error to get user reviews")
+		return pagination.Page[models.Review]{},errors.New("Error to get user reviews")
 	}
 
 	return reviews,nil
@@ -50,11 +51,11 @@ func (s *ReviewService) CreateReview(userIDUint uint, req dto.CreateReviewDTO) e
 	return s.reviewRepo.Create(review)
 }
 
-func (s *ReviewService) GetReviewAll(productId uint ) (dto.ReviewAllProductSummaryResponse,error) {
+func (s *ReviewService) GetReviewAll(productId uint, params pagination.Params) (dto.ReviewAllProductSummaryResponse,error) {
 	var response dto.ReviewAllProductSummaryResponse
 
-	// NOTE - Get all reviews
-	reviews, err := s.reviewRepo.GetReviewAllByProductId(productId)
+	// NOTE - Get reviews ทีละหน้า
+	reviews, err := s.reviewRepo.GetReviewAllByProductId(productId, params)
 	if err != nil {
 		return response, errors.New("Error to get all review Product")
 	}
@@ -65,15 +66,20 @@ func (s *ReviewService) GetReviewAll(productId uint ) (dto.ReviewAllProductSumma
 		return response, errors.New("Error to get average rating")
 	}
 
-	// NOTE - Count per star
-	countMap := make(map[int]int)
-	for _, review := range reviews {
-		countMap[int(review.Rating)]++
+	// NOTE - Count per star นับจากรีวิวทั้งหมดของสินค้า ไม่ใช่แค่หน้าที่ดึงมา
+	countMap, err := s.reviewRepo.CountRatingsByProductId(productId)
+	if err != nil {
+		return response, errors.New("Error to count rating")
+	}
+
+	total := 0
+	for _, count := range countMap {
+		total += count
 	}
 
 	response = dto.ReviewAllProductSummaryResponse{
 		Average:      avg,
-		Total:        len(reviews),
+		Total:        total,
 		CountPerStar: countMap,
 		ReviewList:   reviews,
 	}
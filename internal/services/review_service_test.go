@@ -6,6 +6,7 @@ import (
 
 	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
 	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
 	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
 	"github.com/stretchr/testify/assert"
@@ -31,15 +32,16 @@ func TestGetReviewsByUserID(t *testing.T){
 
 		reviewRepo := repositories.NewReviewRepositoryMock()
 
-		reviewRepo.On("GetUserReviews",userID).Return(reviewMock,nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		reviewRepo.On("GetUserReviews",userID,params).Return(pagination.Page[models.Review]{Items: reviewMock},nil)
 
 		reviewService:= services.NewReviewService(reviewRepo)
 
-		review,err := reviewService.GetReviewsByUserID(userID)
+		review,err := reviewService.GetReviewsByUserID(userID,params)
 
 		assert.NoError(t,err)
-		assert.Equal(t, int64(3), review[0].Rating)
-		assert.Equal(t, int64(4), review[1].Rating)
+		assert.Equal(t, int64(3), review.Items[0].Rating)
+		assert.Equal(t, int64(4), review.Items[1].Rating)
 
 		reviewRepo.AssertExpectations(t)
 
@@ -50,14 +52,15 @@ func TestGetReviewsByUserID(t *testing.T){
 
 		reviewRepo := repositories.NewReviewRepositoryMock()
 
-		reviewRepo.On("GetUserReviews",userID).Return(nil,errors.New("Error to get user reviews"))
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		reviewRepo.On("GetUserReviews",userID,params).Return(nil,errors.New("Error to get user reviews"))
 
 		reviewService:= services.NewReviewService(reviewRepo)
 
-		review,err := reviewService.GetReviewsByUserID(userID)
+		review,err := reviewService.GetReviewsByUserID(userID,params)
 
 		assert.EqualError(t,err,"Error to get user reviews")
-		assert.Nil(t,review)
+		assert.Empty(t,review.Items)
 		reviewRepo.AssertExpectations(t)
 	})
 }
@@ -145,14 +148,21 @@ func TestGetReviewAll(t *testing.T) {
 		}
 		reviewRepo := repositories.NewReviewRepositoryMock()
 
-		reviewRepo.On("GetReviewAllByProductId",productID).Return(reviewMock,nil)
+		params := pagination.Params{Limit: 1}
+		reviewRepo.On("GetReviewAllByProductId",productID,params).Return(pagination.Page[dto.ReviewAllProduct]{Items: reviewMock, NextCursor: "next"},nil)
 		reviewRepo.On("GetAverageRatingByProductId",productID).Return(4.0,nil)
+		reviewRepo.On("CountRatingsByProductId",productID).Return(map[int]int{4: 2, 5: 1},nil)
 
 		reviewService:= services.NewReviewService(reviewRepo)
 
-		_,err := reviewService.GetReviewAll(productID)
+		response,err := reviewService.GetReviewAll(productID,params)
 
 		assert.NoError(t,err)
+		// NOTE - total และ countPerStar มาจากรีวิวทั้งหมด ไม่ใช่แค่หน้านี้
+		assert.Equal(t, 3, response.Total)
+		assert.Equal(t, map[int]int{4: 2, 5: 1}, response.CountPerStar)
+		assert.Len(t, response.ReviewList.Items, 1)
+		assert.Equal(t, "next", response.ReviewList.NextCursor)
 
 		reviewRepo.AssertExpectations(t)
 	})
@@ -162,11 +172,12 @@ func TestGetReviewAll(t *testing.T) {
 
 		reviewRepo := repositories.NewReviewRepositoryMock()
 
-		reviewRepo.On("GetReviewAllByProductId",productID).Return(nil,errors.New("Error to get all review Product"))
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		reviewRepo.On("GetReviewAllByProductId",productID,params).Return(nil,errors.New("Error to get all review Product"))
 
 		reviewService:= services.NewReviewService(reviewRepo)
 
-		_,err := reviewService.GetReviewAll(productID)
+		_,err := reviewService.GetReviewAll(productID,params)
 
 		assert.EqualError(t,err,"Error to get all review Product")
 
@@ -187,15 +198,35 @@ func TestGetReviewAll(t *testing.T) {
 		}
 		reviewRepo := repositories.NewReviewRepositoryMock()
 
-		reviewRepo.On("GetReviewAllByProductId",productID).Return(reviewMock,nil)
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		reviewRepo.On("GetReviewAllByProductId",productID,params).Return(pagination.Page[dto.ReviewAllProduct]{Items: reviewMock},nil)
 		reviewRepo.On("GetAverageRatingByProductId",productID).Return(0.0,errors.New("Error to get average rating"))
 
 		reviewService:= services.NewReviewService(reviewRepo)
 
-		_,err := reviewService.GetReviewAll(productID)
+		_,err := reviewService.GetReviewAll(productID,params)
 
 		assert.EqualError(t,err,"Error to get average rating")
 
 		reviewRepo.AssertExpectations(t)
 	})
+
+	t.Run("Error CountRatingsByProductId",func(t *testing.T) {
+		productID := uint(1)
+
+		reviewRepo := repositories.NewReviewRepositoryMock()
+
+		params := pagination.Params{Limit: pagination.DefaultLimit}
+		reviewRepo.On("GetReviewAllByProductId",productID,params).Return(pagination.Page[dto.ReviewAllProduct]{},nil)
+		reviewRepo.On("GetAverageRatingByProductId",productID).Return(4.0,nil)
+		reviewRepo.On("CountRatingsByProductId",productID).Return(nil,errors.New("Error to count rating"))
+
+		reviewService:= services.NewReviewService(reviewRepo)
+
+		_,err := reviewService.GetReviewAll(productID,params)
+
+		assert.EqualError(t,err,"Error to count rating")
+
+		reviewRepo.AssertExpectations(t)
+	})
 }
\ No newline at end of file
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	"github.com/Beluga-Whale/ecommerce-api/internal/pagination"
+	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"gorm.io/gorm"
+)
+
+type ShipmentServiceInterface interface {
+	CreateShipment(adminID uint, orderID uint, req dto.CreateShipmentDTO) (*models.Shipment, error)
+	UpdateShipment(adminID uint, shipmentID uint, req dto.UpdateShipmentDTO) (*models.Shipment, error)
+	GetShipmentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error)
+}
+
+type ShipmentService struct {
+	db           *gorm.DB
+	shipmentRepo repositories.ShipmentRepositoryInterface
+	orderRepo    repositories.OrderRepositoryInterface
+}
+
+func NewShipmentService(db *gorm.DB, shipmentRepo repositories.ShipmentRepositoryInterface, orderRepo repositories.OrderRepositoryInterface) *ShipmentService {
+	return &ShipmentService{
+		db:           db,
+		shipmentRepo: shipmentRepo,
+		orderRepo:    orderRepo,
+	}
+}
+
+// NOTE - จำนวนที่ยังไม่ได้ส่งของแต่ละ OrderItem
+func remainingToShip(order *models.Order) map[uint]uint {
+	remaining := make(map[uint]uint)
+	for _, item := range order.OrderItem {
+		remaining[item.ID] = item.Quantity
+	}
+
+	for _, shipment := range order.Shipments {
+		for _, item := range shipment.Items {
+			if item.Quantity >= remaining[item.OrderItemID] {
+				remaining[item.OrderItemID] = 0
+			} else {
+				remaining[item.OrderItemID] -= item.Quantity
+			}
+		}
+	}
+
+	return remaining
+}
+
+func (s *ShipmentService) CreateShipment(adminID uint, orderID uint, req dto.CreateShipmentDTO) (*models.Shipment, error) {
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	// NOTE - คำนวณของที่เหลือจาก order ที่ล็อกไว้ admin สองคนส่งของ OrderItem เดียวกันพร้อมกันจะได้ไม่เกินที่สั่ง
+	order, err := s.orderRepo.LockOrderWithShipments(tx, orderID)
+	if err != nil || order == nil {
+		tx.Rollback()
+		return nil, errors.New("order not found")
+	}
+
+	// NOTE - ส่งของได้เฉพาะ order ที่จ่ายแล้ว หรือกำลังทยอยส่ง
+	if order.Status != models.Paid && order.Status != models.Shipped {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: cannot ship order in status %s", ErrInvalidStatusTransition, order.Status)
+	}
+
+	items, err := shipmentItems(order, req.Items)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	now := time.Now()
+	shipment := &models.Shipment{
+		OrderID:        order.ID,
+		Carrier:        req.Carrier,
+		TrackingNumber: req.TrackingNumber,
+		ShippedAt:      &now,
+		Items:          items,
+	}
+
+	if err := s.shipmentRepo.Create(tx, shipment); err != nil {
+		tx.Rollback()
+		return nil, errors.New("Error creating shipment")
+	}
+
+	// NOTE - พัสดุแรกของ order จะเปลี่ยนสถานะเป็น shipped
+	if order.Status == models.Paid {
+		change := statusChange{
+			actor:       AdminActor,
+			actorUserID: &adminID,
+			source:      SourceAdmin,
+			note:        fmt.Sprintf("shipment #%d %s %s", shipment.ID, shipment.Carrier, shipment.TrackingNumber),
+		}
+		if err := applyStatusTransition(tx, s.orderRepo, order, models.Shipped, change); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// NOTE - รายการในพัสดุใหม่ ถ้าไม่ระบุสินค้าคือส่งของที่เหลือทั้งหมด
+func shipmentItems(order *models.Order, reqItems []dto.ShipmentItemDTO) ([]models.ShipmentItem, error) {
+	remaining := remainingToShip(order)
+
+	var items []models.ShipmentItem
+	if len(reqItems) == 0 {
+		for _, item := range order.OrderItem {
+			if remaining[item.ID] > 0 {
+				items = append(items, models.ShipmentItem{OrderItemID: item.ID, Quantity: remaining[item.ID]})
+			}
+		}
+	} else {
+		for _, reqItem := range reqItems {
+			left, ok := remaining[reqItem.OrderItemID]
+			if !ok {
+				return nil, fmt.Errorf("order item %d is not in this order", reqItem.OrderItemID)
+			}
+			if reqItem.Quantity > left {
+				return nil, fmt.Errorf("cannot ship more than %d of order item %d", left, reqItem.OrderItemID)
+			}
+			remaining[reqItem.OrderItemID] -= reqItem.Quantity
+			items = append(items, models.ShipmentItem{OrderItemID: reqItem.OrderItemID, Quantity: reqItem.Quantity})
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("order is already fully shipped")
+	}
+
+	return items, nil
+}
+
+func (s *ShipmentService) UpdateShipment(adminID uint, shipmentID uint, req dto.UpdateShipmentDTO) (*models.Shipment, error) {
+	found, err := s.shipmentRepo.FindByID(shipmentID)
+	if err != nil {
+		return nil, errors.New("Error finding shipment")
+	}
+
+	if found == nil {
+		return nil, errors.New("shipment not found")
+	}
+
+	tx := s.db.Begin()
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	// NOTE - ล็อก order แล้วอ่านพัสดุใหม่ใน transaction พัสดุสองชิ้นที่ส่งถึงพร้อมกันจะเห็นของกันและกัน
+	// ไม่อย่างนั้นต่างฝั่งจะเห็นอีกชิ้นยังไม่ถึง และ order ค้างอยู่ที่ shipped ลูกค้ารีวิวไม่ได้
+	order, err := s.orderRepo.LockOrderWithShipments(tx, found.OrderID)
+	if err != nil || order == nil {
+		tx.Rollback()
+		return nil, errors.New("order not found")
+	}
+
+	var shipment *models.Shipment
+	for i := range order.Shipments {
+		if order.Shipments[i].ID == shipmentID {
+			shipment = &order.Shipments[i]
+			break
+		}
+	}
+
+	if shipment == nil {
+		tx.Rollback()
+		return nil, errors.New("shipment not found")
+	}
+
+	if req.Carrier != nil && *req.Carrier != "" {
+		shipment.Carrier = *req.Carrier
+	}
+	if req.TrackingNumber != nil && *req.TrackingNumber != "" {
+		shipment.TrackingNumber = *req.TrackingNumber
+	}
+
+	justDelivered := req.Delivered && shipment.DeliveredAt == nil
+	if justDelivered {
+		now := time.Now()
+		shipment.DeliveredAt = &now
+	}
+
+	if err := s.shipmentRepo.Update(tx, shipment); err != nil {
+		tx.Rollback()
+		return nil, errors.New("Error updating shipment")
+	}
+
+	// NOTE - ทุกพัสดุถึงมือลูกค้าแล้ว ให้ order เป็น complete เพื่อให้ลูกค้ารีวิวสินค้าได้
+	if justDelivered && order.Status == models.Shipped && allShipmentsDelivered(order) {
+		change := statusChange{
+			actor:       AdminActor,
+			actorUserID: &adminID,
+			source:      SourceAdmin,
+			note:        "all shipments delivered",
+		}
+		if err := applyStatusTransition(tx, s.orderRepo, order, models.Complete, change); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return shipment, nil
+}
+
+// NOTE - ส่งของครบทุกชิ้นและทุกพัสดุมี DeliveredAt แล้ว (รวมพัสดุที่เพิ่งส่งถึงซึ่งอยู่ใน order.Shipments แล้ว)
+func allShipmentsDelivered(order *models.Order) bool {
+	for _, left := range remainingToShip(order) {
+		if left > 0 {
+			return false
+		}
+	}
+
+	for _, shipment := range order.Shipments {
+		if shipment.DeliveredAt == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *ShipmentService) GetShipmentsByOrderID(orderID uint, params pagination.Params) (pagination.Page[models.Shipment], error) {
+	shipments, err := s.shipmentRepo.FindByOrderID(orderID, params)
+	if err != nil {
+		return pagination.Page[models.Shipment]{}, errors.New("Error to get shipments")
+	}
+
+	return shipments, nil
+}
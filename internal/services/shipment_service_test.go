@@ -0,0 +1,199 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/models"
+	repositories "github.com/Beluga-Whale/ecommerce-api/internal/repositories/mocks"
+	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newShippableOrder(status models.Status, shipments ...models.Shipment) *models.Order {
+	return &models.Order{
+		Model:  gorm.Model{ID: 1},
+		UserID: 1,
+		Status: status,
+		OrderItem: []models.OrderItem{
+			{Model: gorm.Model{ID: 10}, OrderID: 1, ProductVariantID: 5, Quantity: 2},
+			{Model: gorm.Model{ID: 11}, OrderID: 1, ProductVariantID: 6, Quantity: 1},
+		},
+		Shipments: shipments,
+	}
+}
+
+func TestCreateShipment(t *testing.T) {
+	req := dto.CreateShipmentDTO{Carrier: "Kerry", TrackingNumber: "KER123"}
+
+	t.Run("First shipment ships the whole order", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Paid), nil)
+		shipmentRepo.On("Create", mock.Anything, mock.MatchedBy(func(s *models.Shipment) bool {
+			return len(s.Items) == 2 && s.Items[0].Quantity == 2 && s.Items[1].Quantity == 1 && s.ShippedAt != nil
+		})).Return(nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Paid, models.Shipped).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.MatchedBy(func(e *models.OrderStatusEvent) bool {
+			return e.ToStatus == models.Shipped && *e.ActorUserID == 9 && e.Source == "admin"
+		})).Return(nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		shipment, err := shipmentService.CreateShipment(9, 1, req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "KER123", shipment.TrackingNumber)
+		shipmentRepo.AssertExpectations(t)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Split shipment of an already shipped order", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		shipped := models.Shipment{Model: gorm.Model{ID: 3}, Items: []models.ShipmentItem{{OrderItemID: 10, Quantity: 1}}}
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Shipped, shipped), nil)
+		shipmentRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		split := req
+		split.Items = []dto.ShipmentItemDTO{{OrderItemID: 10, Quantity: 1}}
+		_, err := shipmentService.CreateShipment(9, 1, split)
+
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "UpdateStatusFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Cannot ship more than ordered", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Paid), nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		tooMany := req
+		tooMany.Items = []dto.ShipmentItemDTO{{OrderItemID: 11, Quantity: 2}}
+		_, err := shipmentService.CreateShipment(9, 1, tooMany)
+
+		assert.EqualError(t, err, "cannot ship more than 1 of order item 11")
+		shipmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Items shipped by another admin are counted", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		// NOTE - order ที่ล็อกแล้วโหลดใหม่เห็นพัสดุที่ admin อีกคนเพิ่ง commit ไป
+		other := models.Shipment{Model: gorm.Model{ID: 3}, Items: []models.ShipmentItem{{OrderItemID: 10, Quantity: 2}, {OrderItemID: 11, Quantity: 1}}}
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Shipped, other), nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		split := req
+		split.Items = []dto.ShipmentItemDTO{{OrderItemID: 10, Quantity: 1}}
+		_, err := shipmentService.CreateShipment(9, 1, split)
+
+		assert.EqualError(t, err, "cannot ship more than 0 of order item 10")
+		shipmentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Pending order cannot be shipped", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Pending), nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		_, err := shipmentService.CreateShipment(9, 1, req)
+
+		assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+	})
+}
+
+func TestUpdateShipment(t *testing.T) {
+	t.Run("Last delivered shipment completes the order", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		deliveredAt := time.Now()
+		first := models.Shipment{Model: gorm.Model{ID: 3}, OrderID: 1, DeliveredAt: &deliveredAt, Items: []models.ShipmentItem{{OrderItemID: 10, Quantity: 2}}}
+		second := models.Shipment{Model: gorm.Model{ID: 4}, OrderID: 1, Items: []models.ShipmentItem{{OrderItemID: 11, Quantity: 1}}}
+
+		shipmentRepo.On("FindByID", uint(4)).Return(&second, nil)
+		shipmentRepo.On("Update", mock.Anything, mock.MatchedBy(func(s *models.Shipment) bool { return s.DeliveredAt != nil })).Return(nil)
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Shipped, first, second), nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Shipped, models.Complete).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		shipment, err := shipmentService.UpdateShipment(9, 4, dto.UpdateShipmentDTO{Delivered: true})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, shipment.DeliveredAt)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Order stays shipped while other shipments are on the way", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		first := models.Shipment{Model: gorm.Model{ID: 3}, OrderID: 1, Items: []models.ShipmentItem{{OrderItemID: 10, Quantity: 2}}}
+		second := models.Shipment{Model: gorm.Model{ID: 4}, OrderID: 1, Items: []models.ShipmentItem{{OrderItemID: 11, Quantity: 1}}}
+
+		shipmentRepo.On("FindByID", uint(4)).Return(&second, nil)
+		shipmentRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Shipped, first, second), nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		_, err := shipmentService.UpdateShipment(9, 4, dto.UpdateShipmentDTO{Delivered: true})
+
+		assert.NoError(t, err)
+		orderRepo.AssertNotCalled(t, "UpdateStatusFrom", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Shipment delivered at the same time is seen after the lock", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		deliveredAt := time.Now()
+		first := models.Shipment{Model: gorm.Model{ID: 3}, OrderID: 1, DeliveredAt: &deliveredAt, Items: []models.ShipmentItem{{OrderItemID: 10, Quantity: 2}}}
+		second := models.Shipment{Model: gorm.Model{ID: 4}, OrderID: 1, Items: []models.ShipmentItem{{OrderItemID: 11, Quantity: 1}}}
+
+		// NOTE - ตอนอ่านก่อนล็อกพัสดุแรกยังไม่ถึง แต่ request อื่น commit ไปก่อนที่จะได้ lock
+		shipmentRepo.On("FindByID", uint(4)).Return(&second, nil)
+		shipmentRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		orderRepo.On("LockOrderWithShipments", mock.Anything, uint(1)).Return(newShippableOrder(models.Shipped, first, second), nil)
+		orderRepo.On("UpdateStatusFrom", mock.Anything, uint(1), models.Shipped, models.Complete).Return(true, nil)
+		orderRepo.On("CreateStatusEvent", mock.Anything, mock.Anything).Return(nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		_, err := shipmentService.UpdateShipment(9, 4, dto.UpdateShipmentDTO{Delivered: true})
+
+		assert.NoError(t, err)
+		orderRepo.AssertExpectations(t)
+	})
+
+	t.Run("Shipment not found", func(t *testing.T) {
+		shipmentRepo := repositories.NewShipmentRepositoryMock()
+		orderRepo := repositories.NewOrderRepositoryMock()
+
+		shipmentRepo.On("FindByID", uint(4)).Return(nil, nil)
+
+		shipmentService := services.NewShipmentService(InitializeDB(t), shipmentRepo, orderRepo)
+
+		_, err := shipmentService.UpdateShipment(9, 4, dto.UpdateShipmentDTO{Delivered: true})
+
+		assert.EqualError(t, err, "shipment not found")
+	})
+}
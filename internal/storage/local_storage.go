@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	defaultUploadDir     = "uploads"
+	defaultUploadBaseURL = "/uploads"
+)
+
+// NOTE - เก็บไฟล์ลง disk ของเครื่องที่รัน server ใช้ตอน dev หรือ deploy เครื่องเดียว
+// main เปิด static route ที่ /uploads ให้อ่านไฟล์จากโฟลเดอร์นี้
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalStorage(dir string, baseURL string) *LocalStorage {
+	if dir == "" {
+		dir = defaultUploadDir
+	}
+
+	if baseURL == "" {
+		baseURL = defaultUploadBaseURL
+	}
+
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (s *LocalStorage) Dir() string {
+	return s.dir
+}
+
+func (s *LocalStorage) Put(key string, body []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filePath, body, 0o644); err != nil {
+		return "", err
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// NOTE - ไฟล์ที่ไม่มีอยู่แล้วถือว่าลบสำเร็จ
+func (s *LocalStorage) Delete(key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package storage
+
+import "sync"
+
+// NOTE - เก็บไฟล์ไว้ใน memory สำหรับเทส service ไม่ต้องเขียน disk หรือต่อ S3
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]MemoryObject
+}
+
+type MemoryObject struct {
+	Body        []byte
+	ContentType string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string]MemoryObject)}
+}
+
+func (s *MemoryStorage) Put(key string, body []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.objects[key] = MemoryObject{Body: body, ContentType: contentType}
+	return "/uploads/" + key, nil
+}
+
+func (s *MemoryStorage) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *MemoryStorage) Get(key string) (MemoryObject, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	object, ok := s.objects[key]
+	return object, ok
+}
+
+func (s *MemoryStorage) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	s3Service       = "s3"
+	amzDateFormat   = "20060102T150405Z"
+	amzDayFormat    = "20060102"
+)
+
+type S3Config struct {
+	Endpoint  string // NOTE - เช่น https://s3.ap-southeast-1.amazonaws.com หรือ http://localhost:9000 ของ MinIO
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PublicURL string // NOTE - URL ที่หน้าเว็บใช้โหลดรูป เช่น CDN ถ้าไม่ตั้งใช้ Endpoint/Bucket
+}
+
+// NOTE - คุยกับ S3 ผ่าน REST API ตรงๆ แบบ path-style (endpoint/bucket/key) เซ็น request ด้วย Signature V4
+// ใช้ได้ทั้ง AWS S3 และตัวที่ API เหมือนกันอย่าง MinIO หรือ Cloudflare R2
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3Storage(config S3Config) *S3Storage {
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3.amazonaws.com"
+	}
+
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	config.PublicURL = strings.TrimSuffix(config.PublicURL, "/")
+
+	return &S3Storage{
+		config: config,
+		client: &http.Client{Timeout: 30 * time.Second},
+		now:    time.Now,
+	}
+}
+
+func (s *S3Storage) Put(key string, body []byte, contentType string) (string, error) {
+	key, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	if err := s.do(req, body); err != nil {
+		return "", err
+	}
+
+	if s.config.PublicURL != "" {
+		return s.config.PublicURL + "/" + key, nil
+	}
+
+	return s.objectURL(key), nil
+}
+
+// NOTE - S3 ตอบ 204 แม้ไม่มี object อยู่แล้ว
+func (s *S3Storage) Delete(key string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+
+	return s.do(req, nil)
+}
+
+func (s *S3Storage) objectURL(key string) string {
+	return s.config.Endpoint + "/" + s.config.Bucket + "/" + escapePath(key)
+}
+
+func (s *S3Storage) do(req *http.Request, body []byte) error {
+	signV4(req, body, s.config.AccessKey, s.config.SecretKey, s.config.Region, s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("s3 %s %s: status %d: %s", req.Method, req.URL.Path, res.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	return nil
+}
+
+// NOTE - AWS Signature Version 4 เซ็นทุก header ที่อยู่ใน request รวม host, x-amz-date และ x-amz-content-sha256
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func signV4(req *http.Request, body []byte, accessKey string, secretKey string, region string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	day := now.Format(amzDayFormat)
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapePath(req.URL.EscapedPath()),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + region + "/" + s3Service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), day)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		sortedValues := append([]string(nil), values[key]...)
+		sort.Strings(sortedValues)
+		for _, value := range sortedValues {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+
+	return strings.ReplaceAll(strings.Join(pairs, "&"), "+", "%20")
+}
+
+// NOTE - escape ตามแบบของ S3 ตัวอักษรอื่นนอกจาก A-Z a-z 0-9 - _ . ~ / ต้องเป็น %XX
+func escapePath(path string) string {
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		unescaped = path
+	}
+
+	var escaped strings.Builder
+	for _, b := range []byte(unescaped) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') || strings.IndexByte("-_.~/", b) >= 0 {
+			escaped.WriteByte(b)
+			continue
+		}
+		fmt.Fprintf(&escaped, "%%%02X", b)
+	}
+
+	return escaped.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// NOTE - ที่เก็บไฟล์รูปสินค้า แยกเป็น interface เพื่อเปลี่ยนจาก disk ในเครื่องเป็น S3 ได้โดยไม่ต้องแก้ service
+// key เป็น path แบบ "products/1/abc.jpg" Put คืน URL ที่ใช้แสดงรูปได้เลย
+type Storage interface {
+	Put(key string, body []byte, contentType string) (string, error)
+	Delete(key string) error
+}
+
+// NOTE - ถ้าตั้ง S3_BUCKET จะเก็บใน S3 (หรือ MinIO ที่ใช้ API เดียวกัน) ไม่งั้นเก็บลง disk ที่ UPLOAD_DIR
+func NewStorage() Storage {
+	if bucket := os.Getenv("S3_BUCKET"); bucket != "" {
+		return NewS3Storage(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    bucket,
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		})
+	}
+
+	return NewLocalStorage(os.Getenv("UPLOAD_DIR"), os.Getenv("UPLOAD_BASE_URL"))
+}
+
+// NOTE - กัน key ที่พาออกนอกโฟลเดอร์ เช่น "../../etc/passwd"
+func cleanKey(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if cleaned == "" || cleaned != strings.TrimPrefix(key, "/") {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,154 @@
+package storage_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage(t *testing.T) {
+	t.Run("Put writes file and returns public URL", func(t *testing.T) {
+		dir := t.TempDir()
+		local := storage.NewLocalStorage(dir, "/uploads/")
+
+		url, err := local.Put("products/1/photo.jpg", []byte("image"), "image/jpeg")
+
+		require.NoError(t, err)
+		assert.Equal(t, "/uploads/products/1/photo.jpg", url)
+
+		body, err := os.ReadFile(filepath.Join(dir, "products", "1", "photo.jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, "image", string(body))
+	})
+
+	t.Run("Delete removes file and ignores missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		local := storage.NewLocalStorage(dir, "")
+
+		_, err := local.Put("products/1/photo.jpg", []byte("image"), "image/jpeg")
+		require.NoError(t, err)
+
+		assert.NoError(t, local.Delete("products/1/photo.jpg"))
+		assert.NoError(t, local.Delete("products/1/photo.jpg"))
+
+		_, err = os.Stat(filepath.Join(dir, "products", "1", "photo.jpg"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Reject key outside upload directory", func(t *testing.T) {
+		local := storage.NewLocalStorage(t.TempDir(), "")
+
+		_, err := local.Put("../escape.jpg", []byte("image"), "image/jpeg")
+
+		assert.ErrorIs(t, err, storage.ErrInvalidKey)
+	})
+}
+
+// NOTE - จำลอง S3 แบบ path-style เก็บ object ใน memory และเช็คว่า request ถูกเซ็นมา
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string]string{}, types: map[string]string{}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=test-access/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		sum := sha256.Sum256(body)
+		if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			fake.objects[r.URL.Path] = string(body)
+			fake.types[r.URL.Path] = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusOK)
+		case http.MethodDelete:
+			delete(fake.objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, server
+}
+
+func TestS3Storage(t *testing.T) {
+	t.Run("Put and delete object", func(t *testing.T) {
+		fake, server := newFakeS3(t)
+
+		s3 := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  server.URL,
+			Bucket:    "product-images",
+			AccessKey: "test-access",
+			SecretKey: "test-secret",
+		})
+
+		url, err := s3.Put("products/1/photo.png", []byte("image"), "image/png")
+
+		require.NoError(t, err)
+		assert.Equal(t, server.URL+"/product-images/products/1/photo.png", url)
+		assert.Equal(t, "image", fake.objects["/product-images/products/1/photo.png"])
+		assert.Equal(t, "image/png", fake.types["/product-images/products/1/photo.png"])
+
+		require.NoError(t, s3.Delete("products/1/photo.png"))
+		assert.Empty(t, fake.objects)
+	})
+
+	t.Run("Use public URL when configured", func(t *testing.T) {
+		_, server := newFakeS3(t)
+
+		s3 := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  server.URL,
+			Bucket:    "product-images",
+			AccessKey: "test-access",
+			SecretKey: "test-secret",
+			PublicURL: "https://cdn.example.com/",
+		})
+
+		url, err := s3.Put("products/1/photo.png", []byte("image"), "image/png")
+
+		require.NoError(t, err)
+		assert.Equal(t, "https://cdn.example.com/products/1/photo.png", url)
+	})
+
+	t.Run("Error status from server", func(t *testing.T) {
+		_, server := newFakeS3(t)
+
+		s3 := storage.NewS3Storage(storage.S3Config{
+			Endpoint:  server.URL,
+			Bucket:    "product-images",
+			AccessKey: "wrong-access",
+			SecretKey: "test-secret",
+		})
+
+		_, err := s3.Put("products/1/photo.png", []byte("image"), "image/png")
+
+		assert.ErrorContains(t, err, "status 403")
+	})
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// NOTE - อ่านระยะเวลาจาก env เช่น "15m", "90s" ถ้าไม่ได้ตั้งหรือรูปแบบผิดจะใช้ค่า fallback
+func DurationFromEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+
+	return duration
+}
+
+// NOTE - อ่านจำนวนเต็มบวกจาก env ถ้าไม่ได้ตั้งหรือรูปแบบผิดจะใช้ค่า fallback
+func IntFromEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+
+	return number
+}
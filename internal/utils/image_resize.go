@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"image"
+	"image/draw"
+)
+
+// NOTE - ย่อรูปให้กว้าง width โดยคงสัดส่วน ใช้ค่าเฉลี่ยของ pixel ในแต่ละช่อง (box filter) ย่อแล้วไม่แตกเป็นขั้นบันได
+// รูปที่แคบกว่า width อยู่แล้วคืนรูปเดิม ไม่ขยายให้แตก
+func ResizeImage(src image.Image, width int) image.Image {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	if width <= 0 || srcWidth <= width {
+		return src
+	}
+
+	height := (srcHeight*width + srcWidth/2) / srcWidth
+	if height < 1 {
+		height = 1
+	}
+
+	// NOTE - แปลงเป็น RGBA ก่อนครั้งเดียว อ่าน Pix ตรงๆ เร็วกว่าเรียก At ทีละ pixel มาก
+	rgba := image.NewRGBA(image.Rect(0, 0, srcWidth, srcHeight))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var r, g, b, a, count int
+			for sy := y0; sy < y1; sy++ {
+				row := rgba.Pix[sy*rgba.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += int(pixel[0])
+					g += int(pixel[1])
+					b += int(pixel[2])
+					a += int(pixel[3])
+					count++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,40 @@
+package utils_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeImage(t *testing.T) {
+	t.Run("Keep aspect ratio and average pixels", func(t *testing.T) {
+		// NOTE - ครึ่งซ้ายดำ ครึ่งขวาขาว ย่อเหลือ 2 pixel ต้องได้ดำกับขาว
+		src := image.NewRGBA(image.Rect(0, 0, 8, 4))
+		for y := 0; y < 4; y++ {
+			for x := 0; x < 8; x++ {
+				if x >= 4 {
+					src.Set(x, y, color.White)
+				} else {
+					src.Set(x, y, color.Black)
+				}
+			}
+		}
+
+		resized := utils.ResizeImage(src, 2)
+
+		assert.Equal(t, image.Rect(0, 0, 2, 1), resized.Bounds())
+		assert.Equal(t, color.RGBAModel.Convert(color.Black), resized.At(0, 0))
+		assert.Equal(t, color.RGBAModel.Convert(color.White), resized.At(1, 0))
+	})
+
+	t.Run("Do not upscale small image", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 100, 50))
+
+		resized := utils.ResizeImage(src, 400)
+
+		assert.Same(t, src, resized)
+	})
+}
@@ -0,0 +1,33 @@
+package utils
+
+import "fmt"
+
+type FakeRefund struct {
+	PaymentIntentID string
+	Amount          int64
+	Metadata        map[string]string
+}
+
+// NOTE - payment provider ปลอมสำหรับเทส เก็บ refund ที่ถูกเรียกไว้ให้ตรวจสอบ ตั้ง Err เพื่อจำลอง gateway error
+type FakePaymentProvider struct {
+	Refunds []FakeRefund
+	Err     error
+}
+
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{}
+}
+
+func (p *FakePaymentProvider) Refund(paymentIntentID string, amount int64, metadata map[string]string) (string, error) {
+	if p.Err != nil {
+		return "", p.Err
+	}
+
+	p.Refunds = append(p.Refunds, FakeRefund{
+		PaymentIntentID: paymentIntentID,
+		Amount:          amount,
+		Metadata:        metadata,
+	})
+
+	return fmt.Sprintf("re_fake_%d", len(p.Refunds)), nil
+}
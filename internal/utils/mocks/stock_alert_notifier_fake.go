@@ -0,0 +1,22 @@
+package utils
+
+import "github.com/Beluga-Whale/ecommerce-api/internal/dto"
+
+// NOTE - notifier ปลอมสำหรับเทส เก็บรายการที่ถูกแจ้งเตือนไว้ให้ตรวจสอบ ตั้ง Err เพื่อจำลองส่งไม่สำเร็จ
+type FakeStockAlertNotifier struct {
+	Notified [][]dto.LowStockVariantDTO
+	Err      error
+}
+
+func NewFakeStockAlertNotifier() *FakeStockAlertNotifier {
+	return &FakeStockAlertNotifier{}
+}
+
+func (n *FakeStockAlertNotifier) NotifyLowStock(variants []dto.LowStockVariantDTO) error {
+	if n.Err != nil {
+		return n.Err
+	}
+
+	n.Notified = append(n.Notified, variants)
+	return nil
+}
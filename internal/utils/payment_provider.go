@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"errors"
+	"os"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/refund"
+)
+
+// NOTE - แยกการคุยกับ payment gateway ออกมา เพื่อให้ service เทสได้โดยไม่ต้องเรียก Stripe จริง
+type PaymentProviderInterface interface {
+	Refund(paymentIntentID string, amount int64, metadata map[string]string) (string, error)
+}
+
+type StripePaymentProvider struct{}
+
+func NewStripePaymentProvider() *StripePaymentProvider {
+	return &StripePaymentProvider{}
+}
+
+// NOTE - amount เป็นหน่วยเล็กสุดของสกุลเงิน (cent) คืนค่า id ของ refund
+func (p *StripePaymentProvider) Refund(paymentIntentID string, amount int64, metadata map[string]string) (string, error) {
+	if paymentIntentID == "" {
+		return "", errors.New("no payment intent id")
+	}
+
+	stripe.Key = os.Getenv("STRIPE_SECRET_KEY")
+
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(paymentIntentID),
+		Amount:        stripe.Int64(amount),
+	}
+	for key, value := range metadata {
+		params.AddMetadata(key, value)
+	}
+
+	result, err := refund.New(params)
+	if err != nil {
+		return "", err
+	}
+
+	return result.ID, nil
+}
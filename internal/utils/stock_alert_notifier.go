@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+)
+
+// NOTE - ช่องทางแจ้งเตือน stock ใกล้หมด แยกออกมาเพื่อเปลี่ยนปลายทางได้ และเทสได้โดยไม่ต้องส่งจริง
+type StockAlertNotifierInterface interface {
+	NotifyLowStock(variants []dto.LowStockVariantDTO) error
+}
+
+// NOTE - ถ้าตั้ง LOW_STOCK_WEBHOOK_URL จะส่งไปที่ webhook ไม่งั้นเขียนลง log
+func NewStockAlertNotifier() StockAlertNotifierInterface {
+	if url := os.Getenv("LOW_STOCK_WEBHOOK_URL"); url != "" {
+		return NewWebhookStockAlertNotifier(url)
+	}
+
+	return NewLogStockAlertNotifier()
+}
+
+type LogStockAlertNotifier struct{}
+
+func NewLogStockAlertNotifier() *LogStockAlertNotifier {
+	return &LogStockAlertNotifier{}
+}
+
+func (n *LogStockAlertNotifier) NotifyLowStock(variants []dto.LowStockVariantDTO) error {
+	for _, variant := range variants {
+		log.Printf("Low stock: %s size %s (variant %d, sku %s) stock %d, threshold %d",
+			variant.ProductName, variant.Size, variant.VariantID, variant.SKU, variant.Stock, variant.ReorderThreshold)
+	}
+
+	return nil
+}
+
+type WebhookStockAlertNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookStockAlertNotifier(url string) *WebhookStockAlertNotifier {
+	return &WebhookStockAlertNotifier{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type lowStockWebhookPayload struct {
+	Event    string                   `json:"event"`
+	SentAt   string                   `json:"sentAt"`
+	Variants []dto.LowStockVariantDTO `json:"variants"`
+}
+
+func (n *WebhookStockAlertNotifier) NotifyLowStock(variants []dto.LowStockVariantDTO) error {
+	body, err := json.Marshal(lowStockWebhookPayload{
+		Event:    "low_stock",
+		SentAt:   time.Now().Format("2006-01-02 15:04:05"),
+		Variants: variants,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("low stock webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Beluga-Whale/ecommerce-api/internal/dto"
+	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookStockAlertNotifier(t *testing.T) {
+	variants := []dto.LowStockVariantDTO{
+		{VariantID: 2, ProductName: "T-Shirt", Size: "L", Stock: 0, ReorderThreshold: 5, OutOfStock: true},
+	}
+
+	t.Run("Post low stock payload", func(t *testing.T) {
+		var received struct {
+			Event    string                   `json:"event"`
+			Variants []dto.LowStockVariantDTO `json:"variants"`
+		}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			json.NewDecoder(r.Body).Decode(&received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := utils.NewWebhookStockAlertNotifier(server.URL).NotifyLowStock(variants)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "low_stock", received.Event)
+		assert.Equal(t, variants, received.Variants)
+	})
+
+	t.Run("Webhook error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		err := utils.NewWebhookStockAlertNotifier(server.URL).NotifyLowStock(variants)
+
+		assert.EqualError(t, err, "low stock webhook responded with status 502")
+	})
+}
+
+func TestNewStockAlertNotifier(t *testing.T) {
+	t.Run("Use log notifier without webhook url", func(t *testing.T) {
+		t.Setenv("LOW_STOCK_WEBHOOK_URL", "")
+
+		assert.IsType(t, &utils.LogStockAlertNotifier{}, utils.NewStockAlertNotifier())
+	})
+
+	t.Run("Use webhook notifier with webhook url", func(t *testing.T) {
+		t.Setenv("LOW_STOCK_WEBHOOK_URL", "http://localhost:9999/hook")
+
+		assert.IsType(t, &utils.WebhookStockAlertNotifier{}, utils.NewStockAlertNotifier())
+	})
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Beluga-Whale/ecommerce-api/config"
 	"github.com/Beluga-Whale/ecommerce-api/internal/handlers"
 	"github.com/Beluga-Whale/ecommerce-api/internal/jobs"
 	"github.com/Beluga-Whale/ecommerce-api/internal/repositories"
+	"github.com/Beluga-Whale/ecommerce-api/internal/search"
 	"github.com/Beluga-Whale/ecommerce-api/internal/services"
+	"github.com/Beluga-Whale/ecommerce-api/internal/storage"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"github.com/Beluga-Whale/ecommerce-api/routes"
 	"github.com/gofiber/fiber/v2"
@@ -23,8 +27,10 @@ func main() {
 	// NOTE - Connect DB
 	config.ConnectDB()
 
-	// NOTE - Fiber
-	app := fiber.New()
+	// NOTE - Fiber เผื่อขนาด body ให้พอกับไฟล์รูปที่อัปโหลดได้บวก multipart header
+	app := fiber.New(fiber.Config{
+		BodyLimit: services.ProductImageMaxBytes() + 1<<20,
+	})
 
 	// NOTE - Use cors
 	app.Use(cors.New(cors.Config{
@@ -40,33 +46,86 @@ func main() {
 	productRepo := repositories.NewProductRepository(config.DB)
 	orderRepo := repositories.NewOrderRepository(config.DB)
 	reviewRepo := repositories.NewReviewRepository(config.DB)
+	cartRepo := repositories.NewCartRepository(config.DB)
+	couponRepo := repositories.NewCouponRepository(config.DB)
+	paymentRepo := repositories.NewPaymentRepository(config.DB)
+	returnRepo := repositories.NewReturnRepository(config.DB)
+	shipmentRepo := repositories.NewShipmentRepository(config.DB)
+	inventoryRepo := repositories.NewInventoryRepository(config.DB)
+
+	// NOTE - Search index
+	searchIndex := search.NewPostgresSearchIndex(config.DB)
+	if err := searchIndex.Migrate(); err != nil {
+		log.Fatalf("Failed to prepare search index: %v", err)
+	}
+
+	// NOTE - ที่เก็บรูปสินค้า ถ้าเก็บลง disk ให้ server เปิดไฟล์ที่ /uploads
+	imageStorage := storage.NewStorage()
+	if localStorage, ok := imageStorage.(*storage.LocalStorage); ok {
+		app.Static("/uploads", localStorage.Dir())
+	}
 
 	// NOTE - Utilities
 	hashPassword := utils.NewPasswordUtil()
 	jwtUtil := utils.NewJwt()
 	productUtil := utils.NewProductUtil()
+	paymentProvider := utils.NewStripePaymentProvider()
+	stockAlertNotifier := utils.NewStockAlertNotifier()
 
 	// NOTE - Create Services
 	userService := services.NewUserService(userRepo,hashPassword,jwtUtil)
-	categoryService := services.NewCategoryService(categoryRepo)
-	productService := services.NewProductService(productRepo,categoryRepo)
+	categoryService := services.NewCategoryService(categoryRepo, searchIndex)
+	productService := services.NewProductService(productRepo, categoryRepo, searchIndex, imageStorage)
+	productImageService := services.NewProductImageService(productRepo, imageStorage)
 	orderService := services.NewOrderService(config.DB,orderRepo, productUtil)
 	reviewService := services.NewReviewService(reviewRepo)
+	cartService := services.NewCartService(cartRepo,orderService)
+	couponService := services.NewCouponService(couponRepo)
+	paymentService := services.NewPaymentService(paymentRepo,orderRepo,paymentProvider)
+	returnService := services.NewReturnService(config.DB,returnRepo,orderRepo,paymentProvider)
+	shipmentService := services.NewShipmentService(config.DB,shipmentRepo,orderRepo)
+	inventoryService := services.NewInventoryService(config.DB,inventoryRepo)
+
+	// NOTE - สินค้าที่สร้างก่อนมี slug ต้องมี slug ก่อนเปิดให้เข้าผ่าน URL แบบ slug
+	if err := productService.BackfillProductSlugs(); err != nil {
+		log.Fatalf("Failed to backfill product slugs: %v", err)
+	}
 	
 	// NOTE - Create Handlers
 	userHandler := handlers.NewUserHandler(userService)
 	categoryHandler := handlers.NewCategoryHandler(categoryService)
 	productHandler := handlers.NewProductHandler(productService)
+	productImageHandler := handlers.NewProductImageHandler(productImageService)
 	orderHandler := handlers.NewOrderHandler(orderService)
-	PaymentHandler := handlers.NewStripeHandler(orderService)
+	PaymentHandler := handlers.NewStripeHandler(orderService,paymentService)
 	ReviewHandler := handlers.NewReviewHandler(reviewService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	couponHandler := handlers.NewCouponHandler(couponService)
+	returnHandler := handlers.NewReturnHandler(returnService)
+	shipmentHandler := handlers.NewShipmentHandler(shipmentService)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
 
 	// NOTE - Set Up Routes
-	routes.SetUpRoutes(app ,jwtUtil,userHandler,categoryHandler,productHandler,orderHandler,PaymentHandler,ReviewHandler)
+	routes.SetUpRoutes(app ,jwtUtil,userHandler,categoryHandler,productHandler,orderHandler,PaymentHandler,ReviewHandler,cartHandler,couponHandler,returnHandler,shipmentHandler,inventoryHandler,productImageHandler)
 	
 
 	// NOTE -ทำงานเพื่อการนับถอยหลังเช็ค order
-	jobs.StartOrderExpirationJob(config.DB, orderService)
+	orderExpirationJob := jobs.StartOrderExpirationJob(orderService)
+	lowStockAlertJob := jobs.StartLowStockAlertJob(inventoryService, stockAlertNotifier)
+
+	// NOTE - รับสัญญาณปิด server ให้หยุด job ก่อนแล้วค่อยปิด fiber
+	go func() {
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+		<-quit
+
+		log.Printf("Shutting down server")
+		orderExpirationJob.Stop()
+		lowStockAlertJob.Stop()
+		if err := app.Shutdown(); err != nil {
+			log.Printf("Failed to shutdown server: %v", err)
+		}
+	}()
 
 	port := os.Getenv("PORT_API")
 
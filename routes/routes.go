@@ -5,9 +5,10 @@ import (
 	"github.com/Beluga-Whale/ecommerce-api/internal/middleware"
 	"github.com/Beluga-Whale/ecommerce-api/internal/utils"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 )
 
-func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handlers.UserHandler, categoryHandler *handlers.CategoryHandler, productHandler *handlers.ProductHandler, orderHandler *handlers.OrderHandler, paymentHandler *handlers.StripeHandler, reviewHandler *handlers.ReviewHandler ) {
+func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handlers.UserHandler, categoryHandler *handlers.CategoryHandler, productHandler *handlers.ProductHandler, orderHandler *handlers.OrderHandler, paymentHandler *handlers.StripeHandler, reviewHandler *handlers.ReviewHandler, cartHandler *handlers.CartHandler, couponHandler *handlers.CouponHandler, returnHandler *handlers.ReturnHandler, shipmentHandler *handlers.ShipmentHandler, inventoryHandler *handlers.InventoryHandler, productImageHandler *handlers.ProductImageHandler ) {
 
 
 	api := app.Group("/api")
@@ -15,18 +16,24 @@ func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handle
 	api.Post("/login",userHandler.Login)
 	api.Post("/logout",userHandler.Logout)
 	api.Get("/category", categoryHandler.GetAll)
+	api.Get("/category/tree", categoryHandler.GetTree)
 	api.Get("/product", productHandler.GetAllProducts)
+	api.Get("/product/search", productHandler.SearchProducts)
 	api.Get("/product/:id", productHandler.GetProductByID)
+	api.Get("/product/sku/:sku", productHandler.GetVariantBySKU)
+	api.Get("/product/slug/:slug", productHandler.GetProductBySlug)
 	api.Get("/user/order/:id", orderHandler.GetOrderByID)
 	api.Get("product/review-all/:id",reviewHandler.GetReviewProductAllByProductId)
 
 	// NOTE  - Payment	
-	api.Post("/stripe/payment-intent",paymentHandler.CreatePaymentIntent)
+	// NOTE - ไม่ใช้ Group เพราะ webhook ของ Stripe ต้องเรียกได้โดยไม่มี token
+	api.Post("/stripe/payment-intent", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("user"), paymentHandler.CreatePaymentIntent)
 	api.Post("/stripe/webhook", paymentHandler.Webhook)
 
 	// NOTE - Category Routes
 	protectedCategoryAdmin := api.Group("/category", middleware.AuthMiddleware(jwtUtil),middleware.RequireRole("admin"))
 	protectedCategoryAdmin.Post("/", categoryHandler.Create) 
+	protectedCategoryAdmin.Post("/merge", categoryHandler.Merge)
 	protectedCategoryAdmin.Put("/:id", categoryHandler.Update) 
 	protectedCategoryAdmin.Delete("/:id", categoryHandler.Delete)
 
@@ -35,6 +42,9 @@ func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handle
 	protectedProductAdmin.Post("/", productHandler.CreateProduct) 
 	protectedProductAdmin.Put("/:id", productHandler.UpdateProduct)
 	protectedProductAdmin.Delete("/:id", productHandler.DeleteProduct)
+	protectedProductAdmin.Post("/:id/images", productImageHandler.Upload)
+	protectedProductAdmin.Put("/:id/images/order", productImageHandler.Reorder)
+	protectedProductAdmin.Delete("/:id/images/:imageId", productImageHandler.Delete)
 
 	// NOTE - Order Route
 	// NOTE - User use createOnly
@@ -43,6 +53,8 @@ func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handle
 	protectedOrderUser.Patch("/", orderHandler.UpdateStatusOrder)
 	protectedOrderUser.Get("/",orderHandler.GetAllOrderByUserId)
 	protectedOrderUser.Patch("/:id/status",orderHandler.UpdateOrderStatusByUser)	
+	protectedOrderUser.Post("/:id/returns",returnHandler.CreateReturn)
+	protectedOrderUser.Get("/:id/timeline",orderHandler.GetOrderTimeline)
 	
 	
 	// NOTE - Admin use Order
@@ -50,6 +62,10 @@ func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handle
 	protectedOrderAdmin.Get("/",orderHandler.GetAllOrders)
 	protectedOrderAdmin.Patch("/:id/status",orderHandler.UpdateOrderStatusByAdmin)
 	protectedOrderAdmin.Delete("/:id",orderHandler.DeleteOrder)
+	protectedOrderAdmin.Get("/:id/payments",paymentHandler.GetPaymentsByOrderID)
+	protectedOrderAdmin.Get("/:id/timeline",orderHandler.GetOrderTimelineAdmin)
+	protectedOrderAdmin.Post("/:id/shipments",shipmentHandler.CreateShipment)
+	protectedOrderAdmin.Get("/:id/shipments",shipmentHandler.GetShipmentsByOrderID)
 
 	// NOTE - Admin dashBoard
 	protectedDashboardAdmin := api.Group("/admin/dashboard", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"))
@@ -67,4 +83,46 @@ func SetUpRoutes(app *fiber.App, jwtUtil utils.JwtInterface, userHandler *handle
 	protectedReviewUser := api.Group("/user/review", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("user"))
 	protectedReviewUser.Get("/",reviewHandler.GetUserReviews)
 	protectedReviewUser.Post("/",reviewHandler.CreateReviews)
-}
\ No newline at end of file
+
+	// NOTE - Cart
+	protectedCartUser := api.Group("/user/cart", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("user"))
+	protectedCartUser.Get("/",cartHandler.GetCart)
+	protectedCartUser.Post("/",cartHandler.AddItem)
+	protectedCartUser.Delete("/",cartHandler.ClearCart)
+	protectedCartUser.Post("/checkout",cartHandler.Checkout)
+	protectedCartUser.Patch("/:id",cartHandler.UpdateItemQuantity)
+	protectedCartUser.Delete("/:id",cartHandler.RemoveItem)
+
+	// NOTE - Admin Coupon
+	protectedCouponAdmin := api.Group("/admin/coupon", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"))
+	protectedCouponAdmin.Get("/",couponHandler.GetAll)
+	protectedCouponAdmin.Post("/",couponHandler.Create)
+	protectedCouponAdmin.Get("/:id",couponHandler.GetByID)
+	protectedCouponAdmin.Put("/:id",couponHandler.Update)
+	protectedCouponAdmin.Delete("/:id",couponHandler.Delete)
+	protectedCouponAdmin.Get("/:id/redemptions",couponHandler.GetRedemptions)
+
+	// NOTE - Return / Refund
+	protectedReturnUser := api.Group("/user/returns", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("user"))
+	protectedReturnUser.Get("/",returnHandler.GetMyReturns)
+
+	protectedReturnAdmin := api.Group("/admin/returns", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"))
+	protectedReturnAdmin.Get("/",returnHandler.GetAllReturns)
+	protectedReturnAdmin.Patch("/:id/approve",returnHandler.ApproveReturn)
+	protectedReturnAdmin.Patch("/:id/reject",returnHandler.RejectReturn)
+
+	// NOTE - Shipment
+	protectedShipmentAdmin := api.Group("/admin/shipments", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"))
+	protectedShipmentAdmin.Patch("/:id",shipmentHandler.UpdateShipment)
+
+	// NOTE - Inventory ledger
+	protectedInventoryAdmin := api.Group("/admin/inventory", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"))
+	protectedInventoryAdmin.Get("/variants/:id/movements",inventoryHandler.GetMovements)
+	protectedInventoryAdmin.Get("/low-stock",inventoryHandler.GetLowStockReport)
+	protectedInventoryAdmin.Post("/variants/:id/adjustments",inventoryHandler.AdjustStock)
+	protectedInventoryAdmin.Patch("/variants/:id/threshold",inventoryHandler.UpdateReorderThreshold)
+	protectedInventoryAdmin.Patch("/sku/:sku",inventoryHandler.UpdateVariantBySKU)
+
+	// NOTE - Monitoring ตัวนับของ job ให้เฉพาะ admin อ่าน ไม่เปิด /debug/vars ให้ใครก็เรียกได้
+	app.Get("/debug/vars", middleware.AuthMiddleware(jwtUtil), middleware.RequireRole("admin"), expvar.New())
+}